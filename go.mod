@@ -1,9 +1,11 @@
 module github.com/drumkitai/go-word
 
-go 1.19
+go 1.21
 
 toolchain go1.24.11
 
 require github.com/yuin/goldmark v1.7.8
 
 require github.com/litao91/goldmark-mathjax v0.0.0-20210217064022-a43cf739a50f
+
+require golang.org/x/image v0.18.0
@@ -0,0 +1,824 @@
+// Package document provides Word document citations and bibliography
+package document
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bibliographyNamespace is the namespace of the customXml part holding the sources
+const bibliographyNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/bibliography"
+
+// SourceType type of a bibliography source
+type SourceType string
+
+const (
+	// SourceBook - book
+	SourceBook SourceType = "Book"
+	// SourceJournalArticle - journal article
+	SourceJournalArticle SourceType = "JournalArticle"
+	// SourceWebsite - web site
+	SourceWebsite SourceType = "InternetSite"
+	// SourceReport - report
+	SourceReport SourceType = "Report"
+)
+
+// BibliographyStyle citation and bibliography style
+type BibliographyStyle string
+
+const (
+	// BibliographyAPA - APA author-date style
+	BibliographyAPA BibliographyStyle = "APA"
+	// BibliographyIEEE - IEEE numbered style
+	BibliographyIEEE BibliographyStyle = "IEEE"
+	// BibliographyChicago - Chicago author-date style
+	BibliographyChicago BibliographyStyle = "Chicago"
+)
+
+// bibliographyStyleSheets maps styles to the style sheet and version Word selects for them
+var bibliographyStyleSheets = map[BibliographyStyle][2]string{
+	BibliographyAPA:     {`\APASixthEditionOfficeOnline.xsl`, "6"},
+	BibliographyIEEE:    {`\IEEE2006OfficeOnline.xsl`, "2006"},
+	BibliographyChicago: {`\CHICAGO.XSL`, "16"},
+}
+
+// SourceAuthor author of a bibliography source
+type SourceAuthor struct {
+	Last   string `xml:"Last,omitempty"`
+	First  string `xml:"First,omitempty"`
+	Middle string `xml:"Middle,omitempty"`
+}
+
+// BibliographySource source that can be cited in the document
+type BibliographySource struct {
+	// Tag identifies the source in citations
+	Tag     string
+	Type    SourceType
+	Authors []SourceAuthor
+	Title   string
+	Year    string
+	// Publisher and City apply to books and reports
+	Publisher string
+	City      string
+	// JournalName, Volume, Issue and Pages apply to journal articles
+	JournalName string
+	Volume      string
+	Issue       string
+	Pages       string
+	// SiteName and Accessed apply to web sites
+	SiteName string
+	Accessed time.Time
+	URL      string
+}
+
+// bibliographySources is the customXml part Word stores the sources in
+type bibliographySources struct {
+	XMLName       xml.Name `xml:"Sources"`
+	Xmlns         string   `xml:"xmlns,attr"`
+	SelectedStyle string   `xml:"SelectedStyle,attr,omitempty"`
+	StyleName     string   `xml:"StyleName,attr,omitempty"`
+	Version       string   `xml:"Version,attr,omitempty"`
+	// Namespaces keeps the prefixes the sources read from the document use
+	Namespaces []xml.Attr               `xml:",any,attr"`
+	Sources    []*bibliographyXMLSource `xml:"Source"`
+}
+
+// bibliographyXMLSource is a source in the customXml part
+type bibliographyXMLSource struct {
+	Tag               string         `xml:"Tag"`
+	SourceType        string         `xml:"SourceType"`
+	GUID              string         `xml:"Guid,omitempty"`
+	Authors           []SourceAuthor `xml:"Author>Author>NameList>Person"`
+	Title             string         `xml:"Title,omitempty"`
+	Year              string         `xml:"Year,omitempty"`
+	City              string         `xml:"City,omitempty"`
+	Publisher         string         `xml:"Publisher,omitempty"`
+	JournalName       string         `xml:"JournalName,omitempty"`
+	Volume            string         `xml:"Volume,omitempty"`
+	Issue             string         `xml:"Issue,omitempty"`
+	Pages             string         `xml:"Pages,omitempty"`
+	InternetSiteTitle string         `xml:"InternetSiteTitle,omitempty"`
+	YearAccessed      string         `xml:"YearAccessed,omitempty"`
+	MonthAccessed     string         `xml:"MonthAccessed,omitempty"`
+	DayAccessed       string         `xml:"DayAccessed,omitempty"`
+	URL               string         `xml:"URL,omitempty"`
+	// Raw is the content of a source read from the document, written back as read to keep the
+	// fields this package does not model
+	Raw string `xml:",innerxml"`
+}
+
+// MarshalXML writes a source read from the document as read and a new source from its fields
+func (s *bibliographyXMLSource) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if s.Raw != "" {
+		return e.EncodeElement(struct {
+			Raw string `xml:",innerxml"`
+		}{s.Raw}, start)
+	}
+	type source bibliographyXMLSource
+	return e.EncodeElement((*source)(s), start)
+}
+
+// bibliographyItemProps is the properties part of the bibliography customXml part
+type bibliographyItemProps struct {
+	XMLName xml.Name `xml:"ds:datastoreItem"`
+	ItemID  string   `xml:"ds:itemID,attr"`
+	Xmlns   string   `xml:"xmlns:ds,attr"`
+	Schema  struct {
+		URI string `xml:"ds:uri,attr"`
+	} `xml:"ds:schemaRefs>ds:schemaRef"`
+}
+
+// AddSource registers a source that can be cited with Paragraph.AddCitation
+func (d *Document) AddSource(source *BibliographySource) error {
+	if source == nil {
+		return NewValidationError("source", "", "source cannot be nil")
+	}
+	if source.Tag == "" || strings.ContainsAny(source.Tag, " \t\n\\\"") {
+		return NewValidationError("tag", source.Tag, "tag must be a single word")
+	}
+	switch source.Type {
+	case SourceBook, SourceJournalArticle, SourceWebsite, SourceReport:
+	default:
+		return NewValidationError("type", string(source.Type), "unsupported source type")
+	}
+	if source.Title == "" {
+		return NewValidationError("title", "", "title cannot be empty")
+	}
+
+	sources, err := d.loadBibliography()
+	if err != nil {
+		return WrapError("add_source", err)
+	}
+	for _, s := range sources.Sources {
+		if strings.EqualFold(s.Tag, source.Tag) {
+			return NewValidationError("tag", source.Tag, "source already exists")
+		}
+	}
+
+	s := &bibliographyXMLSource{
+		Tag:               source.Tag,
+		SourceType:        string(source.Type),
+		GUID:              newGUID(),
+		Authors:           source.Authors,
+		Title:             source.Title,
+		Year:              source.Year,
+		City:              source.City,
+		Publisher:         source.Publisher,
+		JournalName:       source.JournalName,
+		Volume:            source.Volume,
+		Issue:             source.Issue,
+		Pages:             source.Pages,
+		InternetSiteTitle: source.SiteName,
+		URL:               source.URL,
+	}
+	if !source.Accessed.IsZero() {
+		s.YearAccessed = strconv.Itoa(source.Accessed.Year())
+		s.MonthAccessed = source.Accessed.Month().String()
+		s.DayAccessed = strconv.Itoa(source.Accessed.Day())
+	}
+	sources.Sources = append(sources.Sources, s)
+	if err := d.storeBibliography(sources); err != nil {
+		return WrapError("add_source", err)
+	}
+
+	Infof("added bibliography source %s", source.Tag)
+	return nil
+}
+
+// Sources returns the bibliography sources of the document
+func (d *Document) Sources() []*BibliographySource {
+	sources, err := d.loadBibliography()
+	if err != nil {
+		Errorf("failed to read bibliography sources: %v", err)
+		return nil
+	}
+
+	result := make([]*BibliographySource, 0, len(sources.Sources))
+	for _, s := range sources.Sources {
+		source := &BibliographySource{
+			Tag:         s.Tag,
+			Type:        SourceType(s.SourceType),
+			Authors:     s.Authors,
+			Title:       s.Title,
+			Year:        s.Year,
+			Publisher:   s.Publisher,
+			City:        s.City,
+			JournalName: s.JournalName,
+			Volume:      s.Volume,
+			Issue:       s.Issue,
+			Pages:       s.Pages,
+			SiteName:    s.InternetSiteTitle,
+			URL:         s.URL,
+		}
+		if s.YearAccessed != "" {
+			date := strings.Join([]string{s.YearAccessed, s.MonthAccessed, s.DayAccessed}, " ")
+			if accessed, err := time.Parse("2006 January 2", date); err == nil {
+				source.Accessed = accessed
+			}
+		}
+		result = append(result, source)
+	}
+	return result
+}
+
+// SetBibliographyStyle sets the style of citations and of the bibliography
+func (d *Document) SetBibliographyStyle(style BibliographyStyle) error {
+	if _, ok := bibliographyStyleSheets[style]; !ok {
+		return NewValidationError("style", string(style), "unsupported bibliography style")
+	}
+	sources, err := d.loadBibliography()
+	if err != nil {
+		return WrapError("set_bibliography_style", err)
+	}
+	sources.StyleName = string(style)
+	return d.storeBibliography(sources)
+}
+
+// GetBibliographyStyle returns the bibliography style, APA when none is set
+func (d *Document) GetBibliographyStyle() BibliographyStyle {
+	sources, err := d.loadBibliography()
+	if err == nil {
+		for style := range bibliographyStyleSheets {
+			if strings.EqualFold(sources.StyleName, string(style)) {
+				return style
+			}
+		}
+	}
+	return BibliographyAPA
+}
+
+// AddCitation adds a CITATION field for a source, page may be empty
+// The field shows the source tag until Document.UpdateCitations or Document.AddBibliography runs
+func (p *Paragraph) AddCitation(sourceTag string, page string) {
+	instruction := " CITATION " + sourceTag + " "
+	if page != "" {
+		instruction += `\p ` + page + " "
+	}
+	p.Runs = append(p.Runs,
+		Run{FieldChar: &FieldChar{FieldCharType: "begin"}},
+		Run{InstrText: &InstrText{Space: "preserve", Content: instruction}},
+		Run{FieldChar: &FieldChar{FieldCharType: "separate"}},
+		Run{Text: Text{Content: "(" + sourceTag + ")", Space: "preserve"}},
+		Run{FieldChar: &FieldChar{FieldCharType: "end"}},
+	)
+}
+
+// UpdateCitations formats the results of all CITATION fields in the bibliography style
+func (d *Document) UpdateCitations() error {
+	style := d.GetBibliographyStyle()
+	sources := d.Sources()
+	numbers := d.citationNumbers(sources)
+
+	var err error
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		err = updateParagraphCitations(p, func(tag, page string) (string, error) {
+			source := findSource(sources, tag)
+			if source == nil {
+				return "", NewValidationError("tag", tag, "cited source not found")
+			}
+			return formatCitation(style, source, numbers[source.Tag], page), nil
+		})
+		return err == nil
+	})
+	return err
+}
+
+// AddBibliography updates the citations and appends the bibliography with an optional heading
+func (d *Document) AddBibliography(title string) error {
+	sources := d.Sources()
+	if len(sources) == 0 {
+		return NewValidationError("sources", "", "no bibliography sources")
+	}
+	if err := d.UpdateCitations(); err != nil {
+		return WrapError("add_bibliography", err)
+	}
+
+	style := d.GetBibliographyStyle()
+	numbers := d.citationNumbers(sources)
+	if style == BibliographyIEEE {
+		sort.SliceStable(sources, func(i, j int) bool { return numbers[sources[i].Tag] < numbers[sources[j].Tag] })
+	} else {
+		sort.SliceStable(sources, func(i, j int) bool {
+			return strings.ToLower(sourceSortKey(sources[i])) < strings.ToLower(sourceSortKey(sources[j]))
+		})
+	}
+
+	if title != "" {
+		d.AddHeadingParagraph(title, 1)
+	}
+	for i, source := range sources {
+		p := &Paragraph{
+			Properties: &ParagraphProperties{
+				Indentation: &Indentation{Left: "720", Hanging: "720"},
+			},
+		}
+		if i == 0 {
+			p.Runs = append(p.Runs,
+				Run{FieldChar: &FieldChar{FieldCharType: "begin"}},
+				Run{InstrText: &InstrText{Space: "preserve", Content: " BIBLIOGRAPHY "}},
+				Run{FieldChar: &FieldChar{FieldCharType: "separate"}},
+			)
+		}
+		for _, segment := range formatBibliographyEntry(style, source, numbers[source.Tag]) {
+			if segment.italic {
+				p.AddFormattedText(segment.text, &TextFormat{Italic: true})
+			} else {
+				p.Runs = append(p.Runs, Run{Text: Text{Content: segment.text, Space: "preserve"}})
+			}
+		}
+		if i == len(sources)-1 {
+			p.Runs = append(p.Runs, Run{FieldChar: &FieldChar{FieldCharType: "end"}})
+		}
+		d.Body.Elements = append(d.Body.Elements, p)
+	}
+
+	Infof("added bibliography with %d sources", len(sources))
+	return nil
+}
+
+// citationNumbers numbers sources by first citation, then uncited sources in registration order
+func (d *Document) citationNumbers(sources []*BibliographySource) map[string]int {
+	numbers := make(map[string]int)
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		for _, tag := range paragraphCitationTags(p) {
+			if source := findSource(sources, tag); source != nil && numbers[source.Tag] == 0 {
+				numbers[source.Tag] = len(numbers) + 1
+			}
+		}
+		return true
+	})
+	for _, source := range sources {
+		if numbers[source.Tag] == 0 {
+			numbers[source.Tag] = len(numbers) + 1
+		}
+	}
+	return numbers
+}
+
+// findSource returns the source with a tag, tags are case insensitive as in Word
+func findSource(sources []*BibliographySource, tag string) *BibliographySource {
+	for _, source := range sources {
+		if strings.EqualFold(source.Tag, tag) {
+			return source
+		}
+	}
+	return nil
+}
+
+// paragraphCitationTags returns the source tags of the CITATION fields of a paragraph in order
+func paragraphCitationTags(p *Paragraph) []string {
+	var tags []string
+	for _, instruction := range paragraphFieldInstructions(p) {
+		if tag, _, ok := parseCitationInstruction(instruction); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// updateParagraphCitations replaces the result of the CITATION fields of a paragraph by the text of format
+func updateParagraphCitations(p *Paragraph, format func(tag, page string) (string, error)) error {
+	for i := 0; i < len(p.Runs); i++ {
+		if fc := p.Runs[i].FieldChar; fc == nil || fc.FieldCharType != "begin" {
+			continue
+		}
+		var instruction strings.Builder
+		separate, end := -1, -1
+		for j := i + 1; j < len(p.Runs) && end < 0; j++ {
+			run := &p.Runs[j]
+			switch {
+			case run.InstrText != nil && separate < 0:
+				instruction.WriteString(run.InstrText.Content)
+			case run.FieldChar != nil && run.FieldChar.FieldCharType == "separate":
+				separate = j
+			case run.FieldChar != nil && run.FieldChar.FieldCharType == "end":
+				end = j
+			}
+		}
+		if end < 0 {
+			return nil
+		}
+		tag, page, ok := parseCitationInstruction(instruction.String())
+		if !ok {
+			i = end
+			continue
+		}
+		text, err := format(tag, page)
+		if err != nil {
+			return err
+		}
+
+		result := Run{Text: Text{Content: text, Space: "preserve"}}
+		var runs []Run
+		if separate < 0 {
+			runs = append(append(runs, p.Runs[:end]...), Run{FieldChar: &FieldChar{FieldCharType: "separate"}})
+		} else {
+			if separate+1 < end {
+				result.Properties = p.Runs[separate+1].Properties
+			}
+			runs = append(runs, p.Runs[:separate+1]...)
+		}
+		runs = append(runs, result)
+		i = len(runs)
+		p.Runs = append(runs, p.Runs[end:]...)
+	}
+	return nil
+}
+
+// parseCitationInstruction returns the source tag and page of a CITATION field instruction
+func parseCitationInstruction(instruction string) (string, string, bool) {
+	fields := strings.Fields(instruction)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "CITATION") {
+		return "", "", false
+	}
+	page := ""
+	for k := 2; k+1 < len(fields); k++ {
+		if strings.EqualFold(fields[k], `\p`) {
+			page = fields[k+1]
+		}
+	}
+	return fields[1], page, true
+}
+
+// formatCitation formats the result of a citation in a style
+func formatCitation(style BibliographyStyle, source *BibliographySource, number int, page string) string {
+	pagePrefix := "p. "
+	if strings.ContainsAny(page, "-–,") {
+		pagePrefix = "pp. "
+	}
+	switch style {
+	case BibliographyIEEE:
+		if page != "" {
+			return fmt.Sprintf("[%d, %s%s]", number, pagePrefix, page)
+		}
+		return fmt.Sprintf("[%d]", number)
+	case BibliographyChicago:
+		return "(" + joinNonEmpty(", ", citationNames(source, " and ")+" "+sourceYear(source), page) + ")"
+	default:
+		if page != "" {
+			page = pagePrefix + page
+		}
+		return "(" + joinNonEmpty(", ", citationNames(source, " & "), sourceYear(source), page) + ")"
+	}
+}
+
+// citationNames returns the author names of an author-date citation, or the title without authors
+func citationNames(source *BibliographySource, and string) string {
+	authors := source.Authors
+	switch {
+	case len(authors) == 0:
+		return source.Title
+	case len(authors) == 1:
+		return authors[0].Last
+	case len(authors) == 2:
+		return authors[0].Last + and + authors[1].Last
+	default:
+		return authors[0].Last + " et al."
+	}
+}
+
+// entrySegment is a run of text in a bibliography entry
+type entrySegment struct {
+	text   string
+	italic bool
+}
+
+// entryBuilder collects the segments of a bibliography entry, skipping empty text
+type entryBuilder []entrySegment
+
+func (e *entryBuilder) add(text string, italic bool) {
+	if text != "" {
+		*e = append(*e, entrySegment{text: text, italic: italic})
+	}
+}
+
+// formatBibliographyEntry formats a source as bibliography entry segments in a style
+func formatBibliographyEntry(style BibliographyStyle, source *BibliographySource, number int) []entrySegment {
+	var e entryBuilder
+	switch style {
+	case BibliographyIEEE:
+		formatIEEEEntry(&e, source, number)
+	case BibliographyChicago:
+		formatChicagoEntry(&e, source)
+	default:
+		formatAPAEntry(&e, source)
+	}
+	return e
+}
+
+// formatAPAEntry formats an entry as "Last, F. (Year). Title. Publisher."
+func formatAPAEntry(e *entryBuilder, s *BibliographySource) {
+	italicTitle := s.Type != SourceJournalArticle
+	if len(s.Authors) > 0 {
+		e.add(listAuthors(s.Authors, ", & ", ", & ", func(a SourceAuthor, _ int) string {
+			return joinNonEmpty(", ", a.Last, initials(a))
+		})+" ("+sourceYear(s)+"). ", false)
+		e.add(s.Title, italicTitle)
+		e.add(".", false)
+	} else {
+		e.add(s.Title, italicTitle)
+		e.add(". ("+sourceYear(s)+").", false)
+	}
+
+	switch s.Type {
+	case SourceJournalArticle:
+		if s.JournalName != "" {
+			e.add(" ", false)
+			e.add(s.JournalName, true)
+			if s.Volume != "" {
+				e.add(", ", false)
+				e.add(s.Volume, true)
+			}
+			if s.Issue != "" {
+				e.add("("+s.Issue+")", false)
+			}
+			if s.Pages != "" {
+				e.add(", "+s.Pages, false)
+			}
+			e.add(".", false)
+		}
+	case SourceWebsite:
+		if s.SiteName != "" {
+			e.add(" "+s.SiteName+".", false)
+		}
+	default:
+		if s.Publisher != "" {
+			e.add(" "+s.Publisher+".", false)
+		}
+	}
+	if s.URL != "" {
+		e.add(" "+s.URL, false)
+	}
+}
+
+// formatIEEEEntry formats an entry as `[n] F. Last, "Title," Journal, vol. V, no. I, pp. P, Year.`
+func formatIEEEEntry(e *entryBuilder, s *BibliographySource, number int) {
+	prefix := fmt.Sprintf("[%d] ", number)
+	if len(s.Authors) > 0 {
+		prefix += listAuthors(s.Authors, " and ", ", and ", func(a SourceAuthor, _ int) string {
+			return joinNonEmpty(" ", initials(a), a.Last)
+		}) + ", "
+	}
+
+	switch s.Type {
+	case SourceJournalArticle:
+		e.add(prefix+`"`+s.Title+`," `, false)
+		e.add(s.JournalName, true)
+		rest := joinNonEmpty(", ", prefixed("vol. ", s.Volume), prefixed("no. ", s.Issue), prefixed("pp. ", s.Pages), s.Year)
+		if s.JournalName != "" && rest != "" {
+			rest = ", " + rest
+		}
+		e.add(rest+".", false)
+	case SourceWebsite:
+		e.add(prefix+`"`+s.Title+`,"`, false)
+		if s.SiteName != "" {
+			e.add(" ", false)
+			e.add(s.SiteName, true)
+			e.add(".", false)
+		}
+		e.add(prefixed(" [Online]. Available: ", s.URL), false)
+		return
+	case SourceReport:
+		e.add(prefix+`"`+s.Title+`," `+joinNonEmpty(", ", s.Publisher, s.City, "Rep.", s.Year)+".", false)
+	default:
+		e.add(prefix, false)
+		e.add(s.Title, true)
+		e.add(". "+joinNonEmpty(", ", joinNonEmpty(": ", s.City, s.Publisher), s.Year)+".", false)
+	}
+	if s.URL != "" {
+		e.add(" [Online]. Available: "+s.URL, false)
+	}
+}
+
+// formatChicagoEntry formats an entry as "Last, First. Year. Title. City: Publisher."
+func formatChicagoEntry(e *entryBuilder, s *BibliographySource) {
+	quoteTitle := s.Type == SourceJournalArticle || s.Type == SourceWebsite
+	title := func() {
+		if quoteTitle {
+			e.add(`"`+s.Title+`."`, false)
+		} else {
+			e.add(s.Title, true)
+			e.add(".", false)
+		}
+	}
+	if len(s.Authors) > 0 {
+		e.add(listAuthors(s.Authors, ", and ", ", and ", func(a SourceAuthor, i int) string {
+			first := joinNonEmpty(" ", a.First, a.Middle)
+			if i == 0 {
+				return joinNonEmpty(", ", a.Last, first)
+			}
+			return joinNonEmpty(" ", first, a.Last)
+		})+". "+sourceYear(s)+". ", false)
+		title()
+	} else {
+		title()
+		e.add(" "+sourceYear(s)+".", false)
+	}
+
+	switch s.Type {
+	case SourceJournalArticle:
+		if s.JournalName != "" {
+			e.add(" ", false)
+			e.add(s.JournalName, true)
+			issue := ""
+			if s.Issue != "" {
+				issue = " (" + s.Issue + ")"
+			}
+			e.add(prefixed(" ", s.Volume)+issue+prefixed(": ", s.Pages)+".", false)
+		}
+	case SourceWebsite:
+		if s.SiteName != "" {
+			e.add(" "+s.SiteName+".", false)
+		}
+	default:
+		if place := joinNonEmpty(": ", s.City, s.Publisher); place != "" {
+			e.add(" "+place+".", false)
+		}
+	}
+	if s.URL != "" {
+		e.add(" "+s.URL+".", false)
+	}
+}
+
+// listAuthors joins formatted author names, using pair between two names and last before the final name of longer lists
+func listAuthors(authors []SourceAuthor, pair, last string, format func(a SourceAuthor, index int) string) string {
+	names := make([]string, len(authors))
+	for i, a := range authors {
+		names[i] = format(a, i)
+	}
+	if len(names) == 2 {
+		return names[0] + pair + names[1]
+	}
+	if len(names) > 2 {
+		return strings.Join(names[:len(names)-1], ", ") + last + names[len(names)-1]
+	}
+	return names[0]
+}
+
+// initials returns the initials of the first and middle names of an author, as "J. M."
+func initials(a SourceAuthor) string {
+	var parts []string
+	for _, name := range strings.Fields(a.First + " " + a.Middle) {
+		parts = append(parts, string([]rune(name)[:1])+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// sourceYear returns the year of a source, "n.d." when unknown
+func sourceYear(s *BibliographySource) string {
+	if s.Year == "" {
+		return "n.d."
+	}
+	return s.Year
+}
+
+// sourceSortKey returns the key sorting alphabetical bibliographies
+func sourceSortKey(s *BibliographySource) string {
+	if len(s.Authors) > 0 {
+		return s.Authors[0].Last + " " + s.Authors[0].First + " " + s.Title
+	}
+	return s.Title
+}
+
+// prefixed returns prefix followed by value, or "" for an empty value
+func prefixed(prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	return prefix + value
+}
+
+// joinNonEmpty joins the non-empty values with sep
+func joinNonEmpty(sep string, values ...string) string {
+	var parts []string
+	for _, v := range values {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// bibliographyPart returns the name of the customXml part holding the sources, "" if there is none
+func (d *Document) bibliographyPart() string {
+	var names []string
+	for name, data := range d.parts {
+		if strings.HasPrefix(name, "customXml/item") && !strings.HasPrefix(name, "customXml/itemProps") &&
+			strings.HasSuffix(name, ".xml") && bytes.Contains(data, []byte(bibliographyNamespace)) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// loadBibliography parses the bibliography part, returning an empty one when there is none
+func (d *Document) loadBibliography() (*bibliographySources, error) {
+	sources := &bibliographySources{}
+	part := d.bibliographyPart()
+	if part == "" {
+		return sources, nil
+	}
+	if err := xml.Unmarshal(d.parts[part], sources); err != nil {
+		return nil, WrapErrorWithContext("parse_bibliography", err, part)
+	}
+	namespaces := sources.Namespaces[:0]
+	for _, attr := range sources.Namespaces {
+		if attr.Name.Space == "xmlns" {
+			namespaces = append(namespaces, xml.Attr{Name: xml.Name{Local: "xmlns:" + attr.Name.Local}, Value: attr.Value})
+		}
+	}
+	sources.Namespaces = namespaces
+	return sources, nil
+}
+
+// storeBibliography writes the bibliography part, creating it with its properties and relationships
+func (d *Document) storeBibliography(sources *bibliographySources) error {
+	if sources.StyleName == "" {
+		sources.StyleName = string(BibliographyAPA)
+	}
+	for style, sheet := range bibliographyStyleSheets {
+		if strings.EqualFold(sources.StyleName, string(style)) {
+			sources.StyleName = string(style)
+			sources.SelectedStyle, sources.Version = sheet[0], sheet[1]
+		}
+	}
+	sources.Xmlns = bibliographyNamespace
+	data, err := xml.MarshalIndent(sources, "", "  ")
+	if err != nil {
+		return WrapError("marshal_bibliography", err)
+	}
+
+	part := d.bibliographyPart()
+	if part == "" {
+		number := 1
+		for {
+			if _, exists := d.parts[fmt.Sprintf("customXml/item%d.xml", number)]; !exists {
+				break
+			}
+			number++
+		}
+		part = fmt.Sprintf("customXml/item%d.xml", number)
+		if err := d.addBibliographyItemProps(part, number); err != nil {
+			return err
+		}
+	}
+	d.parts[part] = append([]byte(xml.Header), data...)
+	return nil
+}
+
+// addBibliographyItemProps adds the properties part of a new bibliography part and relates both parts
+func (d *Document) addBibliographyItemProps(part string, number int) error {
+	props := &bibliographyItemProps{
+		ItemID: newGUID(),
+		Xmlns:  "http://schemas.openxmlformats.org/officeDocument/2006/customXml",
+	}
+	props.Schema.URI = bibliographyNamespace
+	data, err := xml.MarshalIndent(props, "", "  ")
+	if err != nil {
+		return WrapError("marshal_bibliography_properties", err)
+	}
+	propsPart := fmt.Sprintf("customXml/itemProps%d.xml", number)
+	d.parts[propsPart] = append([]byte(xml.Header), data...)
+	d.addContentType(propsPart, "application/vnd.openxmlformats-officedocument.customXmlProperties+xml")
+	if err := d.storePartRelationships(part, &Relationships{Relationships: []Relationship{{
+		ID:     "rId1",
+		Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXmlProps",
+		Target: fmt.Sprintf("itemProps%d.xml", number),
+	}}}); err != nil {
+		return err
+	}
+
+	if d.documentRelationships == nil {
+		d.documentRelationships = &Relationships{
+			Xmlns:         "http://schemas.openxmlformats.org/package/2006/relationships",
+			Relationships: []Relationship{},
+		}
+	}
+	d.documentRelationships.Relationships = append(d.documentRelationships.Relationships, Relationship{
+		ID:     fmt.Sprintf("rId%d", len(d.documentRelationships.Relationships)+2),
+		Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXml",
+		Target: "../" + part,
+	})
+	return nil
+}
+
+// newGUID returns a random GUID in braces, as Word writes them
+func newGUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "{00000000-0000-4000-8000-000000000000}"
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("{%X-%X-%X-%X-%X}", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,218 @@
+package document
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// addTestSources registers a book, an article and a web site
+func addTestSources(t *testing.T, doc *Document) {
+	sources := []*BibliographySource{
+		{
+			Tag: "Smi20", Type: SourceBook, Title: "Practical Research",
+			Authors: []SourceAuthor{{Last: "Smith", First: "John", Middle: "Michael"}},
+			Year:    "2020", Publisher: "Academic Press", City: "Boston",
+		},
+		{
+			Tag: "Doe19", Type: SourceJournalArticle, Title: "Measuring change",
+			Authors: []SourceAuthor{{Last: "Doe", First: "Jane"}, {Last: "Roe", First: "Richard"}},
+			Year:    "2019", JournalName: "Journal of Methods", Volume: "12", Issue: "3", Pages: "45-67",
+		},
+		{
+			Tag: "Web21", Type: SourceWebsite, Title: "Open data portal", SiteName: "Data Office",
+			Year: "2021", URL: "https://example.com/data", Accessed: time.Date(2021, time.March, 4, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, source := range sources {
+		if err := doc.AddSource(source); err != nil {
+			t.Fatalf("failed to add source %s: %v", source.Tag, err)
+		}
+	}
+}
+
+// TestAddSource tests storing sources in the bibliography part
+func TestAddSource(t *testing.T) {
+	doc := New()
+	addTestSources(t, doc)
+
+	part := doc.bibliographyPart()
+	if part != "customXml/item1.xml" {
+		t.Fatalf("unexpected bibliography part %q", part)
+	}
+	content := string(doc.parts[part])
+	for _, expected := range []string{
+		`<Sources xmlns="http://schemas.openxmlformats.org/officeDocument/2006/bibliography" SelectedStyle="\APASixthEditionOfficeOnline.xsl" StyleName="APA" Version="6">`,
+		`<Author><Author><NameList><Person><Last>Smith</Last><First>John</First><Middle>Michael</Middle></Person></NameList></Author></Author>`,
+		`<SourceType>JournalArticle</SourceType>`,
+		`<MonthAccessed>March</MonthAccessed>`,
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(content), ""), strings.Join(strings.Fields(expected), "")) {
+			t.Errorf("bibliography part should contain %s", expected)
+		}
+	}
+	if !strings.Contains(string(doc.parts["customXml/itemProps1.xml"]), bibliographyNamespace) ||
+		!strings.Contains(string(doc.parts["customXml/_rels/item1.xml.rels"]), `Target="itemProps1.xml"`) {
+		t.Error("bibliography properties part should be related")
+	}
+
+	filename := "test_bibliography_sources.docx"
+	defer os.Remove(filename)
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	sources := opened.Sources()
+	if len(sources) != 3 || sources[1].Authors[1].Last != "Roe" || sources[2].Accessed.Day() != 4 {
+		t.Errorf("sources should be read back, got %+v", sources)
+	}
+
+	invalid := []*BibliographySource{
+		nil,
+		{Tag: "", Type: SourceBook, Title: "x"},
+		{Tag: "two words", Type: SourceBook, Title: "x"},
+		{Tag: "x", Type: "Film", Title: "x"},
+		{Tag: "x", Type: SourceBook},
+		{Tag: "smi20", Type: SourceBook, Title: "Duplicate"},
+	}
+	for i, source := range invalid {
+		if err := doc.AddSource(source); err == nil {
+			t.Errorf("expected error for invalid source %d", i)
+		}
+	}
+}
+
+// TestBibliographyKeepsSourceFields tests that sources written by Word keep their other fields
+func TestBibliographyKeepsSourceFields(t *testing.T) {
+	doc := New()
+	doc.parts["customXml/item1.xml"] = []byte(`<?xml version="1.0" standalone="yes"?>` +
+		`<b:Sources SelectedStyle="\APASixthEditionOfficeOnline.xsl" StyleName="APA" Version="6" ` +
+		`xmlns:b="http://schemas.openxmlformats.org/officeDocument/2006/bibliography" ` +
+		`xmlns="http://schemas.openxmlformats.org/officeDocument/2006/bibliography">` +
+		`<b:Source><b:Tag>Who20</b:Tag><b:SourceType>Book</b:SourceType><b:Guid>{1}</b:Guid><b:LCID>en-US</b:LCID>` +
+		`<b:Author><b:Author><b:Corporate>World Health Organization</b:Corporate></b:Author>` +
+		`<b:Editor><b:NameList><b:Person><b:Last>Smith</b:Last></b:Person></b:NameList></b:Editor></b:Author>` +
+		`<b:Title>World Report</b:Title><b:Year>2020</b:Year><b:Edition>2</b:Edition><b:RefOrder>1</b:RefOrder></b:Source>` +
+		`</b:Sources>`)
+
+	for i := 0; i < 2; i++ {
+		if err := doc.AddSource(&BibliographySource{Tag: fmt.Sprintf("Doe%d", i), Type: SourceBook, Title: "Field Notes"}); err != nil {
+			t.Fatalf("failed to add source: %v", err)
+		}
+	}
+	part := string(doc.parts["customXml/item1.xml"])
+	if err := xml.Unmarshal([]byte(part), new(struct{})); err != nil {
+		t.Fatalf("bibliography part is not well formed: %v", err)
+	}
+	for _, expected := range []string{
+		"<b:LCID>en-US</b:LCID>",
+		"<b:Corporate>World Health Organization</b:Corporate>",
+		"<b:Editor><b:NameList><b:Person><b:Last>Smith</b:Last>",
+		"<b:Edition>2</b:Edition>",
+		"<b:RefOrder>1</b:RefOrder>",
+	} {
+		if strings.Count(part, expected) != 1 {
+			t.Errorf("bibliography part should keep %s once", expected)
+		}
+	}
+	sources := doc.Sources()
+	if len(sources) != 3 || sources[0].Title != "World Report" || sources[2].Tag != "Doe1" {
+		t.Errorf("unexpected sources %+v", sources)
+	}
+}
+
+// TestCitationsAndBibliography tests citation results and generated entries in each style
+func TestCitationsAndBibliography(t *testing.T) {
+	tests := []struct {
+		style     BibliographyStyle
+		citations []string
+		entries   []string
+	}{
+		{
+			style:     BibliographyAPA,
+			citations: []string{"(Doe & Roe, 2019, pp. 45-50)", "(Smith, 2020, p. 12)"},
+			entries: []string{
+				"Doe, J., & Roe, R. (2019). Measuring change. Journal of Methods, 12(3), 45-67.",
+				"Open data portal. (2021). Data Office. https://example.com/data",
+				"Smith, J. M. (2020). Practical Research. Academic Press.",
+			},
+		},
+		{
+			style:     BibliographyIEEE,
+			citations: []string{"[1, pp. 45-50]", "[2, p. 12]"},
+			entries: []string{
+				`[1] J. Doe and R. Roe, "Measuring change," Journal of Methods, vol. 12, no. 3, pp. 45-67, 2019.`,
+				"[2] J. M. Smith, Practical Research. Boston: Academic Press, 2020.",
+				`[3] "Open data portal," Data Office. [Online]. Available: https://example.com/data`,
+			},
+		},
+		{
+			style:     BibliographyChicago,
+			citations: []string{"(Doe and Roe 2019, 45-50)", "(Smith 2020, 12)"},
+			entries: []string{
+				`Doe, Jane, and Richard Roe. 2019. "Measuring change." Journal of Methods 12 (3): 45-67.`,
+				`"Open data portal." 2021. Data Office. https://example.com/data.`,
+				"Smith, John Michael. 2020. Practical Research. Boston: Academic Press.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			doc := New()
+			addTestSources(t, doc)
+			if err := doc.SetBibliographyStyle(tt.style); err != nil {
+				t.Fatalf("failed to set style: %v", err)
+			}
+			p := doc.AddParagraph("As shown ")
+			p.AddCitation("Doe19", "45-50")
+			p.AddCitation("smi20", "12")
+
+			if err := doc.AddBibliography("References"); err != nil {
+				t.Fatalf("failed to add bibliography: %v", err)
+			}
+			for i, expected := range tt.citations {
+				if got := p.Runs[1+5*i+3].Text.Content; got != expected {
+					t.Errorf("citation %d: expected %q, got %q", i, expected, got)
+				}
+			}
+			paragraphs := doc.Body.GetParagraphs()
+			entries := paragraphs[len(paragraphs)-len(tt.entries):]
+			for i, expected := range tt.entries {
+				var sb strings.Builder
+				for _, run := range entries[i].Runs {
+					sb.WriteString(run.Text.Content)
+				}
+				if sb.String() != expected {
+					t.Errorf("entry %d: expected %q, got %q", i, expected, sb.String())
+				}
+			}
+			if entries[0].Runs[1].InstrText == nil || entries[0].Runs[1].InstrText.Content != " BIBLIOGRAPHY " {
+				t.Error("bibliography should start with a BIBLIOGRAPHY field")
+			}
+			last := entries[len(entries)-1].Runs
+			if last[len(last)-1].FieldChar == nil || last[len(last)-1].FieldChar.FieldCharType != "end" {
+				t.Error("bibliography field should end with the last entry")
+			}
+		})
+	}
+
+	doc := New()
+	addTestSources(t, doc)
+	doc.AddParagraph("Unknown ").AddCitation("Missing", "")
+	if err := doc.UpdateCitations(); err == nil {
+		t.Error("expected error for a citation of an unknown source")
+	}
+	if err := doc.SetBibliographyStyle("MLA"); err == nil {
+		t.Error("expected error for unsupported style")
+	}
+	if err := New().AddBibliography(""); err == nil {
+		t.Error("expected error for a bibliography without sources")
+	}
+}
@@ -0,0 +1,751 @@
+// Package document provides native, editable charts
+package document
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ChartType is the kind of chart
+type ChartType string
+
+const (
+	// ChartTypeBar draws horizontal bars
+	ChartTypeBar ChartType = "bar"
+	// ChartTypeColumn draws vertical bars
+	ChartTypeColumn ChartType = "column"
+	// ChartTypeLine connects the values of each series with a line
+	ChartTypeLine ChartType = "line"
+	// ChartTypePie draws the values of a single series as slices of a circle
+	ChartTypePie ChartType = "pie"
+	// ChartTypeScatter draws points at the x values and values of each series
+	ChartTypeScatter ChartType = "scatter"
+	// ChartTypeArea fills the area below the line of each series
+	ChartTypeArea ChartType = "area"
+)
+
+// ChartLegendPosition is the position of the chart legend
+type ChartLegendPosition string
+
+const (
+	ChartLegendRight  ChartLegendPosition = "r"
+	ChartLegendLeft   ChartLegendPosition = "l"
+	ChartLegendTop    ChartLegendPosition = "t"
+	ChartLegendBottom ChartLegendPosition = "b"
+	// ChartLegendNone hides the legend
+	ChartLegendNone ChartLegendPosition = "none"
+)
+
+const (
+	// chartGraphicDataURI identifies charts in graphic data
+	chartGraphicDataURI = "http://schemas.openxmlformats.org/drawingml/2006/chart"
+	// chartNamespace is the namespace of chart parts
+	chartNamespace = "http://schemas.openxmlformats.org/drawingml/2006/chart"
+	// chart axis IDs, the second value axis is the y axis of scatter charts
+	chartCategoryAxisID = "500000001"
+	chartValueAxisID    = "500000002"
+)
+
+// ChartSeries is a named series of values
+type ChartSeries struct {
+	// Name is shown in the legend, "Series N" when empty
+	Name   string
+	Values []float64
+	// XValues are the x values of a scatter chart series, one per value
+	XValues []float64
+	// Color is a hex color such as 4472C4, automatic when empty
+	Color string
+}
+
+// ChartAxis configures an axis of the chart
+type ChartAxis struct {
+	Title string
+	// Min and Max fix the scale of a value axis, nil for automatic
+	Min *float64
+	Max *float64
+	// MajorUnit is the distance between major tick marks of a value axis, 0 for automatic
+	MajorUnit float64
+	// NumberFormat is an Excel number format of the labels, such as 0.0% or #,##0
+	NumberFormat string
+	Gridlines    bool
+	Hidden       bool
+}
+
+// ChartConfig describes a chart, the size in millimeters
+type ChartConfig struct {
+	Type  ChartType
+	Title string
+	// Categories label the values of the series; scatter charts use the series XValues instead
+	Categories []string
+	Series     []ChartSeries
+	// XAxis is the category axis, or the x axis of scatter charts; YAxis is the value axis.
+	// Pie charts have no axes.
+	XAxis  ChartAxis
+	YAxis  ChartAxis
+	Legend ChartLegendPosition
+	// Stacked stacks the series of bar, column and area charts
+	Stacked    bool
+	DataLabels bool
+	Width      float64
+	Height     float64
+	AltText    string
+}
+
+// Chart is a chart placed in the document
+type Chart struct {
+	ID         string
+	RelationID string
+	// Part is the chart part, such as word/charts/chart1.xml
+	Part string
+	// Paragraph is the body paragraph containing the chart
+	Paragraph *Paragraph
+}
+
+// ChartReference refers to a chart part from a drawing
+type ChartReference struct {
+	XMLName xml.Name `xml:"c:chart"`
+	XmlnsC  string   `xml:"xmlns:c,attr"`
+	XmlnsR  string   `xml:"xmlns:r,attr"`
+	ID      string   `xml:"r:id,attr"`
+}
+
+// chartSpaceXML is the root of a chart part
+type chartSpaceXML struct {
+	XMLName        xml.Name           `xml:"c:chartSpace"`
+	XmlnsC         string             `xml:"xmlns:c,attr"`
+	XmlnsA         string             `xml:"xmlns:a,attr"`
+	XmlnsR         string             `xml:"xmlns:r,attr"`
+	RoundedCorners *chartValue        `xml:"c:roundedCorners"`
+	Chart          *chartXML          `xml:"c:chart"`
+	ExternalData   *chartExternalData `xml:"c:externalData"`
+}
+
+// chartValue is a chart element with a single val attribute
+type chartValue struct {
+	Val string `xml:"val,attr"`
+}
+
+// chartExternalData refers to the embedded workbook holding the chart data
+type chartExternalData struct {
+	ID         string      `xml:"r:id,attr"`
+	AutoUpdate *chartValue `xml:"c:autoUpdate"`
+}
+
+// chartXML is the chart with its title, plot area and legend
+type chartXML struct {
+	Title            *chartTitle  `xml:"c:title,omitempty"`
+	AutoTitleDeleted *chartValue  `xml:"c:autoTitleDeleted"`
+	PlotArea         *chartPlot   `xml:"c:plotArea"`
+	Legend           *chartLegend `xml:"c:legend,omitempty"`
+	PlotVisOnly      *chartValue  `xml:"c:plotVisOnly"`
+}
+
+// chartTitle is the title of a chart or an axis
+type chartTitle struct {
+	Rich    *chartRichText `xml:"c:tx>c:rich"`
+	Overlay *chartValue    `xml:"c:overlay"`
+}
+
+// chartRichText is the text of a title
+type chartRichText struct {
+	BodyPr   struct{} `xml:"a:bodyPr"`
+	LstStyle struct{} `xml:"a:lstStyle"`
+	Text     string   `xml:"a:p>a:r>a:t"`
+}
+
+// chartPlot is the plot area with the chart groups and axes
+type chartPlot struct {
+	Layout       struct{}        `xml:"c:layout"`
+	BarChart     *chartGroup     `xml:"c:barChart,omitempty"`
+	LineChart    *chartGroup     `xml:"c:lineChart,omitempty"`
+	PieChart     *chartGroup     `xml:"c:pieChart,omitempty"`
+	ScatterChart *chartGroup     `xml:"c:scatterChart,omitempty"`
+	AreaChart    *chartGroup     `xml:"c:areaChart,omitempty"`
+	CatAx        *chartAxisXML   `xml:"c:catAx,omitempty"`
+	ValAx        []*chartAxisXML `xml:"c:valAx"`
+}
+
+// chartGroup is a group of series drawn the same way, the elements used depend on the chart type
+type chartGroup struct {
+	BarDir        *chartValue       `xml:"c:barDir,omitempty"`
+	ScatterStyle  *chartValue       `xml:"c:scatterStyle,omitempty"`
+	Grouping      *chartValue       `xml:"c:grouping,omitempty"`
+	VaryColors    *chartValue       `xml:"c:varyColors"`
+	Series        []*chartSeriesXML `xml:"c:ser"`
+	DataLabels    *chartDataLabels  `xml:"c:dLbls,omitempty"`
+	GapWidth      *chartValue       `xml:"c:gapWidth,omitempty"`
+	Overlap       *chartValue       `xml:"c:overlap,omitempty"`
+	Marker        *chartValue       `xml:"c:marker,omitempty"`
+	FirstSliceAng *chartValue       `xml:"c:firstSliceAng,omitempty"`
+	AxisIDs       []chartValue      `xml:"c:axId"`
+}
+
+// chartSeriesXML is a series, the elements used depend on the chart type
+type chartSeriesXML struct {
+	Idx              *chartValue      `xml:"c:idx"`
+	Order            *chartValue      `xml:"c:order"`
+	Tx               *chartStrRef     `xml:"c:tx>c:strRef"`
+	SpPr             *chartShapeProps `xml:"c:spPr,omitempty"`
+	InvertIfNegative *chartValue      `xml:"c:invertIfNegative,omitempty"`
+	Marker           *chartMarker     `xml:"c:marker,omitempty"`
+	Cat              *chartStrRef     `xml:"c:cat>c:strRef,omitempty"`
+	Val              *chartNumRef     `xml:"c:val>c:numRef,omitempty"`
+	XVal             *chartNumRef     `xml:"c:xVal>c:numRef,omitempty"`
+	YVal             *chartNumRef     `xml:"c:yVal>c:numRef,omitempty"`
+	Smooth           *chartValue      `xml:"c:smooth,omitempty"`
+}
+
+// chartShapeProps is the fill and line of a series
+type chartShapeProps struct {
+	SolidFill *SolidFill `xml:"a:solidFill,omitempty"`
+	Ln        *Outline   `xml:"a:ln,omitempty"`
+}
+
+// chartMarker is the marker of a line or scatter series
+type chartMarker struct {
+	Symbol *chartValue      `xml:"c:symbol"`
+	Size   *chartValue      `xml:"c:size,omitempty"`
+	SpPr   *chartShapeProps `xml:"c:spPr,omitempty"`
+}
+
+// chartStrRef refers to text cells of the embedded workbook, with cached values
+type chartStrRef struct {
+	F     string         `xml:"c:f"`
+	Cache *chartStrCache `xml:"c:strCache"`
+}
+
+// chartStrCache holds cached text values
+type chartStrCache struct {
+	PtCount *chartValue  `xml:"c:ptCount"`
+	Points  []chartPoint `xml:"c:pt"`
+}
+
+// chartNumRef refers to number cells of the embedded workbook, with cached values
+type chartNumRef struct {
+	F     string         `xml:"c:f"`
+	Cache *chartNumCache `xml:"c:numCache"`
+}
+
+// chartNumCache holds cached numbers
+type chartNumCache struct {
+	FormatCode string       `xml:"c:formatCode"`
+	PtCount    *chartValue  `xml:"c:ptCount"`
+	Points     []chartPoint `xml:"c:pt"`
+}
+
+// chartPoint is a cached value
+type chartPoint struct {
+	Idx int    `xml:"idx,attr"`
+	V   string `xml:"c:v"`
+}
+
+// chartDataLabels shows the values of a chart group
+type chartDataLabels struct {
+	ShowLegendKey  *chartValue `xml:"c:showLegendKey"`
+	ShowVal        *chartValue `xml:"c:showVal"`
+	ShowCatName    *chartValue `xml:"c:showCatName"`
+	ShowSerName    *chartValue `xml:"c:showSerName"`
+	ShowPercent    *chartValue `xml:"c:showPercent"`
+	ShowBubbleSize *chartValue `xml:"c:showBubbleSize"`
+}
+
+// chartAxisXML is a category or value axis
+type chartAxisXML struct {
+	AxID           *chartValue        `xml:"c:axId"`
+	Scaling        *chartScaling      `xml:"c:scaling"`
+	Delete         *chartValue        `xml:"c:delete"`
+	AxPos          *chartValue        `xml:"c:axPos"`
+	MajorGridlines *struct{}          `xml:"c:majorGridlines,omitempty"`
+	Title          *chartTitle        `xml:"c:title,omitempty"`
+	NumFmt         *chartNumberFormat `xml:"c:numFmt,omitempty"`
+	MajorTickMark  *chartValue        `xml:"c:majorTickMark"`
+	MinorTickMark  *chartValue        `xml:"c:minorTickMark"`
+	TickLblPos     *chartValue        `xml:"c:tickLblPos"`
+	CrossAx        *chartValue        `xml:"c:crossAx"`
+	Crosses        *chartValue        `xml:"c:crosses"`
+	Auto           *chartValue        `xml:"c:auto,omitempty"`
+	LblAlgn        *chartValue        `xml:"c:lblAlgn,omitempty"`
+	LblOffset      *chartValue        `xml:"c:lblOffset,omitempty"`
+	CrossBetween   *chartValue        `xml:"c:crossBetween,omitempty"`
+	MajorUnit      *chartValue        `xml:"c:majorUnit,omitempty"`
+}
+
+// chartScaling is the orientation and bounds of an axis
+type chartScaling struct {
+	Orientation *chartValue `xml:"c:orientation"`
+	Max         *chartValue `xml:"c:max,omitempty"`
+	Min         *chartValue `xml:"c:min,omitempty"`
+}
+
+// chartNumberFormat is the number format of axis labels
+type chartNumberFormat struct {
+	FormatCode   string `xml:"formatCode,attr"`
+	SourceLinked string `xml:"sourceLinked,attr"`
+}
+
+// chartLegend is the position of the legend
+type chartLegend struct {
+	LegendPos *chartValue `xml:"c:legendPos"`
+	Overlay   *chartValue `xml:"c:overlay"`
+}
+
+// AddChart adds a native chart with an embedded workbook holding its data
+//
+// The chart can be restyled and its data edited in Word. Scatter charts plot the XValues of
+// each series against its Values; pie charts plot a single series.
+func (d *Document) AddChart(config *ChartConfig) (*Chart, error) {
+	if err := validateChartConfig(config); err != nil {
+		return nil, err
+	}
+
+	chartSpace, rows, err := buildChartSpace(config)
+	if err != nil {
+		return nil, err
+	}
+	workbook, err := buildChartWorkbook(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	number := 1
+	for ; ; number++ {
+		if _, exists := d.parts[fmt.Sprintf("word/charts/chart%d.xml", number)]; !exists {
+			break
+		}
+	}
+	partName := fmt.Sprintf("word/charts/chart%d.xml", number)
+	workbookTarget := fmt.Sprintf("../embeddings/Microsoft_Excel_Worksheet%d.xlsx", number)
+
+	chartSpace.ExternalData = &chartExternalData{ID: "rId1", AutoUpdate: &chartValue{Val: "0"}}
+	data, err := xml.Marshal(chartSpace)
+	if err != nil {
+		return nil, WrapErrorWithContext("marshal_chart", err, partName)
+	}
+	d.parts[partName] = append([]byte(xml.Header), data...)
+	d.parts[resolvePartTarget(partName, workbookTarget)] = workbook
+	if err := d.storePartRelationships(partName, &Relationships{Relationships: []Relationship{{
+		ID:     "rId1",
+		Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/package",
+		Target: workbookTarget,
+	}}}); err != nil {
+		return nil, err
+	}
+	d.addContentType(partName, "application/vnd.openxmlformats-officedocument.drawingml.chart+xml")
+	d.addWorkbookContentType()
+
+	if d.documentRelationships == nil {
+		d.documentRelationships = &Relationships{
+			Xmlns:         "http://schemas.openxmlformats.org/package/2006/relationships",
+			Relationships: []Relationship{},
+		}
+	}
+	relationID := fmt.Sprintf("rId%d", len(d.documentRelationships.Relationships)+2) // +2 because rId1 is reserved for styles
+	d.documentRelationships.Relationships = append(d.documentRelationships.Relationships, Relationship{
+		ID:     relationID,
+		Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart",
+		Target: fmt.Sprintf("charts/chart%d.xml", number),
+	})
+
+	imageID := d.nextImageID
+	d.nextImageID++
+	id := strconv.Itoa(imageID)
+	width, height := config.Width, config.Height
+	if width == 0 {
+		width = 152.4 // Word's default chart size of 6 by 3.5 inches
+	}
+	if height == 0 {
+		height = 88.9
+	}
+	paragraph := &Paragraph{
+		Runs: []Run{
+			{
+				Drawing: &DrawingElement{
+					Inline: &InlineDrawing{
+						DistT: "0",
+						DistB: "0",
+						DistL: "0",
+						DistR: "0",
+						Extent: &DrawingExtent{
+							Cx: strconv.Itoa(int(math.Round(width * 36000))),
+							Cy: strconv.Itoa(int(math.Round(height * 36000))),
+						},
+						DocPr: &DrawingDocPr{ID: id, Name: fmt.Sprintf("Chart %s", id), Descr: config.AltText},
+						Graphic: &DrawingGraphic{
+							Xmlns: "http://schemas.openxmlformats.org/drawingml/2006/main",
+							GraphicData: &GraphicData{
+								Uri:   chartGraphicDataURI,
+								Chart: newChartReference(relationID),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	d.Body.Elements = append(d.Body.Elements, paragraph)
+
+	Infof("added %s chart %s with %d series", config.Type, partName, len(config.Series))
+	return &Chart{ID: id, RelationID: relationID, Part: partName, Paragraph: paragraph}, nil
+}
+
+// newChartReference creates the drawing reference to a chart relationship
+func newChartReference(relationID string) *ChartReference {
+	return &ChartReference{
+		XmlnsC: chartNamespace,
+		XmlnsR: "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
+		ID:     relationID,
+	}
+}
+
+// addWorkbookContentType registers the content type of embedded workbooks
+func (d *Document) addWorkbookContentType() {
+	for _, def := range d.contentTypes.Defaults {
+		if def.Extension == "xlsx" {
+			return
+		}
+	}
+	d.contentTypes.Defaults = append(d.contentTypes.Defaults, Default{
+		Extension:   "xlsx",
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	})
+}
+
+// validateChartConfig checks the chart type, the series and their colors
+func validateChartConfig(config *ChartConfig) error {
+	if config == nil {
+		return NewValidationError("config", "nil", "chart config is required")
+	}
+	switch config.Type {
+	case ChartTypeBar, ChartTypeColumn, ChartTypeLine, ChartTypeArea, ChartTypeScatter:
+	case ChartTypePie:
+		if len(config.Series) > 1 {
+			return NewValidationError("Series", strconv.Itoa(len(config.Series)), "pie charts plot a single series")
+		}
+	default:
+		return NewValidationError("Type", string(config.Type), "unsupported chart type")
+	}
+	if len(config.Series) == 0 {
+		return NewValidationError("Series", "0", "at least one series is required")
+	}
+	if config.Width < 0 || config.Height < 0 {
+		return NewValidationError("size", fmt.Sprintf("%gx%g", config.Width, config.Height), "cannot be negative")
+	}
+
+	count := len(config.Categories)
+	for i, series := range config.Series {
+		if len(series.Values) == 0 {
+			return NewValidationError("Series", strconv.Itoa(i), "series has no values")
+		}
+		if series.Color != "" {
+			if _, ok := parseHexColor(series.Color); !ok {
+				return NewValidationError("Color", series.Color, "must be a hex color such as 4472C4")
+			}
+		}
+		if config.Type == ChartTypeScatter {
+			if len(series.XValues) != len(series.Values) {
+				return NewValidationError("XValues", strconv.Itoa(len(series.XValues)),
+					fmt.Sprintf("series %d needs one x value per value", i))
+			}
+			continue
+		}
+		if count == 0 {
+			count = len(series.Values)
+		}
+		if len(series.Values) != count {
+			return NewValidationError("Values", strconv.Itoa(len(series.Values)),
+				fmt.Sprintf("series %d needs one value per category (%d)", i, count))
+		}
+	}
+	return nil
+}
+
+// buildChartSpace creates the chart part and the rows of its workbook
+func buildChartSpace(config *ChartConfig) (*chartSpaceXML, [][]interface{}, error) {
+	var rows [][]interface{}
+	var series []*chartSeriesXML
+	if config.Type == ChartTypeScatter {
+		rows, series = scatterChartSeries(config)
+	} else {
+		rows, series = categoryChartSeries(config)
+	}
+
+	group := &chartGroup{VaryColors: &chartValue{Val: "0"}, Series: series}
+	if config.DataLabels {
+		group.DataLabels = &chartDataLabels{
+			ShowLegendKey:  &chartValue{Val: "0"},
+			ShowVal:        &chartValue{Val: "1"},
+			ShowCatName:    &chartValue{Val: "0"},
+			ShowSerName:    &chartValue{Val: "0"},
+			ShowPercent:    &chartValue{Val: "0"},
+			ShowBubbleSize: &chartValue{Val: "0"},
+		}
+	}
+	axisIDs := []chartValue{{Val: chartCategoryAxisID}, {Val: chartValueAxisID}}
+	plot := &chartPlot{}
+
+	grouping := "standard"
+	if config.Stacked {
+		grouping = "stacked"
+	}
+	switch config.Type {
+	case ChartTypeBar, ChartTypeColumn:
+		barDir := "col"
+		if config.Type == ChartTypeBar {
+			barDir = "bar"
+		}
+		if !config.Stacked {
+			grouping = "clustered"
+		}
+		group.BarDir = &chartValue{Val: barDir}
+		group.Grouping = &chartValue{Val: grouping}
+		group.GapWidth = &chartValue{Val: "150"}
+		if config.Stacked {
+			group.Overlap = &chartValue{Val: "100"}
+		}
+		group.AxisIDs = axisIDs
+		plot.BarChart = group
+		for _, s := range series {
+			s.InvertIfNegative = &chartValue{Val: "0"}
+		}
+	case ChartTypeLine:
+		group.Grouping = &chartValue{Val: "standard"}
+		group.Marker = &chartValue{Val: "1"}
+		group.AxisIDs = axisIDs
+		plot.LineChart = group
+		for _, s := range series {
+			s.Smooth = &chartValue{Val: "0"}
+		}
+	case ChartTypeArea:
+		group.Grouping = &chartValue{Val: grouping}
+		group.AxisIDs = axisIDs
+		plot.AreaChart = group
+	case ChartTypePie:
+		group.VaryColors = &chartValue{Val: "1"}
+		group.FirstSliceAng = &chartValue{Val: "0"}
+		plot.PieChart = group
+	case ChartTypeScatter:
+		group.ScatterStyle = &chartValue{Val: "lineMarker"}
+		group.AxisIDs = axisIDs
+		plot.ScatterChart = group
+	}
+
+	if config.Type != ChartTypePie {
+		xPos, yPos := "b", "l"
+		if config.Type == ChartTypeBar {
+			xPos, yPos = "l", "b"
+		}
+		yAxis := newChartAxis(chartValueAxisID, chartCategoryAxisID, yPos, &config.YAxis)
+		yAxis.CrossBetween = &chartValue{Val: "between"}
+		if config.Type == ChartTypeScatter {
+			xAxis := newChartAxis(chartCategoryAxisID, chartValueAxisID, xPos, &config.XAxis)
+			xAxis.CrossBetween = &chartValue{Val: "midCat"}
+			yAxis.CrossBetween = &chartValue{Val: "midCat"}
+			plot.ValAx = []*chartAxisXML{xAxis, yAxis}
+		} else {
+			xAxis := newChartAxis(chartCategoryAxisID, chartValueAxisID, xPos, &config.XAxis)
+			xAxis.Scaling.Min, xAxis.Scaling.Max, xAxis.MajorUnit = nil, nil, nil
+			xAxis.Auto = &chartValue{Val: "1"}
+			xAxis.LblAlgn = &chartValue{Val: "ctr"}
+			xAxis.LblOffset = &chartValue{Val: "100"}
+			plot.CatAx = xAxis
+			plot.ValAx = []*chartAxisXML{yAxis}
+		}
+	}
+
+	chart := &chartXML{
+		AutoTitleDeleted: &chartValue{Val: "1"},
+		PlotArea:         plot,
+		PlotVisOnly:      &chartValue{Val: "1"},
+	}
+	if config.Title != "" {
+		chart.Title = newChartTitle(config.Title)
+		chart.AutoTitleDeleted.Val = "0"
+	}
+	if config.Legend != ChartLegendNone {
+		position := config.Legend
+		if position == "" {
+			position = ChartLegendRight
+		}
+		chart.Legend = &chartLegend{LegendPos: &chartValue{Val: string(position)}, Overlay: &chartValue{Val: "0"}}
+	}
+
+	return &chartSpaceXML{
+		XmlnsC:         chartNamespace,
+		XmlnsA:         "http://schemas.openxmlformats.org/drawingml/2006/main",
+		XmlnsR:         "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
+		RoundedCorners: &chartValue{Val: "0"},
+		Chart:          chart,
+	}, rows, nil
+}
+
+// categoryChartSeries lays out categories in column A and one column per series
+func categoryChartSeries(config *ChartConfig) ([][]interface{}, []*chartSeriesXML) {
+	categories := config.Categories
+	if len(categories) == 0 {
+		for i := range config.Series[0].Values {
+			categories = append(categories, strconv.Itoa(i+1))
+		}
+	}
+
+	rows := make([][]interface{}, len(categories)+1)
+	rows[0] = []interface{}{""}
+	for i, category := range categories {
+		rows[i+1] = []interface{}{category}
+	}
+	categoryRef := &chartStrRef{
+		F:     chartRange("A", 2, len(categories)+1),
+		Cache: newChartStrCache(categories),
+	}
+
+	var series []*chartSeriesXML
+	for i, s := range config.Series {
+		column := spreadsheetColumn(i + 1)
+		name := chartSeriesName(s, i)
+		rows[0] = append(rows[0], name)
+		for j, value := range s.Values {
+			rows[j+1] = append(rows[j+1], value)
+		}
+		ser := newChartSeries(i, name, column, s, config.Type)
+		ser.Cat = categoryRef
+		ser.Val = newChartNumRef(chartRange(column, 2, len(s.Values)+1), s.Values)
+		series = append(series, ser)
+	}
+	return rows, series
+}
+
+// scatterChartSeries lays out every series in two columns, its x values and its values
+func scatterChartSeries(config *ChartConfig) ([][]interface{}, []*chartSeriesXML) {
+	count := 0
+	for _, s := range config.Series {
+		count = max(count, len(s.Values))
+	}
+	rows := make([][]interface{}, count+1)
+	for i := range rows {
+		rows[i] = make([]interface{}, 2*len(config.Series))
+	}
+
+	var series []*chartSeriesXML
+	for i, s := range config.Series {
+		xColumn, yColumn := spreadsheetColumn(2*i), spreadsheetColumn(2*i+1)
+		name := chartSeriesName(s, i)
+		rows[0][2*i], rows[0][2*i+1] = "X", name
+		for j := range s.Values {
+			rows[j+1][2*i], rows[j+1][2*i+1] = s.XValues[j], s.Values[j]
+		}
+		ser := newChartSeries(i, name, yColumn, s, config.Type)
+		ser.XVal = newChartNumRef(chartRange(xColumn, 2, len(s.XValues)+1), s.XValues)
+		ser.YVal = newChartNumRef(chartRange(yColumn, 2, len(s.Values)+1), s.Values)
+		ser.Smooth = &chartValue{Val: "0"}
+		series = append(series, ser)
+	}
+	return rows, series
+}
+
+// newChartSeries creates a series named by the first cell of its column
+func newChartSeries(index int, name, column string, s ChartSeries, chartType ChartType) *chartSeriesXML {
+	ser := &chartSeriesXML{
+		Idx:   &chartValue{Val: strconv.Itoa(index)},
+		Order: &chartValue{Val: strconv.Itoa(index)},
+		Tx:    &chartStrRef{F: fmt.Sprintf("Sheet1!$%s$1", column), Cache: newChartStrCache([]string{name})},
+	}
+	var color *SolidFill
+	if rgb, ok := parseHexColor(s.Color); ok && chartType != ChartTypePie {
+		color = &SolidFill{SrgbClr: &SrgbClr{Val: formatHexColor(rgb)}}
+	}
+	switch chartType {
+	case ChartTypeLine:
+		if color != nil {
+			ser.SpPr = &chartShapeProps{Ln: &Outline{W: "28575", SolidFill: color}}
+		}
+		ser.Marker = &chartMarker{Symbol: &chartValue{Val: "none"}}
+	case ChartTypeScatter:
+		// markers only, as Word's default scatter chart
+		ser.SpPr = &chartShapeProps{Ln: &Outline{W: "19050", NoFill: &NoFill{}}}
+		ser.Marker = &chartMarker{Symbol: &chartValue{Val: "circle"}, Size: &chartValue{Val: "5"}}
+		if color != nil {
+			ser.Marker.SpPr = &chartShapeProps{SolidFill: color}
+		}
+	default:
+		if color != nil {
+			ser.SpPr = &chartShapeProps{SolidFill: color}
+		}
+	}
+	return ser
+}
+
+// newChartAxis creates a value axis crossing crossAxis, category axes drop the scale settings
+func newChartAxis(id, crossAxis, position string, config *ChartAxis) *chartAxisXML {
+	axis := &chartAxisXML{
+		AxID:          &chartValue{Val: id},
+		Scaling:       &chartScaling{Orientation: &chartValue{Val: "minMax"}},
+		Delete:        &chartValue{Val: "0"},
+		AxPos:         &chartValue{Val: position},
+		MajorTickMark: &chartValue{Val: "out"},
+		MinorTickMark: &chartValue{Val: "none"},
+		TickLblPos:    &chartValue{Val: "nextTo"},
+		CrossAx:       &chartValue{Val: crossAxis},
+		Crosses:       &chartValue{Val: "autoZero"},
+	}
+	if config.Hidden {
+		axis.Delete.Val = "1"
+	}
+	if config.Gridlines {
+		axis.MajorGridlines = &struct{}{}
+	}
+	if config.Title != "" {
+		axis.Title = newChartTitle(config.Title)
+	}
+	if config.NumberFormat != "" {
+		axis.NumFmt = &chartNumberFormat{FormatCode: config.NumberFormat, SourceLinked: "0"}
+	} else {
+		axis.NumFmt = &chartNumberFormat{FormatCode: "General", SourceLinked: "1"}
+	}
+	if config.Max != nil {
+		axis.Scaling.Max = &chartValue{Val: strconv.FormatFloat(*config.Max, 'g', -1, 64)}
+	}
+	if config.Min != nil {
+		axis.Scaling.Min = &chartValue{Val: strconv.FormatFloat(*config.Min, 'g', -1, 64)}
+	}
+	if config.MajorUnit > 0 {
+		axis.MajorUnit = &chartValue{Val: strconv.FormatFloat(config.MajorUnit, 'g', -1, 64)}
+	}
+	return axis
+}
+
+// newChartTitle creates a chart or axis title
+func newChartTitle(text string) *chartTitle {
+	return &chartTitle{Rich: &chartRichText{Text: text}, Overlay: &chartValue{Val: "0"}}
+}
+
+// newChartStrCache caches text values
+func newChartStrCache(values []string) *chartStrCache {
+	cache := &chartStrCache{PtCount: &chartValue{Val: strconv.Itoa(len(values))}}
+	for i, value := range values {
+		cache.Points = append(cache.Points, chartPoint{Idx: i, V: value})
+	}
+	return cache
+}
+
+// newChartNumRef refers to numbers of the workbook with their cached values
+func newChartNumRef(ref string, values []float64) *chartNumRef {
+	cache := &chartNumCache{FormatCode: "General", PtCount: &chartValue{Val: strconv.Itoa(len(values))}}
+	for i, value := range values {
+		cache.Points = append(cache.Points, chartPoint{Idx: i, V: strconv.FormatFloat(value, 'g', -1, 64)})
+	}
+	return &chartNumRef{F: ref, Cache: cache}
+}
+
+// chartRange returns an absolute reference to rows first to last of a column of Sheet1
+func chartRange(column string, first, last int) string {
+	return fmt.Sprintf("Sheet1!$%s$%d:$%s$%d", column, first, column, last)
+}
+
+// chartSeriesName returns the name of a series, "Series N" when it has none
+func chartSeriesName(s ChartSeries, index int) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return fmt.Sprintf("Series %d", index+1)
+}
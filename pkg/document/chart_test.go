@@ -0,0 +1,181 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// readWorkbookSheet returns the first worksheet of an embedded workbook
+func readWorkbookSheet(t *testing.T, data []byte) string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("embedded workbook is not a zip file: %v", err)
+	}
+	for _, file := range reader.File {
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := file.Open()
+			defer rc.Close()
+			sheet, _ := io.ReadAll(rc)
+			return string(sheet)
+		}
+	}
+	t.Fatal("embedded workbook has no worksheet")
+	return ""
+}
+
+// TestAddChart tests a column chart with its part, workbook and drawing
+func TestAddChart(t *testing.T) {
+	doc := New()
+	maxValue := 200.0
+	chart, err := doc.AddChart(&ChartConfig{
+		Type:       ChartTypeColumn,
+		Title:      "Quarterly sales",
+		Categories: []string{"Q1", "Q2", "Q3"},
+		Series: []ChartSeries{
+			{Name: "North", Values: []float64{120, 135.5, 150}, Color: "4472C4"},
+			{Name: "South", Values: []float64{80, 95, 110}},
+		},
+		YAxis:      ChartAxis{Title: "Units", Max: &maxValue, Gridlines: true, NumberFormat: "#,##0"},
+		Legend:     ChartLegendBottom,
+		DataLabels: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to add chart: %v", err)
+	}
+	if chart.Part != "word/charts/chart1.xml" {
+		t.Errorf("unexpected chart part %s", chart.Part)
+	}
+
+	content := string(doc.parts[chart.Part])
+	if err := xml.Unmarshal(doc.parts[chart.Part], new(struct{})); err != nil {
+		t.Fatalf("chart part is not well formed: %v", err)
+	}
+	for _, expected := range []string{
+		`<c:barDir val="col">`,
+		`<c:grouping val="clustered">`,
+		`<c:f>Sheet1!$B$1</c:f>`,
+		`<c:f>Sheet1!$A$2:$A$4</c:f>`,
+		`<c:f>Sheet1!$C$2:$C$4</c:f>`,
+		`<c:v>135.5</c:v>`,
+		`<a:srgbClr val="4472C4">`,
+		`<a:t>Quarterly sales</a:t>`,
+		`<a:t>Units</a:t>`,
+		`<c:max val="200">`,
+		`<c:numFmt formatCode="#,##0" sourceLinked="0">`,
+		`<c:majorGridlines>`,
+		`<c:showVal val="1">`,
+		`<c:legendPos val="b">`,
+		`<c:externalData r:id="rId1">`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("chart part should contain %s", expected)
+		}
+	}
+
+	rels := string(doc.parts["word/charts/_rels/chart1.xml.rels"])
+	if !strings.Contains(rels, `Target="../embeddings/Microsoft_Excel_Worksheet1.xlsx"`) {
+		t.Errorf("chart should refer to its workbook, got %s", rels)
+	}
+	sheet := readWorkbookSheet(t, doc.parts["word/embeddings/Microsoft_Excel_Worksheet1.xlsx"])
+	for _, expected := range []string{`<c r="B1" t="inlineStr"><is><t>North</t></is></c>`, `<c r="A3" t="inlineStr"><is><t>Q2</t></is></c>`, `<c r="C4"><v>110</v></c>`} {
+		if !strings.Contains(sheet, expected) {
+			t.Errorf("worksheet should contain %s", expected)
+		}
+	}
+	if mediaTarget(doc, chart.RelationID) != "charts/chart1.xml" {
+		t.Error("document should refer to the chart part")
+	}
+
+	filename := "test_chart.docx"
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	defer os.Remove(filename)
+	types := string(doc.parts["[Content_Types].xml"])
+	if !strings.Contains(types, `PartName="/word/charts/chart1.xml"`) || !strings.Contains(types, `Extension="xlsx"`) {
+		t.Error("chart and workbook content types should be registered")
+	}
+
+	// the chart survives opening and saving the document again
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	second, err := opened.AddChart(&ChartConfig{Type: ChartTypePie, Series: []ChartSeries{{Values: []float64{1, 2, 3}}}})
+	if err != nil {
+		t.Fatalf("failed to add chart to opened document: %v", err)
+	}
+	if second.Part != "word/charts/chart2.xml" {
+		t.Errorf("expected a new chart part, got %s", second.Part)
+	}
+	resaved := "test_chart_resaved.docx"
+	defer os.Remove(resaved)
+	document := savedDocumentXML(t, opened, resaved)
+	if !strings.Contains(document, `<c:chart xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" r:id="`+chart.RelationID+`">`) {
+		t.Error("opened chart should be written back")
+	}
+}
+
+// TestAddChartTypes tests the layout of the other chart types
+func TestAddChartTypes(t *testing.T) {
+	doc := New()
+	series := []ChartSeries{{Name: "Sales", Values: []float64{3, 5, 4}}}
+	tests := []struct {
+		config   *ChartConfig
+		expected []string
+	}{
+		{&ChartConfig{Type: ChartTypeBar, Series: series, Stacked: true}, []string{`<c:barDir val="bar">`, `<c:grouping val="stacked">`, `<c:overlap val="100">`, `<c:axPos val="l">`}},
+		{&ChartConfig{Type: ChartTypeLine, Series: series, Legend: ChartLegendNone}, []string{`<c:lineChart>`, `<c:symbol val="none">`, `<c:catAx>`}},
+		{&ChartConfig{Type: ChartTypeArea, Series: series}, []string{`<c:areaChart>`, `<c:grouping val="standard">`, `<c:v>1</c:v>`}},
+		{&ChartConfig{Type: ChartTypePie, Series: series, Categories: []string{"A", "B", "C"}}, []string{`<c:pieChart>`, `<c:varyColors val="1">`, `<c:firstSliceAng val="0">`}},
+		{&ChartConfig{Type: ChartTypeScatter, Series: []ChartSeries{{Values: []float64{1, 4}, XValues: []float64{0.5, 2}}}},
+			[]string{`<c:scatterStyle val="lineMarker">`, `<c:f>Sheet1!$A$2:$A$3</c:f>`, `<c:f>Sheet1!$B$2:$B$3</c:f>`, `<c:crossBetween val="midCat">`, `<c:v>Series 1</c:v>`}},
+	}
+	for _, tt := range tests {
+		chart, err := doc.AddChart(tt.config)
+		if err != nil {
+			t.Fatalf("failed to add %s chart: %v", tt.config.Type, err)
+		}
+		content := string(doc.parts[chart.Part])
+		for _, expected := range tt.expected {
+			if !strings.Contains(content, expected) {
+				t.Errorf("%s chart should contain %s", tt.config.Type, expected)
+			}
+		}
+	}
+	if content := string(doc.parts["word/charts/chart2.xml"]); strings.Contains(content, "<c:legend>") || strings.Contains(content, "<c:catAx>") == false {
+		t.Error("line chart should have a category axis and no legend")
+	}
+	if content := string(doc.parts["word/charts/chart4.xml"]); strings.Contains(content, "<c:valAx>") {
+		t.Error("pie chart should have no axes")
+	}
+
+	invalid := []*ChartConfig{
+		nil,
+		{Type: "radar", Series: series},
+		{Type: ChartTypeLine},
+		{Type: ChartTypeLine, Series: series, Categories: []string{"A"}},
+		{Type: ChartTypePie, Series: append(series, series...)},
+		{Type: ChartTypeScatter, Series: series},
+		{Type: ChartTypeLine, Series: []ChartSeries{{Values: []float64{1}, Color: "blue"}}},
+	}
+	for i, config := range invalid {
+		if _, err := doc.AddChart(config); err == nil {
+			t.Errorf("expected error for invalid config %d", i)
+		}
+	}
+}
+
+// TestSpreadsheetColumn tests spreadsheet column names
+func TestSpreadsheetColumn(t *testing.T) {
+	for index, expected := range map[int]string{0: "A", 1: "B", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if name := spreadsheetColumn(index); name != expected {
+			t.Errorf("column %d: expected %s, got %s", index, expected, name)
+		}
+	}
+}
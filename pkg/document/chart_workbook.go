@@ -0,0 +1,132 @@
+// Package document provides the workbook embedded in charts
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// workbookXML is the workbook part of a chart workbook
+type workbookXML struct {
+	XMLName xml.Name        `xml:"workbook"`
+	Xmlns   string          `xml:"xmlns,attr"`
+	XmlnsR  string          `xml:"xmlns:r,attr"`
+	Sheets  []workbookSheet `xml:"sheets>sheet"`
+}
+
+// workbookSheet is a sheet entry of a workbook
+type workbookSheet struct {
+	Name    string `xml:"name,attr"`
+	SheetID string `xml:"sheetId,attr"`
+	ID      string `xml:"r:id,attr"`
+}
+
+// worksheetXML is a worksheet with inline strings and numbers
+type worksheetXML struct {
+	XMLName xml.Name       `xml:"worksheet"`
+	Xmlns   string         `xml:"xmlns,attr"`
+	Rows    []worksheetRow `xml:"sheetData>row"`
+}
+
+// worksheetRow is a row of a worksheet
+type worksheetRow struct {
+	R     int             `xml:"r,attr"`
+	Cells []worksheetCell `xml:"c"`
+}
+
+// worksheetCell is a worksheet cell, either an inline string or a number
+type worksheetCell struct {
+	R      string  `xml:"r,attr"`
+	T      string  `xml:"t,attr,omitempty"`
+	Inline *string `xml:"is>t"`
+	V      string  `xml:"v,omitempty"`
+}
+
+// buildChartWorkbook creates an XLSX workbook with a single sheet named Sheet1
+// Cells are strings, float64 values or nil for empty cells.
+func buildChartWorkbook(rows [][]interface{}) ([]byte, error) {
+	sheet := worksheetXML{Xmlns: "http://schemas.openxmlformats.org/spreadsheetml/2006/main"}
+	for r, values := range rows {
+		row := worksheetRow{R: r + 1}
+		for c, value := range values {
+			cell := worksheetCell{R: fmt.Sprintf("%s%d", spreadsheetColumn(c), r+1)}
+			switch v := value.(type) {
+			case string:
+				text := v
+				cell.T, cell.Inline = "inlineStr", &text
+			case float64:
+				cell.V = strconv.FormatFloat(v, 'g', -1, 64)
+			default:
+				continue
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+
+	relationshipsXmlns := "http://schemas.openxmlformats.org/package/2006/relationships"
+	parts := []struct {
+		name  string
+		value interface{}
+	}{
+		{"[Content_Types].xml", &ContentTypes{
+			Xmlns: "http://schemas.openxmlformats.org/package/2006/content-types",
+			Defaults: []Default{
+				{Extension: "rels", ContentType: "application/vnd.openxmlformats-package.relationships+xml"},
+				{Extension: "xml", ContentType: "application/xml"},
+			},
+			Overrides: []Override{
+				{PartName: "/xl/workbook.xml", ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"},
+				{PartName: "/xl/worksheets/sheet1.xml", ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"},
+			},
+		}},
+		{"_rels/.rels", &Relationships{Xmlns: relationshipsXmlns, Relationships: []Relationship{{
+			ID:     "rId1",
+			Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument",
+			Target: "xl/workbook.xml",
+		}}}},
+		{"xl/workbook.xml", &workbookXML{
+			Xmlns:  "http://schemas.openxmlformats.org/spreadsheetml/2006/main",
+			XmlnsR: "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
+			Sheets: []workbookSheet{{Name: "Sheet1", SheetID: "1", ID: "rId1"}},
+		}},
+		{"xl/_rels/workbook.xml.rels", &Relationships{Xmlns: relationshipsXmlns, Relationships: []Relationship{{
+			ID:     "rId1",
+			Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet",
+			Target: "worksheets/sheet1.xml",
+		}}}},
+		{"xl/worksheets/sheet1.xml", &sheet},
+	}
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, part := range parts {
+		data, err := xml.Marshal(part.value)
+		if err != nil {
+			return nil, WrapErrorWithContext("marshal_workbook", err, part.name)
+		}
+		w, err := writer.Create(part.name)
+		if err != nil {
+			return nil, WrapErrorWithContext("write_workbook", err, part.name)
+		}
+		if _, err := w.Write(append([]byte(xml.Header), data...)); err != nil {
+			return nil, WrapErrorWithContext("write_workbook", err, part.name)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, WrapError("write_workbook", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// spreadsheetColumn returns the spreadsheet column name of a zero based column index (A, B, ..., AA)
+func spreadsheetColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
@@ -4,6 +4,7 @@ package document
 import (
 	"archive/zip"
 	"bytes"
+	"crypto/sha256"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -31,6 +32,14 @@ type Document struct {
 	parts map[string][]byte
 	// image ID counter, ensure each image has unique ID
 	nextImageID int
+	// relationship IDs of embedded media by hash of their data, built on first use
+	mediaHashes map[[sha256.Size]byte]string
+	// page background color, nil when the pages have no background
+	background *Background
+	// list and numbering definitions, created on first use
+	numbering *NumberingManager
+	// namespace declarations of the opened document, repeated on content kept as read
+	namespaces []xml.Attr
 }
 
 // Body represents the document body
@@ -114,6 +123,8 @@ type ParagraphProperties struct {
 	PageBreakBefore     *PageBreakBefore     `xml:"w:pageBreakBefore,omitempty"` // 段前分页
 	WidowControl        *WidowControl        `xml:"w:widowControl,omitempty"`    // 孤行控制
 	OutlineLevel        *OutlineLevel        `xml:"w:outlineLvl,omitempty"`      // 大纲级别
+	// SectionProperties ends a section at this paragraph, the last section is in the body
+	SectionProperties *SectionProperties `xml:"w:sectPr,omitempty"`
 }
 
 // SnapToGrid grid alignment setting
@@ -193,8 +204,16 @@ type Run struct {
 	Text       Text            `xml:"w:t,omitempty"`
 	Break      *Break          `xml:"w:br,omitempty"` // 分页符 / Page break
 	Drawing    *DrawingElement `xml:"w:drawing,omitempty"`
-	FieldChar  *FieldChar      `xml:"w:fldChar,omitempty"`
-	InstrText  *InstrText      `xml:"w:instrText,omitempty"`
+	// AlternateContent holds shapes and text boxes with their VML fallback
+	AlternateContent *AlternateContent `xml:"mc:AlternateContent,omitempty"`
+	FieldChar        *FieldChar        `xml:"w:fldChar,omitempty"`
+	InstrText        *InstrText        `xml:"w:instrText,omitempty"`
+	// FootnoteReference and EndnoteReference mark a note in the document text
+	FootnoteReference *FootnoteReference `xml:"w:footnoteReference,omitempty"`
+	EndnoteReference  *EndnoteReference  `xml:"w:endnoteReference,omitempty"`
+	// FootnoteRef and EndnoteRef repeat the note number inside the note itself
+	FootnoteRef *FootnoteRef `xml:"w:footnoteRef,omitempty"`
+	EndnoteRef  *EndnoteRef  `xml:"w:endnoteRef,omitempty"`
 }
 
 // MarshalXML custom Run XML serialization
@@ -212,6 +231,28 @@ func (r *Run) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		}
 	}
 
+	// serializeNoteReferences (if exists)
+	if r.FootnoteReference != nil {
+		if err := e.EncodeElement(r.FootnoteReference, xml.StartElement{Name: xml.Name{Local: "w:footnoteReference"}}); err != nil {
+			return err
+		}
+	}
+	if r.EndnoteReference != nil {
+		if err := e.EncodeElement(r.EndnoteReference, xml.StartElement{Name: xml.Name{Local: "w:endnoteReference"}}); err != nil {
+			return err
+		}
+	}
+	if r.FootnoteRef != nil {
+		if err := e.EncodeElement(r.FootnoteRef, xml.StartElement{Name: xml.Name{Local: "w:footnoteRef"}}); err != nil {
+			return err
+		}
+	}
+	if r.EndnoteRef != nil {
+		if err := e.EncodeElement(r.EndnoteRef, xml.StartElement{Name: xml.Name{Local: "w:endnoteRef"}}); err != nil {
+			return err
+		}
+	}
+
 	// serializeText (only when there is content)
 	// This is a critical fix: avoid serializing empty Text elements
 	if r.Text.Content != "" {
@@ -234,6 +275,13 @@ func (r *Run) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		}
 	}
 
+	// serializeAlternateContent (if exists)
+	if r.AlternateContent != nil {
+		if err := e.EncodeElement(r.AlternateContent, xml.StartElement{Name: xml.Name{Local: "mc:AlternateContent"}}); err != nil {
+			return err
+		}
+	}
+
 	// serializeFieldChar (if exists)
 	if r.FieldChar != nil {
 		if err := e.EncodeElement(r.FieldChar, xml.StartElement{Name: xml.Name{Local: "w:fldChar"}}); err != nil {
@@ -256,6 +304,7 @@ func (r *Run) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 // Note: The field order must conform to the OpenXML standard, w:rFonts must be before w:color
 type RunProperties struct {
 	XMLName    xml.Name    `xml:"w:rPr"`
+	RunStyle   *RunStyle   `xml:"w:rStyle,omitempty"`
 	FontFamily *FontFamily `xml:"w:rFonts,omitempty"`
 	Bold       *Bold       `xml:"w:b,omitempty"`
 	BoldCs     *BoldCs     `xml:"w:bCs,omitempty"`
@@ -267,6 +316,13 @@ type RunProperties struct {
 	FontSize   *FontSize   `xml:"w:sz,omitempty"`
 	FontSizeCs *FontSizeCs `xml:"w:szCs,omitempty"`
 	Highlight  *Highlight  `xml:"w:highlight,omitempty"`
+	VertAlign  *VertAlign  `xml:"w:vertAlign,omitempty"`
+}
+
+// RunStyle character style reference
+type RunStyle struct {
+	XMLName xml.Name `xml:"w:rStyle"`
+	Val     string   `xml:"w:val,attr"`
 }
 
 // Bold bold
@@ -324,6 +380,12 @@ type Highlight struct {
 	Val     string   `xml:"w:val,attr"`
 }
 
+// VertAlign superscript or subscript alignment
+type VertAlign struct {
+	XMLName xml.Name `xml:"w:vertAlign"`
+	Val     string   `xml:"w:val,attr"`
+}
+
 // Text text content
 type Text struct {
 	XMLName xml.Name `xml:"w:t"`
@@ -393,6 +455,15 @@ type TextFormat struct {
 	Underline  bool
 	Strike     bool
 	Highlight  string
+	StyleID    string // character style ID, applied as w:rStyle
+}
+
+// runStyle returns the w:rStyle reference of the format's character style, or nil when none is set
+func (f *TextFormat) runStyle() *RunStyle {
+	if f.StyleID == "" {
+		return nil
+	}
+	return &RunStyle{Val: f.StyleID}
 }
 
 // AlignmentType
@@ -423,6 +494,7 @@ type Indentation struct {
 	FirstLine string   `xml:"w:firstLine,attr,omitempty"`
 	Left      string   `xml:"w:left,attr,omitempty"`
 	Right     string   `xml:"w:right,attr,omitempty"`
+	Hanging   string   `xml:"w:hanging,attr,omitempty"`
 }
 
 // Tabs
@@ -632,6 +704,11 @@ func openFromZipReader(zipReader *zip.Reader, filename string) (*Document, error
 		doc.styleManager = style.NewStyleManager()
 	}
 
+	// parse numbering definitions, so new lists do not collide with the existing ones
+	if err := doc.parseNumbering(); err != nil {
+		Debugf("failed to parse numbering, keeping the original numbering.xml: %v", err)
+	}
+
 	// parse document relationships (including relationships for images, etc.)
 	if err := doc.parseDocumentRelationships(); err != nil {
 		Debugf("failed to parse document relationships, using default values: %v", err)
@@ -839,6 +916,8 @@ func (d *Document) AddFormattedParagraph(text string, format *TextFormat) *Parag
 		if format.Highlight != "" {
 			runProps.Highlight = &Highlight{Val: format.Highlight}
 		}
+
+		runProps.RunStyle = format.runStyle()
 	}
 
 	p := &Paragraph{
@@ -1021,6 +1100,8 @@ func (p *Paragraph) AddFormattedText(text string, format *TextFormat) {
 		if format.Highlight != "" {
 			runProps.Highlight = &Highlight{Val: format.Highlight}
 		}
+
+		runProps.RunStyle = format.runStyle()
 	}
 
 	run := Run{
@@ -1261,6 +1342,32 @@ func (p *Paragraph) SetStyle(styleID string) {
 	Debugf("set paragraph style: %s", styleID)
 }
 
+// SetStyle
+//
+// styleID is the ID of the character style to apply, such as "Strong" or a custom style
+// created with StyleManager.CreateCustomStyle. Direct formatting on the run still takes
+// precedence over the style.
+//
+// Example:
+//
+//	para := doc.AddParagraph("")
+//	para.AddFormattedText("important", nil)
+//	para.Runs[len(para.Runs)-1].SetStyle("Strong")
+func (r *Run) SetStyle(styleID string) {
+	if r.Properties == nil {
+		r.Properties = &RunProperties{}
+	}
+
+	if styleID == "" {
+		r.Properties.RunStyle = nil
+		Debugf("clear run style")
+		return
+	}
+
+	r.Properties.RunStyle = &RunStyle{Val: styleID}
+	Debugf("set run style: %s", styleID)
+}
+
 // SetIndentation
 //
 // Parameters:
@@ -2016,6 +2123,12 @@ func (d *Document) parseDocument() error {
 		switch t := token.(type) {
 		case xml.StartElement:
 			if t.Name.Local == "document" && t.Name.Space == "http://schemas.openxmlformats.org/wordprocessingml/2006/main" {
+				d.namespaces = nil
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "xmlns" {
+						d.namespaces = append(d.namespaces, xml.Attr{Name: xml.Name{Local: "xmlns:" + attr.Name.Local}, Value: attr.Value})
+					}
+				}
 				// 开始解析文档
 				if err := d.parseDocumentElement(decoder); err != nil {
 					return err
@@ -2049,6 +2162,13 @@ func (d *Document) parseDocumentElement(decoder *xml.Decoder) error {
 		switch t := token.(type) {
 		case xml.StartElement:
 			switch {
+			case t.Name.Local == "background":
+				if color := getAttributeValue(t.Attr, "color"); color != "" {
+					d.background = &Background{Color: color}
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return err
+				}
 			case t.Name.Local == "body":
 				if err := d.parseBodyElement(decoder); err != nil {
 					return err
@@ -2193,6 +2313,7 @@ func (d *Document) parseParagraphProperties(decoder *xml.Decoder, paragraph *Par
 				indentation.FirstLine = getAttributeValue(t.Attr, "firstLine")
 				indentation.Left = getAttributeValue(t.Attr, "left")
 				indentation.Right = getAttributeValue(t.Attr, "right")
+				indentation.Hanging = getAttributeValue(t.Attr, "hanging")
 				paragraph.Properties.Indentation = indentation
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return err
@@ -2208,7 +2329,7 @@ func (d *Document) parseParagraphProperties(decoder *xml.Decoder, paragraph *Par
 				if err != nil {
 					return err
 				}
-				d.setSectionProperties(sectPr)
+				paragraph.Properties.SectionProperties = sectPr
 			default:
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return err
@@ -2298,6 +2419,52 @@ func (d *Document) parseRun(decoder *xml.Decoder, startElement xml.StartElement)
 					return nil, err
 				}
 				run.Drawing = drawing
+			case "AlternateContent":
+				// shapes and text boxes are kept as read, with the namespaces their prefixes refer to
+				var content struct {
+					RawXML string `xml:",innerxml"`
+				}
+				if err := decoder.DecodeElement(&content, &t); err != nil {
+					return nil, WrapError("parse_run", err)
+				}
+				run.AlternateContent = &AlternateContent{Namespaces: d.namespaces, RawXML: content.RawXML}
+			case "fldChar":
+				run.FieldChar = &FieldChar{FieldCharType: getAttributeValue(t.Attr, "fldCharType")}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			case "instrText":
+				content, err := d.readElementText(decoder, "instrText")
+				if err != nil {
+					return nil, err
+				}
+				run.InstrText = &InstrText{Space: getAttributeValue(t.Attr, "space"), Content: content}
+			case "footnoteReference":
+				run.FootnoteReference = &FootnoteReference{
+					ID:                getAttributeValue(t.Attr, "id"),
+					CustomMarkFollows: getAttributeValue(t.Attr, "customMarkFollows"),
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			case "endnoteReference":
+				run.EndnoteReference = &EndnoteReference{
+					ID:                getAttributeValue(t.Attr, "id"),
+					CustomMarkFollows: getAttributeValue(t.Attr, "customMarkFollows"),
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			case "footnoteRef":
+				run.FootnoteRef = &FootnoteRef{}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			case "endnoteRef":
+				run.EndnoteRef = &EndnoteRef{}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
 			default:
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return nil, err
@@ -2324,6 +2491,14 @@ func (d *Document) parseRunProperties(decoder *xml.Decoder, run *Run) error {
 		switch t := token.(type) {
 		case xml.StartElement:
 			switch t.Name.Local {
+			case "rStyle":
+				val := getAttributeValue(t.Attr, "val")
+				if val != "" {
+					run.Properties.RunStyle = &RunStyle{Val: val}
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return err
+				}
 			case "b":
 				run.Properties.Bold = &Bold{}
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
@@ -2387,6 +2562,14 @@ func (d *Document) parseRunProperties(decoder *xml.Decoder, run *Run) error {
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return err
 				}
+			case "vertAlign":
+				val := getAttributeValue(t.Attr, "val")
+				if val != "" {
+					run.Properties.VertAlign = &VertAlign{Val: val}
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return err
+				}
 			case "rFonts":
 				ascii := getAttributeValue(t.Attr, "ascii")
 				hAnsi := getAttributeValue(t.Attr, "hAnsi")
@@ -2421,6 +2604,7 @@ func (d *Document) parseRunProperties(decoder *xml.Decoder, run *Run) error {
 func (d *Document) parseTable(decoder *xml.Decoder, startElement xml.StartElement) (*Table, error) {
 	table := &Table{
 		Rows: make([]TableRow, 0),
+		doc:  d,
 	}
 
 	for {
@@ -2727,6 +2911,29 @@ func (d *Document) parseSectionProperties(decoder *xml.Decoder, startElement xml
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return nil, err
 				}
+			case "pgBorders":
+				borders, err := d.parsePageBorders(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				sectPr.PageBorders = borders
+			case "lnNumType":
+				sectPr.LineNumbering = &LineNumbering{
+					CountBy:  getAttributeValue(t.Attr, "countBy"),
+					Start:    getAttributeValue(t.Attr, "start"),
+					Distance: getAttributeValue(t.Attr, "distance"),
+					Restart:  getAttributeValue(t.Attr, "restart"),
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			case "type":
+				if val := getAttributeValue(t.Attr, "val"); val != "" {
+					sectPr.Type = &SectionType{Val: val}
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
 			case "cols":
 				// 解析分栏
 				space := getAttributeValue(t.Attr, "space")
@@ -2855,24 +3062,36 @@ func (d *Document) serializeDocument() error {
 
 	// 创建文档结构
 	type documentXML struct {
-		XMLName  xml.Name `xml:"w:document"`
-		Xmlns    string   `xml:"xmlns:w,attr"`
-		XmlnsW15 string   `xml:"xmlns:w15,attr"`
-		XmlnsWP  string   `xml:"xmlns:wp,attr"`
-		XmlnsA   string   `xml:"xmlns:a,attr"`
-		XmlnsPic string   `xml:"xmlns:pic,attr"`
-		XmlnsR   string   `xml:"xmlns:r,attr"`
-		Body     *Body    `xml:"w:body"`
+		XMLName    xml.Name    `xml:"w:document"`
+		Xmlns      string      `xml:"xmlns:w,attr"`
+		XmlnsW15   string      `xml:"xmlns:w15,attr"`
+		XmlnsWP    string      `xml:"xmlns:wp,attr"`
+		XmlnsA     string      `xml:"xmlns:a,attr"`
+		XmlnsPic   string      `xml:"xmlns:pic,attr"`
+		XmlnsR     string      `xml:"xmlns:r,attr"`
+		XmlnsMC    string      `xml:"xmlns:mc,attr"`
+		XmlnsWPS   string      `xml:"xmlns:wps,attr"`
+		XmlnsV     string      `xml:"xmlns:v,attr"`
+		XmlnsO     string      `xml:"xmlns:o,attr"`
+		XmlnsW10   string      `xml:"xmlns:w10,attr"`
+		Background *Background `xml:"w:background,omitempty"`
+		Body       *Body       `xml:"w:body"`
 	}
 
 	doc := documentXML{
-		Xmlns:    "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
-		XmlnsW15: "http://schemas.microsoft.com/office/word/2012/wordml",
-		XmlnsWP:  "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing",
-		XmlnsA:   "http://schemas.openxmlformats.org/drawingml/2006/main",
-		XmlnsPic: "http://schemas.openxmlformats.org/drawingml/2006/picture",
-		XmlnsR:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
-		Body:     d.Body,
+		Xmlns:      "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
+		XmlnsW15:   "http://schemas.microsoft.com/office/word/2012/wordml",
+		XmlnsWP:    "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing",
+		XmlnsA:     "http://schemas.openxmlformats.org/drawingml/2006/main",
+		XmlnsPic:   "http://schemas.openxmlformats.org/drawingml/2006/picture",
+		XmlnsR:     "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
+		XmlnsMC:    "http://schemas.openxmlformats.org/markup-compatibility/2006",
+		XmlnsWPS:   "http://schemas.microsoft.com/office/word/2010/wordprocessingShape",
+		XmlnsV:     "urn:schemas-microsoft-com:vml",
+		XmlnsO:     "urn:schemas-microsoft-com:office:office",
+		XmlnsW10:   "urn:schemas-microsoft-com:office:word",
+		Background: d.background,
+		Body:       d.Body,
 	}
 
 	// serialize为XML
@@ -2929,10 +3148,14 @@ func (d *Document) serializeDocumentRelationships() {
 func (d *Document) serializeStyles() error {
 	Debugf("开始序列化样式")
 
-	// 如果在克隆文档时已经保留了完整的 styles.xml（含 docDefaults 等信息），
-	// 这里直接跳过重新生成，避免丢失模板原有的默认段落/字符设置。
+	// 已有 styles.xml（打开或克隆的文档）时，将样式管理器的修改合并进去，
+	// 保留模板原有的 docDefaults、latentStyles 以及未修改的样式。
 	if existing, ok := d.parts["word/styles.xml"]; ok && len(existing) > 0 {
-		Debugf("检测到已有 styles.xml，跳过样式重建以保留模板默认样式")
+		merged, err := d.mergeStylesPart(existing)
+		if err != nil {
+			return err
+		}
+		d.parts["word/styles.xml"] = merged
 		return nil
 	}
 
@@ -3096,6 +3319,16 @@ func (d *Document) updateNextImageID() {
 		}
 	}
 
+	// pictures sharing a media part have their own docPr IDs
+	walkDrawings(d.Body.Elements, func(drawing *DrawingElement) bool {
+		if docPr := drawingDocPr(drawing); docPr != nil {
+			if id, err := strconv.Atoi(docPr.ID); err == nil && id > maxImageID {
+				maxImageID = id
+			}
+		}
+		return true
+	})
+
 	// 设置nextImageID为最大图片ID + 1
 	// 如果没有现有图片，maxImageID为-1，nextImageID应该为0
 	d.nextImageID = maxImageID + 1
@@ -3326,11 +3559,11 @@ func (d *Document) parseTableCellMargins(decoder *xml.Decoder) (*TableCellMargin
 			switch t.Name.Local {
 			case "top":
 				margins.Top = space
-			case "left":
+			case "left", "start":
 				margins.Left = space
 			case "bottom":
 				margins.Bottom = space
-			case "right":
+			case "right", "end":
 				margins.Right = space
 			}
 
@@ -3442,6 +3675,13 @@ func (d *Document) parseTableCellProperties(decoder *xml.Decoder) (*TableCellPro
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return nil, err
 				}
+			case "tcFitText":
+				// parse fit text
+				val := getAttributeValue(t.Attr, "val")
+				props.TcFitText = &TcFitText{Val: val}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
 			default:
 				// 跳过其他未处理的单元格属性
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
@@ -3479,11 +3719,11 @@ func (d *Document) parseTableCellBorders(decoder *xml.Decoder) (*TableCellBorder
 			switch t.Name.Local {
 			case "top":
 				borders.Top = border
-			case "left":
+			case "left", "start":
 				borders.Left = border
 			case "bottom":
 				borders.Bottom = border
-			case "right":
+			case "right", "end":
 				borders.Right = border
 			case "insideH":
 				borders.InsideH = border
@@ -3898,6 +4138,11 @@ func (d *Document) parseGraphicData(decoder *xml.Decoder, startElement xml.Start
 					return nil, err
 				}
 				graphicData.Pic = pic
+			case "chart":
+				graphicData.Chart = newChartReference(getAttributeValue(t.Attr, "id"))
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
 			default:
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return nil, err
@@ -4030,13 +4275,26 @@ func (d *Document) parseBlipFill(decoder *xml.Decoder, startElement xml.StartEle
 		case xml.StartElement:
 			switch t.Name.Local {
 			case "blip":
-				blip := &Blip{}
+				blip, err := d.parseBlip(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				blipFill.Blip = blip
+			case "srcRect":
+				srcRect := &SrcRect{}
 				for _, attr := range t.Attr {
-					if attr.Name.Local == "embed" {
-						blip.Embed = attr.Value
+					switch attr.Name.Local {
+					case "l":
+						srcRect.L = attr.Value
+					case "t":
+						srcRect.T = attr.Value
+					case "r":
+						srcRect.R = attr.Value
+					case "b":
+						srcRect.B = attr.Value
 					}
 				}
-				blipFill.Blip = blip
+				blipFill.SrcRect = srcRect
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return nil, err
 				}
@@ -4058,6 +4316,54 @@ func (d *Document) parseBlipFill(decoder *xml.Decoder, startElement xml.StartEle
 	}
 }
 
+// parseBlip parses an image reference, including the SVG image of the svgBlip extension
+func (d *Document) parseBlip(decoder *xml.Decoder, startElement xml.StartElement) (*Blip, error) {
+	blip := &Blip{}
+	for _, attr := range startElement.Attr {
+		if attr.Name.Local == "embed" {
+			blip.Embed = attr.Value
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, WrapError("parse_blip", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "extLst", "ext":
+				// descend into the extension list
+			case "svgBlip":
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "embed" {
+						blip.ExtLst = &BlipExtList{Exts: []BlipExt{{
+							URI: svgBlipExtURI,
+							SVGBlip: &SVGBlip{
+								Xmlns: "http://schemas.microsoft.com/office/drawing/2016/SVG/main",
+								Embed: attr.Value,
+							},
+						}}}
+					}
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			default:
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "blip" {
+				return blip, nil
+			}
+		}
+	}
+}
+
 // parseSpPr 解析形状属性
 func (d *Document) parseSpPr(decoder *xml.Decoder, startElement xml.StartElement) (*SpPr, error) {
 	spPr := &SpPr{}
@@ -4088,6 +4394,18 @@ func (d *Document) parseSpPr(decoder *xml.Decoder, startElement xml.StartElement
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return nil, err
 				}
+			case "ln":
+				ln, err := d.parseOutline(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				spPr.Ln = ln
+			case "effectLst":
+				effectLst, err := d.parseEffectList(decoder)
+				if err != nil {
+					return nil, err
+				}
+				spPr.EffectLst = effectLst
 			default:
 				if err := d.skipElement(decoder, t.Name.Local); err != nil {
 					return nil, err
@@ -4104,6 +4422,16 @@ func (d *Document) parseSpPr(decoder *xml.Decoder, startElement xml.StartElement
 // parseXfrm 解析变换元素
 func (d *Document) parseXfrm(decoder *xml.Decoder, startElement xml.StartElement) (*Xfrm, error) {
 	xfrm := &Xfrm{}
+	for _, attr := range startElement.Attr {
+		switch attr.Name.Local {
+		case "rot":
+			xfrm.Rot = attr.Value
+		case "flipH":
+			xfrm.FlipH = attr.Value
+		case "flipV":
+			xfrm.FlipV = attr.Value
+		}
+	}
 
 	for {
 		token, err := decoder.Token()
@@ -4154,3 +4482,127 @@ func (d *Document) parseXfrm(decoder *xml.Decoder, startElement xml.StartElement
 		}
 	}
 }
+
+// parseOutline parses the border line of a picture
+func (d *Document) parseOutline(decoder *xml.Decoder, startElement xml.StartElement) (*Outline, error) {
+	ln := &Outline{W: getAttributeValue(startElement.Attr, "w")}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, WrapError("parse_outline", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "solidFill":
+				fill, err := d.parseSolidColor(decoder, t.Name.Local)
+				if err != nil {
+					return nil, err
+				}
+				ln.SolidFill = fill
+			case "prstDash":
+				ln.PrstDash = &PrstDash{Val: getAttributeValue(t.Attr, "val")}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			default:
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "ln" {
+				return ln, nil
+			}
+		}
+	}
+}
+
+// parseEffectList parses the shadow and soft edge effects of a picture
+func (d *Document) parseEffectList(decoder *xml.Decoder) (*EffectList, error) {
+	effects := &EffectList{}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, WrapError("parse_effect_list", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "outerShdw":
+				shadow := &OuterShdw{
+					BlurRad:      getAttributeValue(t.Attr, "blurRad"),
+					Dist:         getAttributeValue(t.Attr, "dist"),
+					Dir:          getAttributeValue(t.Attr, "dir"),
+					Algn:         getAttributeValue(t.Attr, "algn"),
+					RotWithShape: getAttributeValue(t.Attr, "rotWithShape"),
+				}
+				fill, err := d.parseSolidColor(decoder, t.Name.Local)
+				if err != nil {
+					return nil, err
+				}
+				if fill != nil {
+					shadow.SrgbClr, shadow.SchemeClr = fill.SrgbClr, fill.SchemeClr
+				} else {
+					shadow.SrgbClr = &SrgbClr{Val: "000000"}
+				}
+				effects.OuterShdw = shadow
+			case "softEdge":
+				effects.SoftEdge = &SoftEdge{Rad: getAttributeValue(t.Attr, "rad")}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			default:
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "effectLst" {
+				return normalizeEffectList(effects), nil
+			}
+		}
+	}
+}
+
+// parseSolidColor reads the RGB or theme color inside an element until the element ends
+// nil is returned when the element holds neither.
+func (d *Document) parseSolidColor(decoder *xml.Decoder, elementName string) (*SolidFill, error) {
+	var fill *SolidFill
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, WrapError("parse_solid_color", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "srgbClr":
+				fill = &SolidFill{SrgbClr: &SrgbClr{Val: getAttributeValue(t.Attr, "val")}}
+			case "schemeClr":
+				fill = &SolidFill{SchemeClr: &SchemeClr{Val: getAttributeValue(t.Attr, "val")}}
+			default:
+				if fill != nil && fill.SrgbClr != nil && t.Name.Local == "alpha" {
+					fill.SrgbClr.Alpha = &Alpha{Val: getAttributeValue(t.Attr, "val")}
+				} else if fill != nil && fill.SchemeClr != nil {
+					fill.SchemeClr.Transforms = append(fill.SchemeClr.Transforms, ColorTransform{
+						XMLName: xml.Name{Local: "a:" + t.Name.Local},
+						Val:     getAttributeValue(t.Attr, "val"),
+					})
+				}
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == elementName {
+				return fill, nil
+			}
+		}
+	}
+}
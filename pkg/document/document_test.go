@@ -2,6 +2,7 @@ package document
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/drumkitai/go-word/pkg/style"
@@ -312,6 +313,95 @@ func TestParagraphSetStyle(t *testing.T) {
 	}
 }
 
+// TestRunSetStyle tests character style references on runs
+func TestRunSetStyle(t *testing.T) {
+	doc := New()
+	doc.GetStyleManager().CreateCustomStyle("Keyword", "Keyword", style.StyleTypeCharacter, "")
+
+	para := doc.AddParagraph("plain ")
+	para.AddFormattedText("styled", &TextFormat{StyleID: "Keyword", Bold: true})
+	para.Runs[0].SetStyle("Emphasis")
+
+	if para.Runs[0].Properties.RunStyle == nil || para.Runs[0].Properties.RunStyle.Val != "Emphasis" {
+		t.Error("expected first run style Emphasis")
+	}
+	if para.Runs[1].Properties.RunStyle == nil || para.Runs[1].Properties.RunStyle.Val != "Keyword" {
+		t.Fatal("expected second run style Keyword")
+	}
+
+	para.Runs[0].SetStyle("")
+	if para.Runs[0].Properties.RunStyle != nil {
+		t.Error("empty style ID should clear the run style")
+	}
+
+	// rStyle must be the first child of rPr
+	if err := doc.serializeDocument(); err != nil {
+		t.Fatalf("failed to serialize document: %v", err)
+	}
+	xmlStr := string(doc.parts["word/document.xml"])
+	styleIdx := strings.Index(xmlStr, `<w:rStyle w:val="Keyword">`)
+	if styleIdx < 0 || strings.Index(xmlStr[styleIdx:], "<w:b>") < 0 {
+		t.Errorf("rStyle should be serialized before other run properties: %s", xmlStr)
+	}
+
+	filename := "test_run_style.docx"
+	defer os.Remove(filename)
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	loadedDoc, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	runs := loadedDoc.Body.GetParagraphs()[0].Runs
+	if len(runs) != 2 || runs[1].Properties == nil || runs[1].Properties.RunStyle == nil ||
+		runs[1].Properties.RunStyle.Val != "Keyword" {
+		t.Error("run style should survive a save/open round trip")
+	}
+}
+
+// TestTableStyleReference tests referencing a style manager table style from a table
+func TestTableStyleReference(t *testing.T) {
+	doc := New()
+	api := style.NewQuickStyleAPI(doc.GetStyleManager())
+	_, err := api.CreateQuickTableStyle(style.QuickTableStyleConfig{
+		ID:   "Report",
+		Name: "Report",
+		Regions: map[style.TableStyleOverrideType]*style.QuickTableRegionConfig{
+			style.TableRegionFirstRow: {Shading: "DDDDDD"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table style: %v", err)
+	}
+
+	table, err := doc.AddTable(&TableConfig{Rows: 2, Cols: 2, Width: 4000})
+	if err != nil {
+		t.Fatalf("failed to add table: %v", err)
+	}
+	if err := table.ApplyTableStyle(&TableStyleConfig{StyleID: "Report", FirstRowHeader: true}); err != nil {
+		t.Fatalf("failed to apply table style: %v", err)
+	}
+
+	if err := doc.serializeDocument(); err != nil {
+		t.Fatalf("failed to serialize document: %v", err)
+	}
+	docXML := string(doc.parts["word/document.xml"])
+	tblStyleIdx := strings.Index(docXML, `<w:tblStyle w:val="Report">`)
+	if tblStyleIdx < 0 || tblStyleIdx > strings.Index(docXML, "<w:tblW ") {
+		t.Error("tblStyle should be the first child of tblPr")
+	}
+
+	if err := doc.serializeStyles(); err != nil {
+		t.Fatalf("failed to serialize styles: %v", err)
+	}
+	stylesXML := string(doc.parts["word/styles.xml"])
+	if !strings.Contains(stylesXML, `<w:tblStylePr w:type="firstRow">`) {
+		t.Error("styles.xml should contain the conditional format")
+	}
+}
+
 // TestParagraphSetIndentation 测试段落缩进设置
 func TestParagraphSetIndentation(t *testing.T) {
 	doc := New()
@@ -4,6 +4,8 @@ package document
 import (
 	"encoding/xml"
 	"fmt"
+	"strings"
+	"unicode"
 )
 
 // FieldChar field character
@@ -72,3 +74,56 @@ func CreatePageRefField(anchor string) PageRefField {
 		},
 	}
 }
+
+// paragraphFieldInstructions returns the instructions of the complex fields of a paragraph in order
+func paragraphFieldInstructions(p *Paragraph) []string {
+	var instructions []string
+	var instruction strings.Builder
+	inInstruction := false
+	for _, run := range p.Runs {
+		switch {
+		case run.FieldChar != nil && run.FieldChar.FieldCharType == "begin":
+			instruction.Reset()
+			inInstruction = true
+		case run.InstrText != nil && inInstruction:
+			instruction.WriteString(run.InstrText.Content)
+		case run.FieldChar != nil && inInstruction:
+			instructions = append(instructions, instruction.String())
+			inInstruction = false
+		}
+	}
+	return instructions
+}
+
+// splitFieldInstruction splits a field instruction into its arguments
+// Quoted arguments may contain spaces and \" for a quote, other backslashes are kept
+func splitFieldInstruction(instruction string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes, inArg := false, false
+	runes := []rune(instruction)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case inQuotes && r == '\\' && i+1 < len(runes) && runes[i+1] == '"':
+			current.WriteRune('"')
+			i++
+		case r == '"':
+			inQuotes = !inQuotes
+			inArg = true
+		case !inQuotes && unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,166 @@
+// Package document provides approximate font metrics for measuring text widths
+package document
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fontMetrics holds the advance widths of a font in 1/1000 em
+type fontMetrics struct {
+	ascii [95]int // widths of the printable ASCII characters from ' ' to '~'
+	other int     // width used for other characters that are not full width
+}
+
+// defaultMeasureFont is the font assumed for runs without a font, the default of new documents
+const defaultMeasureFont = "Calibri"
+
+// defaultMeasureFontSize is the font size in half-points assumed for runs without a size
+const defaultMeasureFontSize = 21
+
+// boldWidthFactor approximates how much wider bold text is than regular text
+const boldWidthFactor = 1.05
+
+var (
+	// calibriMetrics are the widths of Calibri
+	calibriMetrics = &fontMetrics{
+		ascii: [95]int{
+			226, 326, 401, 498, 507, 715, 682, 221, 303, 303, 498, 498, 250, 306, 252, 386, // ' ' - '/'
+			507, 507, 507, 507, 507, 507, 507, 507, 507, 507, // '0' - '9'
+			268, 268, 498, 498, 498, 463, 894, // ':' - '@'
+			579, 544, 533, 615, 488, 459, 631, 623, 252, 319, 520, 420, 855, // 'A' - 'M'
+			646, 662, 517, 673, 543, 459, 487, 642, 567, 890, 519, 487, 468, // 'N' - 'Z'
+			324, 386, 324, 498, 498, 291, // '[' - '`'
+			479, 525, 423, 525, 498, 305, 471, 525, 229, 239, 455, 229, 799, // 'a' - 'm'
+			525, 527, 525, 525, 349, 391, 335, 525, 452, 715, 433, 453, 395, // 'n' - 'z'
+			314, 460, 314, 498, // '{' - '~'
+		},
+		other: 500,
+	}
+
+	// arialMetrics are the widths of Arial, metric compatible with Helvetica
+	arialMetrics = &fontMetrics{
+		ascii: [95]int{
+			278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+			556, 556, 556, 556, 556, 556, 556, 556, 556, 556,
+			278, 278, 584, 584, 584, 556, 1015,
+			667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833,
+			722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611,
+			278, 278, 278, 469, 556, 333,
+			556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833,
+			556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500,
+			334, 260, 334, 584,
+		},
+		other: 556,
+	}
+
+	// timesMetrics are the widths of Times New Roman
+	timesMetrics = &fontMetrics{
+		ascii: [95]int{
+			250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+			500, 500, 500, 500, 500, 500, 500, 500, 500, 500,
+			278, 278, 564, 564, 564, 444, 921,
+			722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889,
+			722, 722, 556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611,
+			333, 278, 333, 469, 500, 333,
+			444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778,
+			500, 500, 500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444,
+			480, 200, 480, 541,
+		},
+		other: 500,
+	}
+
+	// fontMetricsByName maps lower case font names to their metrics, including metric compatible fonts
+	fontMetricsByName = map[string]*fontMetrics{
+		"calibri":          calibriMetrics,
+		"carlito":          calibriMetrics,
+		"arial":            arialMetrics,
+		"helvetica":        arialMetrics,
+		"liberation sans":  arialMetrics,
+		"arimo":            arialMetrics,
+		"times new roman":  timesMetrics,
+		"times":            timesMetrics,
+		"liberation serif": timesMetrics,
+		"tinos":            timesMetrics,
+		"courier new":      monospaceMetrics(600),
+		"courier":          monospaceMetrics(600),
+		"liberation mono":  monospaceMetrics(600),
+		"cousine":          monospaceMetrics(600),
+		"consolas":         monospaceMetrics(550),
+	}
+)
+
+// monospaceMetrics creates the metrics of a font whose characters all have the same width
+func monospaceMetrics(width int) *fontMetrics {
+	metrics := &fontMetrics{other: width}
+	for i := range metrics.ascii {
+		metrics.ascii[i] = width
+	}
+	return metrics
+}
+
+// lookupFontMetrics returns the metrics of a font, unknown fonts are measured as Calibri
+func lookupFontMetrics(fontName string) *fontMetrics {
+	if metrics, ok := fontMetricsByName[strings.ToLower(strings.TrimSpace(fontName))]; ok {
+		return metrics
+	}
+	return calibriMetrics
+}
+
+// runeWidth returns the width of a character in 1/1000 em
+func (m *fontMetrics) runeWidth(r rune) int {
+	switch {
+	case r >= ' ' && r <= '~':
+		return m.ascii[r-' ']
+	case r == '\t':
+		return 4 * m.ascii[0]
+	case isFullWidthRune(r):
+		return 1000
+	case unicode.IsMark(r) || unicode.IsControl(r):
+		return 0
+	}
+	return m.other
+}
+
+// isFullWidthRune reports whether a character is rendered full width, such as CJK ideographs
+func isFullWidthRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303F) || // CJK symbols and punctuation
+		(r >= 0xFF00 && r <= 0xFF60) || // full width forms
+		(r >= 0xFFE0 && r <= 0xFFE6)
+}
+
+// measureRunText returns the width of text in twips for a run with the given properties
+func measureRunText(text string, props *RunProperties) int {
+	fontName := defaultMeasureFont
+	size := defaultMeasureFontSize
+	bold := false
+	if props != nil {
+		if props.FontFamily != nil {
+			if props.FontFamily.ASCII != "" {
+				fontName = props.FontFamily.ASCII
+			} else if props.FontFamily.HAnsi != "" {
+				fontName = props.FontFamily.HAnsi
+			}
+		}
+		if props.FontSize != nil {
+			if v := int(parseFloat(props.FontSize.Val)); v > 0 {
+				size = v
+			}
+		}
+		bold = props.Bold != nil
+	}
+
+	metrics := lookupFontMetrics(fontName)
+	units := 0
+	for _, r := range text {
+		units += metrics.runeWidth(r)
+	}
+
+	// units are 1/1000 em, the em is the font size: half-points * 10 twips
+	width := float64(units) * float64(size) * 10 / 1000
+	if bold {
+		width *= boldWidthFactor
+	}
+	return int(width + 0.5)
+}
@@ -4,7 +4,11 @@ package document
 import (
 	"encoding/xml"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+
+	"github.com/drumkitai/go-word/pkg/style"
 )
 
 // FootnoteType footnote type
@@ -49,14 +53,26 @@ type Endnote struct {
 
 // FootnoteReference footnote reference
 type FootnoteReference struct {
-	XMLName xml.Name `xml:"w:footnoteReference"`
-	ID      string   `xml:"w:id,attr"`
+	XMLName           xml.Name `xml:"w:footnoteReference"`
+	CustomMarkFollows string   `xml:"w:customMarkFollows,attr,omitempty"`
+	ID                string   `xml:"w:id,attr"`
 }
 
 // EndnoteReference endnote reference
 type EndnoteReference struct {
-	XMLName xml.Name `xml:"w:endnoteReference"`
-	ID      string   `xml:"w:id,attr"`
+	XMLName           xml.Name `xml:"w:endnoteReference"`
+	CustomMarkFollows string   `xml:"w:customMarkFollows,attr,omitempty"`
+	ID                string   `xml:"w:id,attr"`
+}
+
+// FootnoteRef footnote number mark inside a footnote
+type FootnoteRef struct {
+	XMLName xml.Name `xml:"w:footnoteRef"`
+}
+
+// EndnoteRef endnote number mark inside an endnote
+type EndnoteRef struct {
+	XMLName xml.Name `xml:"w:endnoteRef"`
 }
 
 // FootnoteConfig footnote configuration
@@ -131,10 +147,124 @@ type EndnoteProperties struct {
 type Settings struct {
 	XMLName                 xml.Name                 `xml:"w:settings"`
 	Xmlns                   string                   `xml:"xmlns:w,attr"`
+	DisplayBackgroundShape  *DisplayBackgroundShape  `xml:"w:displayBackgroundShape,omitempty"`
 	DefaultTabStop          *DefaultTabStop          `xml:"w:defaultTabStop,omitempty"`
+	EvenAndOddHeaders       *EvenAndOddHeaders       `xml:"w:evenAndOddHeaders,omitempty"`
 	CharacterSpacingControl *CharacterSpacingControl `xml:"w:characterSpacingControl,omitempty"`
 	FootnotePr              *FootnotePr              `xml:"w:footnotePr,omitempty"`
 	EndnotePr               *EndnotePr               `xml:"w:endnotePr,omitempty"`
+	// Attrs and Other keep the namespaces and the settings this package does not model
+	Attrs []xml.Attr     `xml:",any,attr"`
+	Other []settingsNode `xml:",any"`
+}
+
+// settingsNode is a setting kept as read
+type settingsNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr     `xml:",any,attr"`
+	Text     string         `xml:",chardata"`
+	Children []settingsNode `xml:",any"`
+}
+
+// settingsOrder is the position of the settings in the schema, which Word requires them to follow
+var settingsOrder = func() map[string]int {
+	order := make(map[string]int)
+	for i, name := range []string{
+		"writeProtection", "view", "zoom", "removePersonalInformation", "removeDateAndTime",
+		"doNotDisplayPageBoundaries", "displayBackgroundShape", "printPostScriptOverText",
+		"printFractionalCharacterWidth", "printFormsData", "embedTrueTypeFonts", "embedSystemFonts",
+		"saveSubsetFonts", "saveFormsData", "mirrorMargins", "alignBordersAndEdges",
+		"bordersDoNotSurroundHeader", "bordersDoNotSurroundFooter", "gutterAtTop", "hideSpellingErrors",
+		"hideGrammaticalErrors", "activeWritingStyle", "proofState", "formsDesign", "attachedTemplate",
+		"linkStyles", "stylePaneFormatFilter", "stylePaneSortMethod", "documentType", "mailMerge",
+		"revisionView", "trackRevisions", "doNotTrackMoves", "doNotTrackFormatting", "documentProtection",
+		"autoFormatOverride", "styleLockTheme", "styleLockQFSet", "defaultTabStop", "autoHyphenation",
+		"consecutiveHyphenLimit", "hyphenationZone", "doNotHyphenateCaps", "showEnvelope", "summaryLength",
+		"clickAndTypeStyle", "defaultTableStyle", "evenAndOddHeaders", "bookFoldRevPrinting",
+		"bookFoldPrinting", "bookFoldPrintingSheets", "drawingGridHorizontalSpacing",
+		"drawingGridVerticalSpacing", "displayHorizontalDrawingGridEvery", "displayVerticalDrawingGridEvery",
+		"doNotUseMarginsForDrawingGridOrigin", "drawingGridHorizontalOrigin", "drawingGridVerticalOrigin",
+		"doNotShadeFormData", "noPunctuationKerning", "characterSpacingControl", "printTwoOnOne",
+		"strictFirstAndLastChars", "noLineBreaksAfter", "noLineBreaksBefore", "savePreviewPicture",
+		"doNotValidateAgainstSchema", "saveInvalidXml", "ignoreMixedContent", "alwaysShowPlaceholderText",
+		"doNotDemarcateInvalidXml", "saveXmlDataOnly", "useXSLTWhenSaving", "saveThroughXslt", "showXMLTags",
+		"alwaysMergeEmptyNamespace", "updateFields", "hdrShapeDefaults", "footnotePr", "endnotePr", "compat",
+		"docVars", "rsids", "m:mathPr", "attachedSchema", "themeFontLang", "clrSchemeMapping",
+		"doNotIncludeSubdocsInStats", "doNotAutoCompressPictures", "forceUpgrade", "captions",
+		"readModeInkLockDown", "smartTagType", "sl:schemaLibrary", "shapeDefaults", "doNotEmbedSmartTags",
+		"decimalSymbol", "listSeparator",
+	} {
+		if !strings.Contains(name, ":") {
+			name = "w:" + name
+		}
+		order[name] = i + 1
+	}
+	return order
+}()
+
+// MarshalXML writes the settings in the order of the schema, settings that are not part of it
+// stay after the setting they were read after
+func (s *Settings) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type entry struct {
+		order int
+		value interface{}
+	}
+	var entries []entry
+	order := 0
+	for i := range s.Other {
+		if index, ok := settingsOrder[s.Other[i].XMLName.Local]; ok {
+			order = index
+		}
+		entries = append(entries, entry{order, &s.Other[i]})
+	}
+	for _, known := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"w:displayBackgroundShape", s.DisplayBackgroundShape},
+		{"w:defaultTabStop", s.DefaultTabStop},
+		{"w:evenAndOddHeaders", s.EvenAndOddHeaders},
+		{"w:characterSpacingControl", s.CharacterSpacingControl},
+		{"w:footnotePr", s.FootnotePr},
+		{"w:endnotePr", s.EndnotePr},
+	} {
+		entries = append(entries, entry{settingsOrder[known.name], known.value})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].order < entries[j].order })
+
+	start = xml.StartElement{
+		Name: xml.Name{Local: "w:settings"},
+		Attr: append([]xml.Attr{{Name: xml.Name{Local: "xmlns:w"}, Value: s.Xmlns}}, s.Attrs...),
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := e.Encode(entry.value); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// trimSettingsText drops the indentation read between settings
+func trimSettingsText(nodes []settingsNode) {
+	for i := range nodes {
+		if strings.TrimSpace(nodes[i].Text) == "" {
+			nodes[i].Text = ""
+		}
+		trimSettingsText(nodes[i].Children)
+	}
+}
+
+// DisplayBackgroundShape shows the page background
+type DisplayBackgroundShape struct {
+	XMLName xml.Name `xml:"w:displayBackgroundShape"`
+}
+
+// EvenAndOddHeaders gives even pages their own headers and footers
+type EvenAndOddHeaders struct {
+	XMLName xml.Name `xml:"w:evenAndOddHeaders"`
 }
 
 // DefaultTabStop default tab stop settings
@@ -215,30 +345,6 @@ type EndnotePos struct {
 	Val     string   `xml:"w:val,attr"`
 }
 
-// Global footnote/endnote manager
-var globalFootnoteManager *FootnoteManager
-
-// FootnoteManager footnote manager
-type FootnoteManager struct {
-	nextFootnoteID int
-	nextEndnoteID  int
-	footnotes      map[string]*Footnote
-	endnotes       map[string]*Endnote
-}
-
-// getFootnoteManager gets the global footnote manager
-func getFootnoteManager() *FootnoteManager {
-	if globalFootnoteManager == nil {
-		globalFootnoteManager = &FootnoteManager{
-			nextFootnoteID: 1,
-			nextEndnoteID:  1,
-			footnotes:      make(map[string]*Footnote),
-			endnotes:       make(map[string]*Endnote),
-		}
-	}
-	return globalFootnoteManager
-}
-
 // DefaultFootnoteConfig returns default footnote configuration
 func DefaultFootnoteConfig() *FootnoteConfig {
 	return &FootnoteConfig{
@@ -249,32 +355,18 @@ func DefaultFootnoteConfig() *FootnoteConfig {
 	}
 }
 
-// AddFootnote adds a footnote
+// AddFootnote adds a paragraph with text followed by a footnote reference
 func (d *Document) AddFootnote(text string, footnoteText string) error {
 	return d.addFootnoteOrEndnote(text, footnoteText, FootnoteTypeFootnote)
 }
 
-// AddEndnote adds an endnote
+// AddEndnote adds a paragraph with text followed by an endnote reference
 func (d *Document) AddEndnote(text string, endnoteText string) error {
 	return d.addFootnoteOrEndnote(text, endnoteText, FootnoteTypeEndnote)
 }
 
 // addFootnoteOrEndnote generic method to add footnotes or endnotes
 func (d *Document) addFootnoteOrEndnote(text string, noteText string, noteType FootnoteType) error {
-	manager := getFootnoteManager()
-
-	// Ensure footnote/endnote system is initialized
-	d.ensureFootnoteInitialized(noteType)
-
-	var noteID string
-	if noteType == FootnoteTypeFootnote {
-		noteID = strconv.Itoa(manager.nextFootnoteID)
-		manager.nextFootnoteID++
-	} else {
-		noteID = strconv.Itoa(manager.nextEndnoteID)
-		manager.nextEndnoteID++
-	}
-
 	paragraph := &Paragraph{}
 
 	if text != "" {
@@ -283,45 +375,25 @@ func (d *Document) addFootnoteOrEndnote(text string, noteText string, noteType F
 		}
 		paragraph.Runs = append(paragraph.Runs, textRun)
 	}
-
-	// Add footnote/endnote reference
-	refRun := Run{
-		Properties: &RunProperties{},
-	}
-
-	if noteType == FootnoteTypeFootnote {
-		// Simplified handling: insert footnote marker in text
-		refRun.Text = Text{Content: fmt.Sprintf("[%s]", noteID)}
-	} else {
-		// Simplified handling: insert endnote marker in text
-		refRun.Text = Text{Content: fmt.Sprintf("[endnote%s]", noteID)}
-	}
-
-	paragraph.Runs = append(paragraph.Runs, refRun)
 	d.Body.Elements = append(d.Body.Elements, paragraph)
 
-	// Create footnote/endnote content
-	if err := d.createNoteContent(noteID, noteText, noteType); err != nil {
+	// Create footnote/endnote content and its reference at the end of the paragraph
+	_, err := d.addNote(paragraph, len(paragraph.Runs), noteType, func(b *NoteBuilder) {
+		b.AddParagraph(noteText)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to create %s content: %v", noteType, err)
 	}
 
 	return nil
 }
 
-// AddFootnoteToRun adds a footnote reference to an existing Run
+// AddFootnoteToRun adds a footnote reference after a run of a document paragraph
 func (d *Document) AddFootnoteToRun(run *Run, footnoteText string) error {
-	manager := getFootnoteManager()
-	d.ensureFootnoteInitialized(FootnoteTypeFootnote)
-
-	noteID := strconv.Itoa(manager.nextFootnoteID)
-	manager.nextFootnoteID++
-
-	// Add footnote reference after current Run
-	refText := fmt.Sprintf("[%s]", noteID)
-	run.Text.Content += refText
-
-	// Create footnote content
-	return d.createNoteContent(noteID, footnoteText, FootnoteTypeFootnote)
+	_, err := d.AddFootnoteWithContent(run, func(b *NoteBuilder) {
+		b.AddParagraph(footnoteText)
+	})
+	return err
 }
 
 // SetFootnoteConfig sets footnote configuration
@@ -450,188 +522,48 @@ func (d *Document) initializeEndnotes() {
 	d.addEndnoteRelationship()
 }
 
-// createNoteContent creates footnote/endnote content
-func (d *Document) createNoteContent(noteID string, noteText string, noteType FootnoteType) error {
-	manager := getFootnoteManager()
-
-	// Create footnote/endnote paragraph
-	noteParagraph := &Paragraph{
-		Runs: []Run{
-			{
-				Text: Text{Content: noteText},
-			},
-		},
-	}
-
-	if noteType == FootnoteTypeFootnote {
-		// Create footnote
-		footnote := &Footnote{
-			ID:         noteID,
-			Paragraphs: []*Paragraph{noteParagraph},
-		}
-		manager.footnotes[noteID] = footnote
-
-		// Update footnote file
-		d.updateFootnotesFile()
-	} else {
-		// Create endnote
-		endnote := &Endnote{
-			ID:         noteID,
-			Paragraphs: []*Paragraph{noteParagraph},
-		}
-		manager.endnotes[noteID] = endnote
-
-		// Update endnote file
-		d.updateEndnotesFile()
-	}
-
-	return nil
-}
-
-// updateFootnotesFile updates the footnotes file
-func (d *Document) updateFootnotesFile() {
-	manager := getFootnoteManager()
-
-	footnotes := &Footnotes{
-		Xmlns:     "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
-		Footnotes: []*Footnote{},
-	}
-
-	// Add default separator
-	separatorFootnote := &Footnote{
-		Type: "separator",
-		ID:   "-1",
-		Paragraphs: []*Paragraph{
-			{
-				Runs: []Run{
-					{
-						Text: Text{Content: ""},
-					},
-				},
-			},
-		},
-	}
-	footnotes.Footnotes = append(footnotes.Footnotes, separatorFootnote)
-
-	// 添加所有脚注
-	for _, footnote := range manager.footnotes {
-		footnotes.Footnotes = append(footnotes.Footnotes, footnote)
-	}
-
-	// serialize
-	footnotesXML, err := xml.MarshalIndent(footnotes, "", "  ")
-	if err != nil {
-		return
-	}
-
-	// add XML declaration
-	xmlDeclaration := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
-	d.parts["word/footnotes.xml"] = append(xmlDeclaration, footnotesXML...)
-}
-
-// updateEndnotesFile updates the endnotes file
-func (d *Document) updateEndnotesFile() {
-	manager := getFootnoteManager()
-
-	endnotes := &Endnotes{
-		Xmlns:    "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
-		Endnotes: []*Endnote{},
-	}
-
-	// Add default separator
-	separatorEndnote := &Endnote{
-		Type: "separator",
-		ID:   "-1",
-		Paragraphs: []*Paragraph{
-			{
-				Runs: []Run{
-					{
-						Text: Text{Content: ""},
-					},
-				},
-			},
-		},
-	}
-	endnotes.Endnotes = append(endnotes.Endnotes, separatorEndnote)
-
-	// Add all endnotes
-	for _, endnote := range manager.endnotes {
-		endnotes.Endnotes = append(endnotes.Endnotes, endnote)
-	}
-
-	// serialize
-	endnotesXML, err := xml.MarshalIndent(endnotes, "", "  ")
-	if err != nil {
-		return
-	}
-
-	// add XML declaration
-	xmlDeclaration := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
-	d.parts["word/endnotes.xml"] = append(xmlDeclaration, endnotesXML...)
-}
-
 // addFootnoteRelationship adds a footnote relationship
 func (d *Document) addFootnoteRelationship() {
-	relationshipID := fmt.Sprintf("rId%d", len(d.relationships.Relationships)+1)
+	relationshipID := fmt.Sprintf("rId%d", len(d.documentRelationships.Relationships)+2)
 
 	relationship := Relationship{
 		ID:     relationshipID,
 		Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footnotes",
 		Target: "footnotes.xml",
 	}
-	d.relationships.Relationships = append(d.relationships.Relationships, relationship)
+	d.documentRelationships.Relationships = append(d.documentRelationships.Relationships, relationship)
 }
 
 // addEndnoteRelationship adds an endnote relationship
 func (d *Document) addEndnoteRelationship() {
-	relationshipID := fmt.Sprintf("rId%d", len(d.relationships.Relationships)+1)
+	relationshipID := fmt.Sprintf("rId%d", len(d.documentRelationships.Relationships)+2)
 
 	relationship := Relationship{
 		ID:     relationshipID,
 		Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/endnotes",
 		Target: "endnotes.xml",
 	}
-	d.relationships.Relationships = append(d.relationships.Relationships, relationship)
+	d.documentRelationships.Relationships = append(d.documentRelationships.Relationships, relationship)
 }
 
 // GetFootnoteCount returns the number of footnotes
 func (d *Document) GetFootnoteCount() int {
-	manager := getFootnoteManager()
-	return len(manager.footnotes)
+	return len(d.Footnotes())
 }
 
 // GetEndnoteCount returns the number of endnotes
 func (d *Document) GetEndnoteCount() int {
-	manager := getFootnoteManager()
-	return len(manager.endnotes)
+	return len(d.Endnotes())
 }
 
-// RemoveFootnote removes a specified footnote
+// RemoveFootnote removes a specified footnote and its reference
 func (d *Document) RemoveFootnote(footnoteID string) error {
-	manager := getFootnoteManager()
-
-	if _, exists := manager.footnotes[footnoteID]; !exists {
-		return fmt.Errorf("footnote %s does not exist", footnoteID)
-	}
-
-	delete(manager.footnotes, footnoteID)
-	d.updateFootnotesFile()
-
-	return nil
+	return d.removeNote(FootnoteTypeFootnote, footnoteID)
 }
 
-// RemoveEndnote removes a specified endnote
+// RemoveEndnote removes a specified endnote and its reference
 func (d *Document) RemoveEndnote(endnoteID string) error {
-	manager := getFootnoteManager()
-
-	if _, exists := manager.endnotes[endnoteID]; !exists {
-		return fmt.Errorf("endnote %s does not exist", endnoteID)
-	}
-
-	delete(manager.endnotes, endnoteID)
-	d.updateEndnotesFile()
-
-	return nil
+	return d.removeNote(FootnoteTypeEndnote, endnoteID)
 }
 
 // ensureSettingsInitialized ensures document settings are initialized
@@ -726,29 +658,20 @@ func (d *Document) updateDocumentSettings(footnoteProps *FootnoteProperties, end
 // parseSettings parses the settings.xml file
 func (d *Document) parseSettings() (*Settings, error) {
 	settingsData, exists := d.parts["word/settings.xml"]
-	if !exists {
+	if !exists || len(settingsData) == 0 {
 		// If settings.xml does not exist, return default settings
 		return d.createDefaultSettings(), nil
 	}
 
-	var settings Settings
-
-	// Using xml.Unmarshal directly may have namespace issues, so we use string replacement
-	// Replace w:settings with settings, etc., and then parse using a simplified structure
-	settingsStr := string(settingsData)
-
-	// If XML contains w: prefix, it's serialized XML, directly create default settings and update
-	// This is a simplified approach to avoid namespace parsing issues
-	if len(settingsStr) > 0 {
-		// If file exists and is not empty, use default settings as base
-		settings = *d.createDefaultSettings()
-
-		// Complex XML parsing logic can be added here later
-		// For now, simplified processing, return default settings
-		return &settings, nil
+	settings := &Settings{}
+	if err := style.UnmarshalPrefixedXML(settingsData, settings); err != nil {
+		return nil, WrapError("parse_settings", err)
 	}
-
-	return d.createDefaultSettings(), nil
+	if settings.Xmlns == "" {
+		settings.Xmlns = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	}
+	trimSettingsText(settings.Other)
+	return settings, nil
 }
 
 // createDefaultSettings creates default settings
@@ -535,6 +535,9 @@ func createFormattedParagraph(text string, format *TextFormat, alignment Alignme
 				runProps.Highlight = &Highlight{Val: format.Highlight}
 			}
 
+			// 设置字符样式
+			runProps.RunStyle = format.runStyle()
+
 			run.Properties = runProps
 		}
 
@@ -3,6 +3,7 @@ package document
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/xml"
 	"fmt"
 	"image"
@@ -12,6 +13,11 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	"golang.org/x/image/webp"
 )
 
 // ImageFormat
@@ -21,6 +27,11 @@ const (
 	ImageFormatJPEG ImageFormat = "jpeg"
 	ImageFormatPNG  ImageFormat = "png"
 	ImageFormatGIF  ImageFormat = "gif"
+	ImageFormatBMP  ImageFormat = "bmp"
+	ImageFormatTIFF ImageFormat = "tiff"
+	ImageFormatSVG  ImageFormat = "svg"
+	// ImageFormatWebP images are converted to PNG when they are added, as Word cannot display WebP
+	ImageFormatWebP ImageFormat = "webp"
 )
 
 // ImagePosition
@@ -70,6 +81,11 @@ type ImageInfo struct {
 	Height     int
 	Data       []byte
 	Config     *ImageConfig
+	// SVGRelationID is the relationship of the SVG part of an SVG image,
+	// RelationID then refers to the PNG fallback shown by older Word versions
+	SVGRelationID string
+	// part is the header or footer part of an image listed by Images, empty for the document body
+	part string
 }
 
 // DrawingElement
@@ -268,9 +284,11 @@ type DrawingGraphic struct {
 
 // GraphicData
 type GraphicData struct {
-	XMLName xml.Name    `xml:"a:graphicData"`
-	Uri     string      `xml:"uri,attr"`
-	Pic     *PicElement `xml:"pic:pic"`
+	XMLName xml.Name             `xml:"a:graphicData"`
+	Uri     string               `xml:"uri,attr"`
+	Pic     *PicElement          `xml:"pic:pic"`
+	Shape   *WordprocessingShape `xml:"wps:wsp,omitempty"`
+	Chart   *ChartReference      `xml:"c:chart,omitempty"`
 }
 
 // PicElement
@@ -315,15 +333,62 @@ type PicLocks struct {
 type BlipFill struct {
 	XMLName xml.Name `xml:"pic:blipFill"`
 	Blip    *Blip    `xml:"a:blip"`
+	SrcRect *SrcRect `xml:"a:srcRect,omitempty"`
 	Stretch *Stretch `xml:"a:stretch"`
 }
 
+// SrcRect crops the image, each edge in 1/1000 percent of the image size
+type SrcRect struct {
+	XMLName xml.Name `xml:"a:srcRect"`
+	L       string   `xml:"l,attr,omitempty"`
+	T       string   `xml:"t,attr,omitempty"`
+	R       string   `xml:"r,attr,omitempty"`
+	B       string   `xml:"b,attr,omitempty"`
+}
+
 // Blip
 type Blip struct {
-	XMLName xml.Name `xml:"a:blip"`
+	XMLName xml.Name     `xml:"a:blip"`
+	Embed   string       `xml:"r:embed,attr"`
+	ExtLst  *BlipExtList `xml:"a:extLst,omitempty"`
+}
+
+// svgBlipExtURI identifies the blip extension that carries an SVG image
+const svgBlipExtURI = "{96DAC541-7B7A-43D3-8B79-37D633B846F1}"
+
+// BlipExtList
+type BlipExtList struct {
+	XMLName xml.Name  `xml:"a:extLst"`
+	Exts    []BlipExt `xml:"a:ext"`
+}
+
+// BlipExt
+type BlipExt struct {
+	XMLName xml.Name `xml:"a:ext"`
+	URI     string   `xml:"uri,attr"`
+	SVGBlip *SVGBlip `xml:"asvg:svgBlip,omitempty"`
+}
+
+// SVGBlip references the SVG part of an image (Office 2016 and later)
+type SVGBlip struct {
+	XMLName xml.Name `xml:"asvg:svgBlip"`
+	Xmlns   string   `xml:"xmlns:asvg,attr"`
 	Embed   string   `xml:"r:embed,attr"`
 }
 
+// SVGEmbed returns the relationship ID of the SVG image of the blip, or an empty string
+func (b *Blip) SVGEmbed() string {
+	if b == nil || b.ExtLst == nil {
+		return ""
+	}
+	for _, ext := range b.ExtLst.Exts {
+		if ext.SVGBlip != nil {
+			return ext.SVGBlip.Embed
+		}
+	}
+	return ""
+}
+
 // Stretch
 type Stretch struct {
 	XMLName  xml.Name  `xml:"a:stretch"`
@@ -337,14 +402,19 @@ type FillRect struct {
 
 // SpPr
 type SpPr struct {
-	XMLName  xml.Name  `xml:"pic:spPr"`
-	Xfrm     *Xfrm     `xml:"a:xfrm"`
-	PrstGeom *PrstGeom `xml:"a:prstGeom"`
+	XMLName   xml.Name    `xml:"pic:spPr"`
+	Xfrm      *Xfrm       `xml:"a:xfrm"`
+	PrstGeom  *PrstGeom   `xml:"a:prstGeom"`
+	Ln        *Outline    `xml:"a:ln,omitempty"`
+	EffectLst *EffectList `xml:"a:effectLst,omitempty"`
 }
 
 // Xfrm
 type Xfrm struct {
 	XMLName xml.Name `xml:"a:xfrm"`
+	Rot     string   `xml:"rot,attr,omitempty"` // rotation in 1/60000 degree
+	FlipH   string   `xml:"flipH,attr,omitempty"`
+	FlipV   string   `xml:"flipV,attr,omitempty"`
 	Off     *Off     `xml:"a:off,omitempty"`
 	Ext     *Ext     `xml:"a:ext"`
 }
@@ -375,6 +445,80 @@ type AvLst struct {
 	XMLName xml.Name `xml:"a:avLst"`
 }
 
+// Outline is the border line of a picture
+type Outline struct {
+	XMLName   xml.Name   `xml:"a:ln"`
+	W         string     `xml:"w,attr,omitempty"` // width in EMU
+	NoFill    *NoFill    `xml:"a:noFill,omitempty"`
+	SolidFill *SolidFill `xml:"a:solidFill,omitempty"`
+	PrstDash  *PrstDash  `xml:"a:prstDash,omitempty"`
+	TailEnd   *LineEnd   `xml:"a:tailEnd,omitempty"`
+}
+
+// SolidFill
+type SolidFill struct {
+	XMLName   xml.Name   `xml:"a:solidFill"`
+	SrgbClr   *SrgbClr   `xml:"a:srgbClr"`
+	SchemeClr *SchemeClr `xml:"a:schemeClr,omitempty"`
+}
+
+// SrgbClr is an RGB color with an optional transparency
+type SrgbClr struct {
+	XMLName xml.Name `xml:"a:srgbClr"`
+	Val     string   `xml:"val,attr"`
+	Alpha   *Alpha   `xml:"a:alpha,omitempty"`
+}
+
+// SchemeClr is a theme color, optionally adjusted by color transforms such as lumMod or alpha
+type SchemeClr struct {
+	XMLName    xml.Name         `xml:"a:schemeClr"`
+	Val        string           `xml:"val,attr"`
+	Transforms []ColorTransform `xml:",any"`
+}
+
+// ColorTransform is a color transform element such as a:lumMod, named by XMLName
+type ColorTransform struct {
+	XMLName xml.Name
+	Val     string `xml:"val,attr,omitempty"`
+}
+
+// Alpha is the opacity of a color in 1/1000 percent
+type Alpha struct {
+	XMLName xml.Name `xml:"a:alpha"`
+	Val     string   `xml:"val,attr"`
+}
+
+// PrstDash
+type PrstDash struct {
+	XMLName xml.Name `xml:"a:prstDash"`
+	Val     string   `xml:"val,attr"`
+}
+
+// EffectList holds the visual effects of a picture
+type EffectList struct {
+	XMLName   xml.Name   `xml:"a:effectLst"`
+	OuterShdw *OuterShdw `xml:"a:outerShdw,omitempty"`
+	SoftEdge  *SoftEdge  `xml:"a:softEdge,omitempty"`
+}
+
+// OuterShdw is a drop shadow, distances in EMU and the direction in 1/60000 degree
+type OuterShdw struct {
+	XMLName      xml.Name   `xml:"a:outerShdw"`
+	BlurRad      string     `xml:"blurRad,attr,omitempty"`
+	Dist         string     `xml:"dist,attr,omitempty"`
+	Dir          string     `xml:"dir,attr,omitempty"`
+	Algn         string     `xml:"algn,attr,omitempty"`
+	RotWithShape string     `xml:"rotWithShape,attr,omitempty"`
+	SrgbClr      *SrgbClr   `xml:"a:srgbClr"`
+	SchemeClr    *SchemeClr `xml:"a:schemeClr,omitempty"`
+}
+
+// SoftEdge blurs the edges of a picture, the radius in EMU
+type SoftEdge struct {
+	XMLName xml.Name `xml:"a:softEdge"`
+	Rad     string   `xml:"rad,attr"`
+}
+
 // AddImageFromFile 从文件添加图片到文档
 func (d *Document) AddImageFromFile(filePath string, config *ImageConfig) (*ImageInfo, error) {
 	Debugf("Starting to add image file: %s", filePath)
@@ -410,6 +554,11 @@ func (d *Document) AddImageFromFile(filePath string, config *ImageConfig) (*Imag
 func generateSafeImageFileName(imageID int, originalFileName string, format ImageFormat) string {
 	// get file extension
 	ext := filepath.Ext(originalFileName)
+	switch format {
+	case ImageFormatBMP, ImageFormatTIFF, ImageFormatSVG:
+		// always use the extension registered in the content types (e.g. .tiff instead of .tif)
+		ext = "." + string(format)
+	}
 	if ext == "" {
 		// if there is no extension, add it based on the format
 		switch format {
@@ -431,70 +580,80 @@ func generateSafeImageFileName(imageID int, originalFileName string, format Imag
 
 // AddImageFromData
 func (d *Document) AddImageFromData(imageData []byte, fileName string, format ImageFormat, width, height int, config *ImageConfig) (*ImageInfo, error) {
-	if d.documentRelationships == nil {
-		d.documentRelationships = &Relationships{
-			Xmlns:         "http://schemas.openxmlformats.org/package/2006/relationships",
-			Relationships: []Relationship{},
-		}
+	imageInfo, err := d.AddImageFromDataWithoutElement(imageData, fileName, format, width, height, config)
+	if err != nil {
+		return nil, err
 	}
 
-	// use document-level image ID counter to ensure ID uniqueness
-	imageID := d.nextImageID
-	d.nextImageID++ // increment counter
-
-	// generate safe file name (to avoid errors when opening Word with non-ASCII characters)
-	safeFileName := generateSafeImageFileName(imageID, fileName, format)
-
-	// generate relationship ID, note: rId1 reserved for styles.xml, images start from rId2
-	relationID := fmt.Sprintf("rId%d", len(d.documentRelationships.Relationships)+2)
+	// 创建图片段落并添加到文档
+	paragraph := d.createImageParagraph(imageInfo)
+	d.Body.AddElement(paragraph)
 
-	// add image relationship, using safe file name
-	d.documentRelationships.Relationships = append(d.documentRelationships.Relationships, Relationship{
-		ID:     relationID,
-		Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image",
-		Target: fmt.Sprintf("media/%s", safeFileName),
-	})
+	return imageInfo, nil
+}
 
-	// 存储图片数据，使用安全文件名
-	if d.parts == nil {
-		d.parts = make(map[string][]byte)
+// AddImageFromDataWithoutElement 从数据添加图片到文档但不创建段落元素
+// 此方法供模板引擎等需要自行管理图片段落的场景使用
+//
+// WebP images are converted to PNG. SVG images are stored together with a PNG fallback
+// rasterized from the SVG, which Word versions without SVG support display instead.
+func (d *Document) AddImageFromDataWithoutElement(imageData []byte, fileName string, format ImageFormat, width, height int, config *ImageConfig) (*ImageInfo, error) {
+	var svgData []byte
+	switch format {
+	case ImageFormatWebP:
+		pngData, err := convertImageToPNG(imageData)
+		if err != nil {
+			return nil, err
+		}
+		imageData, format = pngData, ImageFormatPNG
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + ".png"
+	case ImageFormatSVG:
+		pngData, err := rasterizeSVG(imageData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create PNG fallback for SVG image: %v", err)
+		}
+		svgData, imageData = imageData, pngData
 	}
-	d.parts[fmt.Sprintf("word/media/%s", safeFileName)] = imageData
 
-	// 更新内容类型
-	d.addImageContentType(format)
+	// 使用文档级别的图片ID计数器确保ID唯一性
+	imageID := d.nextImageID
+	d.nextImageID++ // increment counter
 
 	// 创建图片信息
 	imageInfo := &ImageInfo{
-		ID:         strconv.Itoa(imageID),
-		RelationID: relationID,
-		Format:     format,
-		Width:      width,
-		Height:     height,
-		Data:       imageData,
-		Config:     config,
+		ID:     strconv.Itoa(imageID),
+		Format: format,
+		Width:  width,
+		Height: height,
+		Data:   imageData,
+		Config: config,
 	}
 
-	// 创建图片段落并添加到文档
-	paragraph := d.createImageParagraph(imageInfo)
-	d.Body.AddElement(paragraph)
+	if svgData != nil {
+		imageInfo.SVGRelationID = d.addImagePart(imageID, fileName, ImageFormatSVG, svgData)
+		imageInfo.RelationID = d.addImagePart(imageID, "", ImageFormatPNG, imageData)
+		imageInfo.Data = svgData
+	} else {
+		imageInfo.RelationID = d.addImagePart(imageID, fileName, format, imageData)
+	}
 
+	// 注意：这个方法不创建段落元素，由调用者负责管理
 	return imageInfo, nil
 }
 
-// AddImageFromDataWithoutElement 从数据添加图片到文档但不创建段落元素
-// 此方法供模板引擎等需要自行管理图片段落的场景使用
-func (d *Document) AddImageFromDataWithoutElement(imageData []byte, fileName string, format ImageFormat, width, height int, config *ImageConfig) (*ImageInfo, error) {
+// addImagePart stores image data as a media part and returns the ID of its relationship
+// Data identical to an already stored image reuses its part and relationship.
+func (d *Document) addImagePart(imageID int, fileName string, format ImageFormat, imageData []byte) string {
 	if d.documentRelationships == nil {
 		d.documentRelationships = &Relationships{
 			Xmlns:         "http://schemas.openxmlformats.org/package/2006/relationships",
 			Relationships: []Relationship{},
 		}
 	}
-
-	// 使用文档级别的图片ID计数器确保ID唯一性
-	imageID := d.nextImageID
-	d.nextImageID++ // increment counter
+	if relationID := d.findMediaRelationship(imageData); relationID != "" {
+		Debugf("reusing media of relationship %s for image %d", relationID, imageID)
+		return relationID
+	}
 
 	// 生成安全的文件名（避免中文等非ASCII字符导致Word打开错误）
 	safeFileName := generateSafeImageFileName(imageID, fileName, format)
@@ -514,23 +673,12 @@ func (d *Document) AddImageFromDataWithoutElement(imageData []byte, fileName str
 		d.parts = make(map[string][]byte)
 	}
 	d.parts[fmt.Sprintf("word/media/%s", safeFileName)] = imageData
+	d.mediaHashes[sha256.Sum256(imageData)] = relationID
 
 	// 更新内容类型
 	d.addImageContentType(format)
 
-	// 创建图片信息
-	imageInfo := &ImageInfo{
-		ID:         strconv.Itoa(imageID),
-		RelationID: relationID,
-		Format:     format,
-		Width:      width,
-		Height:     height,
-		Data:       imageData,
-		Config:     config,
-	}
-
-	// 注意：这个方法不创建段落元素，由调用者负责管理
-	return imageInfo, nil
+	return relationID
 }
 
 // createImageParagraph 创建包含图片的段落
@@ -840,9 +988,7 @@ func (d *Document) createImageGraphic(imageInfo *ImageInfo, displayWidth, displa
 					},
 				},
 				BlipFill: &BlipFill{
-					Blip: &Blip{
-						Embed: imageInfo.RelationID,
-					},
+					Blip: newImageBlip(imageInfo),
 					Stretch: &Stretch{
 						FillRect: &FillRect{},
 					},
@@ -868,6 +1014,21 @@ func (d *Document) createImageGraphic(imageInfo *ImageInfo, displayWidth, displa
 	}
 }
 
+// newImageBlip creates the blip of an image, referencing the SVG part through the svgBlip extension
+func newImageBlip(imageInfo *ImageInfo) *Blip {
+	blip := &Blip{Embed: imageInfo.RelationID}
+	if imageInfo.SVGRelationID != "" {
+		blip.ExtLst = &BlipExtList{Exts: []BlipExt{{
+			URI: svgBlipExtURI,
+			SVGBlip: &SVGBlip{
+				Xmlns: "http://schemas.microsoft.com/office/drawing/2016/SVG/main",
+				Embed: imageInfo.SVGRelationID,
+			},
+		}}}
+	}
+	return blip
+}
+
 // calculateDisplaySize 计算图片显示尺寸（EMU单位）
 func (d *Document) calculateDisplaySize(imageInfo *ImageInfo) (int64, int64) {
 	config := imageInfo.Config
@@ -921,6 +1082,26 @@ func detectImageFormat(data []byte) (ImageFormat, error) {
 		return ImageFormatGIF, nil
 	}
 
+	// BMP
+	if len(data) >= 14 && bytes.Equal(data[:2], []byte("BM")) {
+		return ImageFormatBMP, nil
+	}
+
+	// TIFF, little endian or big endian
+	if len(data) >= 4 && (bytes.Equal(data[:4], []byte("II*\x00")) || bytes.Equal(data[:4], []byte("MM\x00*"))) {
+		return ImageFormatTIFF, nil
+	}
+
+	// WebP, a RIFF container of type WEBP
+	if len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")) {
+		return ImageFormatWebP, nil
+	}
+
+	// SVG, an XML document with an svg root element
+	if isSVGData(data) {
+		return ImageFormatSVG, nil
+	}
+
 	return "", fmt.Errorf("不支持的图片格式")
 }
 
@@ -938,6 +1119,19 @@ func getImageDimensions(data []byte, format ImageFormat) (int, int, error) {
 		img, err = jpeg.Decode(reader)
 	case ImageFormatGIF:
 		img, err = gif.Decode(reader)
+	case ImageFormatBMP:
+		img, err = bmp.Decode(reader)
+	case ImageFormatTIFF:
+		img, err = tiff.Decode(reader)
+	case ImageFormatWebP:
+		img, err = webp.Decode(reader)
+	case ImageFormatSVG:
+		svg, err := parseSVG(data)
+		if err != nil {
+			return 0, 0, fmt.Errorf("解码图片失败: %v", err)
+		}
+		width, height := svg.pixelSize()
+		return width, height, nil
 	default:
 		return 0, 0, fmt.Errorf("不支持的图片格式: %s", format)
 	}
@@ -950,6 +1144,20 @@ func getImageDimensions(data []byte, format ImageFormat) (int, int, error) {
 	return bounds.Dx(), bounds.Dy(), nil
 }
 
+// convertImageToPNG decodes an image in any supported raster format and encodes it as PNG
+func convertImageToPNG(data []byte) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
 // addImageContentType 添加图片内容类型
 func (d *Document) addImageContentType(format ImageFormat) {
 	if d.contentTypes == nil {
@@ -971,6 +1179,15 @@ func (d *Document) addImageContentType(format ImageFormat) {
 	case ImageFormatGIF:
 		extension = "gif"
 		contentType = "image/gif"
+	case ImageFormatBMP:
+		extension = "bmp"
+		contentType = "image/bmp"
+	case ImageFormatTIFF:
+		extension = "tiff"
+		contentType = "image/tiff"
+	case ImageFormatSVG:
+		extension = "svg"
+		contentType = "image/svg+xml"
 	default:
 		return
 	}
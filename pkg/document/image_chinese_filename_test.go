@@ -97,8 +97,6 @@ func TestMultipleNonASCIIFilenames(t *testing.T) {
 	doc := New()
 	doc.AddParagraph("Test multiple non-ASCII filenames")
 
-	imageData := createTestImage(50, 50)
-
 	testFilenames := []string{
 		"Chinese image.png",
 		"Japanese.png",
@@ -108,6 +106,8 @@ func TestMultipleNonASCIIFilenames(t *testing.T) {
 	}
 
 	for i, filename := range testFilenames {
+		// distinct data, identical images would share one media part
+		imageData := createTestImage(50+i, 50)
 		_, err := doc.AddImageFromData(imageData, filename, ImageFormatPNG, 50, 50, nil)
 		if err != nil {
 			t.Fatalf("failed to add image %s: %v", filename, err)
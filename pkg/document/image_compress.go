@@ -0,0 +1,295 @@
+// Package document provides image compression and downsampling
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// defaultJPEGQuality is used when images are encoded as JPEG without a configured quality
+	defaultJPEGQuality = 85
+	// emuPerInch is the number of English Metric Units in an inch
+	emuPerInch = 914400
+	// photoColorThreshold is the number of distinct sampled colors above which an image counts as a photo
+	photoColorThreshold = 1024
+)
+
+// SaveOptions configures the processing of embedded images when a document is saved
+type SaveOptions struct {
+	// MaxImageDPI downsamples images whose resolution at their displayed size exceeds it,
+	// 0 keeps the resolution
+	MaxImageDPI int
+	// JPEGQuality re-encodes JPEG images with the given quality (1-100). With 0 JPEG images
+	// are only re-encoded when they are downsampled, using quality 85
+	JPEGQuality int
+	// ConvertPNGPhotosToJPEG stores opaque PNG, BMP and TIFF images with many colors,
+	// such as photos, as JPEG
+	ConvertPNGPhotosToJPEG bool
+}
+
+// SaveWithOptions compresses the embedded images as configured and saves the document
+// The images are compressed for the saved file only, the document keeps its original images.
+func (d *Document) SaveWithOptions(filename string, opts *SaveOptions) error {
+	if opts == nil {
+		return d.Save(filename)
+	}
+
+	parts := make(map[string][]byte, len(d.parts))
+	for name, data := range d.parts {
+		parts[name] = data
+	}
+	rels, contentTypes, mediaHashes := d.documentRelationships, d.contentTypes, d.mediaHashes
+	if rels != nil {
+		copied := *rels
+		copied.Relationships = append([]Relationship(nil), rels.Relationships...)
+		d.documentRelationships = &copied
+	}
+	if contentTypes != nil {
+		copied := *contentTypes
+		copied.Defaults = append([]Default(nil), contentTypes.Defaults...)
+		d.contentTypes = &copied
+	}
+	defer func() {
+		d.parts, d.documentRelationships, d.contentTypes, d.mediaHashes = parts, rels, contentTypes, mediaHashes
+	}()
+
+	if _, err := d.CompressImages(opts); err != nil {
+		return WrapError("compress_images", err)
+	}
+	return d.Save(filename)
+}
+
+// CompressImages re-encodes and downsamples the images of the document and returns the
+// number of bytes saved
+//
+// The resolution of an image is computed from its displayed size in the body, headers and
+// footers; an image displayed several times uses its largest size, and images that are not
+// displayed as pictures keep their resolution. BMP and TIFF images are stored as PNG. An image
+// is only replaced when the result is smaller. SVG images and their fallbacks, GIF and WebP data
+// are left unchanged.
+func (d *Document) CompressImages(opts *SaveOptions) (int64, error) {
+	if opts == nil {
+		return 0, nil
+	}
+	if opts.MaxImageDPI < 0 {
+		return 0, NewValidationError("MaxImageDPI", strconv.Itoa(opts.MaxImageDPI), "cannot be negative")
+	}
+	if opts.JPEGQuality < 0 || opts.JPEGQuality > 100 {
+		return 0, NewValidationError("JPEGQuality", strconv.Itoa(opts.JPEGQuality), "must be between 1 and 100")
+	}
+
+	// a media part may be shared by several relationships, it is compressed for its largest size
+	owners := d.mediaOwners()
+	var ownerNames []string
+	for owner := range owners {
+		ownerNames = append(ownerNames, owner)
+	}
+	sort.Strings(ownerNames)
+	var media []string
+	listed := make(map[string]bool)
+	for _, owner := range ownerNames {
+		for _, rel := range owners[owner].Relationships {
+			if partName := resolvePartTarget(owner, rel.Target); rel.Type == imageRelationshipType && !listed[partName] {
+				listed[partName] = true
+				media = append(media, partName)
+			}
+		}
+	}
+	partSizes, skipParts := d.imageDisplaySizes(owners)
+
+	var saved int64
+	compressed := 0
+	for _, partName := range media {
+		data, ok := d.parts[partName]
+		if !ok || skipParts[partName] {
+			continue
+		}
+		format, err := detectImageFormat(data)
+		if err != nil {
+			continue
+		}
+
+		maxWidth, maxHeight := 0, 0
+		if size := partSizes[partName]; size[0] > 0 && opts.MaxImageDPI > 0 {
+			maxWidth = int(math.Ceil(size[0] / emuPerInch * float64(opts.MaxImageDPI)))
+			maxHeight = int(math.Ceil(size[1] / emuPerInch * float64(opts.MaxImageDPI)))
+		}
+		newData, newFormat, err := compressImage(data, format, maxWidth, maxHeight, opts)
+		if err != nil {
+			return saved, fmt.Errorf("failed to compress %s: %v", partName, err)
+		}
+		if newData == nil || len(newData) >= len(data) {
+			continue
+		}
+
+		if newFormat != format {
+			newPart := "word/" + d.uniqueMediaTarget(strings.TrimPrefix(partName, "word/"), newFormat)
+			d.parts[newPart] = newData
+			if err := d.retargetMedia(partName, newPart); err != nil {
+				return saved, err
+			}
+			partName = newPart
+			d.addImageContentType(newFormat)
+		} else {
+			d.parts[partName] = newData
+		}
+		saved += int64(len(data) - len(newData))
+		compressed++
+		Debugf("compressed image %s: %d -> %d bytes", partName, len(data), len(newData))
+	}
+
+	Infof("compressed %d images, saved %d bytes", compressed, saved)
+	return saved, nil
+}
+
+// imageDisplaySizes returns the largest displayed size in EMU of every media part shown as a
+// picture in the body, headers or footers, including cropped parts, and the media parts of SVG
+// images and their fallbacks
+func (d *Document) imageDisplaySizes(owners map[string]*Relationships) (map[string][2]float64, map[string]bool) {
+	sizes := make(map[string][2]float64)
+	svg := make(map[string]bool)
+	record := func(owner string) func(*DrawingElement) bool {
+		targets := make(map[string]string)
+		if rels := owners[owner]; rels != nil {
+			for _, rel := range rels.Relationships {
+				targets[rel.ID] = resolvePartTarget(owner, rel.Target)
+			}
+		}
+		return func(drawing *DrawingElement) bool {
+			pic := drawingPic(drawing)
+			if pic == nil || pic.BlipFill.Blip == nil {
+				return true
+			}
+			blip := pic.BlipFill.Blip
+			if svgID := blip.SVGEmbed(); svgID != "" {
+				svg[targets[svgID]], svg[targets[blip.Embed]] = true, true
+				return true
+			}
+
+			extent := drawingExtent(drawing)
+			cx, _ := strconv.ParseFloat(extent.Cx, 64)
+			cy, _ := strconv.ParseFloat(extent.Cy, 64)
+			if rect := pic.BlipFill.SrcRect; rect != nil {
+				cx /= 1 - (srcRectPercent(rect.L)+srcRectPercent(rect.R))/100
+				cy /= 1 - (srcRectPercent(rect.T)+srcRectPercent(rect.B))/100
+			}
+			media := targets[blip.Embed]
+			size := sizes[media]
+			sizes[media] = [2]float64{math.Max(size[0], cx), math.Max(size[1], cy)}
+			return true
+		}
+	}
+
+	walkDrawings(d.Body.Elements, record(""))
+	for owner := range owners {
+		if headerFooterLocation(owner) == "" {
+			continue
+		}
+		drawings, err := d.parsePartDrawings(owner)
+		if err != nil {
+			Debugf("failed to read the pictures of %s: %v", owner, err)
+			continue
+		}
+		fn := record(owner)
+		for _, drawing := range drawings {
+			fn(drawing)
+		}
+	}
+	return sizes, svg
+}
+
+// compressImage downsamples an image to fit maxWidth x maxHeight pixels (0 for no limit) and
+// re-encodes it as configured. It returns nil data when the image is left unchanged.
+func compressImage(data []byte, format ImageFormat, maxWidth, maxHeight int, opts *SaveOptions) ([]byte, ImageFormat, error) {
+	switch format {
+	case ImageFormatJPEG, ImageFormatPNG, ImageFormatBMP, ImageFormatTIFF:
+	default:
+		return nil, format, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, format, fmt.Errorf("解码图片失败: %v", err)
+	}
+
+	resized := false
+	bounds := img.Bounds()
+	if maxWidth > 0 && maxHeight > 0 && (bounds.Dx() > maxWidth || bounds.Dy() > maxHeight) {
+		scale := math.Min(float64(maxWidth)/float64(bounds.Dx()), float64(maxHeight)/float64(bounds.Dy()))
+		width := max(1, int(math.Round(float64(bounds.Dx())*scale)))
+		height := max(1, int(math.Round(float64(bounds.Dy())*scale)))
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+		img, resized = dst, true
+	}
+
+	target := ImageFormatPNG
+	if format == ImageFormatJPEG || (opts.ConvertPNGPhotosToJPEG && isPhotoImage(img)) {
+		target = ImageFormatJPEG
+	}
+	reencode := resized || target != format || (format == ImageFormatJPEG && opts.JPEGQuality > 0)
+	if !reencode {
+		return nil, format, nil
+	}
+
+	var buf bytes.Buffer
+	if target == ImageFormatJPEG {
+		quality := opts.JPEGQuality
+		if quality == 0 {
+			quality = defaultJPEGQuality
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	} else {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		err = encoder.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to encode %s: %v", target, err)
+	}
+	return buf.Bytes(), target, nil
+}
+
+// isPhotoImage reports whether an image is opaque and has many colors, based on a sample grid
+func isPhotoImage(img image.Image) bool {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		return false
+	}
+
+	bounds := img.Bounds()
+	stepX := max(1, bounds.Dx()/64)
+	stepY := max(1, bounds.Dy()/64)
+	colors := make(map[uint32]bool)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a != 0xffff {
+				return false
+			}
+			colors[(r>>8)<<16|(g>>8)<<8|b>>8] = true
+		}
+	}
+	return len(colors) > photoColorThreshold
+}
+
+// uniqueMediaTarget returns the media target of an image converted to another format
+func (d *Document) uniqueMediaTarget(target string, format ImageFormat) string {
+	base := strings.TrimSuffix(target, path.Ext(target))
+	candidate := base + "." + string(format)
+	for n := 1; ; n++ {
+		if _, exists := d.parts["word/"+candidate]; !exists {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s_%d.%s", base, n, format)
+	}
+}
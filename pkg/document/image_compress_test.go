@@ -0,0 +1,174 @@
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+// createPhotoImage creates a PNG image with noisy gradients that compresses like a photo
+func createPhotoImage(width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rnd := rand.New(rand.NewSource(1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 255 / width), uint8(y * 255 / height), uint8(rnd.Intn(256)), 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// mediaTarget returns the media target of an image relationship
+func mediaTarget(doc *Document, relationID string) string {
+	for _, rel := range doc.documentRelationships.Relationships {
+		if rel.ID == relationID {
+			return rel.Target
+		}
+	}
+	return ""
+}
+
+// TestCompressImages tests downsampling to the displayed size and converting photos to JPEG
+func TestCompressImages(t *testing.T) {
+	doc := New()
+	photoData := createPhotoImage(1200, 600)
+	// 50mm wide is about 1.97 inches, 296 pixels at 150 DPI
+	photo, err := doc.AddImageFromData(photoData, "photo.png", ImageFormatPNG, 1200, 600,
+		&ImageConfig{Size: &ImageSize{Width: 50, KeepAspectRatio: true}})
+	if err != nil {
+		t.Fatalf("failed to add photo: %v", err)
+	}
+	logo, err := doc.AddImageFromData(createTestImage(800, 400), "logo.png", ImageFormatPNG, 800, 400,
+		&ImageConfig{Size: &ImageSize{Width: 20, KeepAspectRatio: true}})
+	if err != nil {
+		t.Fatalf("failed to add logo: %v", err)
+	}
+	small, err := doc.AddImageFromData(createTestImage(20, 20), "icon.png", ImageFormatPNG, 20, 20, nil)
+	if err != nil {
+		t.Fatalf("failed to add icon: %v", err)
+	}
+	iconData := doc.parts["word/"+mediaTarget(doc, small.RelationID)]
+
+	saved, err := doc.CompressImages(&SaveOptions{MaxImageDPI: 150, ConvertPNGPhotosToJPEG: true})
+	if err != nil {
+		t.Fatalf("failed to compress images: %v", err)
+	}
+	if saved <= int64(len(photoData)/2) {
+		t.Errorf("expected most of the photo to be saved, saved %d of %d bytes", saved, len(photoData))
+	}
+
+	photoTarget := mediaTarget(doc, photo.RelationID)
+	if photoTarget != "media/image0.jpeg" {
+		t.Fatalf("photo should be converted to JPEG, target is %s", photoTarget)
+	}
+	if _, exists := doc.parts["word/media/image0.png"]; exists {
+		t.Error("the original PNG part should be removed")
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(doc.parts["word/"+photoTarget]))
+	if err != nil || format != "jpeg" || config.Width > 296 || config.Width < 290 {
+		t.Errorf("unexpected compressed photo: %s %dx%d (%v)", format, config.Width, config.Height, err)
+	}
+
+	logoTarget := mediaTarget(doc, logo.RelationID)
+	config, format, _ = image.DecodeConfig(bytes.NewReader(doc.parts["word/"+logoTarget]))
+	if logoTarget != "media/image1.png" || format != "png" || config.Width > 119 {
+		t.Errorf("logo should stay PNG and be downsampled, got %s %dx%d", logoTarget, config.Width, config.Height)
+	}
+	if !bytes.Equal(doc.parts["word/"+mediaTarget(doc, small.RelationID)], iconData) {
+		t.Error("images below the resolution limit should be left unchanged")
+	}
+
+	filename := "test_compress_images.docx"
+	photoData = doc.parts["word/"+photoTarget]
+	if err := doc.SaveWithOptions(filename, &SaveOptions{JPEGQuality: 60}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	defer os.Remove(filename)
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open compressed document: %v", err)
+	}
+	if !strings.Contains(string(opened.parts["[Content_Types].xml"]), `Extension="jpeg"`) {
+		t.Error("JPEG content type should be registered")
+	}
+	if len(opened.parts["word/"+photoTarget]) >= len(photoData) {
+		t.Error("the saved photo should be compressed with the configured quality")
+	}
+	if !bytes.Equal(doc.parts["word/"+photoTarget], photoData) {
+		t.Error("saving with options should not change the images of the document")
+	}
+
+	if _, err := doc.CompressImages(&SaveOptions{JPEGQuality: 101}); err == nil {
+		t.Error("expected error for invalid JPEG quality")
+	}
+}
+
+// TestCompressImagesInHeaders tests that header pictures count for the displayed size
+func TestCompressImagesInHeaders(t *testing.T) {
+	doc := New()
+	// 20mm in the body is about 118 pixels at 150 DPI
+	shared, err := doc.AddImageFromData(createTestImage(800, 400), "banner.png", ImageFormatPNG, 800, 400,
+		&ImageConfig{Size: &ImageSize{Width: 20, KeepAspectRatio: true}})
+	if err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+	sharedMedia := "word/" + mediaTarget(doc, shared.RelationID)
+
+	// the header shows the same media at 240x120 pixels, 2.5 inches or 375 pixels at 150 DPI,
+	// and a header-only image at 120x60 pixels, 188 pixels at 150 DPI
+	if err := doc.AddHeader(HeaderFooterTypeDefault, ""); err != nil {
+		t.Fatalf("failed to add header: %v", err)
+	}
+	header := createStandardHeader()
+	header.Paragraphs = append(header.Paragraphs,
+		doc.createImageParagraph(&ImageInfo{ID: "100", RelationID: "rId1", Format: ImageFormatPNG, Width: 240, Height: 120}),
+		doc.createImageParagraph(&ImageInfo{ID: "101", RelationID: "rId2", Format: ImageFormatPNG, Width: 120, Height: 60}))
+	headerXML, err := xml.Marshal(header)
+	if err != nil {
+		t.Fatalf("failed to marshal header: %v", err)
+	}
+	doc.parts["word/header1.xml"] = append([]byte(xml.Header), headerXML...)
+	doc.parts["word/_rels/header1.xml.rels"] = []byte(xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="` + strings.TrimPrefix(sharedMedia, "word/") + `"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/stamp.png"/>` +
+		`</Relationships>`)
+	doc.parts["word/media/stamp.png"] = createTestImage(1000, 500)
+
+	if _, err := doc.CompressImages(&SaveOptions{MaxImageDPI: 150}); err != nil {
+		t.Fatalf("failed to compress images: %v", err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(doc.parts[sharedMedia]))
+	if err != nil || config.Width < 370 || config.Width > 375 {
+		t.Errorf("shared image should keep the resolution of its larger header placement, got %d pixels (%v)", config.Width, err)
+	}
+	config, _, err = image.DecodeConfig(bytes.NewReader(doc.parts["word/media/stamp.png"]))
+	if err != nil || config.Width < 185 || config.Width > 188 {
+		t.Errorf("header image should be downsampled to its displayed size, got %d pixels (%v)", config.Width, err)
+	}
+}
+
+// TestIsPhotoImage tests the photo detection used for JPEG conversion
+func TestIsPhotoImage(t *testing.T) {
+	photo, _ := png.Decode(bytes.NewReader(createPhotoImage(200, 100)))
+	flat, _ := png.Decode(bytes.NewReader(createTestImage(200, 100)))
+	if !isPhotoImage(photo) {
+		t.Error("noisy image should count as a photo")
+	}
+	if isPhotoImage(flat) {
+		t.Error("single color image should not count as a photo")
+	}
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	if isPhotoImage(transparent) {
+		t.Error("transparent image should not be converted to JPEG")
+	}
+}
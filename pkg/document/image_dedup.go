@@ -0,0 +1,153 @@
+// Package document provides deduplication of embedded media
+package document
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"path"
+	"sort"
+	"strings"
+)
+
+// imageRelationshipType is the relationship type of embedded images
+const imageRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+
+// findMediaRelationship returns the ID of a document relationship whose media part holds the
+// same bytes as data, or an empty string
+func (d *Document) findMediaRelationship(data []byte) string {
+	if d.mediaHashes == nil {
+		d.mediaHashes = make(map[[sha256.Size]byte]string)
+		if d.documentRelationships != nil {
+			for _, rel := range d.documentRelationships.Relationships {
+				if rel.Type != imageRelationshipType {
+					continue
+				}
+				if media, ok := d.parts[resolvePartTarget("", rel.Target)]; ok {
+					if _, exists := d.mediaHashes[sha256.Sum256(media)]; !exists {
+						d.mediaHashes[sha256.Sum256(media)] = rel.ID
+					}
+				}
+			}
+		}
+	}
+
+	relationID, ok := d.mediaHashes[sha256.Sum256(data)]
+	if !ok || d.documentRelationships == nil {
+		return ""
+	}
+	// the part may have been replaced or compressed since it was indexed
+	for _, rel := range d.documentRelationships.Relationships {
+		if rel.ID == relationID && bytes.Equal(d.parts[resolvePartTarget("", rel.Target)], data) {
+			return relationID
+		}
+	}
+	return ""
+}
+
+// DeduplicateMedia stores media parts with identical data once and returns the number of
+// parts removed
+//
+// Images added to a document are deduplicated as they are added; this is useful for opened
+// documents, where every copy of a logo may have its own media part. The relationships of the
+// body, headers and footers are pointed to the remaining part.
+func (d *Document) DeduplicateMedia() (int, error) {
+	owners := d.mediaOwners()
+	used := make(map[string]bool)
+	for owner, rels := range owners {
+		for _, rel := range rels.Relationships {
+			if rel.Type == imageRelationshipType {
+				used[resolvePartTarget(owner, rel.Target)] = true
+			}
+		}
+	}
+	var media []string
+	for name := range used {
+		if _, ok := d.parts[name]; ok {
+			media = append(media, name)
+		}
+	}
+	sort.Strings(media)
+
+	removed := 0
+	var saved int64
+	kept := make(map[[sha256.Size]byte]string)
+	for _, name := range media {
+		data := d.parts[name]
+		hash := sha256.Sum256(data)
+		original, ok := kept[hash]
+		if !ok {
+			kept[hash] = name
+			continue
+		}
+		if err := d.retargetMedia(name, original); err != nil {
+			return removed, err
+		}
+		removed++
+		saved += int64(len(data))
+	}
+
+	d.mediaHashes = nil
+	Infof("removed %d duplicate media parts, saved %d bytes", removed, saved)
+	return removed, nil
+}
+
+// retargetMedia points every image relationship to oldMedia at newMedia and removes oldMedia
+func (d *Document) retargetMedia(oldMedia, newMedia string) error {
+	for owner, rels := range d.mediaOwners() {
+		changed := false
+		for i := range rels.Relationships {
+			rel := &rels.Relationships[i]
+			if rel.Type == imageRelationshipType && resolvePartTarget(owner, rel.Target) == oldMedia {
+				rel.Target = relativePartTarget(owner, newMedia)
+				changed = true
+			}
+		}
+		if changed {
+			if err := d.storePartRelationships(owner, rels); err != nil {
+				return err
+			}
+		}
+	}
+	delete(d.parts, oldMedia)
+	return nil
+}
+
+// mediaOwners returns the relationships of the parts that can hold pictures or picture
+// bullets, by part name
+// The main document is listed under an empty name.
+func (d *Document) mediaOwners() map[string]*Relationships {
+	owners := make(map[string]*Relationships)
+	if d.documentRelationships != nil {
+		owners[""] = d.documentRelationships
+	}
+	for name := range d.parts {
+		if headerFooterLocation(name) == "" {
+			continue
+		}
+		if rels, err := d.partRelationships(name); err == nil {
+			owners[name] = rels
+		} else {
+			Debugf("failed to read the relationships of %s: %v", name, err)
+		}
+	}
+	// picture bullets of the numbering part may share a media part with the body
+	if _, ok := d.parts[relationshipsPartName("word/numbering.xml")]; ok {
+		if rels, err := d.partRelationships("word/numbering.xml"); err == nil {
+			owners["word/numbering.xml"] = rels
+		} else {
+			Debugf("failed to read the relationships of the numbering part: %v", err)
+		}
+	}
+	return owners
+}
+
+// relativePartTarget returns the relationship target of a part as seen from another part
+func relativePartTarget(owner, part string) string {
+	if owner == "" {
+		owner = "word/document.xml"
+	}
+	if dir := path.Dir(owner) + "/"; strings.HasPrefix(part, dir) {
+		return strings.TrimPrefix(part, dir)
+	}
+	return "/" + part
+}
@@ -0,0 +1,195 @@
+package document
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// countMediaParts counts the media parts of a document
+func countMediaParts(doc *Document) int {
+	count := 0
+	for name := range doc.parts {
+		if strings.HasPrefix(name, "word/media/") {
+			count++
+		}
+	}
+	return count
+}
+
+// TestAddImageReusesMedia tests that identical image data is stored once
+func TestAddImageReusesMedia(t *testing.T) {
+	doc := New()
+	logoData := createTestImage(40, 20)
+	var logos []*ImageInfo
+	for i := 0; i < 3; i++ {
+		logo, err := doc.AddImageFromData(logoData, "logo.png", ImageFormatPNG, 40, 20, nil)
+		if err != nil {
+			t.Fatalf("failed to add logo: %v", err)
+		}
+		logos = append(logos, logo)
+	}
+	table, _ := doc.AddTable(&TableConfig{Rows: 1, Cols: 1, Width: 3000})
+	cellLogo, err := doc.AddCellImageFromData(table, 0, 0, logoData, 10)
+	if err != nil {
+		t.Fatalf("failed to add cell image: %v", err)
+	}
+	other, err := doc.AddImageFromData(createTestImage(20, 20), "icon.png", ImageFormatPNG, 20, 20, nil)
+	if err != nil {
+		t.Fatalf("failed to add icon: %v", err)
+	}
+
+	if count := countMediaParts(doc); count != 2 {
+		t.Errorf("expected 2 media parts, got %d", count)
+	}
+	for _, logo := range append(logos[1:], cellLogo) {
+		if logo.RelationID != logos[0].RelationID {
+			t.Errorf("identical images should share relationship %s, got %s", logos[0].RelationID, logo.RelationID)
+		}
+		if logo.ID == logos[0].ID {
+			t.Error("pictures sharing media should keep their own IDs")
+		}
+	}
+	if other.RelationID == logos[0].RelationID {
+		t.Error("different images should not share media")
+	}
+
+	filename := "test_media_reuse.docx"
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	defer os.Remove(filename)
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	if len(opened.Images()) != 5 {
+		t.Errorf("expected 5 pictures, got %d", len(opened.Images()))
+	}
+	added, err := opened.AddImageFromData(logoData, "logo.png", ImageFormatPNG, 40, 20, nil)
+	if err != nil {
+		t.Fatalf("failed to add logo to opened document: %v", err)
+	}
+	if added.RelationID != logos[0].RelationID || countMediaParts(opened) != 2 {
+		t.Error("media of an opened document should be reused")
+	}
+	for _, image := range opened.Images()[:5] {
+		if image.Info.ID == added.ID {
+			t.Errorf("new picture reuses ID %s", added.ID)
+		}
+	}
+}
+
+// TestTemplateImagesReuseMedia tests that template images with identical data are stored once
+func TestTemplateImagesReuseMedia(t *testing.T) {
+	engine := NewTemplateEngine()
+	if _, err := engine.LoadTemplate("catalog", "{{#image first}}\n{{#image second}}"); err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+	data := NewTemplateData()
+	data.SetImageFromData("first", createTestImage(30, 30), nil)
+	data.SetImageFromData("second", createTestImage(30, 30), nil)
+
+	doc, err := engine.RenderToDocument("catalog", data)
+	if err != nil {
+		t.Fatalf("failed to render template: %v", err)
+	}
+	if count := countMediaParts(doc); count != 1 {
+		t.Errorf("expected 1 media part, got %d", count)
+	}
+}
+
+// TestDeduplicateMedia tests merging identical media parts of an opened document
+func TestDeduplicateMedia(t *testing.T) {
+	doc := New()
+	logoData := createTestImage(30, 10)
+	first, _ := doc.AddImageFromData(logoData, "logo.png", ImageFormatPNG, 30, 10, nil)
+	second, _ := doc.AddImageFromData(createTestImage(31, 10), "copy.png", ImageFormatPNG, 31, 10, nil)
+	// documents written by other tools may store every copy of an image in its own part
+	doc.parts["word/"+mediaTarget(doc, second.RelationID)] = logoData
+	addHeaderImage(t, doc, logoData)
+
+	removed, err := doc.DeduplicateMedia()
+	if err != nil {
+		t.Fatalf("failed to deduplicate media: %v", err)
+	}
+	if removed != 2 || countMediaParts(doc) != 1 {
+		t.Fatalf("expected 2 removed parts and 1 left, got %d and %d", removed, countMediaParts(doc))
+	}
+	for _, image := range doc.Images() {
+		if image.MediaPath != "word/media/image0.png" {
+			t.Errorf("picture %s should use the remaining part, got %s", image.Info.ID, image.MediaPath)
+		}
+	}
+	if mediaTarget(doc, first.RelationID) != "media/image0.png" {
+		t.Error("relationship of the remaining part should be unchanged")
+	}
+
+	// replacing one picture of a shared part leaves the other pictures unchanged
+	if err := doc.ReplaceImage(doc.Images()[2].Info, createTestImage(60, 20)); err != nil {
+		t.Fatalf("failed to replace image: %v", err)
+	}
+	for i, image := range doc.Images() {
+		if replaced := i == 2; (image.Info.Width == 60) != replaced || (image.MediaPath == "word/media/image0.png") == replaced {
+			t.Errorf("picture %d: unexpected image %s %d pixels wide", i, image.MediaPath, image.Info.Width)
+		}
+	}
+	if !bytes.Equal(doc.parts["word/media/image0.png"], logoData) {
+		t.Error("the shared part should keep its data")
+	}
+
+	filename := "test_deduplicate_media.docx"
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	defer os.Remove(filename)
+	if _, err := Open(filename); err != nil {
+		t.Fatalf("failed to open deduplicated document: %v", err)
+	}
+
+	if removed, _ := doc.DeduplicateMedia(); removed != 0 {
+		t.Errorf("expected nothing left to deduplicate, removed %d", removed)
+	}
+}
+
+func TestMediaSharedWithPictureBullets(t *testing.T) {
+	doc := New()
+	bulletData := createTestImage(16, 16)
+	info, err := doc.AddImageFromData(bulletData, "dot.png", ImageFormatPNG, 16, 16, nil)
+	if err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+	if _, err := doc.AddListDefinition(&ListDefinition{
+		Levels: []ListLevelDefinition{{PictureBullet: bulletData}},
+	}); err != nil {
+		t.Fatalf("failed to add list definition: %v", err)
+	}
+	if got := doc.mediaReferences("word/" + mediaTarget(doc, info.RelationID)); got != 2 {
+		t.Fatalf("the picture bullet should share the media part of the image, got %d references", got)
+	}
+
+	// replacing the body picture leaves the picture bullet alone
+	if err := doc.ReplaceImage(info, createTestImage(32, 32)); err != nil {
+		t.Fatalf("failed to replace image: %v", err)
+	}
+	if !bytes.Equal(doc.pictureBulletData("0"), bulletData) {
+		t.Error("the picture bullet should keep its image")
+	}
+
+	// a later copy of the bullet image is merged into the part of the bullet
+	copyInfo, err := doc.AddImageFromData(bulletData, "dot.png", ImageFormatPNG, 16, 16, nil)
+	if err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+	if removed, err := doc.DeduplicateMedia(); err != nil || removed != 1 {
+		t.Fatalf("expected one duplicate part to be removed, got %d (%v)", removed, err)
+	}
+	if !bytes.Equal(doc.pictureBulletData("0"), bulletData) {
+		t.Error("the picture bullet should keep its image after deduplication")
+	}
+	if !bytes.Equal(doc.parts["word/"+mediaTarget(doc, copyInfo.RelationID)], bulletData) {
+		t.Error("the copied picture should keep its image after deduplication")
+	}
+}
@@ -0,0 +1,381 @@
+// Package document provides cropping, borders, rotation and effects for images
+package document
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// emuPerPoint is the number of English Metric Units in a point
+const emuPerPoint = 12700
+
+// ImageBorderDash is the dash style of an image border
+type ImageBorderDash string
+
+const (
+	ImageDashSolid      ImageBorderDash = "solid"
+	ImageDashDot        ImageBorderDash = "dot"
+	ImageDashDash       ImageBorderDash = "dash"
+	ImageDashLongDash   ImageBorderDash = "lgDash"
+	ImageDashDashDot    ImageBorderDash = "dashDot"
+	ImageDashSquareDot  ImageBorderDash = "sysDot"
+	ImageDashSquareDash ImageBorderDash = "sysDash"
+)
+
+// ImageShadow configures the drop shadow of an image
+type ImageShadow struct {
+	Color        string  // shadow color in hex, black if empty
+	Transparency float64 // transparency in percent, 0 (opaque) to 100
+	Blur         float64 // blur radius in points
+	Distance     float64 // offset from the image in points
+	Angle        float64 // direction of the offset in degrees, clockwise from the right
+}
+
+// DefaultImageShadow returns a soft shadow offset to the bottom right, like Word's outer shadow preset
+func DefaultImageShadow() *ImageShadow {
+	return &ImageShadow{Color: "000000", Transparency: 60, Blur: 4, Distance: 3, Angle: 45}
+}
+
+// CropImage crops an image, each edge given in percent of the original image size
+//
+// The displayed size shrinks by the cropped part, so the image keeps its scale. Cropping
+// replaces an earlier crop and all zero values remove it.
+func (d *Document) CropImage(imageInfo *ImageInfo, top, right, bottom, left float64) error {
+	for name, value := range map[string]float64{"top": top, "right": right, "bottom": bottom, "left": left} {
+		if value < 0 || value >= 100 {
+			return NewValidationError("crop "+name, fmt.Sprintf("%g", value), "must be between 0 and 100 percent")
+		}
+	}
+	if top+bottom >= 100 || left+right >= 100 {
+		return NewValidationError("crop", fmt.Sprintf("%g/%g/%g/%g", top, right, bottom, left), "cropping leaves no visible area")
+	}
+
+	drawing, pic, err := d.findImagePic(imageInfo)
+	if err != nil {
+		return err
+	}
+
+	// the current size is the uncropped size reduced by the previous crop
+	var oldTop, oldRight, oldBottom, oldLeft float64
+	if rect := pic.BlipFill.SrcRect; rect != nil {
+		oldTop, oldRight = srcRectPercent(rect.T), srcRectPercent(rect.R)
+		oldBottom, oldLeft = srcRectPercent(rect.B), srcRectPercent(rect.L)
+	}
+	cx, _ := strconv.ParseFloat(pic.SpPr.Xfrm.Ext.Cx, 64)
+	cy, _ := strconv.ParseFloat(pic.SpPr.Xfrm.Ext.Cy, 64)
+	fullWidth := cx / (1 - (oldLeft+oldRight)/100)
+	fullHeight := cy / (1 - (oldTop+oldBottom)/100)
+	setDrawingSize(drawing, pic,
+		int64(math.Round(fullWidth*(1-(left+right)/100))),
+		int64(math.Round(fullHeight*(1-(top+bottom)/100))))
+
+	if top == 0 && right == 0 && bottom == 0 && left == 0 {
+		pic.BlipFill.SrcRect = nil
+		return nil
+	}
+	pic.BlipFill.SrcRect = &SrcRect{
+		T: formatSrcRectPercent(top),
+		R: formatSrcRectPercent(right),
+		B: formatSrcRectPercent(bottom),
+		L: formatSrcRectPercent(left),
+	}
+	Debugf("image %s cropped: top %g%%, right %g%%, bottom %g%%, left %g%%", imageInfo.ID, top, right, bottom, left)
+	return nil
+}
+
+// SetImageBorder draws a border around an image, the width in points
+// An empty dash style draws a solid line, and a width of 0 removes the border.
+func (d *Document) SetImageBorder(imageInfo *ImageInfo, color string, width float64, dash ImageBorderDash) error {
+	_, pic, err := d.findImagePic(imageInfo)
+	if err != nil {
+		return err
+	}
+	if width <= 0 {
+		pic.SpPr.Ln = nil
+		return nil
+	}
+
+	rgb, ok := parseHexColor(color)
+	if !ok {
+		return NewValidationError("color", color, "must be a hex color such as FF0000")
+	}
+	if dash == "" {
+		dash = ImageDashSolid
+	}
+	pic.SpPr.Ln = &Outline{
+		W:         strconv.Itoa(int(math.Round(width * emuPerPoint))),
+		SolidFill: &SolidFill{SrgbClr: &SrgbClr{Val: formatHexColor(rgb)}},
+		PrstDash:  &PrstDash{Val: string(dash)},
+	}
+	return nil
+}
+
+// RotateImage rotates an image clockwise by the given angle in degrees
+// The rotation replaces an earlier one; Word rotates the image around its center.
+func (d *Document) RotateImage(imageInfo *ImageInfo, degrees float64) error {
+	_, pic, err := d.findImagePic(imageInfo)
+	if err != nil {
+		return err
+	}
+
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	pic.SpPr.Xfrm.Rot = ""
+	if rot := int(math.Round(degrees * 60000)); rot != 0 && rot != 360*60000 {
+		pic.SpPr.Xfrm.Rot = strconv.Itoa(rot)
+	}
+	return nil
+}
+
+// FlipImage mirrors an image horizontally and/or vertically, false values remove a flip
+func (d *Document) FlipImage(imageInfo *ImageInfo, horizontal, vertical bool) error {
+	_, pic, err := d.findImagePic(imageInfo)
+	if err != nil {
+		return err
+	}
+
+	pic.SpPr.Xfrm.FlipH, pic.SpPr.Xfrm.FlipV = "", ""
+	if horizontal {
+		pic.SpPr.Xfrm.FlipH = "1"
+	}
+	if vertical {
+		pic.SpPr.Xfrm.FlipV = "1"
+	}
+	return nil
+}
+
+// SetImageShadow adds a drop shadow to an image, nil removes the shadow
+func (d *Document) SetImageShadow(imageInfo *ImageInfo, shadow *ImageShadow) error {
+	_, pic, err := d.findImagePic(imageInfo)
+	if err != nil {
+		return err
+	}
+	if shadow == nil {
+		if pic.SpPr.EffectLst != nil {
+			pic.SpPr.EffectLst.OuterShdw = nil
+		}
+		pic.SpPr.EffectLst = normalizeEffectList(pic.SpPr.EffectLst)
+		return nil
+	}
+
+	color := shadow.Color
+	if color == "" {
+		color = "000000"
+	}
+	rgb, ok := parseHexColor(color)
+	if !ok {
+		return NewValidationError("shadow color", shadow.Color, "must be a hex color such as 000000")
+	}
+	if shadow.Transparency < 0 || shadow.Transparency > 100 {
+		return NewValidationError("shadow transparency", fmt.Sprintf("%g", shadow.Transparency), "must be between 0 and 100 percent")
+	}
+	if shadow.Blur < 0 || shadow.Distance < 0 {
+		return NewValidationError("shadow", fmt.Sprintf("%g/%g", shadow.Blur, shadow.Distance), "blur and distance cannot be negative")
+	}
+
+	angle := math.Mod(shadow.Angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	clr := &SrgbClr{Val: formatHexColor(rgb)}
+	if shadow.Transparency > 0 {
+		clr.Alpha = &Alpha{Val: strconv.Itoa(int(math.Round((100 - shadow.Transparency) * 1000)))}
+	}
+
+	if pic.SpPr.EffectLst == nil {
+		pic.SpPr.EffectLst = &EffectList{}
+	}
+	pic.SpPr.EffectLst.OuterShdw = &OuterShdw{
+		BlurRad:      strconv.Itoa(int(math.Round(shadow.Blur * emuPerPoint))),
+		Dist:         strconv.Itoa(int(math.Round(shadow.Distance * emuPerPoint))),
+		Dir:          strconv.Itoa(int(math.Round(angle * 60000))),
+		Algn:         "tl",
+		RotWithShape: "0",
+		SrgbClr:      clr,
+	}
+	return nil
+}
+
+// SetImageSoftEdges blurs the edges of an image over the given radius in points, 0 removes the effect
+func (d *Document) SetImageSoftEdges(imageInfo *ImageInfo, radius float64) error {
+	if radius < 0 {
+		return NewValidationError("soft edge radius", fmt.Sprintf("%g", radius), "cannot be negative")
+	}
+	_, pic, err := d.findImagePic(imageInfo)
+	if err != nil {
+		return err
+	}
+
+	if radius == 0 {
+		if pic.SpPr.EffectLst != nil {
+			pic.SpPr.EffectLst.SoftEdge = nil
+		}
+		pic.SpPr.EffectLst = normalizeEffectList(pic.SpPr.EffectLst)
+		return nil
+	}
+	if pic.SpPr.EffectLst == nil {
+		pic.SpPr.EffectLst = &EffectList{}
+	}
+	pic.SpPr.EffectLst.SoftEdge = &SoftEdge{Rad: strconv.Itoa(int(math.Round(radius * emuPerPoint)))}
+	return nil
+}
+
+// findImagePic locates the drawing of an image in the document body, including table cells
+// It makes sure the picture has shape properties with a transform to edit.
+func (d *Document) findImagePic(imageInfo *ImageInfo) (*DrawingElement, *PicElement, error) {
+	if imageInfo == nil {
+		return nil, nil, fmt.Errorf("图片信息不能为空")
+	}
+
+	drawing := findDrawingInElements(d.Body.Elements, imageInfo.ID)
+	if drawing == nil {
+		return nil, nil, fmt.Errorf("image %s not found in the document", imageInfo.ID)
+	}
+	pic := drawingPic(drawing)
+	if pic == nil {
+		return nil, nil, fmt.Errorf("drawing %s is not a picture", imageInfo.ID)
+	}
+
+	if pic.SpPr == nil {
+		pic.SpPr = &SpPr{PrstGeom: &PrstGeom{Prst: "rect", AvLst: &AvLst{}}}
+	}
+	if pic.SpPr.Xfrm == nil {
+		pic.SpPr.Xfrm = &Xfrm{}
+	}
+	if pic.SpPr.Xfrm.Ext == nil {
+		extent := drawingExtent(drawing)
+		pic.SpPr.Xfrm.Ext = &Ext{Cx: extent.Cx, Cy: extent.Cy}
+	}
+	return drawing, pic, nil
+}
+
+// findDrawingInElements searches paragraphs and tables for the drawing with the given docPr ID
+func findDrawingInElements(elements []interface{}, id string) *DrawingElement {
+	var found *DrawingElement
+	walkDrawings(elements, func(drawing *DrawingElement) bool {
+		if docPr := drawingDocPr(drawing); docPr != nil && docPr.ID == id {
+			found = drawing
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// walkDrawings calls fn for every drawing in paragraphs and tables, including nested tables,
+// until fn returns false. It reports whether the walk completed.
+func walkDrawings(elements []interface{}, fn func(drawing *DrawingElement) bool) bool {
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *Paragraph:
+			if !walkParagraphDrawings(e, fn) {
+				return false
+			}
+		case *Table:
+			if !walkTableDrawings(e, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// walkTableDrawings calls fn for the drawings in the cells of a table
+func walkTableDrawings(table *Table, fn func(drawing *DrawingElement) bool) bool {
+	for r := range table.Rows {
+		for c := range table.Rows[r].Cells {
+			cell := &table.Rows[r].Cells[c]
+			for p := range cell.Paragraphs {
+				if !walkParagraphDrawings(&cell.Paragraphs[p], fn) {
+					return false
+				}
+			}
+			for t := range cell.Tables {
+				if !walkTableDrawings(&cell.Tables[t], fn) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// walkParagraphDrawings calls fn for the drawings of a paragraph
+func walkParagraphDrawings(paragraph *Paragraph, fn func(drawing *DrawingElement) bool) bool {
+	for _, run := range paragraph.Runs {
+		if run.Drawing != nil && (run.Drawing.Inline != nil || run.Drawing.Anchor != nil) {
+			if !fn(run.Drawing) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// drawingDocPr returns the docPr of an inline or floating drawing
+func drawingDocPr(drawing *DrawingElement) *DrawingDocPr {
+	if drawing.Inline != nil {
+		return drawing.Inline.DocPr
+	}
+	return drawing.Anchor.DocPr
+}
+
+// drawingPic returns the picture of a drawing, or nil for other graphics
+func drawingPic(drawing *DrawingElement) *PicElement {
+	var graphic *DrawingGraphic
+	if drawing.Inline != nil {
+		graphic = drawing.Inline.Graphic
+	} else {
+		graphic = drawing.Anchor.Graphic
+	}
+	if graphic == nil || graphic.GraphicData == nil || graphic.GraphicData.Pic == nil || graphic.GraphicData.Pic.BlipFill == nil {
+		return nil
+	}
+	return graphic.GraphicData.Pic
+}
+
+// drawingExtent returns the extent of an inline or floating drawing
+func drawingExtent(drawing *DrawingElement) *DrawingExtent {
+	if drawing.Inline != nil {
+		if drawing.Inline.Extent == nil {
+			drawing.Inline.Extent = &DrawingExtent{Cx: "0", Cy: "0"}
+		}
+		return drawing.Inline.Extent
+	}
+	if drawing.Anchor.Extent == nil {
+		drawing.Anchor.Extent = &DrawingExtent{Cx: "0", Cy: "0"}
+	}
+	return drawing.Anchor.Extent
+}
+
+// setDrawingSize sets the displayed size of a picture in EMU
+func setDrawingSize(drawing *DrawingElement, pic *PicElement, cx, cy int64) {
+	extent := drawingExtent(drawing)
+	extent.Cx, extent.Cy = strconv.FormatInt(cx, 10), strconv.FormatInt(cy, 10)
+	pic.SpPr.Xfrm.Ext.Cx, pic.SpPr.Xfrm.Ext.Cy = extent.Cx, extent.Cy
+}
+
+// srcRectPercent converts a crop value in 1/1000 percent to percent
+func srcRectPercent(value string) float64 {
+	v, _ := strconv.ParseFloat(value, 64)
+	return v / 1000
+}
+
+// formatSrcRectPercent converts percent to a crop value in 1/1000 percent, empty for no crop
+func formatSrcRectPercent(percent float64) string {
+	if percent == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(math.Round(percent * 1000)))
+}
+
+// normalizeEffectList drops an effect list without effects
+func normalizeEffectList(effects *EffectList) *EffectList {
+	if effects == nil || (effects.OuterShdw == nil && effects.SoftEdge == nil) {
+		return nil
+	}
+	return effects
+}
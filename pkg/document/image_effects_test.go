@@ -0,0 +1,197 @@
+package document
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+)
+
+// imagePic returns the picture element of the first drawing in the document body
+func imagePic(t *testing.T, doc *Document) (*DrawingElement, *PicElement) {
+	for _, element := range doc.Body.Elements {
+		if para, ok := element.(*Paragraph); ok {
+			for _, run := range para.Runs {
+				if run.Drawing != nil && run.Drawing.Inline != nil {
+					return run.Drawing, run.Drawing.Inline.Graphic.GraphicData.Pic
+				}
+			}
+		}
+	}
+	t.Fatal("no image in document")
+	return nil, nil
+}
+
+// TestCropImage tests cropping and that the displayed size follows the crop
+func TestCropImage(t *testing.T) {
+	doc := New()
+	info, err := doc.AddImageFromData(createTestImage(200, 100), "crop.png", ImageFormatPNG, 200, 100, nil)
+	if err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+
+	if err := doc.CropImage(info, 10, 25, 10, 25); err != nil {
+		t.Fatalf("failed to crop image: %v", err)
+	}
+	drawing, pic := imagePic(t, doc)
+	rect := pic.BlipFill.SrcRect
+	if rect == nil || rect.T != "10000" || rect.L != "25000" || rect.R != "25000" || rect.B != "10000" {
+		t.Fatalf("unexpected crop rectangle: %+v", rect)
+	}
+	if drawing.Inline.Extent.Cx != "952500" || drawing.Inline.Extent.Cy != "762000" || pic.SpPr.Xfrm.Ext.Cx != "952500" {
+		t.Errorf("cropped image should shrink, got %sx%s", drawing.Inline.Extent.Cx, drawing.Inline.Extent.Cy)
+	}
+
+	// a new crop replaces the previous one and is based on the original size
+	if err := doc.CropImage(info, 0, 0, 0, 0); err != nil {
+		t.Fatalf("failed to remove crop: %v", err)
+	}
+	if pic.BlipFill.SrcRect != nil || drawing.Inline.Extent.Cx != "1905000" || drawing.Inline.Extent.Cy != "952500" {
+		t.Errorf("removing the crop should restore the size, got %sx%s", drawing.Inline.Extent.Cx, drawing.Inline.Extent.Cy)
+	}
+
+	if err := doc.CropImage(info, 60, 0, 40, 0); err == nil {
+		t.Error("expected error when nothing remains visible")
+	}
+	if err := doc.CropImage(&ImageInfo{ID: "99"}, 10, 0, 0, 0); err == nil {
+		t.Error("expected error for an image that is not in the document")
+	}
+}
+
+// TestImageBorderRotationAndEffects tests borders, rotation, flipping and effects with a round trip
+func TestImageBorderRotationAndEffects(t *testing.T) {
+	doc := New()
+	table, _ := doc.AddTable(&TableConfig{Rows: 1, Cols: 1, Width: 3000})
+	if _, err := doc.AddCellImageFromData(table, 0, 0, createTestImage(10, 10), 10); err != nil {
+		t.Fatalf("failed to add cell image: %v", err)
+	}
+	info, err := doc.AddImageFromData(createTestImage(100, 50), "photo.png", ImageFormatPNG, 100, 50, nil)
+	if err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+
+	if err := doc.SetImageBorder(info, "#1f4e79", 1.5, ImageDashDash); err != nil {
+		t.Fatalf("failed to set border: %v", err)
+	}
+	if err := doc.RotateImage(info, -90); err != nil {
+		t.Fatalf("failed to rotate image: %v", err)
+	}
+	if err := doc.FlipImage(info, true, false); err != nil {
+		t.Fatalf("failed to flip image: %v", err)
+	}
+	if err := doc.SetImageShadow(info, DefaultImageShadow()); err != nil {
+		t.Fatalf("failed to set shadow: %v", err)
+	}
+	if err := doc.SetImageSoftEdges(info, 5); err != nil {
+		t.Fatalf("failed to set soft edges: %v", err)
+	}
+	if err := doc.CropImage(info, 0, 0, 20, 0); err != nil {
+		t.Fatalf("failed to crop image: %v", err)
+	}
+
+	filename := "test_image_effects.docx"
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	defer os.Remove(filename)
+
+	// compare without the indentation and attribute spacing of the serializer
+	compact := strings.Join(strings.Fields(string(doc.parts["word/document.xml"])), "")
+	for _, expected := range []string{
+		`<a:srcRect b="20000"></a:srcRect>`,
+		`<a:xfrm rot="16200000" flipH="1">`,
+		`<a:ln w="19050"><a:solidFill><a:srgbClr val="1F4E79"></a:srgbClr></a:solidFill><a:prstDash val="dash"></a:prstDash></a:ln>`,
+		`<a:outerShdw blurRad="50800" dist="38100" dir="2700000" algn="tl" rotWithShape="0"><a:srgbClr val="000000"><a:alpha val="40000"></a:alpha></a:srgbClr></a:outerShdw>`,
+		`<a:softEdge rad="63500"></a:softEdge>`,
+	} {
+		if !strings.Contains(compact, strings.Join(strings.Fields(expected), "")) {
+			t.Errorf("document.xml should contain %s", expected)
+		}
+	}
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	_, pic := imagePic(t, opened)
+	if pic.BlipFill.SrcRect == nil || pic.BlipFill.SrcRect.B != "20000" {
+		t.Error("crop lost after reopening")
+	}
+	if pic.SpPr.Xfrm.Rot != "16200000" || pic.SpPr.Xfrm.FlipH != "1" || pic.SpPr.Xfrm.FlipV != "" {
+		t.Errorf("rotation and flip lost after reopening: %+v", pic.SpPr.Xfrm)
+	}
+	if pic.SpPr.Ln == nil || pic.SpPr.Ln.W != "19050" || pic.SpPr.Ln.SolidFill.SrgbClr.Val != "1F4E79" || pic.SpPr.Ln.PrstDash.Val != "dash" {
+		t.Errorf("border lost after reopening: %+v", pic.SpPr.Ln)
+	}
+	effects := pic.SpPr.EffectLst
+	if effects == nil || effects.OuterShdw == nil || effects.OuterShdw.SrgbClr.Alpha.Val != "40000" || effects.SoftEdge.Rad != "63500" {
+		t.Fatal("effects lost after reopening")
+	}
+
+	// removing everything leaves a plain picture
+	doc.SetImageBorder(info, "", 0, "")
+	doc.RotateImage(info, 360)
+	doc.FlipImage(info, false, false)
+	doc.SetImageShadow(info, nil)
+	doc.SetImageSoftEdges(info, 0)
+	_, pic = imagePic(t, doc)
+	if pic.SpPr.Ln != nil || pic.SpPr.EffectLst != nil || pic.SpPr.Xfrm.Rot != "" || pic.SpPr.Xfrm.FlipH != "" {
+		t.Error("border, rotation, flip and effects should be removed")
+	}
+
+	if err := doc.SetImageBorder(info, "blue", 1, ""); err == nil {
+		t.Error("expected error for an invalid border color")
+	}
+	if err := doc.SetImageShadow(info, &ImageShadow{Transparency: 120}); err == nil {
+		t.Error("expected error for an invalid shadow transparency")
+	}
+}
+
+// TestImageThemeColorsRoundTrip tests that theme colors of borders and shadows survive a round trip
+func TestImageThemeColorsRoundTrip(t *testing.T) {
+	doc := New()
+	info, err := doc.AddImageFromData(createTestImage(100, 50), "theme.png", ImageFormatPNG, 100, 50, nil)
+	if err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+	doc.SetImageBorder(info, "000000", 1, "")
+	doc.SetImageShadow(info, DefaultImageShadow())
+
+	_, pic := imagePic(t, doc)
+	lumMod := ColorTransform{XMLName: xml.Name{Local: "a:lumMod"}, Val: "75000"}
+	pic.SpPr.Ln.SolidFill = &SolidFill{SchemeClr: &SchemeClr{Val: "accent1", Transforms: []ColorTransform{lumMod}}}
+	pic.SpPr.EffectLst.OuterShdw.SrgbClr = nil
+	pic.SpPr.EffectLst.OuterShdw.SchemeClr = &SchemeClr{Val: "tx1"}
+
+	filename := "test_image_theme_colors.docx"
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	defer os.Remove(filename)
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	_, pic = imagePic(t, opened)
+	fill := pic.SpPr.Ln.SolidFill
+	if fill == nil || fill.SrgbClr != nil || fill.SchemeClr == nil || fill.SchemeClr.Val != "accent1" {
+		t.Fatalf("border theme color lost after reopening: %+v", fill)
+	}
+	if len(fill.SchemeClr.Transforms) != 1 || fill.SchemeClr.Transforms[0] != lumMod {
+		t.Errorf("theme color transforms lost after reopening: %+v", fill.SchemeClr.Transforms)
+	}
+	shadow := pic.SpPr.EffectLst.OuterShdw
+	if shadow.SrgbClr != nil || shadow.SchemeClr == nil || shadow.SchemeClr.Val != "tx1" {
+		t.Errorf("shadow theme color lost after reopening: %+v", shadow)
+	}
+
+	if err := opened.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	compact := strings.Join(strings.Fields(string(opened.parts["word/document.xml"])), "")
+	expected := `<a:solidFill><a:schemeClrval="accent1"><a:lumModval="75000"></a:lumMod></a:schemeClr></a:solidFill>`
+	if !strings.Contains(compact, expected) {
+		t.Errorf("document.xml should contain %s", expected)
+	}
+}
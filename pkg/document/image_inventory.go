@@ -0,0 +1,377 @@
+// Package document provides listing, extraction and replacement of images
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ImageLocation is the part of the document an image is placed in
+type ImageLocation string
+
+const (
+	ImageInBody   ImageLocation = "body"
+	ImageInTable  ImageLocation = "table"
+	ImageInHeader ImageLocation = "header"
+	ImageInFooter ImageLocation = "footer"
+)
+
+// DocumentImage describes a picture placed in the document
+type DocumentImage struct {
+	// Info holds the docPr ID, the relationship ID, the format, the pixel size and the data
+	Info *ImageInfo
+	// Location tells whether the picture is in the body, a table, a header or a footer
+	Location ImageLocation
+	// Part is the document part containing the picture, e.g. word/document.xml or word/header1.xml
+	Part string
+	// MediaPath is the part holding the image data, e.g. word/media/image1.png
+	MediaPath string
+	// DisplayWidth and DisplayHeight are the displayed size in EMU
+	DisplayWidth  int64
+	DisplayHeight int64
+	AltText       string
+	Title         string
+	Floating      bool
+}
+
+// Images lists the pictures of the document body, its tables, headers and footers
+//
+// Body pictures come first in document order, followed by the pictures of the headers and
+// footers ordered by part name. A picture shown several times is listed once per placement.
+func (d *Document) Images() []*DocumentImage {
+	var images []*DocumentImage
+	collect := func(location ImageLocation) func(*DrawingElement) bool {
+		return func(drawing *DrawingElement) bool {
+			if image := d.documentImage(drawing, location, ""); image != nil {
+				images = append(images, image)
+			}
+			return true
+		}
+	}
+	for _, element := range d.Body.Elements {
+		switch e := element.(type) {
+		case *Paragraph:
+			walkParagraphDrawings(e, collect(ImageInBody))
+		case *Table:
+			walkTableDrawings(e, collect(ImageInTable))
+		}
+	}
+
+	var parts []string
+	for name := range d.parts {
+		if headerFooterLocation(name) != "" {
+			parts = append(parts, name)
+		}
+	}
+	sort.Strings(parts)
+	for _, part := range parts {
+		drawings, err := d.parsePartDrawings(part)
+		if err != nil {
+			Debugf("failed to read the pictures of %s: %v", part, err)
+			continue
+		}
+		for _, drawing := range drawings {
+			if image := d.documentImage(drawing, headerFooterLocation(part), part); image != nil {
+				images = append(images, image)
+			}
+		}
+	}
+	return images
+}
+
+// ExtractImages writes the image data of every picture to dir and returns the written files
+// Images used by several pictures are written once, named after their media part.
+func (d *Document) ExtractImages(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, WrapErrorWithContext("create_dir", err, dir)
+	}
+
+	var files []string
+	written := make(map[string]bool)
+	for _, image := range d.Images() {
+		if written[image.MediaPath] {
+			continue
+		}
+		written[image.MediaPath] = true
+
+		file := filepath.Join(dir, path.Base(image.MediaPath))
+		if err := os.WriteFile(file, image.Info.Data, 0644); err != nil {
+			return files, WrapErrorWithContext("write_image", err, file)
+		}
+		files = append(files, file)
+	}
+	Infof("extracted %d images to %s", len(files), dir)
+	return files, nil
+}
+
+// ReplaceImage replaces the data of an image while keeping its displayed size and position
+//
+// imageInfo is an image returned by Images or one added to the document. The new data may be in
+// another format, WebP data is converted to PNG. When the image data is shared with other
+// pictures, for example after deduplication, the picture gets a media part of its own and the
+// other pictures keep the old image. SVG images cannot be replaced or used as replacement.
+func (d *Document) ReplaceImage(imageInfo *ImageInfo, newData []byte) error {
+	if imageInfo == nil {
+		return fmt.Errorf("图片信息不能为空")
+	}
+	format, err := detectImageFormat(newData)
+	if err != nil {
+		return WrapError("replace_image", err)
+	}
+	if format == ImageFormatSVG || imageInfo.SVGRelationID != "" {
+		return NewValidationError("format", string(format), "replacing SVG images is not supported")
+	}
+	if format == ImageFormatWebP {
+		if newData, err = convertImageToPNG(newData); err != nil {
+			return WrapError("replace_image", err)
+		}
+		format = ImageFormatPNG
+	}
+	width, height, err := getImageDimensions(newData, format)
+	if err != nil {
+		return WrapError("replace_image", err)
+	}
+
+	rels, err := d.partRelationships(imageInfo.part)
+	if err != nil {
+		return err
+	}
+	var rel *Relationship
+	for i := range rels.Relationships {
+		if rels.Relationships[i].ID == imageInfo.RelationID {
+			rel = &rels.Relationships[i]
+		}
+	}
+	if rel == nil {
+		return fmt.Errorf("image relationship %s not found", imageInfo.RelationID)
+	}
+
+	oldMedia := resolvePartTarget(imageInfo.part, rel.Target)
+	oldFormat, _ := detectImageFormat(d.parts[oldMedia])
+	relUses := d.relationshipPlacements(imageInfo.part, rel.ID)
+	switch {
+	case relUses > 1 && imageInfo.part == "":
+		// the relationship is shared by several pictures, give this one a relationship of its own
+		var pic *PicElement
+		if drawing := findDrawingInElements(d.Body.Elements, imageInfo.ID); drawing != nil {
+			pic = drawingPic(drawing)
+		}
+		if pic != nil && pic.BlipFill.Blip != nil {
+			media := resolvePartTarget("", d.uniqueMediaTarget(rel.Target, format))
+			d.parts[media] = newData
+			relationID, err := d.addPartImageRelationship("", media)
+			if err != nil {
+				return err
+			}
+			pic.BlipFill.Blip.Embed = relationID
+			imageInfo.RelationID = relationID
+			d.addImageContentType(format)
+			break
+		}
+		fallthrough
+	case relUses > 1 || d.mediaReferences(oldMedia) > 1:
+		// the media part is shared with other relationships, point only this one at new data
+		media := resolvePartTarget(imageInfo.part, d.uniqueMediaTarget(rel.Target, format))
+		d.parts[media] = newData
+		rel.Target = relativePartTarget(imageInfo.part, media)
+		if err := d.storePartRelationships(imageInfo.part, rels); err != nil {
+			return err
+		}
+		d.addImageContentType(format)
+	case oldFormat == format:
+		d.parts[oldMedia] = newData
+	default:
+		media := resolvePartTarget(imageInfo.part, d.uniqueMediaTarget(rel.Target, format))
+		d.parts[media] = newData
+		if err := d.retargetMedia(oldMedia, media); err != nil {
+			return err
+		}
+		d.addImageContentType(format)
+	}
+
+	imageInfo.Format = format
+	imageInfo.Data = newData
+	imageInfo.Width, imageInfo.Height = width, height
+	Infof("replaced image %s (%s, %dx%d)", imageInfo.ID, format, width, height)
+	return nil
+}
+
+// mediaReferences returns the number of image relationships pointing at a media part
+func (d *Document) mediaReferences(media string) int {
+	count := 0
+	for owner, rels := range d.mediaOwners() {
+		for _, rel := range rels.Relationships {
+			if rel.Type == imageRelationshipType && resolvePartTarget(owner, rel.Target) == media {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// relationshipPlacements returns the number of pictures of a part using an image relationship
+func (d *Document) relationshipPlacements(part, relationID string) int {
+	count := 0
+	countDrawing := func(drawing *DrawingElement) bool {
+		if pic := drawingPic(drawing); pic != nil && pic.BlipFill.Blip != nil && pic.BlipFill.Blip.Embed == relationID {
+			count++
+		}
+		return true
+	}
+	if part == "" {
+		walkDrawings(d.Body.Elements, countDrawing)
+		return count
+	}
+	drawings, err := d.parsePartDrawings(part)
+	if err != nil {
+		Debugf("failed to read the pictures of %s: %v", part, err)
+	}
+	for _, drawing := range drawings {
+		countDrawing(drawing)
+	}
+	return count
+}
+
+// documentImage describes a picture drawing, returning nil for drawings that are not pictures
+func (d *Document) documentImage(drawing *DrawingElement, location ImageLocation, part string) *DocumentImage {
+	pic := drawingPic(drawing)
+	if pic == nil || pic.BlipFill.Blip == nil {
+		return nil
+	}
+	blip := pic.BlipFill.Blip
+
+	info := &ImageInfo{RelationID: blip.Embed, SVGRelationID: blip.SVGEmbed(), part: part}
+	if docPr := drawingDocPr(drawing); docPr != nil {
+		info.ID = docPr.ID
+	}
+	image := &DocumentImage{
+		Info:     info,
+		Location: location,
+		Part:     part,
+		Floating: drawing.Anchor != nil && drawing.Inline == nil,
+	}
+	if part == "" {
+		image.Part = "word/document.xml"
+	}
+	if docPr := drawingDocPr(drawing); docPr != nil {
+		image.AltText, image.Title = docPr.Descr, docPr.Title
+	}
+	extent := drawingExtent(drawing)
+	image.DisplayWidth, _ = strconv.ParseInt(extent.Cx, 10, 64)
+	image.DisplayHeight, _ = strconv.ParseInt(extent.Cy, 10, 64)
+
+	relationID := info.RelationID
+	if info.SVGRelationID != "" {
+		relationID = info.SVGRelationID
+	}
+	if rels, err := d.partRelationships(part); err == nil {
+		for _, rel := range rels.Relationships {
+			if rel.ID == relationID {
+				image.MediaPath = resolvePartTarget(part, rel.Target)
+			}
+		}
+	}
+	info.Data = d.parts[image.MediaPath]
+	if format, err := detectImageFormat(info.Data); err == nil {
+		info.Format = format
+		info.Width, info.Height, _ = getImageDimensions(info.Data, format)
+	}
+	return image
+}
+
+// headerFooterLocation returns the location of a header or footer part, or an empty string
+func headerFooterLocation(part string) ImageLocation {
+	name := strings.TrimPrefix(part, "word/")
+	if strings.Contains(name, "/") || !strings.HasSuffix(name, ".xml") {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(name, "header"):
+		return ImageInHeader
+	case strings.HasPrefix(name, "footer"):
+		return ImageInFooter
+	}
+	return ""
+}
+
+// parsePartDrawings reads the drawings of a part that is kept as XML, such as a header
+func (d *Document) parsePartDrawings(part string) ([]*DrawingElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(d.parts[part]))
+	var drawings []*DrawingElement
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return drawings, nil
+		}
+		if err != nil {
+			return nil, WrapError("parse_part_drawings", err)
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "drawing" {
+			drawing, err := d.parseDrawingElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			if drawing.Inline != nil || drawing.Anchor != nil {
+				drawings = append(drawings, drawing)
+			}
+		}
+	}
+}
+
+// partRelationships returns the relationships of a part, "" being the main document
+func (d *Document) partRelationships(part string) (*Relationships, error) {
+	if part == "" || part == "word/document.xml" {
+		if d.documentRelationships == nil {
+			return &Relationships{}, nil
+		}
+		return d.documentRelationships, nil
+	}
+
+	rels := &Relationships{}
+	data, ok := d.parts[relationshipsPartName(part)]
+	if !ok {
+		return rels, nil
+	}
+	if err := xml.Unmarshal(data, rels); err != nil {
+		return nil, WrapErrorWithContext("parse_relationships", err, part)
+	}
+	return rels, nil
+}
+
+// storePartRelationships writes back the relationships of a part kept as XML
+func (d *Document) storePartRelationships(part string, rels *Relationships) error {
+	if part == "" || part == "word/document.xml" {
+		return nil
+	}
+	rels.Xmlns = "http://schemas.openxmlformats.org/package/2006/relationships"
+	data, err := xml.MarshalIndent(rels, "", "  ")
+	if err != nil {
+		return WrapErrorWithContext("marshal_relationships", err, part)
+	}
+	d.parts[relationshipsPartName(part)] = append([]byte(xml.Header), data...)
+	return nil
+}
+
+// relationshipsPartName returns the name of the relationships part of a part
+func relationshipsPartName(part string) string {
+	return path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+}
+
+// resolvePartTarget resolves a relationship target relative to the folder of its part
+func resolvePartTarget(part, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	if part == "" {
+		part = "word/document.xml"
+	}
+	return path.Join(path.Dir(part), target)
+}
@@ -0,0 +1,149 @@
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// addHeaderImage puts a picture into the default header, as Word does for a logo
+func addHeaderImage(t *testing.T, doc *Document, data []byte) {
+	if err := doc.AddHeader(HeaderFooterTypeDefault, ""); err != nil {
+		t.Fatalf("failed to add header: %v", err)
+	}
+	logo := &ImageInfo{ID: "100", RelationID: "rId1", Format: ImageFormatPNG, Width: 30, Height: 10,
+		Config: &ImageConfig{AltText: "Company logo"}}
+	header := createStandardHeader()
+	header.Paragraphs = append(header.Paragraphs, doc.createImageParagraph(logo))
+	headerXML, err := xml.Marshal(header)
+	if err != nil {
+		t.Fatalf("failed to marshal header: %v", err)
+	}
+	doc.parts["word/header1.xml"] = append([]byte(xml.Header), headerXML...)
+	doc.parts["word/_rels/header1.xml.rels"] = []byte(xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/logo.png"/>` +
+		`</Relationships>`)
+	doc.parts["word/media/logo.png"] = data
+}
+
+// TestImagesInventory tests listing and extracting the pictures of body, tables and headers
+func TestImagesInventory(t *testing.T) {
+	doc := New()
+	if _, err := doc.AddImageFromData(createTestImage(40, 20), "chart.png", ImageFormatPNG, 40, 20,
+		&ImageConfig{AltText: "Sales chart", Position: ImagePositionFloatLeft}); err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+	table, _ := doc.AddTable(&TableConfig{Rows: 1, Cols: 1, Width: 3000})
+	if _, err := doc.AddCellImageFromData(table, 0, 0, createTestImage(10, 10), 10); err != nil {
+		t.Fatalf("failed to add cell image: %v", err)
+	}
+	addHeaderImage(t, doc, createTestImage(30, 10))
+
+	images := doc.Images()
+	if len(images) != 3 {
+		t.Fatalf("expected 3 images, got %d", len(images))
+	}
+	chart, cell, logo := images[0], images[1], images[2]
+	if chart.Location != ImageInBody || !chart.Floating || chart.AltText != "Sales chart" || chart.MediaPath != "word/media/image0.png" {
+		t.Errorf("unexpected body image: %+v", chart)
+	}
+	if chart.Info.Width != 40 || chart.Info.Height != 20 || chart.DisplayWidth != 40*9525 {
+		t.Errorf("unexpected body image size: %dx%d displayed %d", chart.Info.Width, chart.Info.Height, chart.DisplayWidth)
+	}
+	if cell.Location != ImageInTable || cell.DisplayWidth != 360000 {
+		t.Errorf("unexpected table image: %+v", cell)
+	}
+	if logo.Location != ImageInHeader || logo.Part != "word/header1.xml" || logo.MediaPath != "word/media/logo.png" ||
+		logo.AltText != "Company logo" || logo.Info.Width != 30 {
+		t.Errorf("unexpected header image: %+v", logo)
+	}
+
+	dir := t.TempDir()
+	files, err := doc.ExtractImages(dir)
+	if err != nil {
+		t.Fatalf("failed to extract images: %v", err)
+	}
+	if len(files) != 3 || filepath.Base(files[2]) != "logo.png" {
+		t.Fatalf("unexpected extracted files: %v", files)
+	}
+	if data, _ := os.ReadFile(files[0]); !bytes.Equal(data, chart.Info.Data) {
+		t.Error("extracted file should contain the image data")
+	}
+}
+
+// TestReplaceImage tests swapping image data in the body and in a header
+func TestReplaceImage(t *testing.T) {
+	doc := New()
+	info, err := doc.AddImageFromData(createTestImage(40, 20), "chart.png", ImageFormatPNG, 40, 20, nil)
+	if err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+	addHeaderImage(t, doc, createTestImage(30, 10))
+
+	newChart := createTestImage(80, 40)
+	if err := doc.ReplaceImage(info, newChart); err != nil {
+		t.Fatalf("failed to replace image: %v", err)
+	}
+	if !bytes.Equal(doc.parts["word/media/image0.png"], newChart) || info.Width != 80 {
+		t.Error("image data should be replaced in place")
+	}
+	if chart := doc.Images()[0]; chart.DisplayWidth != 40*9525 {
+		t.Errorf("displayed size should be kept, got %d", chart.DisplayWidth)
+	}
+
+	// a logo swap to another format changes the media part of the header
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 60, 20)), nil)
+	logo := doc.Images()[1].Info
+	if err := doc.ReplaceImage(logo, buf.Bytes()); err != nil {
+		t.Fatalf("failed to replace header image: %v", err)
+	}
+	if _, exists := doc.parts["word/media/logo.png"]; exists {
+		t.Error("unused media part should be removed")
+	}
+	if !strings.Contains(string(doc.parts["word/_rels/header1.xml.rels"]), `Target="media/logo.jpeg"`) {
+		t.Error("header relationship should point to the new media part")
+	}
+	if replaced := doc.Images()[1]; replaced.Info.Format != ImageFormatJPEG || replaced.Info.Width != 60 {
+		t.Errorf("header image should be the new JPEG, got %s %d", replaced.Info.Format, replaced.Info.Width)
+	}
+
+	// a picture placed twice through one relationship is replaced on its own
+	logoData := createTestImage(20, 20)
+	first, _ := doc.AddImageFromData(logoData, "logo.png", ImageFormatPNG, 20, 20, nil)
+	second, _ := doc.AddImageFromData(logoData, "logo.png", ImageFormatPNG, 20, 20, nil)
+	if first.RelationID != second.RelationID {
+		t.Fatal("duplicate pictures should share a relationship")
+	}
+	if err := doc.ReplaceImage(second, createTestImage(50, 20)); err != nil {
+		t.Fatalf("failed to replace shared image: %v", err)
+	}
+	if second.RelationID == first.RelationID || first.Width != 20 {
+		t.Error("only the replaced picture should get a new relationship")
+	}
+	if !bytes.Equal(doc.parts["word/"+mediaTarget(doc, first.RelationID)], logoData) {
+		t.Error("the other picture should keep its image")
+	}
+	if images := doc.Images(); images[1].Info.Width != 20 || images[2].Info.Width != 50 {
+		t.Error("only the replaced picture should show the new image")
+	}
+
+	filename := "test_replace_image.docx"
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	defer os.Remove(filename)
+
+	if err := doc.ReplaceImage(info, []byte("not an image")); err == nil {
+		t.Error("expected error for invalid image data")
+	}
+	if err := doc.ReplaceImage(&ImageInfo{RelationID: "rId99"}, newChart); err == nil {
+		t.Error("expected error for an unknown relationship")
+	}
+}
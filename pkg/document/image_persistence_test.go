@@ -197,6 +197,7 @@ func TestImageIDCounterAfterOpen(t *testing.T) {
 	doc1 := New()
 	doc1.AddParagraph("test image ID counter")
 
+	// distinct data, identical images would share one media part
 	imageData := createTestImageForPersistence(50, 50, color.RGBA{255, 0, 0, 255})
 
 	_, err := doc1.AddImageFromData(imageData, "img1.png", ImageFormatPNG, 50, 50, nil)
@@ -204,7 +205,7 @@ func TestImageIDCounterAfterOpen(t *testing.T) {
 		t.Fatalf("failed to add first image: %v", err)
 	}
 
-	_, err = doc1.AddImageFromData(imageData, "img2.png", ImageFormatPNG, 50, 50, nil)
+	_, err = doc1.AddImageFromData(createTestImageForPersistence(50, 50, color.RGBA{0, 255, 0, 255}), "img2.png", ImageFormatPNG, 50, 50, nil)
 	if err != nil {
 		t.Fatalf("failed to add second image: %v", err)
 	}
@@ -227,7 +228,7 @@ func TestImageIDCounterAfterOpen(t *testing.T) {
 
 	t.Logf("✓ nextImageID = %d (expected)", doc2.nextImageID)
 
-	_, err = doc2.AddImageFromData(imageData, "img3.png", ImageFormatPNG, 50, 50, nil)
+	_, err = doc2.AddImageFromData(createTestImageForPersistence(50, 50, color.RGBA{0, 0, 255, 255}), "img3.png", ImageFormatPNG, 50, 50, nil)
 	if err != nil {
 		t.Fatalf("failed to add third image: %v", err)
 	}
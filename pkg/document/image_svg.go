@@ -0,0 +1,893 @@
+// Package document provides SVG support for images
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/vector"
+)
+
+const (
+	// default size of an SVG image without width, height and viewBox, as in browsers
+	defaultSVGWidth  = 300
+	defaultSVGHeight = 150
+	// maxSVGRasterSize limits the larger side of the PNG fallback of an SVG image
+	maxSVGRasterSize = 2048
+	// curveSegments is the number of line segments a curve is flattened to
+	curveSegments = 16
+)
+
+// svgNode is a generic SVG element
+type svgNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []svgNode  `xml:",any"`
+}
+
+// attr returns the value of an attribute of the node, or an empty string
+func (n *svgNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+// number returns a numeric attribute of the node, or 0
+func (n *svgNode) number(name string) float64 {
+	v, _ := parseSVGLength(n.attr(name))
+	return v
+}
+
+// svgDocument is a parsed SVG image
+type svgDocument struct {
+	root    *svgNode
+	ids     map[string]*svgNode
+	viewBox []float64 // min-x, min-y, width, height; nil if not set
+}
+
+// isSVGData reports whether data looks like an SVG document
+func isSVGData(data []byte) bool {
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")))
+	return bytes.HasPrefix(head, []byte("<")) && bytes.Contains(head, []byte("<svg"))
+}
+
+// parseSVG parses an SVG document
+func parseSVG(data []byte) (*svgDocument, error) {
+	root := &svgNode{}
+	if err := xml.Unmarshal(data, root); err != nil {
+		return nil, fmt.Errorf("invalid SVG: %v", err)
+	}
+	if root.XMLName.Local != "svg" {
+		return nil, fmt.Errorf("invalid SVG: root element is %s", root.XMLName.Local)
+	}
+
+	svg := &svgDocument{root: root, ids: make(map[string]*svgNode)}
+	svg.indexIDs(root)
+	if viewBox := parseSVGNumbers(root.attr("viewBox")); len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 {
+		svg.viewBox = viewBox
+	}
+	return svg, nil
+}
+
+// indexIDs records the elements with an id attribute, used to resolve gradient references
+func (s *svgDocument) indexIDs(node *svgNode) {
+	if id := node.attr("id"); id != "" {
+		s.ids[id] = node
+	}
+	for i := range node.Children {
+		s.indexIDs(&node.Children[i])
+	}
+}
+
+// pixelSize returns the size of the image in pixels at 96 DPI
+//
+// A missing width or height is derived from the viewBox, keeping its aspect ratio.
+func (s *svgDocument) pixelSize() (int, int) {
+	width, okW := parseSVGLength(s.root.attr("width"))
+	height, okH := parseSVGLength(s.root.attr("height"))
+	okW, okH = okW && width > 0, okH && height > 0
+
+	switch {
+	case okW && okH:
+	case s.viewBox != nil && okW:
+		height = width * s.viewBox[3] / s.viewBox[2]
+	case s.viewBox != nil && okH:
+		width = height * s.viewBox[2] / s.viewBox[3]
+	case s.viewBox != nil:
+		width, height = s.viewBox[2], s.viewBox[3]
+	default:
+		if !okW {
+			width = defaultSVGWidth
+		}
+		if !okH {
+			height = defaultSVGHeight
+		}
+	}
+	return max(1, int(math.Round(width))), max(1, int(math.Round(height)))
+}
+
+// rasterizeSVG renders an SVG image to PNG
+//
+// The renderer covers the basic shapes, paths, transforms and solid colors, which is enough
+// for a fallback image. Text, gradients (drawn with their first stop color), patterns, clip
+// paths and filters are not rendered.
+func rasterizeSVG(data []byte) ([]byte, error) {
+	svg, err := parseSVG(data)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := svg.pixelSize()
+	scale := 1.0
+	if larger := max(width, height); larger > maxSVGRasterSize {
+		scale = float64(maxSVGRasterSize) / float64(larger)
+		width = max(1, int(float64(width)*scale))
+		height = max(1, int(float64(height)*scale))
+	}
+
+	// map the viewBox to the image, centered and keeping the aspect ratio (xMidYMid meet)
+	m := svgMatrix{scale, 0, 0, scale, 0, 0}
+	if svg.viewBox != nil {
+		vb := svg.viewBox
+		k := math.Min(float64(width)/vb[2], float64(height)/vb[3])
+		m = svgMatrix{k, 0, 0, k,
+			(float64(width)-vb[2]*k)/2 - vb[0]*k,
+			(float64(height)-vb[3]*k)/2 - vb[1]*k}
+	}
+
+	r := &svgRenderer{
+		svg:    svg,
+		canvas: image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+	r.renderChildren(svg.root, m, defaultSVGStyle())
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, r.canvas); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// svgMatrix is an affine transform [a b c d e f], mapping (x, y) to (ax+cy+e, bx+dy+f)
+type svgMatrix [6]float64
+
+// mul returns the transform applying n first and then m
+func (m svgMatrix) mul(n svgMatrix) svgMatrix {
+	return svgMatrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+// apply transforms a point
+func (m svgMatrix) apply(p svgPoint) svgPoint {
+	return svgPoint{m[0]*p.x + m[2]*p.y + m[4], m[1]*p.x + m[3]*p.y + m[5]}
+}
+
+// scale returns the average scale factor of the transform, used for stroke widths
+func (m svgMatrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// parseSVGTransform parses a transform attribute
+func parseSVGTransform(value string) svgMatrix {
+	m := svgMatrix{1, 0, 0, 1, 0, 0}
+	for value != "" {
+		open := strings.Index(value, "(")
+		end := strings.Index(value, ")")
+		if open < 0 || end < open {
+			break
+		}
+		name := strings.TrimSpace(strings.Trim(strings.TrimSpace(value[:open]), ","))
+		args := parseSVGNumbers(value[open+1 : end])
+		value = value[end+1:]
+
+		var t svgMatrix
+		switch {
+		case name == "matrix" && len(args) == 6:
+			t = svgMatrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		case name == "translate" && len(args) >= 1:
+			t = svgMatrix{1, 0, 0, 1, args[0], 0}
+			if len(args) > 1 {
+				t[5] = args[1]
+			}
+		case name == "scale" && len(args) >= 1:
+			t = svgMatrix{args[0], 0, 0, args[0], 0, 0}
+			if len(args) > 1 {
+				t[3] = args[1]
+			}
+		case name == "rotate" && len(args) >= 1:
+			a := args[0] * math.Pi / 180
+			t = svgMatrix{math.Cos(a), math.Sin(a), -math.Sin(a), math.Cos(a), 0, 0}
+			if len(args) == 3 {
+				t = svgMatrix{1, 0, 0, 1, args[1], args[2]}.mul(t).mul(svgMatrix{1, 0, 0, 1, -args[1], -args[2]})
+			}
+		case name == "skewX" && len(args) == 1:
+			t = svgMatrix{1, 0, math.Tan(args[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(args) == 1:
+			t = svgMatrix{1, math.Tan(args[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			continue
+		}
+		m = m.mul(t)
+	}
+	return m
+}
+
+// svgStyle holds the presentation properties inherited by child elements
+type svgStyle struct {
+	fill, stroke                        string
+	strokeWidth                         float64
+	opacity, fillOpacity, strokeOpacity float64
+}
+
+// defaultSVGStyle returns the initial presentation properties
+func defaultSVGStyle() svgStyle {
+	return svgStyle{fill: "black", stroke: "none", strokeWidth: 1, opacity: 1, fillOpacity: 1, strokeOpacity: 1}
+}
+
+// svgRenderer draws SVG elements onto a canvas
+type svgRenderer struct {
+	svg    *svgDocument
+	canvas *image.RGBA
+	raster *vector.Rasterizer
+}
+
+// renderChildren renders the child elements of a node
+func (r *svgRenderer) renderChildren(node *svgNode, m svgMatrix, style svgStyle) {
+	for i := range node.Children {
+		r.render(&node.Children[i], m, style)
+	}
+}
+
+// render renders an element and its children
+func (r *svgRenderer) render(node *svgNode, m svgMatrix, style svgStyle) {
+	properties := nodeStyleProperties(node)
+	if properties["display"] == "none" || properties["visibility"] == "hidden" {
+		return
+	}
+	if transform := node.attr("transform"); transform != "" {
+		m = m.mul(parseSVGTransform(transform))
+	}
+	style = style.inherit(properties)
+
+	var paths [][]svgPoint
+	var closed bool
+	switch node.XMLName.Local {
+	case "svg", "g", "a", "switch":
+		r.renderChildren(node, m, style)
+		return
+	case "rect":
+		paths, closed = svgRectPath(node), true
+	case "circle":
+		radius := node.number("r")
+		paths, closed = svgEllipsePath(node.number("cx"), node.number("cy"), radius, radius), true
+	case "ellipse":
+		paths, closed = svgEllipsePath(node.number("cx"), node.number("cy"), node.number("rx"), node.number("ry")), true
+	case "line":
+		paths = [][]svgPoint{{{node.number("x1"), node.number("y1")}, {node.number("x2"), node.number("y2")}}}
+	case "polyline", "polygon":
+		numbers := parseSVGNumbers(node.attr("points"))
+		var points []svgPoint
+		for i := 0; i+1 < len(numbers); i += 2 {
+			points = append(points, svgPoint{numbers[i], numbers[i+1]})
+		}
+		paths, closed = [][]svgPoint{points}, node.XMLName.Local == "polygon"
+	case "path":
+		paths = parseSVGPath(node.attr("d"))
+	default:
+		// defs, text, images, gradients and other elements are not rendered
+		return
+	}
+
+	for _, path := range paths {
+		for i := range path {
+			path[i] = m.apply(path[i])
+		}
+	}
+	if fill, ok := r.paint(style.fill, style.opacity*style.fillOpacity); ok && node.XMLName.Local != "line" {
+		r.fillPaths(paths, fill)
+	}
+	if stroke, ok := r.paint(style.stroke, style.opacity*style.strokeOpacity); ok && style.strokeWidth > 0 {
+		r.strokePaths(paths, closed, style.strokeWidth*m.scale(), stroke)
+	}
+}
+
+// inherit returns the style of an element with the given presentation properties
+func (s svgStyle) inherit(properties map[string]string) svgStyle {
+	if v, ok := properties["fill"]; ok && v != "inherit" {
+		s.fill = v
+	}
+	if v, ok := properties["stroke"]; ok && v != "inherit" {
+		s.stroke = v
+	}
+	if v, ok := parseSVGLength(properties["stroke-width"]); ok {
+		s.strokeWidth = v
+	}
+	// group opacity is approximated by applying it to every element of the group
+	if v, ok := parseSVGOpacity(properties["opacity"]); ok {
+		s.opacity *= v
+	}
+	if v, ok := parseSVGOpacity(properties["fill-opacity"]); ok {
+		s.fillOpacity = v
+	}
+	if v, ok := parseSVGOpacity(properties["stroke-opacity"]); ok {
+		s.strokeOpacity = v
+	}
+	return s
+}
+
+// nodeStyleProperties collects the presentation attributes of an element, the style attribute taking precedence
+func nodeStyleProperties(node *svgNode) map[string]string {
+	properties := make(map[string]string)
+	for _, name := range []string{"fill", "stroke", "stroke-width", "opacity", "fill-opacity", "stroke-opacity", "display", "visibility"} {
+		if v := node.attr(name); v != "" {
+			properties[name] = v
+		}
+	}
+	for _, declaration := range strings.Split(node.attr("style"), ";") {
+		if parts := strings.SplitN(declaration, ":", 2); len(parts) == 2 {
+			properties[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return properties
+}
+
+// paint resolves a fill or stroke value to a color, reporting false for none
+func (r *svgRenderer) paint(value string, opacity float64) (color.NRGBA, bool) {
+	if strings.HasPrefix(value, "url(") {
+		end := strings.Index(value, ")")
+		if end < 0 {
+			return color.NRGBA{}, false
+		}
+		fallback := strings.TrimSpace(value[end+1:])
+		id := strings.TrimPrefix(strings.TrimSpace(value[4:end]), "#")
+		// gradients are drawn with the color of their first stop
+		if gradient, ok := r.svg.ids[id]; ok && len(gradient.Children) > 0 {
+			stop := &gradient.Children[0]
+			properties := nodeStyleProperties(stop)
+			for _, name := range []string{"stop-color", "stop-opacity"} {
+				if v := stop.attr(name); v != "" {
+					if _, ok := properties[name]; !ok {
+						properties[name] = v
+					}
+				}
+			}
+			if stopOpacity, ok := parseSVGOpacity(properties["stop-opacity"]); ok {
+				opacity *= stopOpacity
+			}
+			value = properties["stop-color"]
+			if value == "" {
+				value = "black"
+			}
+		} else {
+			value = fallback
+		}
+	}
+
+	c, ok := parseSVGColor(value)
+	if !ok {
+		return color.NRGBA{}, false
+	}
+	c.A = uint8(math.Round(float64(c.A) * math.Max(0, math.Min(1, opacity))))
+	return c, c.A > 0
+}
+
+// fillPaths fills the area enclosed by the paths, using the nonzero winding rule
+func (r *svgRenderer) fillPaths(paths [][]svgPoint, c color.NRGBA) {
+	raster := r.rasterizer()
+	drawn := false
+	for _, path := range paths {
+		if len(path) < 3 {
+			continue
+		}
+		raster.MoveTo(float32(path[0].x), float32(path[0].y))
+		for _, p := range path[1:] {
+			raster.LineTo(float32(p.x), float32(p.y))
+		}
+		raster.ClosePath()
+		drawn = true
+	}
+	if drawn {
+		raster.Draw(r.canvas, r.canvas.Bounds(), image.NewUniform(c), image.Point{})
+	}
+}
+
+// strokePaths draws the outlines of the paths, each segment as a quadrilateral of the stroke width
+func (r *svgRenderer) strokePaths(paths [][]svgPoint, closed bool, width float64, c color.NRGBA) {
+	raster := r.rasterizer()
+	half := width / 2
+	drawn := false
+	for _, path := range paths {
+		points := path
+		if closed && len(path) > 2 {
+			points = append(append([]svgPoint{}, path...), path[0])
+		}
+		for i := 0; i+1 < len(points); i++ {
+			p0, p1 := points[i], points[i+1]
+			dx, dy := p1.x-p0.x, p1.y-p0.y
+			length := math.Hypot(dx, dy)
+			if length == 0 {
+				continue
+			}
+			nx, ny := -dy/length*half, dx/length*half
+			raster.MoveTo(float32(p0.x+nx), float32(p0.y+ny))
+			raster.LineTo(float32(p1.x+nx), float32(p1.y+ny))
+			raster.LineTo(float32(p1.x-nx), float32(p1.y-ny))
+			raster.LineTo(float32(p0.x-nx), float32(p0.y-ny))
+			raster.ClosePath()
+			drawn = true
+		}
+	}
+	if drawn {
+		raster.Draw(r.canvas, r.canvas.Bounds(), image.NewUniform(c), image.Point{})
+	}
+}
+
+// rasterizer returns a cleared rasterizer of the canvas size
+func (r *svgRenderer) rasterizer() *vector.Rasterizer {
+	size := r.canvas.Bounds().Size()
+	if r.raster == nil {
+		r.raster = vector.NewRasterizer(size.X, size.Y)
+	} else {
+		r.raster.Reset(size.X, size.Y)
+	}
+	return r.raster
+}
+
+// svgPoint is a point in user or image coordinates
+type svgPoint struct {
+	x, y float64
+}
+
+// svgRectPath returns the outline of a rect element, with rounded corners if rx or ry is set
+func svgRectPath(node *svgNode) [][]svgPoint {
+	x, y, w, h := node.number("x"), node.number("y"), node.number("width"), node.number("height")
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	rx, okX := parseSVGLength(node.attr("rx"))
+	ry, okY := parseSVGLength(node.attr("ry"))
+	if !okX {
+		rx = ry
+	}
+	if !okY {
+		ry = rx
+	}
+	rx, ry = math.Min(math.Max(rx, 0), w/2), math.Min(math.Max(ry, 0), h/2)
+	if rx == 0 || ry == 0 {
+		return [][]svgPoint{{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}}
+	}
+
+	// quarter ellipses at the corners, starting at the top right corner
+	var points []svgPoint
+	corners := []svgPoint{{x + w - rx, y + ry}, {x + w - rx, y + h - ry}, {x + rx, y + h - ry}, {x + rx, y + ry}}
+	for i, center := range corners {
+		start := -math.Pi/2 + float64(i)*math.Pi/2
+		for s := 0; s <= curveSegments/4; s++ {
+			a := start + float64(s)*math.Pi/2/float64(curveSegments/4)
+			points = append(points, svgPoint{center.x + rx*math.Cos(a), center.y + ry*math.Sin(a)})
+		}
+	}
+	return [][]svgPoint{points}
+}
+
+// svgEllipsePath returns the outline of an ellipse
+func svgEllipsePath(cx, cy, rx, ry float64) [][]svgPoint {
+	if rx <= 0 || ry <= 0 {
+		return nil
+	}
+	points := make([]svgPoint, 0, 4*curveSegments)
+	for i := 0; i < 4*curveSegments; i++ {
+		a := 2 * math.Pi * float64(i) / float64(4*curveSegments)
+		points = append(points, svgPoint{cx + rx*math.Cos(a), cy + ry*math.Sin(a)})
+	}
+	return [][]svgPoint{points}
+}
+
+// parseSVGPath parses path data into flattened subpaths
+func parseSVGPath(d string) [][]svgPoint {
+	tokens := tokenizeSVGPath(d)
+	var paths [][]svgPoint
+	var current []svgPoint
+	var cur, start, lastCtrl svgPoint
+	var lastCmd byte
+
+	flush := func() {
+		if len(current) > 1 {
+			paths = append(paths, current)
+		}
+		current = nil
+	}
+	lineTo := func(p svgPoint) {
+		if len(current) == 0 {
+			current = append(current, cur)
+		}
+		current = append(current, p)
+		cur = p
+	}
+
+	i := 0
+	number := func() (float64, bool) {
+		if i >= len(tokens) || tokens[i].cmd != 0 {
+			return 0, false
+		}
+		i++
+		return tokens[i-1].value, true
+	}
+	point := func(relative bool) (svgPoint, bool) {
+		x, okX := number()
+		y, okY := number()
+		if !okX || !okY {
+			return svgPoint{}, false
+		}
+		if relative {
+			return svgPoint{cur.x + x, cur.y + y}, true
+		}
+		return svgPoint{x, y}, true
+	}
+
+	var cmd byte
+	for i < len(tokens) {
+		if tokens[i].cmd != 0 {
+			cmd = tokens[i].cmd
+			i++
+		} else if cmd == 0 {
+			break
+		}
+		relative := cmd >= 'a' && cmd <= 'z'
+		upper := cmd &^ 0x20
+
+		switch upper {
+		case 'M':
+			p, ok := point(relative)
+			if !ok {
+				return paths
+			}
+			flush()
+			cur, start = p, p
+			current = []svgPoint{p}
+			// following coordinate pairs are implicit line commands
+			if relative {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L':
+			p, ok := point(relative)
+			if !ok {
+				return paths
+			}
+			lineTo(p)
+		case 'H', 'V':
+			v, ok := number()
+			if !ok {
+				return paths
+			}
+			p := cur
+			switch {
+			case upper == 'H' && relative:
+				p.x += v
+			case upper == 'H':
+				p.x = v
+			case relative:
+				p.y += v
+			default:
+				p.y = v
+			}
+			lineTo(p)
+		case 'C', 'S':
+			var c1 svgPoint
+			if upper == 'C' {
+				var ok bool
+				if c1, ok = point(relative); !ok {
+					return paths
+				}
+			} else if lastCmd == 'C' || lastCmd == 'S' {
+				c1 = svgPoint{2*cur.x - lastCtrl.x, 2*cur.y - lastCtrl.y}
+			} else {
+				c1 = cur
+			}
+			c2, ok2 := point(relative)
+			p, ok := point(relative)
+			if !ok2 || !ok {
+				return paths
+			}
+			p0 := cur
+			for s := 1; s <= curveSegments; s++ {
+				t := float64(s) / curveSegments
+				u := 1 - t
+				lineTo(svgPoint{
+					u*u*u*p0.x + 3*u*u*t*c1.x + 3*u*t*t*c2.x + t*t*t*p.x,
+					u*u*u*p0.y + 3*u*u*t*c1.y + 3*u*t*t*c2.y + t*t*t*p.y,
+				})
+			}
+			lastCtrl = c2
+		case 'Q', 'T':
+			var c svgPoint
+			if upper == 'Q' {
+				var ok bool
+				if c, ok = point(relative); !ok {
+					return paths
+				}
+			} else if lastCmd == 'Q' || lastCmd == 'T' {
+				c = svgPoint{2*cur.x - lastCtrl.x, 2*cur.y - lastCtrl.y}
+			} else {
+				c = cur
+			}
+			p, ok := point(relative)
+			if !ok {
+				return paths
+			}
+			p0 := cur
+			for s := 1; s <= curveSegments; s++ {
+				t := float64(s) / curveSegments
+				u := 1 - t
+				lineTo(svgPoint{u*u*p0.x + 2*u*t*c.x + t*t*p.x, u*u*p0.y + 2*u*t*c.y + t*t*p.y})
+			}
+			lastCtrl = c
+		case 'A':
+			rx, ok1 := number()
+			ry, ok2 := number()
+			rotation, ok3 := number()
+			large, ok4 := number()
+			sweep, ok5 := number()
+			p, ok := point(relative)
+			if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || !ok {
+				return paths
+			}
+			for _, q := range flattenSVGArc(cur, p, rx, ry, rotation, large != 0, sweep != 0) {
+				lineTo(q)
+			}
+		case 'Z':
+			if len(current) > 0 && cur != start {
+				current = append(current, start)
+			}
+			cur = start
+			flush()
+			// numbers after a closepath are invalid
+			cmd = 0
+		default:
+			return paths
+		}
+		lastCmd = upper
+	}
+	flush()
+	return paths
+}
+
+// svgPathToken is a command letter or a number of path data
+type svgPathToken struct {
+	cmd   byte
+	value float64
+}
+
+// tokenizeSVGPath splits path data into commands and numbers
+func tokenizeSVGPath(d string) []svgPathToken {
+	var tokens []svgPathToken
+	for i := 0; i < len(d); {
+		c := d[i]
+		switch {
+		case c == ' ' || c == ',' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0:
+			tokens = append(tokens, svgPathToken{cmd: c})
+			i++
+		default:
+			end := scanSVGNumber(d, i)
+			if end == i {
+				return tokens
+			}
+			v, err := strconv.ParseFloat(d[i:end], 64)
+			if err != nil {
+				return tokens
+			}
+			tokens = append(tokens, svgPathToken{value: v})
+			i = end
+		}
+	}
+	return tokens
+}
+
+// scanSVGNumber returns the end of the number starting at i, allowing forms such as "1.5.5" and "-1-2"
+func scanSVGNumber(s string, i int) int {
+	j := i
+	if j < len(s) && (s[j] == '+' || s[j] == '-') {
+		j++
+	}
+	digits, dot := false, false
+	for j < len(s) {
+		c := s[j]
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+		j++
+	}
+	if !digits {
+		return i
+	}
+	if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+		k := j + 1
+		if k < len(s) && (s[k] == '+' || s[k] == '-') {
+			k++
+		}
+		if k < len(s) && s[k] >= '0' && s[k] <= '9' {
+			for k < len(s) && s[k] >= '0' && s[k] <= '9' {
+				k++
+			}
+			j = k
+		}
+	}
+	return j
+}
+
+// flattenSVGArc converts an elliptical arc to points, following the SVG implementation notes
+func flattenSVGArc(p0, p1 svgPoint, rx, ry, rotation float64, large, sweep bool) []svgPoint {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || (p0 == p1) {
+		return []svgPoint{p1}
+	}
+
+	phi := rotation * math.Pi / 180
+	cosPhi, sinPhi := math.Cos(phi), math.Sin(phi)
+	dx, dy := (p0.x-p1.x)/2, (p0.y-p1.y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+
+	// scale up radii that are too small to reach the end point
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		k := math.Sqrt(lambda)
+		rx, ry = rx*k, ry*k
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := 0.0
+	if den != 0 && num > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		coef = -coef
+	}
+	cx1, cy1 := coef*rx*y1/ry, -coef*ry*x1/rx
+	cx := cosPhi*cx1 - sinPhi*cy1 + (p0.x+p1.x)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (p0.y+p1.y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	segments := max(1, int(math.Ceil(math.Abs(delta)/(math.Pi/2)*curveSegments/4)))
+	points := make([]svgPoint, 0, segments)
+	for s := 1; s <= segments; s++ {
+		a := theta + delta*float64(s)/float64(segments)
+		x, y := rx*math.Cos(a), ry*math.Sin(a)
+		points = append(points, svgPoint{cosPhi*x - sinPhi*y + cx, sinPhi*x + cosPhi*y + cy})
+	}
+	points[len(points)-1] = p1
+	return points
+}
+
+// parseSVGNumbers parses a list of numbers separated by spaces or commas
+func parseSVGNumbers(s string) []float64 {
+	var numbers []float64
+	for _, token := range tokenizeSVGPath(s) {
+		if token.cmd != 0 {
+			break
+		}
+		numbers = append(numbers, token.value)
+	}
+	return numbers
+}
+
+// parseSVGLength parses a length in user units (pixels), converting absolute units
+func parseSVGLength(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	units := map[string]float64{"px": 1, "pt": 96.0 / 72, "pc": 16, "mm": 96 / 25.4, "cm": 96 / 2.54, "in": 96, "em": 16, "ex": 8}
+	factor := 1.0
+	if len(s) > 2 {
+		if f, ok := units[s[len(s)-2:]]; ok {
+			factor = f
+			s = s[:len(s)-2]
+		}
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, false
+	}
+	return v * factor, true
+}
+
+// parseSVGOpacity parses an opacity value, a number or a percentage, clamped to 0..1
+func parseSVGOpacity(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	divisor := 1.0
+	if strings.HasSuffix(s, "%") {
+		s, divisor = strings.TrimSuffix(s, "%"), 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return math.Max(0, math.Min(1, v/divisor)), true
+}
+
+// parseSVGColor parses a color keyword, #rgb, #rrggbb or rgb() value, reporting false for none
+func parseSVGColor(s string) (color.NRGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "" || s == "none" || s == "transparent":
+		return color.NRGBA{}, false
+	case s == "currentcolor":
+		return color.NRGBA{A: 255}, true
+	case strings.HasPrefix(s, "#"):
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 6 || err != nil {
+			return color.NRGBA{}, false
+		}
+		return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, true
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			return color.NRGBA{}, false
+		}
+		var channels [3]uint8
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			scale := 1.0
+			if strings.HasSuffix(part, "%") {
+				part, scale = strings.TrimSuffix(part, "%"), 2.55
+			}
+			v, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return color.NRGBA{}, false
+			}
+			channels[i] = uint8(math.Round(math.Max(0, math.Min(255, v*scale))))
+		}
+		return color.NRGBA{channels[0], channels[1], channels[2], 255}, true
+	}
+	if c, ok := colornames.Map[s]; ok {
+		return color.NRGBA{c.R, c.G, c.B, c.A}, true
+	}
+	return color.NRGBA{}, false
+}
@@ -0,0 +1,146 @@
+package document
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testSVG = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="120" viewBox="0 0 60 30">
+  <defs>
+    <linearGradient id="g"><stop offset="0" stop-color="#0000ff"/><stop offset="1" stop-color="white"/></linearGradient>
+  </defs>
+  <rect width="30" height="30" fill="red"/>
+  <g transform="translate(30 0)">
+    <path d="M0 0 H30 V30 Z" style="fill:url(#g)"/>
+  </g>
+  <circle cx="15" cy="15" r="5" fill="none" stroke="rgb(0,128,0)" stroke-width="2"/>
+</svg>`
+
+// TestSVGImageSize tests the size of SVG images from width, height and viewBox
+func TestSVGImageSize(t *testing.T) {
+	tests := []struct {
+		svg           string
+		width, height int
+	}{
+		{testSVG, 120, 60},
+		{`<svg xmlns="http://www.w3.org/2000/svg" width="2in" height="72pt"/>`, 192, 96},
+		{`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0,0,64,48"/>`, 64, 48},
+		{`<svg xmlns="http://www.w3.org/2000/svg" width="100%"/>`, 300, 150},
+	}
+	for _, tt := range tests {
+		format, err := detectImageFormat([]byte(tt.svg))
+		if err != nil || format != ImageFormatSVG {
+			t.Fatalf("expected SVG format, got %q: %v", format, err)
+		}
+		width, height, err := getImageDimensions([]byte(tt.svg), format)
+		if err != nil {
+			t.Fatalf("failed to get SVG size: %v", err)
+		}
+		if width != tt.width || height != tt.height {
+			t.Errorf("expected %dx%d, got %dx%d for %s", tt.width, tt.height, width, height, tt.svg)
+		}
+	}
+
+	if _, err := detectImageFormat([]byte("<html><body>no image</body></html>")); err == nil {
+		t.Error("HTML should not be detected as SVG")
+	}
+}
+
+// TestRasterizeSVG tests the PNG fallback rendering of shapes, transforms and colors
+func TestRasterizeSVG(t *testing.T) {
+	data, err := rasterizeSVG([]byte(testSVG))
+	if err != nil {
+		t.Fatalf("failed to rasterize SVG: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("fallback is not a PNG image: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 120 || size.Y != 60 {
+		t.Fatalf("expected 120x60 fallback, got %v", size)
+	}
+
+	at := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	}
+	if c := at(5, 5); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("rect should be red, got %v", c)
+	}
+	if c := at(39, 30); c.G < 100 || c.R > 50 {
+		t.Errorf("circle stroke should be green, got %v", c)
+	}
+	// the path is a triangle above the diagonal of the translated square, filled with the first stop color
+	if c := at(110, 10); c != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("path should be filled with the gradient's first color, got %v", c)
+	}
+	if c := at(70, 50); c.A != 0 {
+		t.Errorf("area outside the path should be transparent, got %v", c)
+	}
+
+	if paths := parseSVGPath("m10-5l5.5.5a5 5 0 0 1 10 0zM0,0"); len(paths) != 1 || len(paths[0]) < 4 {
+		t.Errorf("unexpected path parsing result: %v", paths)
+	}
+	if _, err := rasterizeSVG([]byte("<svg><rect")); err == nil {
+		t.Error("expected error for invalid SVG")
+	}
+}
+
+// TestAddSVGImage tests that SVG images are stored with a PNG fallback and survive a round trip
+func TestAddSVGImage(t *testing.T) {
+	doc := New()
+	info, err := doc.AddImageFromData([]byte(testSVG), "logo.svg", ImageFormatSVG, 120, 60, nil)
+	if err != nil {
+		t.Fatalf("failed to add SVG image: %v", err)
+	}
+	if info.SVGRelationID == "" || info.SVGRelationID == info.RelationID {
+		t.Fatal("SVG image should reference the SVG and the fallback separately")
+	}
+	if _, ok := doc.parts["word/media/image0.svg"]; !ok {
+		t.Error("SVG part missing")
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(doc.parts["word/media/image0.png"])); err != nil {
+		t.Errorf("PNG fallback missing: %v", err)
+	}
+
+	filename := "test_svg_image.docx"
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	defer os.Remove(filename)
+
+	documentXML := string(doc.parts["word/document.xml"])
+	for _, expected := range []string{
+		`<a:blip r:embed="` + info.RelationID + `">`,
+		`<a:ext uri="` + svgBlipExtURI + `">`,
+		`<asvg:svgBlip xmlns:asvg="http://schemas.microsoft.com/office/drawing/2016/SVG/main" r:embed="` + info.SVGRelationID + `">`,
+	} {
+		if !strings.Contains(documentXML, expected) {
+			t.Errorf("document.xml should contain %s", expected)
+		}
+	}
+	if !strings.Contains(string(doc.parts["[Content_Types].xml"]), `Extension="svg" ContentType="image/svg+xml"`) {
+		t.Error("SVG content type missing")
+	}
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	para, ok := opened.Body.Elements[0].(*Paragraph)
+	if !ok || para.Runs[0].Drawing == nil {
+		t.Fatal("image paragraph missing after reopening")
+	}
+	blip := para.Runs[0].Drawing.Inline.Graphic.GraphicData.Pic.BlipFill.Blip
+	if blip.Embed != info.RelationID || blip.SVGEmbed() != info.SVGRelationID {
+		t.Errorf("blip references lost: %s / %s", blip.Embed, blip.SVGEmbed())
+	}
+
+	if _, err := doc.AddImageFromData([]byte("<svg"), "bad.svg", ImageFormatSVG, 1, 1, nil); err == nil {
+		t.Error("expected error for an invalid SVG image")
+	}
+}
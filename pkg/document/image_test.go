@@ -2,11 +2,15 @@ package document
 
 import (
 	"bytes"
+	"encoding/base64"
 	"image"
 	"image/color"
 	"image/png"
 	"os"
 	"testing"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
 )
 
 // createTestImage 创建一个测试用的PNG图片
@@ -534,3 +538,63 @@ func TestImageParagraphAlignment(t *testing.T) {
 		t.Error("图片对齐方式修改失败")
 	}
 }
+
+// TestAddImageExtraRasterFormats tests BMP and TIFF images and the conversion of WebP to PNG
+func TestAddImageExtraRasterFormats(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	var bmpData, tiffData bytes.Buffer
+	if err := bmp.Encode(&bmpData, img); err != nil {
+		t.Fatalf("failed to encode BMP: %v", err)
+	}
+	if err := tiff.Encode(&tiffData, img, nil); err != nil {
+		t.Fatalf("failed to encode TIFF: %v", err)
+	}
+	// 1x1 lossless WebP image
+	webpData, _ := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+
+	doc := New()
+	tests := []struct {
+		data      []byte
+		fileName  string
+		format    ImageFormat
+		width     int
+		part      string
+		extension string
+	}{
+		{bmpData.Bytes(), "scan.bmp", ImageFormatBMP, 40, "word/media/image0.bmp", "bmp"},
+		{tiffData.Bytes(), "scan.tif", ImageFormatTIFF, 40, "word/media/image1.tiff", "tiff"},
+		{webpData, "photo.webp", ImageFormatPNG, 1, "word/media/image2.png", "png"},
+	}
+	for _, tt := range tests {
+		format, err := detectImageFormat(tt.data)
+		if err != nil {
+			t.Fatalf("%s: failed to detect format: %v", tt.fileName, err)
+		}
+		width, height, err := getImageDimensions(tt.data, format)
+		if err != nil || width != tt.width {
+			t.Fatalf("%s: unexpected dimensions %dx%d: %v", tt.fileName, width, height, err)
+		}
+
+		info, err := doc.AddImageFromData(tt.data, tt.fileName, format, width, height, nil)
+		if err != nil {
+			t.Fatalf("%s: failed to add image: %v", tt.fileName, err)
+		}
+		if info.Format != tt.format {
+			t.Errorf("%s: expected format %s, got %s", tt.fileName, tt.format, info.Format)
+		}
+		if _, ok := doc.parts[tt.part]; !ok {
+			t.Errorf("%s: expected media part %s", tt.fileName, tt.part)
+		}
+		found := false
+		for _, def := range doc.contentTypes.Defaults {
+			found = found || def.Extension == tt.extension
+		}
+		if !found {
+			t.Errorf("%s: content type for %s not registered", tt.fileName, tt.extension)
+		}
+	}
+
+	if _, err := png.Decode(bytes.NewReader(doc.parts["word/media/image2.png"])); err != nil {
+		t.Errorf("WebP image should be stored as PNG: %v", err)
+	}
+}
@@ -0,0 +1,499 @@
+// Package document provides Word document index generation functionality
+package document
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// IndexPageNumberAlignment placement of page numbers in index entries
+type IndexPageNumberAlignment string
+
+const (
+	// IndexPageNumbersInline - page numbers follow the entry after a comma
+	IndexPageNumbersInline IndexPageNumberAlignment = "inline"
+	// IndexPageNumbersRight - page numbers are right aligned with a dot leader
+	IndexPageNumbersRight IndexPageNumberAlignment = "right"
+)
+
+// IndexConfig index configuration
+type IndexConfig struct {
+	// Title is the heading above the index, no heading when empty
+	Title string
+	// Columns is the number of columns of the index, 1 to 4
+	Columns int
+	// RunIn places subentries on the line of their main entry
+	RunIn               bool
+	PageNumberAlignment IndexPageNumberAlignment
+}
+
+// indexColumnSpace is the space between index columns in twips
+const indexColumnSpace = 720
+
+// indexIndent is the indent of each index entry level in twips
+const indexIndent = 220
+
+// indexTerm is an index entry with its pages, cross references and subentries
+type indexTerm struct {
+	text     string
+	pages    []int
+	seeAlso  []string
+	subterms map[string]*indexTerm
+}
+
+// DefaultIndexConfig returns the default index configuration
+func DefaultIndexConfig() *IndexConfig {
+	return &IndexConfig{
+		Title:               "Index",
+		Columns:             2,
+		PageNumberAlignment: IndexPageNumbersInline,
+	}
+}
+
+// MarkIndexEntry adds an XE field marking an index entry at the end of the paragraph
+// subterm and seeAlso may be empty, seeAlso shows "See also" followed by it instead of the page number
+func (p *Paragraph) MarkIndexEntry(term, subterm, seeAlso string) {
+	if term == "" {
+		return
+	}
+	text := escapeIndexText(term)
+	if subterm != "" {
+		text += ":" + escapeIndexText(subterm)
+	}
+	instruction := ` XE "` + text + `" `
+	if seeAlso != "" {
+		instruction += `\t "See also ` + strings.ReplaceAll(seeAlso, `"`, `\"`) + `" `
+	}
+	p.Runs = append(p.Runs,
+		Run{FieldChar: &FieldChar{FieldCharType: "begin"}},
+		Run{InstrText: &InstrText{Space: "preserve", Content: instruction}},
+		Run{FieldChar: &FieldChar{FieldCharType: "end"}},
+	)
+}
+
+// GenerateIndex appends an INDEX field with entries computed from the XE fields of the document
+// Page numbers are estimated from the text length, Word recomputes them when the field is updated
+func (d *Document) GenerateIndex(config *IndexConfig) error {
+	if config == nil {
+		config = DefaultIndexConfig()
+	}
+	columns := config.Columns
+	if columns == 0 {
+		columns = 1
+	}
+	if columns < 1 || columns > 4 {
+		return NewValidationError("columns", strconv.Itoa(config.Columns), "must be between 1 and 4")
+	}
+	alignment := config.PageNumberAlignment
+	if alignment == "" {
+		alignment = IndexPageNumbersInline
+	}
+	if alignment != IndexPageNumbersInline && alignment != IndexPageNumbersRight {
+		return NewValidationError("page_number_alignment", string(alignment), "unsupported alignment")
+	}
+
+	terms := d.collectIndexTerms()
+	if len(terms) == 0 {
+		return NewValidationError("entries", "", "no index entries marked")
+	}
+
+	sectPr := d.getSectionProperties()
+	if columns > 1 {
+		// end the previous section at the title so that only the index is set in columns
+		var breakParagraph *Paragraph
+		if config.Title != "" {
+			breakParagraph = d.AddHeadingParagraph(config.Title, 1)
+		} else {
+			breakParagraph = &Paragraph{}
+			d.Body.Elements = append(d.Body.Elements, breakParagraph)
+		}
+		if breakParagraph.Properties == nil {
+			breakParagraph.Properties = &ParagraphProperties{}
+		}
+		breakParagraph.Properties.SectionProperties = sectPr.clone()
+	} else if config.Title != "" {
+		d.AddHeadingParagraph(config.Title, 1)
+	}
+
+	tabPos := d.indexColumnWidth(columns)
+	instruction := fmt.Sprintf(` INDEX \h "A" \c "%d" `, columns)
+	if config.RunIn {
+		instruction += `\r `
+	}
+	if alignment == IndexPageNumbersRight {
+		instruction += "\\e \"\t\" "
+	}
+
+	var paragraphs []*Paragraph
+	letter := ""
+	for _, term := range sortedIndexTerms(terms) {
+		if l := indexLetter(term.text); l != letter {
+			letter = l
+			heading := &Paragraph{
+				Properties: &ParagraphProperties{
+					KeepNext: &KeepNext{},
+					Spacing:  &Spacing{Before: "240", After: "60"},
+				},
+			}
+			heading.AddFormattedText(letter, &TextFormat{Bold: true})
+			paragraphs = append(paragraphs, heading)
+		}
+
+		if config.RunIn && len(term.subterms) > 0 {
+			p := newIndexParagraph(1, alignment, tabPos)
+			text := term.text + indexLocators(term, ", ")
+			var subentries []string
+			for _, sub := range sortedIndexTerms(term.subterms) {
+				subentries = append(subentries, sub.text+indexLocators(sub, ", "))
+			}
+			p.Runs = append(p.Runs, Run{Text: Text{Content: text + ": " + strings.Join(subentries, "; "), Space: "preserve"}})
+			paragraphs = append(paragraphs, p)
+			continue
+		}
+
+		paragraphs = append(paragraphs, newIndexEntryParagraph(term, 1, alignment, tabPos))
+		for _, sub := range sortedIndexTerms(term.subterms) {
+			paragraphs = append(paragraphs, newIndexEntryParagraph(sub, 2, alignment, tabPos))
+		}
+	}
+
+	first, last := paragraphs[0], paragraphs[len(paragraphs)-1]
+	first.Runs = append([]Run{
+		{FieldChar: &FieldChar{FieldCharType: "begin"}},
+		{InstrText: &InstrText{Space: "preserve", Content: instruction}},
+		{FieldChar: &FieldChar{FieldCharType: "separate"}},
+	}, first.Runs...)
+	last.Runs = append(last.Runs, Run{FieldChar: &FieldChar{FieldCharType: "end"}})
+
+	if columns > 1 {
+		// the index is the last section, it starts on the same page and is set in columns
+		sectPr.Type = &SectionType{Val: "continuous"}
+		sectPr.Columns = &Columns{Num: strconv.Itoa(columns), Space: strconv.Itoa(indexColumnSpace)}
+	}
+	for _, p := range paragraphs {
+		d.Body.Elements = append(d.Body.Elements, p)
+	}
+
+	Infof("generated index with %d entries", len(terms))
+	return nil
+}
+
+// collectIndexTerms collects the entries of the XE fields of the document by main entry text
+func (d *Document) collectIndexTerms() map[string]*indexTerm {
+	pages := d.estimatePageNumbers()
+	terms := make(map[string]*indexTerm)
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		for _, instruction := range paragraphFieldInstructions(p) {
+			args := splitFieldInstruction(instruction)
+			if len(args) < 2 || !strings.EqualFold(args[0], "XE") {
+				continue
+			}
+			seeAlso := ""
+			for k := 2; k+1 < len(args); k++ {
+				if strings.EqualFold(args[k], `\t`) {
+					seeAlso = args[k+1]
+				}
+			}
+
+			levels := splitIndexText(args[1])
+			term := addIndexTerm(terms, levels[0])
+			if len(levels) > 1 {
+				if term.subterms == nil {
+					term.subterms = make(map[string]*indexTerm)
+				}
+				term = addIndexTerm(term.subterms, levels[1])
+			}
+			if seeAlso != "" {
+				term.seeAlso = append(term.seeAlso, seeAlso)
+			} else {
+				term.pages = append(term.pages, pages[p])
+			}
+		}
+		return true
+	})
+	return terms
+}
+
+// addIndexTerm returns the term with a text, adding it when missing
+func addIndexTerm(terms map[string]*indexTerm, text string) *indexTerm {
+	term, ok := terms[text]
+	if !ok {
+		term = &indexTerm{text: text}
+		terms[text] = term
+	}
+	return term
+}
+
+// sortedIndexTerms returns terms sorted alphabetically, ignoring case, symbols and digits first
+func sortedIndexTerms(terms map[string]*indexTerm) []*indexTerm {
+	sorted := make([]*indexTerm, 0, len(terms))
+	for _, term := range terms {
+		sorted = append(sorted, term)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].text, sorted[j].text
+		if la, lb := indexLetter(a) == "#", indexLetter(b) == "#"; la != lb {
+			return la
+		}
+		if strings.ToLower(a) != strings.ToLower(b) {
+			return strings.ToLower(a) < strings.ToLower(b)
+		}
+		return a < b
+	})
+	return sorted
+}
+
+// indexLetter returns the letter heading of a term, "#" for terms not starting with a letter
+func indexLetter(text string) string {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return string(unicode.ToUpper(r))
+		}
+		break
+	}
+	return "#"
+}
+
+// indexLocators returns the page numbers and cross references of a term, after sep
+func indexLocators(term *indexTerm, sep string) string {
+	result := ""
+	if len(term.pages) > 0 {
+		result = sep + indexPages(term.pages)
+	}
+	for _, ref := range term.seeAlso {
+		result += ". " + ref
+	}
+	return result
+}
+
+// indexPages returns the sorted distinct page numbers separated by commas
+func indexPages(pages []int) string {
+	sorted := append([]int(nil), pages...)
+	sort.Ints(sorted)
+	var numbers []string
+	for i, page := range sorted {
+		if i == 0 || page != sorted[i-1] {
+			numbers = append(numbers, strconv.Itoa(page))
+		}
+	}
+	return strings.Join(numbers, ", ")
+}
+
+// newIndexParagraph creates an index paragraph for an entry level
+func newIndexParagraph(level int, alignment IndexPageNumberAlignment, tabPos int) *Paragraph {
+	props := &ParagraphProperties{
+		Spacing: &Spacing{After: "0"},
+		Indentation: &Indentation{
+			Left:    strconv.Itoa(level * indexIndent),
+			Hanging: strconv.Itoa(indexIndent),
+		},
+	}
+	if alignment == IndexPageNumbersRight {
+		props.Tabs = &Tabs{Tabs: []TabDef{{Val: "right", Leader: "dot", Pos: strconv.Itoa(tabPos)}}}
+	}
+	return &Paragraph{Properties: props}
+}
+
+// newIndexEntryParagraph creates the paragraph of an entry with its page numbers
+func newIndexEntryParagraph(term *indexTerm, level int, alignment IndexPageNumberAlignment, tabPos int) *Paragraph {
+	p := newIndexParagraph(level, alignment, tabPos)
+	p.Runs = append(p.Runs, Run{Text: Text{Content: term.text, Space: "preserve"}})
+
+	if len(term.pages) > 0 {
+		sep := ", "
+		if alignment == IndexPageNumbersRight {
+			sep = "\t"
+		}
+		p.Runs = append(p.Runs, Run{Text: Text{Content: sep + indexPages(term.pages), Space: "preserve"}})
+	}
+	for _, ref := range term.seeAlso {
+		p.Runs = append(p.Runs, Run{Text: Text{Content: ". ", Space: "preserve"}})
+		p.AddFormattedText(ref, &TextFormat{Italic: true})
+	}
+	return p
+}
+
+// indexColumnWidth returns the width of an index column in twips
+func (d *Document) indexColumnWidth(columns int) int {
+	settings := d.GetPageSettings()
+	width, _ := getPageDimensions(settings)
+	textWidth := mmToTwips(width - settings.MarginLeft - settings.MarginRight)
+	return int((textWidth - float64((columns-1)*indexColumnSpace)) / float64(columns))
+}
+
+// escapeIndexText escapes the colons and quotes of index entry text
+func escapeIndexText(text string) string {
+	text = strings.ReplaceAll(text, ":", `\:`)
+	return strings.ReplaceAll(text, `"`, `\"`)
+}
+
+// splitIndexText splits XE entry text into its levels at unescaped colons
+func splitIndexText(text string) []string {
+	var levels []string
+	var current strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == ':':
+			current.WriteRune(':')
+			i++
+		case runes[i] == ':':
+			levels = append(levels, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(runes[i])
+		}
+	}
+	return append(levels, strings.TrimSpace(current.String()))
+}
+
+// estimatePageNumbers estimates the page of each paragraph from the text length and page breaks
+func (d *Document) estimatePageNumbers() map[*Paragraph]int {
+	settings := d.GetPageSettings()
+	width, height := getPageDimensions(settings)
+	layout := &pageEstimate{
+		textWidth:  mmToTwips(width - settings.MarginLeft - settings.MarginRight),
+		textHeight: mmToTwips(height - settings.MarginTop - settings.MarginBottom),
+		page:       1,
+		pages:      make(map[*Paragraph]int),
+	}
+	layout.addElements(d.Body.Elements)
+	return layout.pages
+}
+
+// pageEstimate tracks the position on the current page while estimating page numbers
+type pageEstimate struct {
+	textWidth  float64
+	textHeight float64
+	page       int
+	y          float64
+	pages      map[*Paragraph]int
+}
+
+// addElements lays out body elements
+func (e *pageEstimate) addElements(elements []interface{}) {
+	for _, element := range elements {
+		switch el := element.(type) {
+		case *Paragraph:
+			e.addParagraph(el)
+		case *Table:
+			e.addTable(el)
+		case *SDT:
+			if el.Content != nil {
+				e.addElements(el.Content.Elements)
+			}
+		}
+	}
+}
+
+// addParagraph lays out a paragraph, breaking pages at explicit page breaks
+func (e *pageEstimate) addParagraph(p *Paragraph) {
+	if p.Properties != nil && p.Properties.PageBreakBefore != nil && e.y > 0 {
+		e.newPage()
+	}
+	lineHeight := paragraphLineHeight(p)
+	if e.y+lineHeight > e.textHeight {
+		e.newPage()
+	}
+	e.pages[p] = e.page
+
+	lineWidth := 0
+	for _, run := range p.Runs {
+		lineWidth += measureRunText(run.Text.Content, run.Properties)
+		if run.Break != nil && run.Break.Type == "page" {
+			e.advance(estimateLines(lineWidth, e.textWidth) * lineHeight)
+			e.newPage()
+			lineWidth = 0
+		}
+	}
+	before, after := paragraphSpacing(p)
+	e.advance(before + estimateLines(lineWidth, e.textWidth)*lineHeight + after)
+}
+
+// addTable lays out a table row by row, cell paragraphs are on the page of their row
+func (e *pageEstimate) addTable(t *Table) {
+	for r := range t.Rows {
+		cells := t.Rows[r].Cells
+		if len(cells) == 0 {
+			continue
+		}
+		cellWidth := e.textWidth / float64(len(cells))
+		rowHeight := 0.0
+		for c := range cells {
+			cellHeight := 0.0
+			for k := range cells[c].Paragraphs {
+				p := &cells[c].Paragraphs[k]
+				lineWidth := 0
+				for _, run := range p.Runs {
+					lineWidth += measureRunText(run.Text.Content, run.Properties)
+				}
+				cellHeight += estimateLines(lineWidth, cellWidth) * paragraphLineHeight(p)
+			}
+			rowHeight = math.Max(rowHeight, cellHeight)
+		}
+		if e.y+rowHeight > e.textHeight && e.y > 0 {
+			e.newPage()
+		}
+		row := &Table{Rows: t.Rows[r : r+1]}
+		walkTableParagraphs(row, func(p *Paragraph) bool {
+			e.pages[p] = e.page
+			return true
+		})
+		e.advance(rowHeight)
+	}
+}
+
+// advance moves down the page, continuing on the next pages when the height does not fit
+func (e *pageEstimate) advance(height float64) {
+	e.y += height
+	for e.y > e.textHeight && e.textHeight > 0 {
+		e.page++
+		e.y -= e.textHeight
+	}
+}
+
+// newPage continues on the next page
+func (e *pageEstimate) newPage() {
+	e.page++
+	e.y = 0
+}
+
+// estimateLines returns the number of lines text of a width takes in a line width
+func estimateLines(width int, lineWidth float64) float64 {
+	if width <= 0 || lineWidth <= 0 {
+		return 1
+	}
+	return math.Ceil(float64(width) / lineWidth)
+}
+
+// paragraphLineHeight returns the line height of a paragraph in twips from its largest font size
+func paragraphLineHeight(p *Paragraph) float64 {
+	size := defaultMeasureFontSize
+	for _, run := range p.Runs {
+		if run.Properties != nil && run.Properties.FontSize != nil {
+			if v := int(parseFloat(run.Properties.FontSize.Val)); v > size {
+				size = v
+			}
+		}
+	}
+	// half-points * 10 twips, with single line spacing about 1.2 times the font size
+	return float64(size) * 10 * 1.2
+}
+
+// paragraphSpacing returns the spacing before and after a paragraph in twips
+func paragraphSpacing(p *Paragraph) (float64, float64) {
+	before, after := 0.0, 160.0
+	if p.Properties != nil && p.Properties.Spacing != nil {
+		if p.Properties.Spacing.Before != "" {
+			before = parseFloat(p.Properties.Spacing.Before)
+		}
+		if p.Properties.Spacing.After != "" {
+			after = parseFloat(p.Properties.Spacing.After)
+		}
+	}
+	return before, after
+}
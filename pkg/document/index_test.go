@@ -0,0 +1,149 @@
+package document
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// indexParagraphTexts returns the text of the paragraphs following the first n paragraphs
+func indexParagraphTexts(doc *Document, n int) []string {
+	var texts []string
+	for _, p := range doc.Body.GetParagraphs()[n:] {
+		var sb strings.Builder
+		for _, run := range p.Runs {
+			sb.WriteString(run.Text.Content)
+		}
+		texts = append(texts, sb.String())
+	}
+	return texts
+}
+
+// TestMarkIndexEntry tests the XE field instruction and reading it back
+func TestMarkIndexEntry(t *testing.T) {
+	p := &Paragraph{}
+	p.MarkIndexEntry("Ratio: gear", "worm", "")
+	p.MarkIndexEntry("Gearbox", "", `Drive "train"`)
+	p.MarkIndexEntry("", "ignored", "")
+
+	instructions := paragraphFieldInstructions(p)
+	if len(instructions) != 2 {
+		t.Fatalf("expected 2 XE fields, got %d", len(instructions))
+	}
+	if instructions[0] != ` XE "Ratio\: gear:worm" ` {
+		t.Errorf("unexpected instruction %q", instructions[0])
+	}
+	args := splitFieldInstruction(instructions[1])
+	if len(args) != 4 || args[1] != "Gearbox" || args[3] != `See also Drive "train"` {
+		t.Errorf("unexpected arguments %q", args)
+	}
+	if levels := splitIndexText(`Ratio\: gear:worm`); len(levels) != 2 || levels[0] != "Ratio: gear" || levels[1] != "worm" {
+		t.Errorf("unexpected levels %q", levels)
+	}
+}
+
+// TestGenerateIndex tests grouped entries, page numbers and the index section
+func TestGenerateIndex(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Gears transmit torque.").MarkIndexEntry("gear", "", "")
+	p := doc.AddParagraph("A worm gear reduces speed.")
+	p.MarkIndexEntry("gear", "worm", "")
+	p.MarkIndexEntry("Axle", "", "")
+	p.AddPageBreak()
+	doc.AddParagraph("Bearings carry the axle.").MarkIndexEntry("axle", "", "")
+	doc.AddParagraph("See the drive.").MarkIndexEntry("Bearing", "", "Axle")
+	doc.AddParagraph("Version 2").MarkIndexEntry("2.0 release", "", "")
+	if err := doc.SetPageMargins(25, 20, 25, 20); err != nil {
+		t.Fatalf("failed to set margins: %v", err)
+	}
+	before := len(doc.Body.GetParagraphs())
+
+	if err := doc.GenerateIndex(nil); err != nil {
+		t.Fatalf("failed to generate index: %v", err)
+	}
+	expected := []string{
+		"Index",
+		"#", "2.0 release, 2",
+		"A", "Axle, 1", "axle, 2",
+		"B", "Bearing. See also Axle",
+		"G", "gear, 1", "worm, 1",
+	}
+	texts := indexParagraphTexts(doc, before)
+	if strings.Join(texts, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected index\n got: %q\nwant: %q", texts, expected)
+	}
+
+	paragraphs := doc.Body.GetParagraphs()
+	title := paragraphs[before]
+	if title.Properties.SectionProperties == nil || title.Properties.SectionProperties.Columns != nil {
+		t.Error("the title should end the previous section")
+	}
+	// the index is the last section, without an empty section after it
+	if sectPr := doc.getSectionProperties(); sectPr.Columns.Num != "2" || sectPr.Type.Val != "continuous" {
+		t.Error("the index should be a continuous two column section")
+	}
+	if previous := title.Properties.SectionProperties; previous.PageMargins == nil || previous.PageMargins == doc.getSectionProperties().PageMargins {
+		t.Error("the section before the index should not share its settings with the index")
+	}
+	if instruction := paragraphs[before+1].Runs[1].InstrText; instruction == nil || instruction.Content != ` INDEX \h "A" \c "2" ` {
+		t.Errorf("unexpected INDEX field %+v", instruction)
+	}
+
+	filename := "test_index.docx"
+	defer os.Remove(filename)
+	content := savedDocumentXML(t, doc, filename)
+	if strings.Count(content, "<w:sectPr") != 2 || !strings.Contains(content, `<w:type w:val="continuous">`) {
+		t.Error("document.xml should contain the section breaks around the index")
+	}
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	if sectPr := opened.Body.GetParagraphs()[before].Properties.SectionProperties; sectPr == nil || sectPr.Columns != nil {
+		t.Error("paragraph section properties should be read back")
+	}
+	if sectPr := opened.getSectionProperties(); sectPr.Type == nil || sectPr.Columns.Num != "2" {
+		t.Error("the index section should be read back")
+	}
+}
+
+// TestGenerateIndexRunIn tests run-in subentries and right aligned page numbers
+func TestGenerateIndexRunIn(t *testing.T) {
+	doc := New()
+	p := doc.AddParagraph("Fasteners")
+	p.MarkIndexEntry("screw", "machine", "")
+	p.MarkIndexEntry("screw", "wood", "")
+	doc.AddParagraph("Nails").MarkIndexEntry("nail", "", "")
+	before := len(doc.Body.GetParagraphs())
+
+	err := doc.GenerateIndex(&IndexConfig{Columns: 1, RunIn: true, PageNumberAlignment: IndexPageNumbersRight})
+	if err != nil {
+		t.Fatalf("failed to generate index: %v", err)
+	}
+	expected := []string{"N", "nail\t1", "S", "screw: machine, 1; wood, 1"}
+	if texts := indexParagraphTexts(doc, before); strings.Join(texts, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected index %q", texts)
+	}
+	entry := doc.Body.GetParagraphs()[before+1]
+	if tabs := entry.Properties.Tabs; tabs == nil || tabs.Tabs[0].Val != "right" || tabs.Tabs[0].Leader != "dot" {
+		t.Error("page numbers should be right aligned with a dot leader")
+	}
+	if instruction := doc.Body.GetParagraphs()[before].Runs[1].InstrText.Content; instruction != " INDEX \\h \"A\" \\c \"1\" \\r \\e \"\t\" " {
+		t.Errorf("unexpected INDEX field %q", instruction)
+	}
+	for _, p := range doc.Body.GetParagraphs() {
+		if p.Properties != nil && p.Properties.SectionProperties != nil {
+			t.Error("a single column index should not add sections")
+		}
+	}
+
+	if err := doc.GenerateIndex(&IndexConfig{Columns: 5}); err == nil {
+		t.Error("expected error for too many columns")
+	}
+	if err := doc.GenerateIndex(&IndexConfig{PageNumberAlignment: "left"}); err == nil {
+		t.Error("expected error for unsupported alignment")
+	}
+	if err := New().GenerateIndex(nil); err == nil {
+		t.Error("expected error for a document without entries")
+	}
+}
@@ -0,0 +1,591 @@
+// Package document provides rich footnote and endnote content and reading
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Note is a footnote or endnote read from the document
+type Note struct {
+	ID   string
+	Type FootnoteType
+	// Paragraphs holds the note content without its leading reference mark
+	Paragraphs []*Paragraph
+	// CustomMark is the mark used instead of the automatic number, if any
+	CustomMark string
+	// Paragraph is the document paragraph referencing the note, nil if it is not referenced
+	Paragraph *Paragraph
+}
+
+// RunIndex returns the index of the reference run in Paragraph, or -1 if the note is not referenced
+// The index is looked up on every call as adding runs or notes to the paragraph moves the reference.
+func (n *Note) RunIndex() int {
+	if n.Paragraph == nil {
+		return -1
+	}
+	for i := range n.Paragraph.Runs {
+		if noteReferenceID(&n.Paragraph.Runs[i], n.Type) == n.ID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Text returns the plain text of the note, one line per paragraph
+func (n *Note) Text() string {
+	lines := make([]string, 0, len(n.Paragraphs))
+	for _, p := range n.Paragraphs {
+		var sb strings.Builder
+		for _, run := range p.Runs {
+			sb.WriteString(run.Text.Content)
+		}
+		lines = append(lines, sb.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NoteBuilder builds the content of a footnote or endnote
+type NoteBuilder struct {
+	paragraphs []*Paragraph
+	customMark string
+}
+
+// AddParagraph adds a paragraph to the note
+func (b *NoteBuilder) AddParagraph(text string) *Paragraph {
+	p := &Paragraph{}
+	if text != "" {
+		p.Runs = append(p.Runs, Run{
+			Text: Text{
+				Content: text,
+				Space:   "preserve",
+			},
+		})
+	}
+	b.paragraphs = append(b.paragraphs, p)
+	return p
+}
+
+// AddFormattedParagraph adds a paragraph with formatted text to the note
+func (b *NoteBuilder) AddFormattedParagraph(text string, format *TextFormat) *Paragraph {
+	p := &Paragraph{}
+	p.AddFormattedText(text, format)
+	b.paragraphs = append(b.paragraphs, p)
+	return p
+}
+
+// AddText appends formatted text to the last paragraph of the note
+func (b *NoteBuilder) AddText(text string, format *TextFormat) {
+	b.lastParagraph().AddFormattedText(text, format)
+}
+
+// AddHyperlink appends a hyperlink to an external address to the last paragraph of the note
+func (b *NoteBuilder) AddHyperlink(text, url string) {
+	p := b.lastParagraph()
+	p.Runs = append(p.Runs,
+		Run{FieldChar: &FieldChar{FieldCharType: "begin"}},
+		Run{InstrText: &InstrText{Space: "preserve", Content: fmt.Sprintf(" HYPERLINK \"%s\" ", url)}},
+		Run{FieldChar: &FieldChar{FieldCharType: "separate"}},
+	)
+	p.AddFormattedText(text, &TextFormat{FontColor: "0563C1", Underline: true})
+	p.Runs = append(p.Runs, Run{FieldChar: &FieldChar{FieldCharType: "end"}})
+}
+
+// SetCustomMark uses mark instead of the automatic number to reference the note
+func (b *NoteBuilder) SetCustomMark(mark string) {
+	b.customMark = mark
+}
+
+// Paragraphs returns the paragraphs of the note
+func (b *NoteBuilder) Paragraphs() []*Paragraph {
+	return b.paragraphs
+}
+
+// Clear removes all paragraphs from the note
+func (b *NoteBuilder) Clear() {
+	b.paragraphs = nil
+}
+
+// lastParagraph returns the last paragraph of the note, adding one if there is none
+func (b *NoteBuilder) lastParagraph() *Paragraph {
+	if len(b.paragraphs) == 0 {
+		return b.AddParagraph("")
+	}
+	return b.paragraphs[len(b.paragraphs)-1]
+}
+
+// AddFootnoteWithContent adds a footnote reference after a run of a document paragraph
+// The reference is a run of its own, so pointers to the runs of the paragraph are to be taken
+// again from its Runs afterwards.
+func (d *Document) AddFootnoteWithContent(run *Run, build func(*NoteBuilder)) (*Note, error) {
+	return d.addNoteAfterRun(run, FootnoteTypeFootnote, build)
+}
+
+// AddEndnoteWithContent adds an endnote reference after a run of a document paragraph, see
+// AddFootnoteWithContent
+func (d *Document) AddEndnoteWithContent(run *Run, build func(*NoteBuilder)) (*Note, error) {
+	return d.addNoteAfterRun(run, FootnoteTypeEndnote, build)
+}
+
+// Footnotes returns the footnotes of the document with their reference location
+func (d *Document) Footnotes() []*Note {
+	return d.readNotes(FootnoteTypeFootnote)
+}
+
+// Endnotes returns the endnotes of the document with their reference location
+func (d *Document) Endnotes() []*Note {
+	return d.readNotes(FootnoteTypeEndnote)
+}
+
+// EditFootnote replaces the content of a footnote, the builder starts with the current content
+func (d *Document) EditFootnote(id string, build func(*NoteBuilder)) error {
+	return d.editNote(FootnoteTypeFootnote, id, build)
+}
+
+// EditEndnote replaces the content of an endnote, the builder starts with the current content
+func (d *Document) EditEndnote(id string, build func(*NoteBuilder)) error {
+	return d.editNote(FootnoteTypeEndnote, id, build)
+}
+
+// addNoteAfterRun adds a note referenced right after run
+func (d *Document) addNoteAfterRun(run *Run, noteType FootnoteType, build func(*NoteBuilder)) (*Note, error) {
+	if run == nil {
+		return nil, NewValidationError("run", "", "run cannot be nil")
+	}
+	var paragraph *Paragraph
+	position := -1
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		for i := range p.Runs {
+			if &p.Runs[i] == run {
+				paragraph, position = p, i+1
+				return false
+			}
+		}
+		return true
+	})
+	if paragraph == nil {
+		return nil, NewValidationError("run", "", "run is not part of the document")
+	}
+	return d.addNote(paragraph, position, noteType, build)
+}
+
+// addNote stores a new note and inserts its reference run at position in paragraph
+func (d *Document) addNote(paragraph *Paragraph, position int, noteType FootnoteType, build func(*NoteBuilder)) (*Note, error) {
+	if build == nil {
+		return nil, NewValidationError("build", "", "note builder function cannot be nil")
+	}
+	builder := &NoteBuilder{}
+	build(builder)
+	if len(builder.paragraphs) == 0 {
+		return nil, NewValidationError("content", "", "note must have at least one paragraph")
+	}
+
+	d.ensureFootnoteInitialized(noteType)
+	notes, err := d.loadNotes(noteType)
+	if err != nil {
+		return nil, WrapError("add_"+string(noteType), err)
+	}
+
+	id := 1
+	for _, note := range notes {
+		if n, err := strconv.Atoi(note.ID); err == nil && n >= id {
+			id = n + 1
+		}
+	}
+	note := &Note{
+		ID:         strconv.Itoa(id),
+		Type:       noteType,
+		Paragraphs: builder.paragraphs,
+		CustomMark: builder.customMark,
+		Paragraph:  paragraph,
+	}
+	notes = append(notes, &Footnote{ID: note.ID, Paragraphs: markedNoteParagraphs(note)})
+	if err := d.storeNotes(noteType, notes); err != nil {
+		return nil, WrapError("add_"+string(noteType), err)
+	}
+
+	paragraph.Runs = append(paragraph.Runs, Run{})
+	copy(paragraph.Runs[position+1:], paragraph.Runs[position:])
+	paragraph.Runs[position] = noteReferenceRun(noteType, note.ID, note.CustomMark)
+
+	Infof("added %s %s", noteType, note.ID)
+	return note, nil
+}
+
+// readNotes reads the notes of a type, skipping separators
+func (d *Document) readNotes(noteType FootnoteType) []*Note {
+	notes, err := d.loadNotes(noteType)
+	if err != nil {
+		Errorf("failed to read %ss: %v", noteType, err)
+		return nil
+	}
+
+	references := d.noteReferences(noteType)
+	result := make([]*Note, 0, len(notes))
+	for _, n := range notes {
+		if n.Type != "" {
+			continue
+		}
+		note := &Note{ID: n.ID, Type: noteType}
+		if ref, ok := references[n.ID]; ok {
+			note.Paragraph = ref.paragraph
+			note.CustomMark = ref.customMark
+		}
+		note.Paragraphs = unmarkedNoteParagraphs(n.Paragraphs, note.CustomMark)
+		result = append(result, note)
+	}
+	return result
+}
+
+// editNote rebuilds the content of an existing note and updates its reference mark
+func (d *Document) editNote(noteType FootnoteType, id string, build func(*NoteBuilder)) error {
+	if build == nil {
+		return NewValidationError("build", "", "note builder function cannot be nil")
+	}
+	var note *Note
+	for _, n := range d.readNotes(noteType) {
+		if n.ID == id {
+			note = n
+			break
+		}
+	}
+	if note == nil {
+		return NewValidationError("id", id, fmt.Sprintf("%s not found", noteType))
+	}
+
+	builder := &NoteBuilder{paragraphs: note.Paragraphs, customMark: note.CustomMark}
+	build(builder)
+	if len(builder.paragraphs) == 0 {
+		return NewValidationError("content", "", "note must have at least one paragraph")
+	}
+	note.Paragraphs, note.CustomMark = builder.paragraphs, builder.customMark
+
+	notes, err := d.loadNotes(noteType)
+	if err != nil {
+		return WrapError("edit_"+string(noteType), err)
+	}
+	for _, n := range notes {
+		if n.Type == "" && n.ID == id {
+			n.Paragraphs = markedNoteParagraphs(note)
+		}
+	}
+	if err := d.storeNotes(noteType, notes); err != nil {
+		return WrapError("edit_"+string(noteType), err)
+	}
+
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		for i := range p.Runs {
+			if noteReferenceID(&p.Runs[i], noteType) == id {
+				p.Runs[i] = noteReferenceRun(noteType, id, note.CustomMark)
+			}
+		}
+		return true
+	})
+	return nil
+}
+
+// removeNote removes a note and its references from the document
+func (d *Document) removeNote(noteType FootnoteType, id string) error {
+	notes, err := d.loadNotes(noteType)
+	if err != nil {
+		return WrapError("remove_"+string(noteType), err)
+	}
+	kept := notes[:0]
+	for _, n := range notes {
+		if n.Type == "" && n.ID == id {
+			continue
+		}
+		kept = append(kept, n)
+	}
+	if len(kept) == len(notes) {
+		return NewValidationError("id", id, fmt.Sprintf("%s not found", noteType))
+	}
+	if err := d.storeNotes(noteType, kept); err != nil {
+		return WrapError("remove_"+string(noteType), err)
+	}
+
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		runs := p.Runs[:0]
+		for i := range p.Runs {
+			if noteReferenceID(&p.Runs[i], noteType) != id {
+				runs = append(runs, p.Runs[i])
+			}
+		}
+		p.Runs = runs
+		return true
+	})
+	return nil
+}
+
+// noteReference is the location of a note reference in the document
+type noteReference struct {
+	paragraph  *Paragraph
+	customMark string
+}
+
+// noteReferences returns the first reference of each note of a type by note ID
+func (d *Document) noteReferences(noteType FootnoteType) map[string]noteReference {
+	references := make(map[string]noteReference)
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		for i := range p.Runs {
+			id := noteReferenceID(&p.Runs[i], noteType)
+			if _, seen := references[id]; id == "" || seen {
+				continue
+			}
+			ref := noteReference{paragraph: p}
+			if customMarkFollows(&p.Runs[i], noteType) {
+				ref.customMark = p.Runs[i].Text.Content
+			}
+			references[id] = ref
+		}
+		return true
+	})
+	return references
+}
+
+// noteReferenceID returns the ID of the note a run refers to, or "" for other runs
+func noteReferenceID(run *Run, noteType FootnoteType) string {
+	if noteType == FootnoteTypeFootnote && run.FootnoteReference != nil {
+		return run.FootnoteReference.ID
+	}
+	if noteType == FootnoteTypeEndnote && run.EndnoteReference != nil {
+		return run.EndnoteReference.ID
+	}
+	return ""
+}
+
+// customMarkFollows reports whether a reference run carries its own mark text
+func customMarkFollows(run *Run, noteType FootnoteType) bool {
+	val := ""
+	if noteType == FootnoteTypeFootnote && run.FootnoteReference != nil {
+		val = run.FootnoteReference.CustomMarkFollows
+	} else if noteType == FootnoteTypeEndnote && run.EndnoteReference != nil {
+		val = run.EndnoteReference.CustomMarkFollows
+	}
+	return val == "1" || val == "true" || val == "on"
+}
+
+// noteReferenceRun creates the superscript run referencing a note from the document text
+func noteReferenceRun(noteType FootnoteType, id, customMark string) Run {
+	run := Run{
+		Properties: &RunProperties{VertAlign: &VertAlign{Val: "superscript"}},
+		Text:       Text{Content: customMark},
+	}
+	customMarkFollows := ""
+	if customMark != "" {
+		customMarkFollows = "1"
+	}
+	if noteType == FootnoteTypeFootnote {
+		run.FootnoteReference = &FootnoteReference{ID: id, CustomMarkFollows: customMarkFollows}
+	} else {
+		run.EndnoteReference = &EndnoteReference{ID: id, CustomMarkFollows: customMarkFollows}
+	}
+	return run
+}
+
+// markedNoteParagraphs returns the note paragraphs with the reference mark leading the first one
+func markedNoteParagraphs(note *Note) []*Paragraph {
+	mark := Run{Properties: &RunProperties{VertAlign: &VertAlign{Val: "superscript"}}}
+	switch {
+	case note.CustomMark != "":
+		mark.Text = Text{Content: note.CustomMark}
+	case note.Type == FootnoteTypeFootnote:
+		mark.FootnoteRef = &FootnoteRef{}
+	default:
+		mark.EndnoteRef = &EndnoteRef{}
+	}
+
+	paragraphs := make([]*Paragraph, len(note.Paragraphs))
+	copy(paragraphs, note.Paragraphs)
+	first := *paragraphs[0]
+	first.Runs = append([]Run{mark, {Text: Text{Content: " ", Space: "preserve"}}}, first.Runs...)
+	paragraphs[0] = &first
+	return paragraphs
+}
+
+// unmarkedNoteParagraphs returns copies of the note paragraphs without the leading reference mark
+func unmarkedNoteParagraphs(paragraphs []*Paragraph, customMark string) []*Paragraph {
+	result := make([]*Paragraph, 0, len(paragraphs))
+	for i, p := range paragraphs {
+		runs := p.Runs
+		if i == 0 && len(runs) > 0 {
+			first := runs[0]
+			if first.FootnoteRef != nil || first.EndnoteRef != nil || (customMark != "" && first.Text.Content == customMark) {
+				runs = runs[1:]
+				if len(runs) > 0 && runs[0].Text.Content != "" && strings.TrimSpace(runs[0].Text.Content) == "" {
+					runs = runs[1:]
+				}
+			}
+		}
+		paragraph := *p
+		paragraph.Runs = append([]Run(nil), runs...)
+		result = append(result, &paragraph)
+	}
+	return result
+}
+
+// notesPart returns the part name of the notes of a type
+func notesPart(noteType FootnoteType) string {
+	if noteType == FootnoteTypeEndnote {
+		return "word/endnotes.xml"
+	}
+	return "word/footnotes.xml"
+}
+
+// loadNotes parses the notes part of a type, separators included
+func (d *Document) loadNotes(noteType FootnoteType) ([]*Footnote, error) {
+	data, ok := d.parts[notesPart(noteType)]
+	if !ok {
+		return nil, nil
+	}
+
+	var notes []*Footnote
+	var current *Footnote
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return notes, nil
+		}
+		if err != nil {
+			return nil, WrapError("parse_notes", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == string(noteType):
+				current = &Footnote{
+					Type: getAttributeValue(t.Attr, "type"),
+					ID:   getAttributeValue(t.Attr, "id"),
+				}
+			case t.Name.Local == "p" && current != nil:
+				paragraph, err := d.parseParagraph(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				current.Paragraphs = append(current.Paragraphs, paragraph)
+			case current != nil:
+				if err := d.skipElement(decoder, t.Name.Local); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == string(noteType) && current != nil {
+				notes = append(notes, current)
+				current = nil
+			}
+		}
+	}
+}
+
+// storeNotes rewrites the notes part of a type
+// Separators are kept as they are in the part, the other notes are replaced by notes
+func (d *Document) storeNotes(noteType FootnoteType, notes []*Footnote) error {
+	part := notesPart(noteType)
+	data, ok := d.parts[part]
+	if !ok {
+		return fmt.Errorf("%s not found", part)
+	}
+
+	var separators bytes.Buffer
+	rootEnd, rootClose, depth := -1, -1, 0
+	noteStart, typed := int64(0), false
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return WrapError("parse_notes", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				rootEnd = int(decoder.InputOffset())
+			} else if depth == 2 && t.Name.Local == string(noteType) {
+				noteStart, typed = offset, getAttributeValue(t.Attr, "type") != ""
+			}
+		case xml.EndElement:
+			if depth == 2 && t.Name.Local == string(noteType) && typed {
+				separators.Write(data[noteStart:decoder.InputOffset()])
+			} else if depth == 1 {
+				rootClose = int(offset)
+			}
+			depth--
+		}
+	}
+	if rootEnd < 0 || rootClose < rootEnd {
+		return fmt.Errorf("%s has no root element", part)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data[:rootEnd])
+	buf.Write(separators.Bytes())
+	for _, note := range notes {
+		if note.Type != "" {
+			continue
+		}
+		var element interface{} = note
+		if noteType == FootnoteTypeEndnote {
+			element = &Endnote{ID: note.ID, Paragraphs: note.Paragraphs}
+		}
+		noteXML, err := xml.Marshal(element)
+		if err != nil {
+			return WrapError("marshal_notes", err)
+		}
+		buf.Write(noteXML)
+	}
+	buf.Write(data[rootClose:])
+	d.parts[part] = buf.Bytes()
+	return nil
+}
+
+// walkParagraphs calls fn for the paragraphs of body elements until fn returns false
+func walkParagraphs(elements []interface{}, fn func(p *Paragraph) bool) bool {
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *Paragraph:
+			if !fn(e) {
+				return false
+			}
+		case *Table:
+			if !walkTableParagraphs(e, fn) {
+				return false
+			}
+		case *SDT:
+			if e.Content != nil && !walkParagraphs(e.Content.Elements, fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// walkTableParagraphs calls fn for the paragraphs in the cells of a table
+func walkTableParagraphs(table *Table, fn func(p *Paragraph) bool) bool {
+	for r := range table.Rows {
+		for c := range table.Rows[r].Cells {
+			cell := &table.Rows[r].Cells[c]
+			for p := range cell.Paragraphs {
+				if !fn(&cell.Paragraphs[p]) {
+					return false
+				}
+			}
+			for t := range cell.Tables {
+				if !walkTableParagraphs(&cell.Tables[t], fn) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package document
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestAddFootnoteWithContent tests rich footnote content and reading it back
+func TestAddFootnoteWithContent(t *testing.T) {
+	doc := New()
+	p := doc.AddParagraph("The claim")
+	doc.AddParagraph("Unrelated")
+	note, err := doc.AddFootnoteWithContent(&p.Runs[0], func(b *NoteBuilder) {
+		b.AddParagraph("See ")
+		b.AddText("the report ", &TextFormat{Bold: true})
+		b.AddHyperlink("online", "https://example.com/report")
+		b.AddParagraph("Second paragraph")
+	})
+	if err != nil {
+		t.Fatalf("failed to add footnote: %v", err)
+	}
+	if note.ID != "1" || note.RunIndex() != 1 {
+		t.Errorf("unexpected note %+v", note)
+	}
+	if ref := p.Runs[1].FootnoteReference; ref == nil || ref.ID != "1" {
+		t.Fatal("reference run should follow the run")
+	}
+
+	filename := "test_footnote_content.docx"
+	defer os.Remove(filename)
+	content := savedDocumentXML(t, doc, filename)
+	if !strings.Contains(content, `<w:vertAlign w:val="superscript">`) || !strings.Contains(content, `<w:footnoteReference w:id="1">`) {
+		t.Error("document.xml should contain a superscript footnote reference")
+	}
+	notes := string(doc.parts["word/footnotes.xml"])
+	for _, expected := range []string{`w:type="separator"`, `<w:footnoteRef></w:footnoteRef>`, `HYPERLINK &#34;https://example.com/report&#34;`} {
+		if !strings.Contains(notes, expected) {
+			t.Errorf("footnotes.xml should contain %s", expected)
+		}
+	}
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	footnotes := opened.Footnotes()
+	if len(footnotes) != 1 {
+		t.Fatalf("expected 1 footnote, got %d", len(footnotes))
+	}
+	if text := footnotes[0].Text(); text != "See the report online\nSecond paragraph" {
+		t.Errorf("unexpected footnote text %q", text)
+	}
+	if footnotes[0].Paragraph == nil || footnotes[0].Paragraph.Runs[0].Text.Content != "The claim" || footnotes[0].RunIndex() != 1 {
+		t.Error("footnote reference location should be read back")
+	}
+
+	if _, err := doc.AddFootnoteWithContent(&Run{}, func(b *NoteBuilder) { b.AddParagraph("x") }); err == nil {
+		t.Error("expected error for a run outside the document")
+	}
+	if _, err := doc.AddFootnoteWithContent(&p.Runs[0], func(b *NoteBuilder) {}); err == nil {
+		t.Error("expected error for an empty note")
+	}
+}
+
+// TestNoteRunIndexAfterInsert tests that reference positions follow later insertions
+func TestNoteRunIndexAfterInsert(t *testing.T) {
+	doc := New()
+	p := doc.AddParagraph("First claim")
+	p.AddFormattedText(" and second claim", nil)
+	second, err := doc.AddFootnoteWithContent(&p.Runs[1], func(b *NoteBuilder) { b.AddParagraph("Second source") })
+	if err != nil {
+		t.Fatalf("failed to add footnote: %v", err)
+	}
+	first, err := doc.AddFootnoteWithContent(&p.Runs[0], func(b *NoteBuilder) { b.AddParagraph("First source") })
+	if err != nil {
+		t.Fatalf("failed to add footnote: %v", err)
+	}
+	if first.RunIndex() != 1 || second.RunIndex() != 3 {
+		t.Errorf("expected references at 1 and 3, got %d and %d", first.RunIndex(), second.RunIndex())
+	}
+	if ref := p.Runs[second.RunIndex()].FootnoteReference; ref == nil || ref.ID != second.ID {
+		t.Error("the index should point to the reference run of the note")
+	}
+	if (&Note{ID: "9", Type: FootnoteTypeFootnote, Paragraph: p}).RunIndex() != -1 {
+		t.Error("a note without reference should have no run index")
+	}
+}
+
+// TestEditEndnoteCustomMark tests custom reference marks and editing a note in place
+func TestEditEndnoteCustomMark(t *testing.T) {
+	doc := New()
+	p := doc.AddParagraph("Source")
+	if _, err := doc.AddEndnoteWithContent(&p.Runs[0], func(b *NoteBuilder) {
+		b.SetCustomMark("*")
+		b.AddParagraph("Original note")
+	}); err != nil {
+		t.Fatalf("failed to add endnote: %v", err)
+	}
+	ref := p.Runs[1].EndnoteReference
+	if ref == nil || ref.CustomMarkFollows != "1" || p.Runs[1].Text.Content != "*" {
+		t.Fatalf("reference should carry the custom mark, got %+v", p.Runs[1])
+	}
+
+	err := doc.EditEndnote("1", func(b *NoteBuilder) {
+		if b.Paragraphs()[0].Runs[0].Text.Content != "Original note" {
+			t.Error("builder should start with the current content")
+		}
+		b.Clear()
+		b.AddParagraph("Edited note")
+		b.SetCustomMark("†")
+	})
+	if err != nil {
+		t.Fatalf("failed to edit endnote: %v", err)
+	}
+	if p.Runs[1].Text.Content != "†" {
+		t.Error("reference mark should be updated")
+	}
+	endnotes := doc.Endnotes()
+	if len(endnotes) != 1 || endnotes[0].Text() != "Edited note" || endnotes[0].CustomMark != "†" {
+		t.Errorf("unexpected endnotes %+v", endnotes)
+	}
+	if strings.Contains(string(doc.parts["word/endnotes.xml"]), "Original") {
+		t.Error("the previous content should be replaced")
+	}
+	if err := doc.EditEndnote("7", func(b *NoteBuilder) {}); err == nil {
+		t.Error("expected error for unknown endnote")
+	}
+}
+
+// TestRemoveFootnote tests removing a footnote and its reference
+func TestRemoveFootnote(t *testing.T) {
+	doc := New()
+	if err := doc.AddFootnote("First", "Note one"); err != nil {
+		t.Fatalf("failed to add footnote: %v", err)
+	}
+	if err := doc.AddFootnote("Second", "Note two"); err != nil {
+		t.Fatalf("failed to add footnote: %v", err)
+	}
+	if err := doc.RemoveFootnote("1"); err != nil {
+		t.Fatalf("failed to remove footnote: %v", err)
+	}
+	footnotes := doc.Footnotes()
+	if len(footnotes) != 1 || footnotes[0].ID != "2" || footnotes[0].Text() != "Note two" {
+		t.Errorf("unexpected footnotes %+v", footnotes)
+	}
+	first := doc.Body.GetParagraphs()[0]
+	if len(first.Runs) != 1 {
+		t.Error("the reference of the removed footnote should be removed")
+	}
+	if err := doc.RemoveFootnote("1"); err == nil {
+		t.Error("expected error for removed footnote")
+	}
+}
+
+// TestStoreNotesKeepsSeparators tests that separators written by Word are kept as they are
+func TestStoreNotesKeepsSeparators(t *testing.T) {
+	doc := New()
+	separator := `<w:footnote w:type="separator" w:id="-1"><w:p w14:paraId="1A2B3C4D"><w:r><w:separator/></w:r></w:p></w:footnote>` +
+		`<w:footnote w:type="continuationSeparator" w:id="0"><w:p><w:r><w:continuationSeparator/></w:r></w:p></w:footnote>`
+	doc.parts["word/footnotes.xml"] = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:footnotes xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml">` +
+		separator + `<w:footnote w:id="1"><w:p><w:r><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r><w:r><w:t>Existing</w:t></w:r></w:p></w:footnote></w:footnotes>`)
+
+	if err := doc.AddFootnote("Text", "Added"); err != nil {
+		t.Fatalf("failed to add footnote: %v", err)
+	}
+	notes := string(doc.parts["word/footnotes.xml"])
+	if !strings.Contains(notes, separator) || !strings.Contains(notes, `xmlns:w14=`) {
+		t.Errorf("separators should be kept, got %s", notes)
+	}
+	footnotes := doc.Footnotes()
+	if len(footnotes) != 2 || footnotes[0].Text() != "Existing" || footnotes[1].ID != "2" {
+		t.Errorf("unexpected footnotes %+v", footnotes)
+	}
+}
@@ -2,9 +2,15 @@
 package document
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
+	"strings"
+
+	"github.com/drumkitai/go-word/pkg/style"
 )
 
 // ListType list type
@@ -18,6 +24,13 @@ const (
 	ListTypeUpperLetter ListType = "upperLetter"
 	ListTypeLowerRoman  ListType = "lowerRoman"
 	ListTypeUpperRoman  ListType = "upperRoman"
+
+	ListTypeDecimalZero          ListType = "decimalZero"          // 01, 02, ... 10
+	ListTypeOrdinal              ListType = "ordinal"              // 1st, 2nd, 3rd
+	ListTypeCardinalText         ListType = "cardinalText"         // One, Two, Three
+	ListTypeChineseCounting      ListType = "chineseCounting"      // 一, 二, 三
+	ListTypeIdeographTraditional ListType = "ideographTraditional" // 甲, 乙, 丙
+	ListTypeNone                 ListType = "none"                 // level text without a number
 )
 
 // BulletType bullet type
@@ -33,10 +46,44 @@ const (
 
 // Numbering numbering definition
 type Numbering struct {
-	XMLName            xml.Name       `xml:"w:numbering"`
-	Xmlns              string         `xml:"xmlns:w,attr"`
-	AbstractNums       []*AbstractNum `xml:"w:abstractNum"`
-	NumberingInstances []*NumInstance `xml:"w:num"`
+	XMLName            xml.Name        `xml:"w:numbering"`
+	Xmlns              string          `xml:"xmlns:w,attr"`
+	PicBullets         []*NumPicBullet `xml:"w:numPicBullet"`
+	AbstractNums       []*AbstractNum  `xml:"w:abstractNum"`
+	NumberingInstances []*NumInstance  `xml:"w:num"`
+}
+
+// NumPicBullet picture bullet definition
+type NumPicBullet struct {
+	XMLName xml.Name       `xml:"w:numPicBullet"`
+	ID      string         `xml:"w:numPicBulletId,attr"`
+	Pict    *picBulletPict `xml:"w:pict"`
+}
+
+// picBulletPict is the VML picture of a picture bullet
+type picBulletPict struct {
+	XMLName xml.Name        `xml:"w:pict"`
+	XmlnsV  string          `xml:"xmlns:v,attr,omitempty"`
+	XmlnsO  string          `xml:"xmlns:o,attr,omitempty"`
+	XmlnsR  string          `xml:"xmlns:r,attr,omitempty"`
+	Shape   *picBulletShape `xml:"v:shape"`
+}
+
+// picBulletShape is the VML shape holding the image of a picture bullet
+type picBulletShape struct {
+	XMLName   xml.Name            `xml:"v:shape"`
+	ID        string              `xml:"id,attr"`
+	Type      string              `xml:"type,attr"`
+	Style     string              `xml:"style,attr"`
+	Bullet    string              `xml:"o:bullet,attr"`
+	ImageData *picBulletImageData `xml:"v:imagedata"`
+}
+
+// picBulletImageData refers to the image of a picture bullet
+type picBulletImageData struct {
+	XMLName xml.Name `xml:"v:imagedata"`
+	ID      string   `xml:"r:id,attr"`
+	Title   string   `xml:"o:title,attr"`
 }
 
 // AbstractNum abstract numbering definition
@@ -48,9 +95,24 @@ type AbstractNum struct {
 
 // NumInstance numbering instance
 type NumInstance struct {
-	XMLName       xml.Name              `xml:"w:num"`
-	NumID         string                `xml:"w:numId,attr"`
-	AbstractNumID *AbstractNumReference `xml:"w:abstractNumId"`
+	XMLName        xml.Name              `xml:"w:num"`
+	NumID          string                `xml:"w:numId,attr"`
+	AbstractNumID  *AbstractNumReference `xml:"w:abstractNumId"`
+	LevelOverrides []*LevelOverride      `xml:"w:lvlOverride,omitempty"`
+}
+
+// LevelOverride overrides the start value or the definition of a level for one numbering instance
+type LevelOverride struct {
+	XMLName       xml.Name       `xml:"w:lvlOverride"`
+	ILevel        string         `xml:"w:ilvl,attr"`
+	StartOverride *StartOverride `xml:"w:startOverride,omitempty"`
+	Level         *Level         `xml:"w:lvl,omitempty"`
+}
+
+// StartOverride restarts a level of a numbering instance at a value
+type StartOverride struct {
+	XMLName xml.Name `xml:"w:startOverride"`
+	Val     string   `xml:"w:val,attr"`
 }
 
 // AbstractNumReference abstract numbering reference
@@ -61,14 +123,18 @@ type AbstractNumReference struct {
 
 // Level numbering level
 type Level struct {
-	XMLName   xml.Name   `xml:"w:lvl"`
-	ILevel    string     `xml:"w:ilvl,attr"`
-	Start     *Start     `xml:"w:start,omitempty"`
-	NumFmt    *NumFmt    `xml:"w:numFmt,omitempty"`
-	LevelText *LevelText `xml:"w:lvlText,omitempty"`
-	LevelJc   *LevelJc   `xml:"w:lvlJc,omitempty"`
-	PPr       *LevelPPr  `xml:"w:pPr,omitempty"`
-	RPr       *LevelRPr  `xml:"w:rPr,omitempty"`
+	XMLName   xml.Name        `xml:"w:lvl"`
+	ILevel    string          `xml:"w:ilvl,attr"`
+	Start     *Start          `xml:"w:start,omitempty"`
+	NumFmt    *NumFmt         `xml:"w:numFmt,omitempty"`
+	PStyle    *ParagraphStyle `xml:"w:pStyle,omitempty"`
+	IsLgl     *IsLgl          `xml:"w:isLgl,omitempty"`
+	Suffix    *LevelSuffix    `xml:"w:suff,omitempty"`
+	LevelText *LevelText      `xml:"w:lvlText,omitempty"`
+	PicBullet *LevelPicBullet `xml:"w:lvlPicBulletId,omitempty"`
+	LevelJc   *LevelJc        `xml:"w:lvlJc,omitempty"`
+	PPr       *LevelPPr       `xml:"w:pPr,omitempty"`
+	RPr       *LevelRPr       `xml:"w:rPr,omitempty"`
 }
 
 // Start numbering start value
@@ -83,6 +149,23 @@ type NumFmt struct {
 	Val     string   `xml:"w:val,attr"`
 }
 
+// IsLgl displays all numbers of a level as Arabic numerals (legal numbering)
+type IsLgl struct {
+	XMLName xml.Name `xml:"w:isLgl"`
+}
+
+// LevelSuffix character between the number and the text of a level
+type LevelSuffix struct {
+	XMLName xml.Name `xml:"w:suff"`
+	Val     string   `xml:"w:val,attr"`
+}
+
+// LevelPicBullet picture bullet of a level
+type LevelPicBullet struct {
+	XMLName xml.Name `xml:"w:lvlPicBulletId"`
+	Val     string   `xml:"w:val,attr"`
+}
+
 // LevelText level text
 type LevelText struct {
 	XMLName xml.Name `xml:"w:lvlText"`
@@ -122,28 +205,86 @@ type ListConfig struct {
 	IndentLevel  int        // 缩进级别（0-8）
 }
 
-// 全局编号管理器
-var globalNumberingManager *NumberingManager
-
 // NumberingManager 编号管理器
 type NumberingManager struct {
-	nextAbstractNumID int
-	nextNumID         int
-	abstractNums      map[string]*AbstractNum
-	numInstances      map[string]*NumInstance
+	abstractNums map[string]*AbstractNum
+	numInstances map[string]*NumInstance
+	picBullets   []*NumPicBullet
 }
 
-// getNumberingManager 获取全局编号管理器
-func getNumberingManager() *NumberingManager {
-	if globalNumberingManager == nil {
-		globalNumberingManager = &NumberingManager{
-			nextAbstractNumID: 0,
-			nextNumID:         1,
-			abstractNums:      make(map[string]*AbstractNum),
-			numInstances:      make(map[string]*NumInstance),
+// numberingManager returns the numbering definitions of the document, read from numbering.xml on first use
+func (d *Document) numberingManager() *NumberingManager {
+	if d.numbering == nil {
+		if err := d.parseNumbering(); err != nil {
+			Errorf("failed to parse numbering definitions: %v", err)
+		}
+	}
+	if d.numbering == nil {
+		d.numbering = &NumberingManager{
+			abstractNums: make(map[string]*AbstractNum),
+			numInstances: make(map[string]*NumInstance),
 		}
 	}
-	return globalNumberingManager
+	return d.numbering
+}
+
+// parseNumbering reads the numbering definitions of numbering.xml into the numbering manager
+func (d *Document) parseNumbering() error {
+	data, ok := d.parts["word/numbering.xml"]
+	if !ok {
+		return nil
+	}
+	var numbering Numbering
+	if err := style.UnmarshalPrefixedXML(data, &numbering); err != nil {
+		return WrapError("parse_numbering", err)
+	}
+
+	manager := &NumberingManager{
+		abstractNums: make(map[string]*AbstractNum),
+		numInstances: make(map[string]*NumInstance),
+		picBullets:   numbering.PicBullets,
+	}
+	for _, abstractNum := range numbering.AbstractNums {
+		manager.abstractNums["parsed_"+abstractNum.AbstractNumID] = abstractNum
+	}
+	for _, instance := range numbering.NumberingInstances {
+		manager.numInstances[instance.NumID] = instance
+	}
+	d.numbering = manager
+	Debugf("parsed %d abstract numberings and %d numbering instances", len(numbering.AbstractNums), len(numbering.NumberingInstances))
+	return nil
+}
+
+// abstractNum returns the abstract numbering with an ID
+func (m *NumberingManager) abstractNum(abstractNumID string) *AbstractNum {
+	for _, abstractNum := range m.abstractNums {
+		if abstractNum.AbstractNumID == abstractNumID {
+			return abstractNum
+		}
+	}
+	return nil
+}
+
+// newAbstractNumID allocates the ID of a new abstract numbering, after the highest ID of the document
+func (m *NumberingManager) newAbstractNumID() string {
+	next := 0
+	for _, abstractNum := range m.abstractNums {
+		if n, err := strconv.Atoi(abstractNum.AbstractNumID); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return strconv.Itoa(next)
+}
+
+// newNumID allocates the ID of a new numbering instance, after the highest ID of the document
+func (m *NumberingManager) newNumID() string {
+	next := 1
+	for id := range m.numInstances {
+		if n, err := strconv.Atoi(id); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return strconv.Itoa(next)
 }
 
 // AddListItem 添加列表项
@@ -265,7 +406,7 @@ func (d *Document) initializeNumbering() {
 
 // getOrCreateNumbering 获取或创建编号定义
 func (d *Document) getOrCreateNumbering(config *ListConfig) string {
-	manager := getNumberingManager()
+	manager := d.numberingManager()
 
 	// 生成抽象编号键
 	abstractKey := fmt.Sprintf("%s_%s_%d", config.Type, config.BulletSymbol, config.IndentLevel)
@@ -276,16 +417,14 @@ func (d *Document) getOrCreateNumbering(config *ListConfig) string {
 		abstractNum = existing
 	} else {
 		// 创建新的抽象编号
-		abstractNumID := strconv.Itoa(manager.nextAbstractNumID)
-		manager.nextAbstractNumID++
+		abstractNumID := manager.newAbstractNumID()
 
 		abstractNum = d.createAbstractNum(abstractNumID, config)
 		manager.abstractNums[abstractKey] = abstractNum
 	}
 
 	// 创建编号实例
-	numID := strconv.Itoa(manager.nextNumID)
-	manager.nextNumID++
+	numID := manager.newNumID()
 
 	numInstance := &NumInstance{
 		NumID: numID,
@@ -354,40 +493,222 @@ func (d *Document) createLevel(levelIndex int, config *ListConfig) *Level {
 	case ListTypeUpperRoman:
 		level.NumFmt = &NumFmt{Val: "upperRoman"}
 		level.LevelText = &LevelText{Val: fmt.Sprintf("%%%d.", levelIndex+1)}
+	case ListTypeDecimalZero, ListTypeOrdinal, ListTypeCardinalText, ListTypeChineseCounting, ListTypeIdeographTraditional:
+		level.NumFmt = &NumFmt{Val: string(config.Type)}
+		level.LevelText = &LevelText{Val: fmt.Sprintf("%%%d.", levelIndex+1)}
 	}
 
 	return level
 }
 
 // updateNumberingFile 更新编号定义文件
+// Definitions already in numbering.xml are kept as they are, new ones are added after them.
 func (d *Document) updateNumberingFile() {
-	manager := getNumberingManager()
+	if err := d.storeNumbering(); err != nil {
+		Errorf("failed to update numbering definitions: %v", err)
+	}
+}
 
-	numbering := &Numbering{
-		Xmlns:              "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
-		AbstractNums:       []*AbstractNum{},
-		NumberingInstances: []*NumInstance{},
+// storeNumbering writes the picture bullets, abstract numberings and numbering instances
+// missing from numbering.xml after the elements of the same kind
+func (d *Document) storeNumbering() error {
+	manager := d.numberingManager()
+	data := d.parts["word/numbering.xml"]
+
+	present := make(map[string]bool)
+	namespaces := make(map[string]bool)
+	rootEnd, depth := -1, 0
+	var picEnd, abstractEnd, numEnd int
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return WrapError("parse_numbering", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				rootEnd = int(decoder.InputOffset())
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "xmlns" {
+						namespaces[attr.Name.Local] = true
+					}
+				}
+			} else if depth == 2 {
+				switch t.Name.Local {
+				case "numPicBullet":
+					present["numPicBullet:"+getAttributeValue(t.Attr, "numPicBulletId")] = true
+				case "abstractNum":
+					present["abstractNum:"+getAttributeValue(t.Attr, "abstractNumId")] = true
+				case "num":
+					present["num:"+getAttributeValue(t.Attr, "numId")] = true
+				}
+			}
+		case xml.EndElement:
+			if depth == 2 {
+				switch t.Name.Local {
+				case "numPicBullet":
+					picEnd = int(decoder.InputOffset())
+				case "abstractNum":
+					abstractEnd = int(decoder.InputOffset())
+				case "num":
+					numEnd = int(decoder.InputOffset())
+				}
+			}
+			depth--
+		}
+	}
+	if rootEnd < 0 {
+		return fmt.Errorf("numbering.xml has no root element")
+	}
+	// a kind of element missing from the part goes after the kinds preceding it
+	if picEnd == 0 {
+		picEnd = rootEnd
+	}
+	if abstractEnd == 0 {
+		abstractEnd = picEnd
+	}
+	if numEnd == 0 {
+		numEnd = abstractEnd
+	}
+
+	var pics, abstractNums, nums bytes.Buffer
+	for _, picBullet := range manager.picBullets {
+		if present["numPicBullet:"+picBullet.ID] {
+			continue
+		}
+		if picBullet.Pict != nil {
+			// picture bullets are VML shapes referring to images of the numbering part
+			if !namespaces["v"] {
+				picBullet.Pict.XmlnsV = "urn:schemas-microsoft-com:vml"
+			}
+			if !namespaces["o"] {
+				picBullet.Pict.XmlnsO = "urn:schemas-microsoft-com:office:office"
+			}
+			if !namespaces["r"] {
+				picBullet.Pict.XmlnsR = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+			}
+		}
+		if err := marshalNumberingElement(&pics, picBullet); err != nil {
+			return err
+		}
 	}
 
-	// 添加所有抽象编号
+	var newAbstractNums []*AbstractNum
 	for _, abstractNum := range manager.abstractNums {
-		numbering.AbstractNums = append(numbering.AbstractNums, abstractNum)
+		if !present["abstractNum:"+abstractNum.AbstractNumID] {
+			newAbstractNums = append(newAbstractNums, abstractNum)
+		}
+	}
+	sort.Slice(newAbstractNums, func(i, j int) bool {
+		return numberingIDLess(newAbstractNums[i].AbstractNumID, newAbstractNums[j].AbstractNumID)
+	})
+	for _, abstractNum := range newAbstractNums {
+		if err := marshalNumberingElement(&abstractNums, abstractNum); err != nil {
+			return err
+		}
 	}
 
-	// 添加所有编号实例
-	for _, numInstance := range manager.numInstances {
-		numbering.NumberingInstances = append(numbering.NumberingInstances, numInstance)
+	var newInstances []*NumInstance
+	for _, instance := range manager.numInstances {
+		if !present["num:"+instance.NumID] {
+			newInstances = append(newInstances, instance)
+		}
+	}
+	sort.Slice(newInstances, func(i, j int) bool {
+		return numberingIDLess(newInstances[i].NumID, newInstances[j].NumID)
+	})
+	for _, instance := range newInstances {
+		if err := marshalNumberingElement(&nums, instance); err != nil {
+			return err
+		}
 	}
 
-	// serialize
-	numberingXML, err := xml.MarshalIndent(numbering, "", "  ")
+	var buf bytes.Buffer
+	buf.Write(data[:picEnd])
+	buf.Write(pics.Bytes())
+	buf.Write(data[picEnd:abstractEnd])
+	buf.Write(abstractNums.Bytes())
+	buf.Write(data[abstractEnd:numEnd])
+	buf.Write(nums.Bytes())
+	buf.Write(data[numEnd:])
+	d.parts["word/numbering.xml"] = buf.Bytes()
+	return nil
+}
+
+// dropNumberingElements removes elements from numbering.xml, keyed like "abstractNum:1" or "num:2",
+// so that storeNumbering writes them again from the numbering manager
+func (d *Document) dropNumberingElements(keys ...string) error {
+	drop := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		drop[key] = true
+	}
+
+	data := d.parts["word/numbering.xml"]
+	var buf bytes.Buffer
+	kept, start, depth := 0, -1, 0
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return WrapError("parse_numbering", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 {
+				id := ""
+				switch t.Name.Local {
+				case "abstractNum":
+					id = getAttributeValue(t.Attr, "abstractNumId")
+				case "num":
+					id = getAttributeValue(t.Attr, "numId")
+				}
+				if id != "" && drop[t.Name.Local+":"+id] {
+					start = offset
+				}
+			}
+		case xml.EndElement:
+			if depth == 2 && start >= 0 {
+				buf.Write(data[kept:start])
+				kept, start = int(decoder.InputOffset()), -1
+			}
+			depth--
+		}
+	}
+	buf.Write(data[kept:])
+	d.parts["word/numbering.xml"] = buf.Bytes()
+	return nil
+}
+
+// marshalNumberingElement appends the XML of a numbering element to a buffer
+func marshalNumberingElement(buf *bytes.Buffer, element interface{}) error {
+	data, err := xml.Marshal(element)
 	if err != nil {
-		return
+		return WrapError("marshal_numbering", err)
 	}
+	buf.Write(data)
+	return nil
+}
 
-	// add XML declaration
-	xmlDeclaration := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
-	d.parts["word/numbering.xml"] = append(xmlDeclaration, numberingXML...)
+// numberingIDLess orders numbering IDs numerically
+func numberingIDLess(a, b string) bool {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return x < y
 }
 
 // addNumberingRelationship 添加编号关系
@@ -408,11 +729,10 @@ func (d *Document) addNumberingRelationship() {
 func (d *Document) RestartNumbering(numID string) {
 	// 重置编号计数器
 	// 在实际实现中，需要创建新的编号实例来重置计数
-	manager := getNumberingManager()
+	manager := d.numberingManager()
 
 	// 创建新的编号实例
-	newNumID := strconv.Itoa(manager.nextNumID)
-	manager.nextNumID++
+	newNumID := manager.newNumID()
 
 	// 如果存在原有实例，复制其抽象编号引用
 	if existing, exists := manager.numInstances[numID]; exists {
@@ -426,3 +746,931 @@ func (d *Document) RestartNumbering(numID string) {
 		d.updateNumberingFile()
 	}
 }
+
+// EnableHeadingNumbering numbers the Heading1 to Heading9 styles with a multilevel list.
+//
+// format is the number format of the headings (decimal, letters or roman numerals).
+// Each level of the list is linked to its heading style, and the heading styles refer
+// back to the list, so every heading paragraph is numbered automatically ("1", "1.2", "1.2.3").
+// Levels below the first use legal numbering, showing the numbers of all levels as
+// Arabic numerals even when the first level uses letters or roman numerals.
+func (d *Document) EnableHeadingNumbering(format ListType) error {
+	numFmt := string(format)
+	switch format {
+	case ListTypeNumber:
+		numFmt = "decimal"
+	case ListTypeDecimal, ListTypeLowerLetter, ListTypeUpperLetter, ListTypeLowerRoman, ListTypeUpperRoman:
+	default:
+		return NewValidationError("format", string(format), "heading numbering requires a numbered list type")
+	}
+
+	d.ensureNumberingInitialized()
+	manager := d.numberingManager()
+
+	var headingStyles [9]*style.Style
+	for i := range headingStyles {
+		headingStyles[i] = d.headingStyle(i + 1)
+	}
+
+	// enabling the numbering again replaces the definition it created before
+	numID := ""
+	var abstractNum *AbstractNum
+	if first := headingStyles[0]; first != nil && first.ParagraphPr != nil && first.ParagraphPr.NumberingProperties != nil &&
+		first.ParagraphPr.NumberingProperties.NumID != nil {
+		if instance, ok := manager.numInstances[first.ParagraphPr.NumberingProperties.NumID.Val]; ok && instance.AbstractNumID != nil {
+			existing := manager.abstractNum(instance.AbstractNumID.Val)
+			if existing != nil && len(existing.Levels) > 0 && existing.Levels[0].PStyle != nil && existing.Levels[0].PStyle.Val == first.StyleID {
+				numID, abstractNum = instance.NumID, existing
+				instance.LevelOverrides = nil
+				if err := d.dropNumberingElements("abstractNum:"+existing.AbstractNumID, "num:"+numID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if abstractNum == nil {
+		abstractNum = &AbstractNum{AbstractNumID: manager.newAbstractNumID()}
+		manager.abstractNums["heading_"+abstractNum.AbstractNumID] = abstractNum
+		numID = manager.newNumID()
+		manager.numInstances[numID] = &NumInstance{
+			NumID:         numID,
+			AbstractNumID: &AbstractNumReference{Val: abstractNum.AbstractNumID},
+		}
+	}
+
+	abstractNum.Levels = nil
+	placeholders := make([]string, 0, 9)
+	for i := 0; i <= 8; i++ {
+		placeholders = append(placeholders, fmt.Sprintf("%%%d", i+1))
+		level := &Level{
+			ILevel:    strconv.Itoa(i),
+			Start:     &Start{Val: "1"},
+			NumFmt:    &NumFmt{Val: numFmt},
+			LevelText: &LevelText{Val: strings.Join(placeholders, ".")},
+			LevelJc:   &LevelJc{Val: "left"},
+		}
+		if headingStyles[i] != nil {
+			level.PStyle = &ParagraphStyle{Val: headingStyles[i].StyleID}
+		}
+		if i > 0 {
+			level.IsLgl = &IsLgl{}
+		}
+		abstractNum.Levels = append(abstractNum.Levels, level)
+	}
+	d.updateNumberingFile()
+
+	// link the heading styles to the list
+	for i, headingStyle := range headingStyles {
+		if headingStyle == nil {
+			continue
+		}
+		if headingStyle.ParagraphPr == nil {
+			headingStyle.ParagraphPr = &style.ParagraphProperties{}
+		}
+		headingStyle.ParagraphPr.NumberingProperties = &style.NumberingProperties{
+			ILevel: &style.ILevel{Val: strconv.Itoa(i)},
+			NumID:  &style.NumID{Val: numID},
+		}
+	}
+
+	Infof("heading numbering enabled with format %s (numId %s)", numFmt, numID)
+	return nil
+}
+
+// headingStyle returns the paragraph style of a heading level from 1 to 9, found by its built-in
+// name or else by its outline level, as documents in other languages use other style IDs. A
+// style of the document wins over the predefined HeadingN style with the same name.
+func (d *Document) headingStyle(level int) *style.Style {
+	name, predefined := fmt.Sprintf("heading %d", level), fmt.Sprintf("Heading%d", level)
+	var byName, byOutline *style.Style
+	better := func(found, s *style.Style) bool {
+		if found == nil {
+			return true
+		}
+		if (found.StyleID == predefined) != (s.StyleID == predefined) {
+			return found.StyleID == predefined
+		}
+		return s.StyleID < found.StyleID
+	}
+	for _, s := range d.styleManager.GetStylesByType(style.StyleTypeParagraph) {
+		if s.Name != nil && strings.EqualFold(s.Name.Val, name) {
+			if better(byName, s) {
+				byName = s
+			}
+		} else if s.ParagraphPr != nil && s.ParagraphPr.OutlineLevel != nil && s.ParagraphPr.OutlineLevel.Val == strconv.Itoa(level-1) &&
+			better(byOutline, s) {
+			byOutline = s
+		}
+	}
+	if byName != nil {
+		return byName
+	}
+	return byOutline
+}
+
+// numberingLevels returns the levels of the abstract numbering used by a numbering instance,
+// indexed by level number, with the level overrides of the instance applied
+func (d *Document) numberingLevels(numID string) []*Level {
+	manager := d.numberingManager()
+	instance, ok := manager.numInstances[numID]
+	if !ok || instance.AbstractNumID == nil {
+		return nil
+	}
+	abstractNum := manager.abstractNum(instance.AbstractNumID.Val)
+	if abstractNum == nil {
+		return nil
+	}
+
+	levels := make([]*Level, 9)
+	for _, level := range abstractNum.Levels {
+		if i, err := strconv.Atoi(level.ILevel); err == nil && i >= 0 && i < len(levels) {
+			levels[i] = level
+		}
+	}
+	for _, override := range instance.LevelOverrides {
+		i, err := strconv.Atoi(override.ILevel)
+		if err != nil || i < 0 || i >= len(levels) {
+			continue
+		}
+		if override.Level != nil {
+			levels[i] = override.Level
+		}
+		if override.StartOverride != nil && levels[i] != nil {
+			level := *levels[i]
+			level.Start = &Start{Val: override.StartOverride.Val}
+			levels[i] = &level
+		}
+	}
+	return levels
+}
+
+// paragraphNumbering returns the numbering instance and level of a paragraph,
+// taken from its own properties or else from its paragraph style
+func (d *Document) paragraphNumbering(p *Paragraph) (string, int, bool) {
+	if p.Properties == nil {
+		return "", 0, false
+	}
+	if numPr := p.Properties.NumberingProperties; numPr != nil && numPr.NumID != nil {
+		level := 0
+		if numPr.ILevel != nil {
+			level, _ = strconv.Atoi(numPr.ILevel.Val)
+		}
+		return numPr.NumID.Val, level, numPr.NumID.Val != "0"
+	}
+	if p.Properties.ParagraphStyle == nil || d.styleManager == nil {
+		return "", 0, false
+	}
+
+	styleID := p.Properties.ParagraphStyle.Val
+	paragraphStyle := d.styleManager.GetStyle(styleID)
+	if paragraphStyle == nil || paragraphStyle.ParagraphPr == nil {
+		return "", 0, false
+	}
+	numPr := paragraphStyle.ParagraphPr.NumberingProperties
+	if numPr == nil || numPr.NumID == nil || numPr.NumID.Val == "0" {
+		return "", 0, false
+	}
+	level := 0
+	if numPr.ILevel != nil {
+		level, _ = strconv.Atoi(numPr.ILevel.Val)
+	} else {
+		// the level is linked to the style through w:pStyle in numbering.xml
+		for i, lvl := range d.numberingLevels(numPr.NumID.Val) {
+			if lvl != nil && lvl.PStyle != nil && lvl.PStyle.Val == styleID {
+				level = i
+			}
+		}
+	}
+	return numPr.NumID.Val, level, true
+}
+
+// listLabels computes the rendered number of every numbered paragraph, counting in document order
+func (d *Document) listLabels() map[*Paragraph]string {
+	labels := make(map[*Paragraph]string)
+	counts := make(map[string][]int)
+	used := make(map[string]bool)
+
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		numID, ilvl, ok := d.paragraphNumbering(p)
+		if !ok {
+			return true
+		}
+		levels := d.numberingLevels(numID)
+		if ilvl < 0 || ilvl >= len(levels) || levels[ilvl] == nil {
+			return true
+		}
+
+		// Word keeps one count per abstract numbering, shared by the instances referring to it
+		instance := d.numberingManager().numInstances[numID]
+		key := instance.AbstractNumID.Val
+		count, exists := counts[key]
+		if !exists {
+			count = make([]int, len(levels))
+			counts[key] = count
+		}
+		// an instance with start overrides restarts the overridden levels where it is first used
+		if !used[numID] {
+			used[numID] = true
+			for _, override := range instance.LevelOverrides {
+				if i, err := strconv.Atoi(override.ILevel); err == nil && i >= 0 && i < len(count) && override.StartOverride != nil {
+					count[i] = 0
+				}
+			}
+		}
+		count[ilvl]++
+		for i := ilvl + 1; i < len(count); i++ {
+			count[i] = 0
+		}
+		labels[p] = formatListLabel(levels, count, ilvl)
+		return true
+	})
+
+	return labels
+}
+
+// formatListLabel fills in the level text of a level with the current numbers of the list
+func formatListLabel(levels []*Level, count []int, ilvl int) string {
+	level := levels[ilvl]
+	if level.PicBullet != nil {
+		return string(BulletTypeDot)
+	}
+	if level.LevelText == nil {
+		return ""
+	}
+	if level.NumFmt != nil && level.NumFmt.Val == "bullet" {
+		return level.LevelText.Val
+	}
+
+	label := level.LevelText.Val
+	for i := 0; i <= ilvl; i++ {
+		placeholder := fmt.Sprintf("%%%d", i+1)
+		if !strings.Contains(label, placeholder) {
+			continue
+		}
+
+		// a level that has not occurred yet shows its start value
+		value := count[i]
+		if value == 0 {
+			value = 1
+		}
+		numFmt := "decimal"
+		if levels[i] != nil {
+			if levels[i].Start != nil {
+				if start, err := strconv.Atoi(levels[i].Start.Val); err == nil {
+					value += start - 1
+				}
+			}
+			if levels[i].NumFmt != nil && level.IsLgl == nil {
+				numFmt = levels[i].NumFmt.Val
+			}
+		}
+		label = strings.ReplaceAll(label, placeholder, formatListNumber(value, numFmt))
+	}
+	return label
+}
+
+// formatListNumber formats a list number in a numbering format
+func formatListNumber(value int, numFmt string) string {
+	switch numFmt {
+	case "upperRoman":
+		return toRomanUpper(value)
+	case "lowerRoman":
+		return toRomanLower(value)
+	case "upperLetter", "lowerLetter":
+		if value <= 0 {
+			return strconv.Itoa(value)
+		}
+		// letters repeat after Z: A..Z, AA..ZZ, AAA..
+		letter := strings.Repeat(string(rune('A'+(value-1)%26)), (value-1)/26+1)
+		if numFmt == "lowerLetter" {
+			return strings.ToLower(letter)
+		}
+		return letter
+	case "decimalZero":
+		if value >= 0 && value < 10 {
+			return "0" + strconv.Itoa(value)
+		}
+		return strconv.Itoa(value)
+	case "ordinal":
+		return strconv.Itoa(value) + ordinalSuffix(value)
+	case "cardinalText":
+		text := cardinalText(value)
+		return strings.ToUpper(text[:1]) + text[1:]
+	case "chineseCounting":
+		return chineseCounting(value)
+	case "ideographTraditional":
+		if value <= 0 {
+			return strconv.Itoa(value)
+		}
+		return string([]rune("甲乙丙丁戊己庚辛壬癸")[(value-1)%10])
+	case "none":
+		return ""
+	default:
+		return strconv.Itoa(value)
+	}
+}
+
+// ordinalSuffix returns the English ordinal suffix of a number
+func ordinalSuffix(value int) string {
+	if value%100 >= 11 && value%100 <= 13 {
+		return "th"
+	}
+	switch value % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// cardinalText spells out a number in English words, such as "twenty-one"
+func cardinalText(value int) string {
+	ones := []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+		"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	tens := []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+	switch {
+	case value < 0:
+		return strconv.Itoa(value)
+	case value < 20:
+		return ones[value]
+	case value < 100:
+		if value%10 == 0 {
+			return tens[value/10]
+		}
+		return tens[value/10] + "-" + ones[value%10]
+	case value < 1000:
+		if value%100 == 0 {
+			return ones[value/100] + " hundred"
+		}
+		return ones[value/100] + " hundred " + cardinalText(value%100)
+	case value < 1000000:
+		if value%1000 == 0 {
+			return cardinalText(value/1000) + " thousand"
+		}
+		return cardinalText(value/1000) + " thousand " + cardinalText(value%1000)
+	}
+	return strconv.Itoa(value)
+}
+
+// chineseCounting writes a number with Chinese counting characters, such as "二十一"
+func chineseCounting(value int) string {
+	digits := []rune("〇一二三四五六七八九")
+	if value <= 0 || value >= 100000 {
+		return strconv.Itoa(value)
+	}
+	if value < 10 {
+		return string(digits[value])
+	}
+
+	units := []string{"", "十", "百", "千", "万"}
+	var sb strings.Builder
+	zero := false
+	for i := 4; i >= 0; i-- {
+		unit := 1
+		for j := 0; j < i; j++ {
+			unit *= 10
+		}
+		digit := value / unit % 10
+		switch {
+		case digit == 0:
+			zero = sb.Len() > 0
+		case digit == 1 && i == 1 && sb.Len() == 0:
+			// 10-19 are written 十, 十一 rather than 一十
+			sb.WriteString(units[i])
+			zero = false
+		default:
+			if zero {
+				sb.WriteRune(digits[0])
+				zero = false
+			}
+			sb.WriteRune(digits[digit])
+			sb.WriteString(units[i])
+		}
+	}
+	return sb.String()
+}
+
+// ListSuffix character written between a list number and the paragraph text
+type ListSuffix string
+
+const (
+	ListSuffixTab     ListSuffix = "tab"
+	ListSuffixSpace   ListSuffix = "space"
+	ListSuffixNothing ListSuffix = "nothing"
+)
+
+// ListLevelDefinition defines one level of a custom list
+type ListLevelDefinition struct {
+	Format        ListType   // number format, ListTypeBullet for bullets; decimal when empty
+	Text          string     // level text, %1 to %9 are replaced by the numbers of the levels, e.g. "%1.%2)"
+	Start         int        // first number, 1 when zero
+	Indent        float64    // left indentation in points, (level+1)*36 when zero
+	Hanging       float64    // hanging indentation in points, 18 when zero
+	Suffix        ListSuffix // character after the number, a tab when empty
+	Alignment     string     // alignment of the number: left, center or right
+	Font          string     // font of the number or bullet
+	Legal         bool       // show the numbers of all levels as Arabic numerals
+	PictureBullet []byte     // image of a picture bullet (PNG, JPEG, GIF or BMP)
+}
+
+// ListDefinition custom multilevel list definition
+type ListDefinition struct {
+	Levels []ListLevelDefinition // up to 9 levels, the first being level 0
+}
+
+// AddListDefinition adds a custom multilevel list definition and returns its numbering ID,
+// used to add items with AddDefinedListItem.
+//
+// Example:
+//
+//	numID, err := doc.AddListDefinition(&document.ListDefinition{
+//		Levels: []document.ListLevelDefinition{
+//			{Format: document.ListTypeDecimal, Text: "%1."},
+//			{Format: document.ListTypeLowerLetter, Text: "%1.%2)", Suffix: document.ListSuffixSpace},
+//		},
+//	})
+func (d *Document) AddListDefinition(def *ListDefinition) (string, error) {
+	if def == nil || len(def.Levels) == 0 || len(def.Levels) > 9 {
+		return "", NewValidationError("levels", "", "a list definition needs 1 to 9 levels")
+	}
+	for i, levelDef := range def.Levels {
+		if err := validateListLevel(i, levelDef); err != nil {
+			return "", err
+		}
+	}
+
+	d.ensureNumberingInitialized()
+	manager := d.numberingManager()
+
+	abstractNum := &AbstractNum{AbstractNumID: manager.newAbstractNumID()}
+	for i, levelDef := range def.Levels {
+		level, err := d.createDefinedLevel(i, levelDef)
+		if err != nil {
+			return "", WrapError("add_list_definition", err)
+		}
+		abstractNum.Levels = append(abstractNum.Levels, level)
+	}
+	manager.abstractNums["custom_"+abstractNum.AbstractNumID] = abstractNum
+
+	numID := manager.newNumID()
+	manager.numInstances[numID] = &NumInstance{
+		NumID:         numID,
+		AbstractNumID: &AbstractNumReference{Val: abstractNum.AbstractNumID},
+	}
+	d.updateNumberingFile()
+
+	Infof("list definition added with %d levels (numId %s)", len(def.Levels), numID)
+	return numID, nil
+}
+
+// AddDefinedListItem adds a list item numbered by a list definition at a level (0-8)
+func (d *Document) AddDefinedListItem(text string, numID string, level int) (*Paragraph, error) {
+	levels := d.numberingLevels(numID)
+	if levels == nil {
+		return nil, NewValidationError("numID", numID, "numbering instance not found")
+	}
+	if level < 0 || level >= len(levels) || levels[level] == nil {
+		return nil, NewValidationError("level", strconv.Itoa(level), "level not defined by the list")
+	}
+
+	paragraph := &Paragraph{
+		Properties: &ParagraphProperties{
+			NumberingProperties: &NumberingProperties{
+				ILevel: &ILevel{Val: strconv.Itoa(level)},
+				NumID:  &NumID{Val: numID},
+			},
+		},
+	}
+	if text != "" {
+		paragraph.Runs = append(paragraph.Runs, Run{Text: Text{Content: text}})
+	}
+	d.Body.Elements = append(d.Body.Elements, paragraph)
+	return paragraph, nil
+}
+
+// ListLabel returns the number or bullet Word renders in front of a list paragraph, such as "2.3.a",
+// counting the list paragraphs before it. Picture bullets are returned as "•".
+// It returns an empty string when the paragraph is not numbered. Each call counts the list
+// paragraphs of the whole document, ListInfos returns the labels of all paragraphs at once.
+func (d *Document) ListLabel(para *Paragraph) string {
+	if para == nil {
+		return ""
+	}
+	return d.listLabels()[para]
+}
+
+// validateListLevel checks one level of a list definition
+func validateListLevel(index int, def ListLevelDefinition) error {
+	field := fmt.Sprintf("levels[%d]", index)
+	switch def.Format {
+	case "", ListTypeBullet, ListTypeNumber, ListTypeDecimal, ListTypeLowerLetter, ListTypeUpperLetter,
+		ListTypeLowerRoman, ListTypeUpperRoman, ListTypeDecimalZero, ListTypeOrdinal, ListTypeCardinalText,
+		ListTypeChineseCounting, ListTypeIdeographTraditional, ListTypeNone:
+	default:
+		return NewValidationError(field+".format", string(def.Format), "unsupported number format")
+	}
+	switch def.Suffix {
+	case "", ListSuffixTab, ListSuffixSpace, ListSuffixNothing:
+	default:
+		return NewValidationError(field+".suffix", string(def.Suffix), "suffix must be tab, space or nothing")
+	}
+	switch def.Alignment {
+	case "", "left", "center", "right":
+	default:
+		return NewValidationError(field+".alignment", def.Alignment, "alignment must be left, center or right")
+	}
+	for i := index + 2; i <= 9; i++ {
+		if strings.Contains(def.Text, fmt.Sprintf("%%%d", i)) {
+			return NewValidationError(field+".text", def.Text, "level text can only refer to this level and the levels above it")
+		}
+	}
+	if def.Start < 0 || def.Indent < 0 || def.Hanging < 0 {
+		return NewValidationError(field, "", "start and indentation cannot be negative")
+	}
+	if def.PictureBullet != nil {
+		format, err := detectImageFormat(def.PictureBullet)
+		if err != nil || (format != ImageFormatPNG && format != ImageFormatJPEG && format != ImageFormatGIF && format != ImageFormatBMP) {
+			return NewValidationError(field+".pictureBullet", "", "picture bullets must be PNG, JPEG, GIF or BMP images")
+		}
+	}
+	return nil
+}
+
+// createDefinedLevel creates a numbering level from a list level definition
+func (d *Document) createDefinedLevel(index int, def ListLevelDefinition) (*Level, error) {
+	numFmt := string(def.Format)
+	switch def.Format {
+	case "", ListTypeNumber:
+		numFmt = "decimal"
+	}
+	start := def.Start
+	if start == 0 {
+		start = 1
+	}
+	indent, hanging := def.Indent, def.Hanging
+	if indent == 0 {
+		indent = float64((index + 1) * 36)
+	}
+	if hanging == 0 {
+		hanging = 18
+	}
+	alignment := def.Alignment
+	if alignment == "" {
+		alignment = "left"
+	}
+	text := def.Text
+	if text == "" && def.PictureBullet == nil {
+		switch def.Format {
+		case ListTypeBullet:
+			text = string(BulletTypeDot)
+		case ListTypeNone:
+		default:
+			text = fmt.Sprintf("%%%d.", index+1)
+		}
+	}
+
+	level := &Level{
+		ILevel:    strconv.Itoa(index),
+		Start:     &Start{Val: strconv.Itoa(start)},
+		NumFmt:    &NumFmt{Val: numFmt},
+		LevelText: &LevelText{Val: text},
+		LevelJc:   &LevelJc{Val: alignment},
+		PPr: &LevelPPr{
+			Ind: &LevelIndent{
+				Left:    strconv.Itoa(int(indent * 20)),
+				Hanging: strconv.Itoa(int(hanging * 20)),
+			},
+		},
+	}
+	if def.Legal {
+		level.IsLgl = &IsLgl{}
+	}
+	if def.Suffix != "" && def.Suffix != ListSuffixTab {
+		level.Suffix = &LevelSuffix{Val: string(def.Suffix)}
+	}
+	if def.Font != "" {
+		level.RPr = &LevelRPr{
+			FontFamily: &FontFamily{ASCII: def.Font, HAnsi: def.Font, CS: def.Font, Hint: "default"},
+		}
+	}
+	if def.PictureBullet != nil {
+		id, err := d.addPictureBullet(def.PictureBullet)
+		if err != nil {
+			return nil, err
+		}
+		level.NumFmt = &NumFmt{Val: "bullet"}
+		level.PicBullet = &LevelPicBullet{Val: id}
+	}
+	return level, nil
+}
+
+// addPictureBullet stores the image of a picture bullet and returns the ID of its definition
+func (d *Document) addPictureBullet(data []byte) (string, error) {
+	format, err := detectImageFormat(data)
+	if err != nil {
+		return "", err
+	}
+	media := d.storeMediaPart(data, format, "bullet")
+	relationID, err := d.addPartImageRelationship("word/numbering.xml", media)
+	if err != nil {
+		return "", err
+	}
+
+	manager := d.numberingManager()
+	next := 0
+	for _, picBullet := range manager.picBullets {
+		if n, err := strconv.Atoi(picBullet.ID); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	id := strconv.Itoa(next)
+	manager.picBullets = append(manager.picBullets, &NumPicBullet{
+		ID: id,
+		Pict: &picBulletPict{
+			Shape: &picBulletShape{
+				ID:        fmt.Sprintf("_x0000_i%d", 1025+len(manager.picBullets)),
+				Type:      "#_x0000_t75",
+				Style:     "width:9pt;height:9pt",
+				Bullet:    "t",
+				ImageData: &picBulletImageData{ID: relationID},
+			},
+		},
+	})
+	return id, nil
+}
+
+// ListInfo describes the list a paragraph belongs to
+type ListInfo struct {
+	NumID         string          // numbering instance used by the paragraph or its style
+	AbstractNumID string          // abstract numbering of the instance
+	Level         int             // list level of the paragraph (0-8)
+	Label         string          // number or bullet rendered in front of the paragraph
+	Definition    *ListDefinition // levels of the list, with the overrides of the instance applied
+}
+
+// ListInfo returns the list a paragraph belongs to, or nil when the paragraph is not numbered.
+// Paragraphs numbered through their paragraph style, such as numbered headings, are included.
+// It is a method of Document because a paragraph does not refer to the document holding its
+// numbering definitions.
+func (d *Document) ListInfo(para *Paragraph) *ListInfo {
+	if para == nil {
+		return nil
+	}
+	return d.listInfo(para, d.ListLabel(para))
+}
+
+// ListInfos returns the list information of every numbered paragraph of the body, computing the
+// labels of the document once. Use it rather than ListInfo when going through all paragraphs.
+func (d *Document) ListInfos() map[*Paragraph]*ListInfo {
+	labels := d.listLabels()
+	infos := make(map[*Paragraph]*ListInfo, len(labels))
+	for para, label := range labels {
+		if info := d.listInfo(para, label); info != nil {
+			infos[para] = info
+		}
+	}
+	return infos
+}
+
+// listInfo describes the list of a paragraph with the given label
+func (d *Document) listInfo(para *Paragraph, label string) *ListInfo {
+	numID, level, ok := d.paragraphNumbering(para)
+	if !ok {
+		return nil
+	}
+	levels := d.numberingLevels(numID)
+	if levels == nil {
+		return nil
+	}
+
+	info := &ListInfo{
+		NumID:         numID,
+		AbstractNumID: d.numberingManager().numInstances[numID].AbstractNumID.Val,
+		Level:         level,
+		Label:         label,
+		Definition:    &ListDefinition{},
+	}
+	last := -1
+	for i, lvl := range levels {
+		if lvl != nil {
+			last = i
+		}
+	}
+	for _, lvl := range levels[:last+1] {
+		info.Definition.Levels = append(info.Definition.Levels, d.listLevelDefinition(lvl))
+	}
+	return info
+}
+
+// ContinueList inserts an empty list item right after afterPara, numbered as the next item of its list.
+// Text is added to the returned paragraph with AddFormattedText. This is the way to append items to a
+// list of an opened document without restarting its numbering. Lists in table cells cannot be
+// continued, as inserting a paragraph in a cell would move the paragraphs the caller refers to.
+func (d *Document) ContinueList(afterPara *Paragraph) (*Paragraph, error) {
+	if afterPara == nil {
+		return nil, NewValidationError("afterPara", "", "paragraph is required")
+	}
+	numID, level, ok := d.paragraphNumbering(afterPara)
+	if !ok {
+		return nil, NewValidationError("afterPara", "", "paragraph is not a list item")
+	}
+
+	paragraph := &Paragraph{Properties: &ParagraphProperties{}}
+	if afterPara.Properties.ParagraphStyle != nil {
+		paragraph.Properties.ParagraphStyle = &ParagraphStyle{Val: afterPara.Properties.ParagraphStyle.Val}
+	}
+	if afterPara.Properties.NumberingProperties != nil {
+		paragraph.Properties.NumberingProperties = &NumberingProperties{
+			ILevel: &ILevel{Val: strconv.Itoa(level)},
+			NumID:  &NumID{Val: numID},
+		}
+	}
+
+	if cellParagraph(d.Body.Elements, afterPara) {
+		return nil, NewValidationError("afterPara", "", "lists in table cells cannot be continued")
+	}
+
+	var inserted *Paragraph
+	d.Body.Elements, inserted = insertParagraphAfter(d.Body.Elements, afterPara, paragraph)
+	if inserted == nil {
+		return nil, NewValidationError("afterPara", "", "paragraph not found in the document")
+	}
+	return inserted, nil
+}
+
+// RestartListAt restarts the numbering of a list at para, which is numbered n.
+// A new numbering instance of the same list with a start override (w:lvlOverride/w:startOverride)
+// is used by para and by the following paragraphs of the list. Paragraphs numbered through their
+// style, such as numbered headings, are given a w:numPr referring to the new instance.
+func (d *Document) RestartListAt(para *Paragraph, n int) error {
+	if para == nil {
+		return NewValidationError("para", "", "paragraph is required")
+	}
+	if n < 0 {
+		return NewValidationError("n", strconv.Itoa(n), "start value cannot be negative")
+	}
+	numID, level, ok := d.paragraphNumbering(para)
+	if !ok || d.numberingLevels(numID) == nil {
+		return NewValidationError("para", "", "paragraph is not a list item")
+	}
+
+	manager := d.numberingManager()
+	instance := manager.numInstances[numID]
+	restarted := &NumInstance{
+		NumID:         manager.newNumID(),
+		AbstractNumID: &AbstractNumReference{Val: instance.AbstractNumID.Val},
+	}
+	// level definitions overridden by the instance still apply, its start overrides do not
+	for _, override := range instance.LevelOverrides {
+		if override.Level != nil && override.ILevel != strconv.Itoa(level) {
+			restarted.LevelOverrides = append(restarted.LevelOverrides, &LevelOverride{ILevel: override.ILevel, Level: override.Level})
+		}
+	}
+	restarted.LevelOverrides = append(restarted.LevelOverrides, &LevelOverride{
+		ILevel:        strconv.Itoa(level),
+		StartOverride: &StartOverride{Val: strconv.Itoa(n)},
+	})
+	manager.numInstances[restarted.NumID] = restarted
+
+	// paragraphs numbered through their style get the restarted instance of their own
+	found := false
+	walkParagraphs(d.Body.Elements, func(p *Paragraph) bool {
+		found = found || p == para
+		if !found {
+			return true
+		}
+		if pNumID, pLevel, ok := d.paragraphNumbering(p); ok && pNumID == numID {
+			if numPr := p.Properties.NumberingProperties; numPr != nil && numPr.NumID != nil {
+				numPr.NumID.Val = restarted.NumID
+			} else {
+				p.Properties.NumberingProperties = &NumberingProperties{
+					ILevel: &ILevel{Val: strconv.Itoa(pLevel)},
+					NumID:  &NumID{Val: restarted.NumID},
+				}
+			}
+		}
+		return true
+	})
+	d.updateNumberingFile()
+
+	Infof("list %s restarted at %d (numId %s)", numID, n, restarted.NumID)
+	return nil
+}
+
+// listLevelDefinition describes a numbering level as a list level definition
+func (d *Document) listLevelDefinition(level *Level) ListLevelDefinition {
+	var def ListLevelDefinition
+	if level == nil {
+		return def
+	}
+	if level.NumFmt != nil {
+		def.Format = ListType(level.NumFmt.Val)
+	}
+	if level.LevelText != nil {
+		def.Text = level.LevelText.Val
+	}
+	if level.Start != nil {
+		def.Start, _ = strconv.Atoi(level.Start.Val)
+	}
+	if level.PPr != nil && level.PPr.Ind != nil {
+		def.Indent = parseFloat(level.PPr.Ind.Left) / 20
+		def.Hanging = parseFloat(level.PPr.Ind.Hanging) / 20
+	}
+	if level.Suffix != nil {
+		def.Suffix = ListSuffix(level.Suffix.Val)
+	}
+	if level.LevelJc != nil {
+		def.Alignment = level.LevelJc.Val
+	}
+	if level.RPr != nil && level.RPr.FontFamily != nil {
+		def.Font = level.RPr.FontFamily.ASCII
+	}
+	def.Legal = level.IsLgl != nil
+	if level.PicBullet != nil {
+		def.PictureBullet = d.pictureBulletData(level.PicBullet.Val)
+	}
+	return def
+}
+
+// pictureBulletData returns the image of a picture bullet
+func (d *Document) pictureBulletData(id string) []byte {
+	for _, picBullet := range d.numberingManager().picBullets {
+		if picBullet.ID != id || picBullet.Pict == nil || picBullet.Pict.Shape == nil || picBullet.Pict.Shape.ImageData == nil {
+			continue
+		}
+		rels, err := d.partRelationships("word/numbering.xml")
+		if err != nil {
+			return nil
+		}
+		for _, rel := range rels.Relationships {
+			if rel.ID == picBullet.Pict.Shape.ImageData.ID {
+				return d.parts[resolvePartTarget("word/numbering.xml", rel.Target)]
+			}
+		}
+	}
+	return nil
+}
+
+// insertParagraphAfter inserts p after the paragraph after, looking into content controls,
+// and returns the inserted paragraph, nil when after was not found
+func insertParagraphAfter(elements []interface{}, after, p *Paragraph) ([]interface{}, *Paragraph) {
+	for i, element := range elements {
+		switch e := element.(type) {
+		case *Paragraph:
+			if e == after {
+				elements = append(elements[:i+1], append([]interface{}{p}, elements[i+1:]...)...)
+				return elements, p
+			}
+		case *SDT:
+			if e.Content == nil {
+				continue
+			}
+			var inserted *Paragraph
+			if e.Content.Elements, inserted = insertParagraphAfter(e.Content.Elements, after, p); inserted != nil {
+				return elements, inserted
+			}
+		}
+	}
+	return elements, nil
+}
+
+// cellParagraph reports whether p is a paragraph of a table cell, looking into content controls
+func cellParagraph(elements []interface{}, p *Paragraph) bool {
+	for _, element := range elements {
+		switch e := element.(type) {
+		case *Table:
+			if tableParagraph(e, p) {
+				return true
+			}
+		case *SDT:
+			if e.Content != nil && cellParagraph(e.Content.Elements, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tableParagraph reports whether p is a paragraph of the cells of a table or of its nested tables
+func tableParagraph(table *Table, p *Paragraph) bool {
+	for r := range table.Rows {
+		for c := range table.Rows[r].Cells {
+			cell := &table.Rows[r].Cells[c]
+			for k := range cell.Paragraphs {
+				if &cell.Paragraphs[k] == p {
+					return true
+				}
+			}
+			for t := range cell.Tables {
+				if tableParagraph(&cell.Tables[t], p) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,457 @@
+package document
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestEnableHeadingNumbering tests heading styles linked to a legal multilevel list
+func TestEnableHeadingNumbering(t *testing.T) {
+	doc := New()
+	if err := doc.EnableHeadingNumbering(ListTypeUpperRoman); err != nil {
+		t.Fatalf("failed to enable heading numbering: %v", err)
+	}
+	doc.AddHeadingParagraph("Introduction", 1)
+	doc.AddHeadingParagraph("Scope", 2)
+	doc.AddHeadingParagraph("Terms", 2)
+	doc.AddParagraph("Body text")
+	doc.AddHeadingParagraph("Units", 3)
+	doc.AddHeadingParagraph("Design", 1)
+	doc.AddHeadingParagraph("Layout", 2)
+
+	expected := []string{"I Introduction", "1.1 Scope", "1.2 Terms", "1.2.1 Units", "II Design", "2.1 Layout"}
+	var got []string
+	for _, entry := range doc.ListHeadings() {
+		got = append(got, entry.displayText())
+	}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected headings\n got: %q\nwant: %q", got, expected)
+	}
+
+	numPr := doc.styleManager.GetStyle("Heading2").ParagraphPr.NumberingProperties
+	if numPr == nil || numPr.ILevel.Val != "1" {
+		t.Fatal("Heading2 should be linked to the second list level")
+	}
+	numbering := string(doc.parts["word/numbering.xml"])
+	for _, expected := range []string{`<w:pStyle w:val="Heading2"></w:pStyle>`, `<w:isLgl></w:isLgl>`, `<w:lvlText w:val="%1.%2.%3"></w:lvlText>`} {
+		if !strings.Contains(numbering, expected) {
+			t.Errorf("numbering.xml should contain %s", expected)
+		}
+	}
+
+	filename := "test_heading_numbering.docx"
+	defer os.Remove(filename)
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	if styles := string(doc.parts["word/styles.xml"]); !strings.Contains(styles, `<w:numId w:val="`+numPr.NumID.Val+`">`) {
+		t.Error("styles.xml should link the heading styles to the list")
+	}
+
+	if err := doc.EnableHeadingNumbering(ListTypeBullet); err == nil {
+		t.Error("expected error for bullet heading numbering")
+	}
+}
+
+// TestEnableHeadingNumberingAgain tests that enabling the numbering again reuses its definition
+func TestEnableHeadingNumberingAgain(t *testing.T) {
+	doc := New()
+	if err := doc.EnableHeadingNumbering(ListTypeUpperRoman); err != nil {
+		t.Fatalf("failed to enable heading numbering: %v", err)
+	}
+	manager := doc.numberingManager()
+	abstractNums, instances := len(manager.abstractNums), len(manager.numInstances)
+	if err := doc.EnableHeadingNumbering(ListTypeDecimal); err != nil {
+		t.Fatalf("failed to enable heading numbering: %v", err)
+	}
+	if len(manager.abstractNums) != abstractNums || len(manager.numInstances) != instances {
+		t.Error("the heading numbering definition should be replaced, not added")
+	}
+	numbering := string(doc.parts["word/numbering.xml"])
+	if strings.Contains(numbering, "upperRoman") || strings.Count(numbering, "<w:abstractNum ") != abstractNums {
+		t.Error("numbering.xml should hold the replaced heading numbering definition")
+	}
+	doc.AddHeadingParagraph("Introduction", 1)
+	if headings := doc.ListHeadings(); len(headings) != 1 || headings[0].displayText() != "1 Introduction" {
+		t.Errorf("the headings should use the new format, got %q", headings[0].displayText())
+	}
+}
+
+// TestEnableHeadingNumberingSaved tests that the heading styles of an opened document are saved with their numbering
+func TestEnableHeadingNumberingSaved(t *testing.T) {
+	filename := "test_heading_numbering_saved.docx"
+	defer os.Remove(filename)
+
+	doc := newDocumentWithStyles(t, testStylesXML)
+	if err := doc.EnableHeadingNumbering(ListTypeDecimal); err != nil {
+		t.Fatalf("failed to enable heading numbering: %v", err)
+	}
+	numID := doc.styleManager.GetStyle("Heading1").ParagraphPr.NumberingProperties.NumID.Val
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	reopened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	compact := regexp.MustCompile(`>\s+<`).ReplaceAllString(string(reopened.parts["word/styles.xml"]), "><")
+	start := strings.Index(compact, `w:styleId="Heading1"`)
+	if start < 0 {
+		t.Fatal("Heading1 should be saved")
+	}
+	end := strings.Index(compact[start:], "</w:style>")
+	if end < 0 || !strings.Contains(compact[start:start+end], `<w:numPr><w:ilvl w:val="0"></w:ilvl><w:numId w:val="`+numID+`"></w:numId></w:numPr>`) {
+		t.Errorf("Heading1 should be saved with its numbering: %s", compact)
+	}
+	heading := reopened.AddHeadingParagraph("Introduction", 1)
+	if label := reopened.ListLabel(heading); label != "1" {
+		t.Errorf("headings of the reopened document should be numbered, got %q", label)
+	}
+}
+
+// TestEnableHeadingNumberingLocalizedStyles tests heading styles with other IDs
+func TestEnableHeadingNumberingLocalizedStyles(t *testing.T) {
+	doc := newDocumentWithStyles(t, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:styleId="Titre1"><w:name w:val="heading 1"/><w:pPr><w:outlineLvl w:val="0"/></w:pPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Kop2"><w:name w:val="heading 2"/><w:pPr><w:outlineLvl w:val="1"/></w:pPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Niveau3"><w:name w:val="Niveau 3"/><w:pPr><w:outlineLvl w:val="2"/></w:pPr></w:style>
+</w:styles>`)
+	// without a style named "heading 3" the style with outline level 2 is used
+	doc.styleManager.RemoveStyle("Heading3")
+	if err := doc.EnableHeadingNumbering(ListTypeDecimal); err != nil {
+		t.Fatalf("failed to enable heading numbering: %v", err)
+	}
+	for id, level := range map[string]string{"Titre1": "0", "Kop2": "1", "Niveau3": "2"} {
+		s := doc.styleManager.GetStyle(id)
+		if s.ParagraphPr.NumberingProperties == nil || s.ParagraphPr.NumberingProperties.ILevel.Val != level {
+			t.Errorf("%s should be linked to list level %s", id, level)
+		}
+	}
+	numbering := string(doc.parts["word/numbering.xml"])
+	if !strings.Contains(numbering, `<w:pStyle w:val="Titre1">`) || !strings.Contains(numbering, `<w:pStyle w:val="Kop2">`) ||
+		!strings.Contains(numbering, `<w:pStyle w:val="Niveau3">`) {
+		t.Error("the list levels should be linked to the heading styles")
+	}
+}
+
+// TestFormatListNumber tests the supported number formats
+func TestFormatListNumber(t *testing.T) {
+	tests := []struct {
+		value    int
+		numFmt   string
+		expected string
+	}{
+		{3, "decimal", "3"},
+		{4, "upperRoman", "IV"},
+		{9, "lowerRoman", "ix"},
+		{2, "upperLetter", "B"},
+		{28, "lowerLetter", "bb"},
+		{5, "none", ""},
+		{7, "decimalZero", "07"},
+		{12, "ordinal", "12th"},
+		{22, "ordinal", "22nd"},
+		{21, "cardinalText", "Twenty-one"},
+		{105, "cardinalText", "One hundred five"},
+		{3, "chineseCounting", "三"},
+		{15, "chineseCounting", "十五"},
+		{101, "chineseCounting", "一百〇一"},
+		{11, "ideographTraditional", "甲"},
+	}
+	for _, tt := range tests {
+		if got := formatListNumber(tt.value, tt.numFmt); got != tt.expected {
+			t.Errorf("formatListNumber(%d, %s) = %q, want %q", tt.value, tt.numFmt, got, tt.expected)
+		}
+	}
+}
+
+// TestAddListDefinition tests custom levels, picture bullets and computed list labels
+func TestAddListDefinition(t *testing.T) {
+	doc := New()
+	numID, err := doc.AddListDefinition(&ListDefinition{
+		Levels: []ListLevelDefinition{
+			{Format: ListTypeDecimal, Start: 2},
+			{Format: ListTypeDecimal, Text: "%1.%2", Legal: true},
+			{Format: ListTypeLowerLetter, Text: "%1.%2.%3)", Suffix: ListSuffixSpace, Indent: 54, Hanging: 27, Font: "Arial"},
+			{Format: ListTypeChineseCounting, Text: "第%4章", Suffix: ListSuffixNothing},
+			{PictureBullet: createTestImage(16, 16)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to add list definition: %v", err)
+	}
+
+	items := []struct {
+		text  string
+		level int
+		label string
+	}{
+		{"Scope", 0, "2."},
+		{"Terms", 1, "2.1"},
+		{"Units", 2, "2.1.a)"},
+		{"Symbols", 2, "2.1.b)"},
+		{"Chapter", 3, "第一章"},
+		{"Picture", 4, "•"},
+		{"Design", 0, "3."},
+		{"Layout", 2, "3.1.a)"},
+	}
+	var paragraphs []*Paragraph
+	for _, item := range items {
+		p, err := doc.AddDefinedListItem(item.text, numID, item.level)
+		if err != nil {
+			t.Fatalf("failed to add list item: %v", err)
+		}
+		paragraphs = append(paragraphs, p)
+	}
+	for i, item := range items {
+		if label := doc.ListLabel(paragraphs[i]); label != item.label {
+			t.Errorf("%s: expected label %q, got %q", item.text, item.label, label)
+		}
+	}
+	if label := doc.ListLabel(doc.AddParagraph("plain")); label != "" {
+		t.Errorf("a paragraph outside lists should have no label, got %q", label)
+	}
+	// instances of the same abstract numbering share their count
+	first := doc.AddNumberedList("first", 0, ListTypeUpperRoman)
+	second := doc.AddNumberedList("second", 0, ListTypeUpperRoman)
+	if doc.ListLabel(first) != "I." || doc.ListLabel(second) != "II." {
+		t.Errorf("unexpected labels %q and %q", doc.ListLabel(first), doc.ListLabel(second))
+	}
+
+	numbering := string(doc.parts["word/numbering.xml"])
+	for _, expected := range []string{
+		`<w:suff w:val="space"></w:suff>`,
+		`<w:ind w:left="1080" w:hanging="540"></w:ind>`,
+		`<w:rFonts w:ascii="Arial" w:hAnsi="Arial" w:cs="Arial" w:hint="default"></w:rFonts>`,
+		`<w:numPicBullet w:numPicBulletId="0">`,
+		`<w:lvlPicBulletId w:val="0"></w:lvlPicBulletId>`,
+		`xmlns:v="urn:schemas-microsoft-com:vml"`,
+	} {
+		if !strings.Contains(numbering, expected) {
+			t.Errorf("numbering.xml should contain %s", expected)
+		}
+	}
+	if !strings.Contains(string(doc.parts["word/_rels/numbering.xml.rels"]), `Target="media/bullet.png"`) {
+		t.Error("the picture bullet should be related to numbering.xml")
+	}
+
+	filename := "test_list_definition.docx"
+	defer os.Remove(filename)
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	if _, err := Open(filename); err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+
+	invalid := []*ListDefinition{
+		nil,
+		{},
+		{Levels: []ListLevelDefinition{{Format: "kanji"}}},
+		{Levels: []ListLevelDefinition{{Text: "%1.%2"}}},
+		{Levels: []ListLevelDefinition{{Suffix: "dash"}}},
+		{Levels: []ListLevelDefinition{{PictureBullet: []byte("not an image")}}},
+	}
+	for i, def := range invalid {
+		if _, err := doc.AddListDefinition(def); err == nil {
+			t.Errorf("expected error for invalid definition %d", i)
+		}
+	}
+	if _, err := doc.AddDefinedListItem("x", "999", 0); err == nil {
+		t.Error("expected error for an unknown numbering instance")
+	}
+	if _, err := doc.AddDefinedListItem("x", numID, 5); err == nil {
+		t.Error("expected error for an undefined level")
+	}
+}
+
+// templateNumberingXML is the numbering part of a customer template, with elements the numbering model does not know
+const templateNumberingXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w15="http://schemas.microsoft.com/office/word/2012/wordml">` +
+	`<w:abstractNum w:abstractNumId="3" w15:restartNumberingAfterBreak="0"><w:nsid w:val="1A2B3C4D"/><w:multiLevelType w:val="multilevel"/>` +
+	`<w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="360" w:hanging="360"/></w:pPr></w:lvl>` +
+	`<w:lvl w:ilvl="1"><w:start w:val="1"/><w:numFmt w:val="lowerLetter"/><w:lvlText w:val="%1.%2"/><w:lvlJc w:val="left"/></w:lvl>` +
+	`</w:abstractNum><w:num w:numId="1"><w:abstractNumId w:val="3"/></w:num><w:numIdMacAtCleanup w:val="1"/></w:numbering>`
+
+// openListTemplate saves and opens a document whose list uses the template numbering
+func openListTemplate(t *testing.T, filename string) *Document {
+	doc := New()
+	doc.ensureNumberingInitialized()
+	doc.parts["word/numbering.xml"] = []byte(templateNumberingXML)
+	for _, item := range []struct {
+		text  string
+		level string
+	}{{"Alpha", "0"}, {"Beta", "1"}, {"Gamma", "0"}} {
+		p := doc.AddParagraph(item.text)
+		p.Properties = &ParagraphProperties{
+			NumberingProperties: &NumberingProperties{ILevel: &ILevel{Val: item.level}, NumID: &NumID{Val: "1"}},
+		}
+	}
+	doc.AddParagraph("Closing remarks")
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save template: %v", err)
+	}
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open template: %v", err)
+	}
+	return opened
+}
+
+// TestListInfo tests reading the numbering of an opened document
+func TestListInfo(t *testing.T) {
+	filename := "test_list_info.docx"
+	defer os.Remove(filename)
+	doc := openListTemplate(t, filename)
+	paragraphs := doc.Body.GetParagraphs()
+
+	info := doc.ListInfo(paragraphs[1])
+	if info == nil || info.NumID != "1" || info.AbstractNumID != "3" || info.Level != 1 || info.Label != "1.a" {
+		t.Fatalf("unexpected list info %+v", info)
+	}
+	if levels := info.Definition.Levels; len(levels) != 2 || levels[0].Indent != 18 || levels[1].Format != ListTypeLowerLetter || levels[1].Text != "%1.%2" {
+		t.Errorf("unexpected list definition %+v", info.Definition)
+	}
+	if doc.ListInfo(paragraphs[3]) != nil {
+		t.Error("a plain paragraph should not be a list item")
+	}
+	infos := doc.ListInfos()
+	for _, p := range paragraphs {
+		if expected, got := doc.ListInfo(p), infos[p]; (expected == nil) != (got == nil) || (got != nil && got.Label != expected.Label) {
+			t.Errorf("ListInfos should match ListInfo, got %+v, expected %+v", got, expected)
+		}
+	}
+
+	item := doc.AddNumberedList("Other list", 0, ListTypeDecimal)
+	if numID := item.Properties.NumberingProperties.NumID.Val; numID == "1" {
+		t.Error("a new list should not reuse the numbering instance of the template")
+	}
+	if label := doc.ListLabel(item); label != "1." {
+		t.Errorf("a new list should start at 1, got %q", label)
+	}
+}
+
+// TestContinueAndRestartList tests appending items to a template list and restarting it
+func TestContinueAndRestartList(t *testing.T) {
+	filename := "test_continue_list.docx"
+	defer os.Remove(filename)
+	doc := openListTemplate(t, filename)
+	paragraphs := doc.Body.GetParagraphs()
+	gamma := paragraphs[2]
+
+	delta, err := doc.ContinueList(gamma)
+	if err != nil {
+		t.Fatalf("failed to continue list: %v", err)
+	}
+	delta.AddFormattedText("Delta", nil)
+	if paragraphs = doc.Body.GetParagraphs(); paragraphs[3] != delta {
+		t.Fatal("the new item should follow the paragraph it continues")
+	}
+	if label := doc.ListLabel(delta); label != "3." {
+		t.Errorf("the new item should continue the list, got %q", label)
+	}
+
+	if err := doc.RestartListAt(gamma, 5); err != nil {
+		t.Fatalf("failed to restart list: %v", err)
+	}
+	if doc.ListLabel(paragraphs[0]) != "1." || doc.ListLabel(gamma) != "5." || doc.ListLabel(delta) != "6." {
+		t.Errorf("unexpected labels %q, %q, %q", doc.ListLabel(paragraphs[0]), doc.ListLabel(gamma), doc.ListLabel(delta))
+	}
+
+	numbering := string(doc.parts["word/numbering.xml"])
+	for _, expected := range []string{
+		`<w:nsid w:val="1A2B3C4D"/>`,
+		`w15:restartNumberingAfterBreak="0"`,
+		`<w:startOverride w:val="5"></w:startOverride></w:lvlOverride></w:num><w:numIdMacAtCleanup w:val="1"/>`,
+	} {
+		if !strings.Contains(numbering, expected) {
+			t.Errorf("numbering.xml should contain %s", expected)
+		}
+	}
+
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	reopened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	paragraphs = reopened.Body.GetParagraphs()
+	if reopened.ListLabel(paragraphs[2]) != "5." || reopened.ListLabel(paragraphs[3]) != "6." {
+		t.Error("the restarted numbering should be read back")
+	}
+
+	if _, err := doc.ContinueList(paragraphs[4]); err == nil {
+		t.Error("expected error for a paragraph outside lists")
+	}
+	if err := doc.RestartListAt(gamma, -1); err == nil {
+		t.Error("expected error for a negative start value")
+	}
+}
+
+// TestRestartHeadingNumbering tests restarting a list numbered through the heading styles
+func TestRestartHeadingNumbering(t *testing.T) {
+	doc := New()
+	if err := doc.EnableHeadingNumbering(ListTypeDecimal); err != nil {
+		t.Fatalf("failed to enable heading numbering: %v", err)
+	}
+	first := doc.AddHeadingParagraph("First", 1)
+	second := doc.AddHeadingParagraph("Second", 1)
+	section := doc.AddHeadingParagraph("Section", 2)
+	third := doc.AddHeadingParagraph("Third", 1)
+
+	if err := doc.RestartListAt(second, 5); err != nil {
+		t.Fatalf("failed to restart list: %v", err)
+	}
+	for p, expected := range map[*Paragraph]string{first: "1", second: "5", section: "5.1", third: "6"} {
+		if label := doc.ListLabel(p); label != expected {
+			t.Errorf("expected label %q, got %q", expected, label)
+		}
+	}
+	if first.Properties.NumberingProperties != nil {
+		t.Error("headings before the restart should keep their style numbering")
+	}
+	if numPr := section.Properties.NumberingProperties; numPr == nil || numPr.ILevel.Val != "1" {
+		t.Error("headings after the restart should keep their level")
+	}
+}
+
+// TestContinueListInTableCell tests that lists in table cells are not continued
+func TestContinueListInTableCell(t *testing.T) {
+	doc := New()
+	item := doc.AddNumberedList("Alpha", 0, ListTypeDecimal)
+	doc.Body.Elements = doc.Body.Elements[:0]
+	table, err := doc.AddTable(&TableConfig{Rows: 1, Cols: 1, Width: 4000})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	cell := &table.Rows[0].Cells[0]
+	cell.Paragraphs = []Paragraph{*item}
+
+	if _, err := doc.ContinueList(&cell.Paragraphs[0]); err == nil {
+		t.Error("expected error for a paragraph of a table cell")
+	}
+	if len(cell.Paragraphs) != 1 {
+		t.Error("the cell should be left unchanged")
+	}
+}
+
+// TestNumberingIDsPerDocument tests that numbering IDs follow the definitions of each document
+func TestNumberingIDsPerDocument(t *testing.T) {
+	first, second := New(), New()
+	for _, doc := range []*Document{first, second} {
+		if numID := doc.AddNumberedList("item", 0, ListTypeDecimal).Properties.NumberingProperties.NumID.Val; numID != "1" {
+			t.Errorf("the first list of a document should get ID 1, got %s", numID)
+		}
+	}
+
+	manager := first.numberingManager()
+	manager.numInstances["7"] = &NumInstance{NumID: "7", AbstractNumID: &AbstractNumReference{Val: "4"}}
+	manager.abstractNums["parsed_4"] = &AbstractNum{AbstractNumID: "4"}
+	if numID, abstractNumID := manager.newNumID(), manager.newAbstractNumID(); numID != "8" || abstractNumID != "5" {
+		t.Errorf("new IDs should follow the highest IDs of the document, got %s and %s", numID, abstractNumID)
+	}
+}
@@ -60,8 +60,11 @@ var (
 type SectionProperties struct {
 	XMLName          xml.Name                 `xml:"w:sectPr"`
 	XmlnsR           string                   `xml:"xmlns:r,attr,omitempty"`
+	Type             *SectionType             `xml:"w:type,omitempty"`
 	PageSize         *PageSizeXML             `xml:"w:pgSz,omitempty"`
 	PageMargins      *PageMargin              `xml:"w:pgMar,omitempty"`
+	PageBorders      *PageBorders             `xml:"w:pgBorders,omitempty"`
+	LineNumbering    *LineNumbering           `xml:"w:lnNumType,omitempty"`
 	Columns          *Columns                 `xml:"w:cols,omitempty"`
 	HeaderReferences []*HeaderFooterReference `xml:"w:headerReference,omitempty"`
 	FooterReferences []*FooterReference       `xml:"w:footerReference,omitempty"`
@@ -90,6 +93,12 @@ type PageMargin struct {
 	Gutter  string   `xml:"w:gutter,attr"` // gutter margin (twips)
 }
 
+// SectionType how a section starts: nextPage, continuous, evenPage, oddPage or nextColumn
+type SectionType struct {
+	XMLName xml.Name `xml:"w:type"`
+	Val     string   `xml:"w:val,attr"`
+}
+
 // Columns column settings
 type Columns struct {
 	XMLName xml.Name `xml:"w:cols"`
@@ -209,7 +218,11 @@ func (d *Document) SetPageSettings(settings *PageSettings) error {
 
 // GetPageSettings 获取当前文档的页面设置
 func (d *Document) GetPageSettings() *PageSettings {
-	sectPr := d.getSectionProperties()
+	return sectionPageSettings(d.getSectionProperties())
+}
+
+// sectionPageSettings 将节属性转换为页面设置，缺少的属性使用默认值
+func sectionPageSettings(sectPr *SectionProperties) *PageSettings {
 	settings := DefaultPageSettings()
 
 	if sectPr.PageSize != nil {
@@ -345,6 +358,54 @@ func (d *Document) getSectionProperties() *SectionProperties {
 	return sectPr
 }
 
+// sectionOf returns the section properties of the section containing a body element
+// A section ends at a paragraph carrying section properties, the last section uses the body-level ones.
+func (d *Document) sectionOf(element interface{}) *SectionProperties {
+	if d.Body != nil {
+		found := false
+		for _, el := range d.Body.Elements {
+			if !found {
+				found = el == element || elementContains(el, element)
+			}
+			if p, ok := el.(*Paragraph); ok && found && p.Properties != nil && p.Properties.SectionProperties != nil {
+				return p.Properties.SectionProperties
+			}
+		}
+	}
+	return d.getSectionProperties()
+}
+
+// elementContains reports whether a table or content control contains the target element
+func elementContains(element, target interface{}) bool {
+	switch e := element.(type) {
+	case *Table:
+		for r := range e.Rows {
+			for c := range e.Rows[r].Cells {
+				cell := &e.Rows[r].Cells[c]
+				for p := range cell.Paragraphs {
+					if target == interface{}(&cell.Paragraphs[p]) {
+						return true
+					}
+				}
+				for t := range cell.Tables {
+					if target == interface{}(&cell.Tables[t]) || elementContains(&cell.Tables[t], target) {
+						return true
+					}
+				}
+			}
+		}
+	case *SDT:
+		if e.Content != nil {
+			for _, child := range e.Content.Elements {
+				if child == target || elementContains(child, target) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // setSectionProperties 替换或设置节属性
 func (d *Document) setSectionProperties(sectPr *SectionProperties) {
 	if sectPr == nil {
@@ -371,6 +432,63 @@ func (s *SectionProperties) ElementType() string {
 	return "sectionProperties"
 }
 
+// clone returns a deep copy of the section properties
+func (s *SectionProperties) clone() *SectionProperties {
+	c := *s
+	if s.Type != nil {
+		value := *s.Type
+		c.Type = &value
+	}
+	if s.PageSize != nil {
+		value := *s.PageSize
+		c.PageSize = &value
+	}
+	if s.PageMargins != nil {
+		value := *s.PageMargins
+		c.PageMargins = &value
+	}
+	if s.PageBorders != nil {
+		borders := *s.PageBorders
+		for _, line := range []**ParagraphBorderLine{&borders.Top, &borders.Left, &borders.Bottom, &borders.Right} {
+			if *line != nil {
+				value := **line
+				*line = &value
+			}
+		}
+		c.PageBorders = &borders
+	}
+	if s.LineNumbering != nil {
+		value := *s.LineNumbering
+		c.LineNumbering = &value
+	}
+	if s.Columns != nil {
+		value := *s.Columns
+		c.Columns = &value
+	}
+	c.HeaderReferences = nil
+	for _, ref := range s.HeaderReferences {
+		value := *ref
+		c.HeaderReferences = append(c.HeaderReferences, &value)
+	}
+	c.FooterReferences = nil
+	for _, ref := range s.FooterReferences {
+		value := *ref
+		c.FooterReferences = append(c.FooterReferences, &value)
+	}
+	if s.TitlePage != nil {
+		c.TitlePage = &TitlePage{}
+	}
+	if s.PageNumType != nil {
+		value := *s.PageNumType
+		c.PageNumType = &value
+	}
+	if s.DocGrid != nil {
+		value := *s.DocGrid
+		c.DocGrid = &value
+	}
+	return &c
+}
+
 // validatePageSettings 验证页面设置
 func validatePageSettings(settings *PageSettings) error {
 	// 验证页面尺寸
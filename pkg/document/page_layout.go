@@ -0,0 +1,300 @@
+// Package document provides page borders, page background and line numbering
+package document
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// PageBorderOffset is what the distance of page borders is measured from
+type PageBorderOffset string
+
+const (
+	// PageBorderFromText measures the distance from the text, Word's default
+	PageBorderFromText PageBorderOffset = "text"
+	// PageBorderFromEdge measures the distance from the edge of the page
+	PageBorderFromEdge PageBorderOffset = "page"
+)
+
+// PageBorderDisplay is the pages that show the page borders
+type PageBorderDisplay string
+
+const (
+	PageBorderAllPages     PageBorderDisplay = "allPages"
+	PageBorderFirstPage    PageBorderDisplay = "firstPage"
+	PageBorderNotFirstPage PageBorderDisplay = "notFirstPage"
+)
+
+// Art border styles for page borders, drawn with repeated pictures instead of lines
+const (
+	BorderArtApples         BorderStyle = "apples"
+	BorderArtBasicBlackDots BorderStyle = "basicBlackDots"
+	BorderArtCelticKnotwork BorderStyle = "celticKnotwork"
+	BorderArtDoubleDiamonds BorderStyle = "doubleDiamonds"
+	BorderArtHearts         BorderStyle = "hearts"
+	BorderArtStars          BorderStyle = "stars"
+	BorderArtTrees          BorderStyle = "trees"
+	BorderArtWeavingBraid   BorderStyle = "weavingBraid"
+)
+
+// artBorderStyles are the art borders, whose width is in points instead of 1/8 points
+var artBorderStyles = map[BorderStyle]bool{
+	BorderArtApples: true, BorderArtBasicBlackDots: true, BorderArtCelticKnotwork: true, BorderArtDoubleDiamonds: true,
+	BorderArtHearts: true, BorderArtStars: true, BorderArtTrees: true, BorderArtWeavingBraid: true,
+}
+
+// LineNumberRestart is when line numbers start again
+type LineNumberRestart string
+
+const (
+	LineNumberRestartPage    LineNumberRestart = "newPage"
+	LineNumberRestartSection LineNumberRestart = "newSection"
+	LineNumberContinuous     LineNumberRestart = "continuous"
+)
+
+// PageBorderConfig configures the borders around the pages
+//
+// Sides left nil have no border. Width is in 1/8 points for line styles and in points for art
+// borders; Space is the distance in points from the text or the page edge, see OffsetFrom.
+type PageBorderConfig struct {
+	Top    *BorderConfig
+	Left   *BorderConfig
+	Bottom *BorderConfig
+	Right  *BorderConfig
+	// OffsetFrom is what Space is measured from, the text when empty
+	OffsetFrom PageBorderOffset
+	// Display is the pages showing the borders, all pages when empty
+	Display PageBorderDisplay
+}
+
+// PageBorders is the page borders of a section
+type PageBorders struct {
+	XMLName    xml.Name             `xml:"w:pgBorders"`
+	OffsetFrom string               `xml:"w:offsetFrom,attr,omitempty"`
+	Display    string               `xml:"w:display,attr,omitempty"`
+	Top        *ParagraphBorderLine `xml:"w:top,omitempty"`
+	Left       *ParagraphBorderLine `xml:"w:left,omitempty"`
+	Bottom     *ParagraphBorderLine `xml:"w:bottom,omitempty"`
+	Right      *ParagraphBorderLine `xml:"w:right,omitempty"`
+}
+
+// LineNumbering is the line numbering of a section
+type LineNumbering struct {
+	XMLName  xml.Name `xml:"w:lnNumType"`
+	CountBy  string   `xml:"w:countBy,attr,omitempty"`
+	Start    string   `xml:"w:start,attr,omitempty"`
+	Distance string   `xml:"w:distance,attr,omitempty"`
+	Restart  string   `xml:"w:restart,attr,omitempty"`
+}
+
+// Background is the page background of the document
+type Background struct {
+	XMLName xml.Name `xml:"w:background"`
+	Color   string   `xml:"w:color,attr"`
+}
+
+// SetPageBorders sets the borders around the pages, nil removes them
+func (d *Document) SetPageBorders(config *PageBorderConfig) error {
+	sectPr := d.getSectionProperties()
+	if config == nil {
+		sectPr.PageBorders = nil
+		return nil
+	}
+	switch config.OffsetFrom {
+	case "", PageBorderFromText, PageBorderFromEdge:
+	default:
+		return NewValidationError("offsetFrom", string(config.OffsetFrom), "must be text or page")
+	}
+	switch config.Display {
+	case "", PageBorderAllPages, PageBorderFirstPage, PageBorderNotFirstPage:
+	default:
+		return NewValidationError("display", string(config.Display), "must be allPages, firstPage or notFirstPage")
+	}
+
+	borders := &PageBorders{Display: string(config.Display)}
+	if config.OffsetFrom == PageBorderFromEdge {
+		borders.OffsetFrom = string(PageBorderFromEdge)
+	}
+	sides := []struct {
+		name   string
+		config *BorderConfig
+		line   **ParagraphBorderLine
+	}{
+		{"top", config.Top, &borders.Top},
+		{"left", config.Left, &borders.Left},
+		{"bottom", config.Bottom, &borders.Bottom},
+		{"right", config.Right, &borders.Right},
+	}
+	for _, side := range sides {
+		if side.config == nil {
+			continue
+		}
+		line, err := pageBorderLine(side.name, side.config)
+		if err != nil {
+			return err
+		}
+		*side.line = line
+	}
+	if borders.Top == nil && borders.Left == nil && borders.Bottom == nil && borders.Right == nil {
+		return NewValidationError("config", "empty", "at least one side is required")
+	}
+
+	sectPr.PageBorders = borders
+	Infof("page borders set")
+	return nil
+}
+
+// pageBorderLine returns the XML of a page border side
+func pageBorderLine(side string, config *BorderConfig) (*ParagraphBorderLine, error) {
+	style := config.Style
+	if style == "" {
+		style = BorderStyleSingle
+	}
+	width := config.Width
+	if artBorderStyles[style] {
+		// art borders are 1 to 31 points wide
+		if width == 0 {
+			width = 20
+		}
+		if width < 1 || width > 31 {
+			return nil, NewValidationError(side, strconv.Itoa(width), "art border width must be between 1 and 31 points")
+		}
+	} else {
+		if width == 0 {
+			width = 4
+		}
+		if width < 2 || width > 96 {
+			return nil, NewValidationError(side, strconv.Itoa(width), "border width must be between 2 and 96 eighths of a point")
+		}
+	}
+	if config.Space < 0 || config.Space > 31 {
+		return nil, NewValidationError(side, strconv.Itoa(config.Space), "border space must be between 0 and 31 points")
+	}
+	color := "auto"
+	if config.Color != "" {
+		rgb, ok := parseHexColor(config.Color)
+		if !ok {
+			return nil, NewValidationError(side, config.Color, "must be a hex color such as FF0000")
+		}
+		color = formatHexColor(rgb)
+	}
+	return &ParagraphBorderLine{
+		Val:   string(style),
+		Color: color,
+		Sz:    strconv.Itoa(width),
+		Space: strconv.Itoa(config.Space),
+	}, nil
+}
+
+// SetPageBackground sets the background color of the pages, an empty color removes it
+// Word shows page backgrounds when displayBackgroundShape is set in the document settings,
+// which is updated as well.
+func (d *Document) SetPageBackground(color string) error {
+	if color == "" {
+		d.background = nil
+		return d.setDisplayBackgroundShape(false)
+	}
+	rgb, ok := parseHexColor(color)
+	if !ok {
+		return NewValidationError("color", color, "must be a hex color such as FF0000")
+	}
+	d.background = &Background{Color: formatHexColor(rgb)}
+	Infof("page background set to %s", d.background.Color)
+	return d.setDisplayBackgroundShape(true)
+}
+
+// SetLineNumbering numbers the lines in the margin
+//
+// start is the first line number and is 1 when 0; every countBy-th line shows its number.
+// distance is the distance between the numbers and the text in millimeters, automatic when 0.
+// restart is when the numbers start again, at every page when empty.
+func (d *Document) SetLineNumbering(start, countBy int, restart LineNumberRestart, distance float64) error {
+	if start < 0 {
+		return NewValidationError("start", strconv.Itoa(start), "cannot be negative")
+	}
+	if countBy < 1 {
+		return NewValidationError("countBy", strconv.Itoa(countBy), "must be at least 1")
+	}
+	if distance < 0 {
+		return NewValidationError("distance", strconv.FormatFloat(distance, 'f', -1, 64), "cannot be negative")
+	}
+	switch restart {
+	case "":
+		restart = LineNumberRestartPage
+	case LineNumberRestartPage, LineNumberRestartSection, LineNumberContinuous:
+	default:
+		return NewValidationError("restart", string(restart), "must be newPage, newSection or continuous")
+	}
+
+	numbering := &LineNumbering{CountBy: strconv.Itoa(countBy), Restart: string(restart)}
+	// Word stores the number before the first line
+	if start > 1 {
+		numbering.Start = strconv.Itoa(start - 1)
+	}
+	if distance > 0 {
+		numbering.Distance = fmt.Sprintf("%.0f", mmToTwips(distance))
+	}
+	d.getSectionProperties().LineNumbering = numbering
+	Infof("line numbering set: start=%d, countBy=%d, restart=%s", max(start, 1), countBy, restart)
+	return nil
+}
+
+// ClearLineNumbering removes the line numbering
+func (d *Document) ClearLineNumbering() {
+	d.getSectionProperties().LineNumbering = nil
+}
+
+// setDisplayBackgroundShape shows or hides the page background in the document settings
+func (d *Document) setDisplayBackgroundShape(enabled bool) error {
+	d.ensureSettingsInitialized()
+	settings, err := d.parseSettings()
+	if err != nil {
+		return err
+	}
+	settings.DisplayBackgroundShape = nil
+	if enabled {
+		settings.DisplayBackgroundShape = &DisplayBackgroundShape{}
+	}
+	return d.saveSettings(settings)
+}
+
+// parsePageBorders parses the page borders of a section
+func (d *Document) parsePageBorders(decoder *xml.Decoder, startElement xml.StartElement) (*PageBorders, error) {
+	borders := &PageBorders{
+		OffsetFrom: getAttributeValue(startElement.Attr, "offsetFrom"),
+		Display:    getAttributeValue(startElement.Attr, "display"),
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, WrapError("parse_page_borders", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			line := &ParagraphBorderLine{
+				Val:   getAttributeValue(t.Attr, "val"),
+				Color: getAttributeValue(t.Attr, "color"),
+				Sz:    getAttributeValue(t.Attr, "sz"),
+				Space: getAttributeValue(t.Attr, "space"),
+			}
+			switch t.Name.Local {
+			case "top":
+				borders.Top = line
+			case "left":
+				borders.Left = line
+			case "bottom":
+				borders.Bottom = line
+			case "right":
+				borders.Right = line
+			}
+			if err := d.skipElement(decoder, t.Name.Local); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if t.Name.Local == startElement.Name.Local {
+				return borders, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package document
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestSetPageBorders tests line and art page borders
+func TestSetPageBorders(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Certificate")
+	err := doc.SetPageBorders(&PageBorderConfig{
+		Top:        &BorderConfig{Style: BorderStyleDouble, Width: 12, Color: "#1F4E79", Space: 24},
+		Bottom:     &BorderConfig{Style: BorderStyleDouble, Width: 12, Color: "1F4E79", Space: 24},
+		Left:       &BorderConfig{Style: BorderArtStars, Width: 10},
+		Right:      &BorderConfig{},
+		OffsetFrom: PageBorderFromEdge,
+		Display:    PageBorderFirstPage,
+	})
+	if err != nil {
+		t.Fatalf("failed to set page borders: %v", err)
+	}
+
+	filename := "test_page_borders.docx"
+	defer os.Remove(filename)
+	content := savedDocumentXML(t, doc, filename)
+	for _, expected := range []string{
+		`<w:pgBorders w:offsetFrom="page" w:display="firstPage">`,
+		`<w:top w:val="double" w:color="1F4E79" w:sz="12" w:space="24">`,
+		`<w:left w:val="stars" w:color="auto" w:sz="10" w:space="0">`,
+		`<w:right w:val="single" w:color="auto" w:sz="4" w:space="0">`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("document.xml should contain %s", expected)
+		}
+	}
+	if strings.Index(content, "<w:pgMar") > strings.Index(content, "<w:pgBorders") {
+		t.Error("page borders should follow the page margins")
+	}
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	borders := opened.getSectionProperties().PageBorders
+	if borders == nil || borders.Display != "firstPage" || borders.Left == nil || borders.Left.Val != "stars" {
+		t.Errorf("page borders should be read back, got %+v", borders)
+	}
+
+	if err := doc.SetPageBorders(nil); err != nil || doc.getSectionProperties().PageBorders != nil {
+		t.Error("nil should remove the page borders")
+	}
+	invalid := []*PageBorderConfig{
+		{},
+		{Top: &BorderConfig{Width: 200}},
+		{Top: &BorderConfig{Style: BorderArtApples, Width: 40}},
+		{Top: &BorderConfig{Space: 40}},
+		{Top: &BorderConfig{Color: "navy"}},
+		{Top: &BorderConfig{}, OffsetFrom: "margin"},
+		{Top: &BorderConfig{}, Display: "lastPage"},
+	}
+	for i, config := range invalid {
+		if err := doc.SetPageBorders(config); err == nil {
+			t.Errorf("expected error for invalid config %d", i)
+		}
+	}
+}
+
+// TestSetPageBackground tests the page background and its display setting
+func TestSetPageBackground(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Colored page")
+	if err := doc.SetPageBackground("#fff2cc"); err != nil {
+		t.Fatalf("failed to set page background: %v", err)
+	}
+	// setting it again does not repeat the display setting
+	if err := doc.SetPageBackground("FFF2CC"); err != nil {
+		t.Fatalf("failed to set page background: %v", err)
+	}
+
+	filename := "test_page_background.docx"
+	defer os.Remove(filename)
+	content := savedDocumentXML(t, doc, filename)
+	if !strings.Contains(content, `<w:background w:color="FFF2CC"></w:background>`) || strings.Index(content, "<w:background") > strings.Index(content, "<w:body") {
+		t.Error("background should be written before the body")
+	}
+	settings := string(doc.parts["word/settings.xml"])
+	if strings.Count(settings, "<w:displayBackgroundShape") != 1 || !strings.Contains(settings, "<w:defaultTabStop") {
+		t.Errorf("settings should display the background once and keep the other settings, got %s", settings)
+	}
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	if opened.background == nil || opened.background.Color != "FFF2CC" {
+		t.Error("background should be read back")
+	}
+	if err := opened.SetPageBackground(""); err != nil {
+		t.Fatalf("failed to remove page background: %v", err)
+	}
+	if opened.background != nil || strings.Contains(string(opened.parts["word/settings.xml"]), "displayBackgroundShape") {
+		t.Error("background and its display setting should be removed")
+	}
+
+	if err := doc.SetPageBackground("yellow"); err == nil {
+		t.Error("expected error for invalid color")
+	}
+}
+
+// TestPageBackgroundSettings tests the display setting among the settings of an opened document
+func TestPageBackgroundSettings(t *testing.T) {
+	doc := New()
+	doc.parts["word/settings.xml"] = []byte(`<?xml version="1.0"?><w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml"><w:zoom w:percent="100"/><w:proofState w:spelling="clean"/>` +
+		`<w:defaultTabStop w:val="720"/><w:compat><w:compatSetting w:name="compatibilityMode" w:val="15"/></w:compat>` +
+		`<w14:docId w14:val="1A2B3C4D"/></w:settings>`)
+	if err := doc.SetPageBackground("FFF2CC"); err != nil {
+		t.Fatalf("failed to set page background: %v", err)
+	}
+	settings := regexp.MustCompile(`>\s+<`).ReplaceAllString(string(doc.parts["word/settings.xml"]), "><")
+	for _, expected := range []string{
+		`<w:zoom w:percent="100"></w:zoom><w:displayBackgroundShape></w:displayBackgroundShape><w:proofState w:spelling="clean">`,
+		`<w:defaultTabStop w:val="720"></w:defaultTabStop>`,
+		`<w:compat><w:compatSetting w:name="compatibilityMode" w:val="15"></w:compatSetting></w:compat><w14:docId w14:val="1A2B3C4D">`,
+		`xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml"`,
+	} {
+		if !strings.Contains(settings, expected) {
+			t.Errorf("settings should contain %s, got %s", expected, settings)
+		}
+	}
+
+	parsed, err := doc.parseSettings()
+	if err != nil {
+		t.Fatalf("failed to parse settings: %v", err)
+	}
+	if parsed.DisplayBackgroundShape == nil || parsed.DefaultTabStop == nil || parsed.DefaultTabStop.Val != "720" {
+		t.Error("settings should be read back into the settings model")
+	}
+}
+
+// TestSetLineNumbering tests line numbering of the section
+func TestSetLineNumbering(t *testing.T) {
+	doc := New()
+	for i := 0; i < 5; i++ {
+		doc.AddParagraph("Pleading line")
+	}
+	if err := doc.SetLineNumbering(1, 1, LineNumberRestartPage, 0); err != nil {
+		t.Fatalf("failed to set line numbering: %v", err)
+	}
+	numbering := doc.getSectionProperties().LineNumbering
+	if numbering.Start != "" || numbering.CountBy != "1" || numbering.Restart != "newPage" || numbering.Distance != "" {
+		t.Errorf("unexpected line numbering %+v", numbering)
+	}
+
+	if err := doc.SetLineNumbering(10, 5, LineNumberContinuous, 5); err != nil {
+		t.Fatalf("failed to set line numbering: %v", err)
+	}
+	filename := "test_line_numbering.docx"
+	defer os.Remove(filename)
+	content := savedDocumentXML(t, doc, filename)
+	if !strings.Contains(content, `<w:lnNumType w:countBy="5" w:start="9" w:distance="283" w:restart="continuous">`) {
+		t.Error("document.xml should contain the line numbering")
+	}
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	if numbering := opened.getSectionProperties().LineNumbering; numbering == nil || numbering.Start != "9" {
+		t.Errorf("line numbering should be read back, got %+v", numbering)
+	}
+	opened.ClearLineNumbering()
+	if opened.getSectionProperties().LineNumbering != nil {
+		t.Error("line numbering should be removed")
+	}
+
+	for _, args := range []struct {
+		start, countBy int
+		restart        LineNumberRestart
+		distance       float64
+	}{{-1, 1, "", 0}, {1, 0, "", 0}, {1, 1, "never", 0}, {1, 1, "", -2}} {
+		if err := doc.SetLineNumbering(args.start, args.countBy, args.restart, args.distance); err == nil {
+			t.Errorf("expected error for %+v", args)
+		}
+	}
+}
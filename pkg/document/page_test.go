@@ -2,6 +2,8 @@
 package document
 
 import (
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -401,3 +403,42 @@ func TestCompletePageSettings(t *testing.T) {
 		t.Errorf("上边距不匹配，期望: %.1fmm, 实际: %.1fmm", settings.MarginTop, retrieved.MarginTop)
 	}
 }
+
+// TestOpenMultipleSections 测试打开多节文档时保留每一节的设置
+func TestOpenMultipleSections(t *testing.T) {
+	doc := New()
+	wide := doc.AddParagraph("Wide table")
+	wide.Properties = &ParagraphProperties{SectionProperties: &SectionProperties{
+		PageSize:         &PageSizeXML{W: "16838", H: "11906", Orient: string(OrientationLandscape)},
+		HeaderReferences: []*HeaderFooterReference{{Type: string(HeaderFooterTypeDefault), ID: "rId9"}},
+	}}
+	doc.AddParagraph("Portrait text")
+	if err := doc.SetPageOrientation(OrientationPortrait); err != nil {
+		t.Fatalf("设置页面方向失败: %v", err)
+	}
+
+	filename := "test_multiple_sections.docx"
+	defer os.Remove(filename)
+	if content := savedDocumentXML(t, doc, filename); strings.Count(content, "<w:sectPr") != 2 {
+		t.Fatal("document.xml should contain both sections")
+	}
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("打开文档失败: %v", err)
+	}
+
+	paragraphs := opened.Body.GetParagraphs()
+	first := paragraphs[0].Properties
+	if first == nil || first.SectionProperties == nil || first.SectionProperties.PageSize.Orient != string(OrientationLandscape) {
+		t.Fatal("the first section should stay on its paragraph")
+	}
+	if refs := first.SectionProperties.HeaderReferences; len(refs) != 1 || refs[0].ID != "rId9" {
+		t.Error("the header of the first section should be read back")
+	}
+	if settings := opened.GetPageSettings(); settings.Orientation != OrientationPortrait {
+		t.Errorf("the last section should keep its own orientation, got %s", settings.Orientation)
+	}
+	if content := savedDocumentXML(t, opened, filename); strings.Count(content, "<w:sectPr") != 2 {
+		t.Error("both sections should be saved again")
+	}
+}
@@ -0,0 +1,557 @@
+// Package document provides text boxes and basic shapes
+package document
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ShapeKind is the geometry of a shape
+type ShapeKind string
+
+const (
+	ShapeRect      ShapeKind = "rect"
+	ShapeRoundRect ShapeKind = "roundRect"
+	ShapeEllipse   ShapeKind = "ellipse"
+	// ShapeLine is drawn from the top left to the bottom right corner of its box
+	ShapeLine ShapeKind = "line"
+	// ShapeArrow is a line with an arrow head at its end
+	ShapeArrow ShapeKind = "arrow"
+)
+
+const (
+	// wpsGraphicDataURI identifies the shapes of Word 2010 and later in graphic data
+	wpsGraphicDataURI = "http://schemas.microsoft.com/office/word/2010/wordprocessingShape"
+	// shapeRelativeHeight is the z-order of the first shape, later shapes are placed above it
+	shapeRelativeHeight = 251659264
+	// mmPerPoint converts millimeters to points for VML styles
+	mmPerPoint = 25.4 / 72
+	// defaultTextBoxWidth and defaultTextBoxHeight size text boxes without a size, in millimeters
+	defaultTextBoxWidth  = 60
+	defaultTextBoxHeight = 30
+)
+
+// ShapeOutline is the border line of a shape or text box
+type ShapeOutline struct {
+	// Color is a hex color such as FF0000, black when empty
+	Color string
+	// Width is the line width in points, 0.75 when 0
+	Width float64
+	// Dash is the dash style, solid when empty
+	Dash ImageBorderDash
+}
+
+// ShapeConfig sizes and places a shape, sizes and offsets in millimeters
+//
+// Shapes and text boxes always float over the text. Position places them at the left or right
+// margin and centers them for other values; OffsetX and OffsetY place them relative to the
+// top left corner of the margins instead.
+type ShapeConfig struct {
+	Width    float64
+	Height   float64
+	Position ImagePosition
+	WrapText ImageWrapText
+	OffsetX  float64
+	OffsetY  float64
+	AltText  string
+}
+
+// TextBoxConfig configures a text box
+// A width or height of 0 uses the default size of 60x30 mm.
+type TextBoxConfig struct {
+	ShapeConfig
+	// Fill is the background color, transparent when empty
+	Fill string
+	// Outline is the border, nil for a text box without border
+	Outline *ShapeOutline
+	// Padding is the distance between the border and the text in millimeters, Word's default when 0
+	Padding float64
+}
+
+// Shape is a shape placed in the document
+type Shape struct {
+	ID   string
+	Kind ShapeKind
+	// Paragraph is the body paragraph the shape is anchored to
+	Paragraph *Paragraph
+}
+
+// TextBox is a floating container of paragraphs
+type TextBox struct {
+	ID string
+	// Paragraph is the body paragraph the text box is anchored to
+	Paragraph *Paragraph
+	content   *TextBoxContent
+}
+
+// AlternateContent holds content for readers that support it and a fallback for older readers
+type AlternateContent struct {
+	XMLName  xml.Name           `xml:"mc:AlternateContent"`
+	Choice   *AlternateChoice   `xml:"mc:Choice"`
+	Fallback *AlternateFallback `xml:"mc:Fallback,omitempty"`
+	// Namespaces and RawXML keep the content read from a document, which is written back as is
+	Namespaces []xml.Attr `xml:",any,attr"`
+	RawXML     string     `xml:",innerxml"`
+}
+
+// AlternateChoice is content for readers that understand the Requires namespace prefix
+type AlternateChoice struct {
+	XMLName  xml.Name        `xml:"mc:Choice"`
+	Requires string          `xml:"Requires,attr"`
+	Drawing  *DrawingElement `xml:"w:drawing"`
+}
+
+// AlternateFallback is shown by readers that do not support the choice
+type AlternateFallback struct {
+	XMLName xml.Name `xml:"mc:Fallback"`
+	Pict    *Pict    `xml:"w:pict"`
+}
+
+// Pict holds a VML drawing
+type Pict struct {
+	XMLName xml.Name `xml:"w:pict"`
+	Shape   *VMLShape
+}
+
+// VMLShape is a VML shape, its element name is set by the kind of shape (v:rect, v:oval, ...)
+type VMLShape struct {
+	XMLName      xml.Name
+	ID           string      `xml:"id,attr,omitempty"`
+	Style        string      `xml:"style,attr"`
+	ArcSize      string      `xml:"arcsize,attr,omitempty"`
+	From         string      `xml:"from,attr,omitempty"`
+	To           string      `xml:"to,attr,omitempty"`
+	Filled       string      `xml:"filled,attr,omitempty"`
+	FillColor    string      `xml:"fillcolor,attr,omitempty"`
+	Stroked      string      `xml:"stroked,attr,omitempty"`
+	StrokeColor  string      `xml:"strokecolor,attr,omitempty"`
+	StrokeWeight string      `xml:"strokeweight,attr,omitempty"`
+	Stroke       *VMLStroke  `xml:"v:stroke,omitempty"`
+	TextBox      *VMLTextBox `xml:"v:textbox,omitempty"`
+	Wrap         *VMLWrap    `xml:"w10:wrap,omitempty"`
+}
+
+// VMLStroke sets the dash style and arrow heads of a VML line
+type VMLStroke struct {
+	XMLName   xml.Name `xml:"v:stroke"`
+	DashStyle string   `xml:"dashstyle,attr,omitempty"`
+	EndArrow  string   `xml:"endarrow,attr,omitempty"`
+}
+
+// VMLTextBox is the text of a VML shape
+type VMLTextBox struct {
+	XMLName xml.Name        `xml:"v:textbox"`
+	Inset   string          `xml:"inset,attr,omitempty"`
+	Content *TextBoxContent `xml:"w:txbxContent"`
+}
+
+// VMLWrap is the text wrapping of a VML shape
+type VMLWrap struct {
+	XMLName xml.Name `xml:"w10:wrap"`
+	Type    string   `xml:"type,attr"`
+}
+
+// WordprocessingShape is a shape of Word 2010 and later
+type WordprocessingShape struct {
+	XMLName xml.Name             `xml:"wps:wsp"`
+	CNvSpPr *ShapeNonVisualProps `xml:"wps:cNvSpPr"`
+	SpPr    *ShapeProperties     `xml:"wps:spPr"`
+	TextBox *WordprocessingTxbx  `xml:"wps:txbx,omitempty"`
+	BodyPr  *ShapeBodyProperties `xml:"wps:bodyPr"`
+}
+
+// ShapeNonVisualProps marks a shape as a text box
+type ShapeNonVisualProps struct {
+	XMLName xml.Name `xml:"wps:cNvSpPr"`
+	TxBox   string   `xml:"txBox,attr,omitempty"`
+}
+
+// ShapeProperties is the geometry, fill and outline of a shape
+type ShapeProperties struct {
+	XMLName   xml.Name   `xml:"wps:spPr"`
+	Xfrm      *Xfrm      `xml:"a:xfrm"`
+	PrstGeom  *PrstGeom  `xml:"a:prstGeom"`
+	NoFill    *NoFill    `xml:"a:noFill,omitempty"`
+	SolidFill *SolidFill `xml:"a:solidFill,omitempty"`
+	Ln        *Outline   `xml:"a:ln,omitempty"`
+}
+
+// NoFill leaves a shape transparent
+type NoFill struct {
+	XMLName xml.Name `xml:"a:noFill"`
+}
+
+// WordprocessingTxbx holds the text of a shape
+type WordprocessingTxbx struct {
+	XMLName xml.Name        `xml:"wps:txbx"`
+	Content *TextBoxContent `xml:"w:txbxContent"`
+}
+
+// TextBoxContent holds the paragraphs of a text box
+type TextBoxContent struct {
+	XMLName    xml.Name     `xml:"w:txbxContent"`
+	Paragraphs []*Paragraph `xml:"w:p"`
+}
+
+// MarshalXML writes the paragraphs of the text box, with an empty one when there are none
+// as a text box must contain at least one paragraph
+func (c *TextBoxContent) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	paragraphs := c.Paragraphs
+	if len(paragraphs) == 0 {
+		paragraphs = []*Paragraph{{}}
+	}
+	return e.EncodeElement(struct {
+		Paragraphs []*Paragraph `xml:"w:p"`
+	}{paragraphs}, xml.StartElement{Name: xml.Name{Local: "w:txbxContent"}})
+}
+
+// ShapeBodyProperties sets the text insets of a shape in EMU
+type ShapeBodyProperties struct {
+	XMLName xml.Name `xml:"wps:bodyPr"`
+	Rot     string   `xml:"rot,attr,omitempty"`
+	Vert    string   `xml:"vert,attr,omitempty"`
+	Wrap    string   `xml:"wrap,attr,omitempty"`
+	LIns    string   `xml:"lIns,attr,omitempty"`
+	TIns    string   `xml:"tIns,attr,omitempty"`
+	RIns    string   `xml:"rIns,attr,omitempty"`
+	BIns    string   `xml:"bIns,attr,omitempty"`
+	Anchor  string   `xml:"anchor,attr,omitempty"`
+}
+
+// LineEnd is the arrow head at the end of a line
+type LineEnd struct {
+	XMLName xml.Name `xml:"a:tailEnd"`
+	Type    string   `xml:"type,attr"`
+}
+
+// AddTextBox adds a floating text box to the document and returns it to add paragraphs to
+func (d *Document) AddTextBox(config *TextBoxConfig) (*TextBox, error) {
+	if config == nil {
+		return nil, NewValidationError("config", "nil", "text box config is required")
+	}
+	shape := config.ShapeConfig
+	if shape.Width == 0 {
+		shape.Width = defaultTextBoxWidth
+	}
+	if shape.Height == 0 {
+		shape.Height = defaultTextBoxHeight
+	}
+	content := &TextBoxContent{}
+	paragraph, id, err := d.addShapeParagraph(ShapeRect, config.Fill, config.Outline, &shape, content, config.Padding)
+	if err != nil {
+		return nil, err
+	}
+	Infof("added text box %s", id)
+	return &TextBox{ID: id, Paragraph: paragraph, content: content}, nil
+}
+
+// AddShape adds a floating shape to the document
+// fill is a hex color, empty for a transparent shape; outline nil draws no border. Lines and
+// arrows ignore the fill and are drawn with a thin black line when outline is nil.
+func (d *Document) AddShape(kind ShapeKind, fill string, outline *ShapeOutline, config *ShapeConfig) (*Shape, error) {
+	if config == nil {
+		return nil, NewValidationError("config", "nil", "shape config is required")
+	}
+	switch kind {
+	case ShapeRect, ShapeRoundRect, ShapeEllipse:
+	case ShapeLine, ShapeArrow:
+		fill = ""
+		if outline == nil {
+			outline = &ShapeOutline{}
+		}
+	default:
+		return nil, NewValidationError("kind", string(kind), "unsupported shape")
+	}
+	paragraph, id, err := d.addShapeParagraph(kind, fill, outline, config, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	Infof("added %s shape %s", kind, id)
+	return &Shape{ID: id, Kind: kind, Paragraph: paragraph}, nil
+}
+
+// AddParagraph adds a paragraph to the text box
+func (t *TextBox) AddParagraph(text string) *Paragraph {
+	p := &Paragraph{
+		Runs: []Run{
+			{
+				Text: Text{
+					Content: text,
+					Space:   "preserve",
+				},
+			},
+		},
+	}
+	t.content.Paragraphs = append(t.content.Paragraphs, p)
+	return p
+}
+
+// AddFormattedParagraph adds a paragraph with formatted text to the text box
+func (t *TextBox) AddFormattedParagraph(text string, format *TextFormat) *Paragraph {
+	p := &Paragraph{}
+	p.AddFormattedText(text, format)
+	t.content.Paragraphs = append(t.content.Paragraphs, p)
+	return p
+}
+
+// Paragraphs returns the paragraphs of the text box
+func (t *TextBox) Paragraphs() []*Paragraph {
+	return t.content.Paragraphs
+}
+
+// addShapeParagraph appends a paragraph anchoring a shape, with content for text boxes
+func (d *Document) addShapeParagraph(kind ShapeKind, fill string, outline *ShapeOutline, config *ShapeConfig, content *TextBoxContent, padding float64) (*Paragraph, string, error) {
+	isLine := kind == ShapeLine || kind == ShapeArrow
+	if config.Width < 0 || config.Height < 0 || (!isLine && (config.Width == 0 || config.Height == 0)) ||
+		(isLine && config.Width == 0 && config.Height == 0) {
+		return nil, "", NewValidationError("size", fmt.Sprintf("%gx%g", config.Width, config.Height), "shape size must be positive")
+	}
+	if padding < 0 {
+		return nil, "", NewValidationError("padding", strconv.FormatFloat(padding, 'f', -1, 64), "cannot be negative")
+	}
+
+	var fillColor, lineColor string
+	if fill != "" {
+		rgb, ok := parseHexColor(fill)
+		if !ok {
+			return nil, "", NewValidationError("fill", fill, "must be a hex color such as FF0000")
+		}
+		fillColor = formatHexColor(rgb)
+	}
+	lineWidth := 0.75
+	if outline != nil {
+		lineColor = "000000"
+		if outline.Color != "" {
+			rgb, ok := parseHexColor(outline.Color)
+			if !ok {
+				return nil, "", NewValidationError("outline", outline.Color, "must be a hex color such as FF0000")
+			}
+			lineColor = formatHexColor(rgb)
+		}
+		if outline.Width > 0 {
+			lineWidth = outline.Width
+		}
+	}
+
+	imageID := d.nextImageID
+	d.nextImageID++
+	id := strconv.Itoa(imageID)
+	cx := int64(math.Round(config.Width * 36000))
+	cy := int64(math.Round(config.Height * 36000))
+
+	name := fmt.Sprintf("Shape %s", id)
+	if content != nil {
+		name = fmt.Sprintf("Text Box %s", id)
+	}
+	shape := &WordprocessingShape{
+		CNvSpPr: &ShapeNonVisualProps{},
+		SpPr: &ShapeProperties{
+			Xfrm: &Xfrm{
+				Off: &Off{X: "0", Y: "0"},
+				Ext: &Ext{Cx: strconv.FormatInt(cx, 10), Cy: strconv.FormatInt(cy, 10)},
+			},
+			PrstGeom: &PrstGeom{Prst: string(kind), AvLst: &AvLst{}},
+		},
+		BodyPr: &ShapeBodyProperties{},
+	}
+	if isLine {
+		shape.SpPr.PrstGeom.Prst = string(ShapeLine)
+	} else if fillColor != "" {
+		shape.SpPr.SolidFill = &SolidFill{SrgbClr: &SrgbClr{Val: fillColor}}
+	} else {
+		shape.SpPr.NoFill = &NoFill{}
+	}
+	if outline != nil {
+		dash := outline.Dash
+		if dash == "" {
+			dash = ImageDashSolid
+		}
+		shape.SpPr.Ln = &Outline{
+			W:         strconv.Itoa(int(math.Round(lineWidth * emuPerPoint))),
+			SolidFill: &SolidFill{SrgbClr: &SrgbClr{Val: lineColor}},
+			PrstDash:  &PrstDash{Val: string(dash)},
+		}
+		if kind == ShapeArrow {
+			shape.SpPr.Ln.TailEnd = &LineEnd{Type: "triangle"}
+		}
+	}
+
+	// Word's default insets are 0.1 inch left and right and 0.05 inch at the top and bottom
+	insetX, insetY := int64(91440), int64(45720)
+	if padding > 0 {
+		insetX = int64(math.Round(padding * 36000))
+		insetY = insetX
+	}
+	if content != nil {
+		shape.CNvSpPr.TxBox = "1"
+		shape.TextBox = &WordprocessingTxbx{Content: content}
+		shape.BodyPr = &ShapeBodyProperties{
+			Rot:    "0",
+			Vert:   "horz",
+			Wrap:   "square",
+			LIns:   strconv.FormatInt(insetX, 10),
+			TIns:   strconv.FormatInt(insetY, 10),
+			RIns:   strconv.FormatInt(insetX, 10),
+			BIns:   strconv.FormatInt(insetY, 10),
+			Anchor: "t",
+		}
+	}
+
+	anchor := &AnchorDrawing{
+		DistT:          "0",
+		DistB:          "0",
+		DistL:          "114300",
+		DistR:          "114300",
+		SimplePos:      "0",
+		RelativeHeight: strconv.Itoa(shapeRelativeHeight + imageID),
+		BehindDoc:      "0",
+		Locked:         "0",
+		LayoutInCell:   "1",
+		AllowOverlap:   "1",
+		SimplePosition: &SimplePosition{X: "0", Y: "0"},
+		Extent:         &DrawingExtent{Cx: strconv.FormatInt(cx, 10), Cy: strconv.FormatInt(cy, 10)},
+		EffectExtent:   &EffectExtent{L: "0", T: "0", R: "0", B: "0"},
+		DocPr:          &DrawingDocPr{ID: id, Name: name, Descr: config.AltText},
+		Graphic: &DrawingGraphic{
+			Xmlns:       "http://schemas.openxmlformats.org/drawingml/2006/main",
+			GraphicData: &GraphicData{Uri: wpsGraphicDataURI, Shape: shape},
+		},
+	}
+	positionConfig := &ImageConfig{
+		Position: config.Position,
+		WrapText: config.WrapText,
+		OffsetX:  config.OffsetX,
+		OffsetY:  config.OffsetY,
+	}
+	d.setFloatingImagePosition(anchor, positionConfig)
+	d.setFloatingImageWrap(anchor, positionConfig)
+
+	fallback := &VMLShape{
+		ID:    name,
+		Style: vmlShapeStyle(config, shapeRelativeHeight+imageID, !isLine),
+	}
+	switch kind {
+	case ShapeRoundRect:
+		fallback.XMLName.Local = "v:roundrect"
+		fallback.ArcSize = "10923f" // Word's default corner, 1/6 of the shorter side
+	case ShapeEllipse:
+		fallback.XMLName.Local = "v:oval"
+	case ShapeLine, ShapeArrow:
+		fallback.XMLName.Local = "v:line"
+		fallback.From = "0,0"
+		fallback.To = fmt.Sprintf("%s,%s", formatVMLPoints(config.Width), formatVMLPoints(config.Height))
+	default:
+		fallback.XMLName.Local = "v:rect"
+	}
+	if fillColor != "" {
+		fallback.FillColor = "#" + fillColor
+	} else if !isLine {
+		fallback.Filled = "f"
+	}
+	if outline != nil {
+		fallback.StrokeColor = "#" + lineColor
+		fallback.StrokeWeight = formatVMLPoints(lineWidth * mmPerPoint)
+		stroke := &VMLStroke{DashStyle: vmlDashStyle(outline.Dash)}
+		if kind == ShapeArrow {
+			stroke.EndArrow = "block"
+		}
+		if stroke.DashStyle != "" || stroke.EndArrow != "" {
+			fallback.Stroke = stroke
+		}
+	} else {
+		fallback.Stroked = "f"
+	}
+	if content != nil {
+		fallback.TextBox = &VMLTextBox{
+			Inset: fmt.Sprintf("%s,%s,%s,%s",
+				formatVMLPoints(float64(insetX)/36000), formatVMLPoints(float64(insetY)/36000),
+				formatVMLPoints(float64(insetX)/36000), formatVMLPoints(float64(insetY)/36000)),
+			Content: content,
+		}
+	}
+	fallback.Wrap = &VMLWrap{Type: vmlWrapType(anchor)}
+
+	paragraph := &Paragraph{
+		Runs: []Run{
+			{
+				AlternateContent: &AlternateContent{
+					Choice:   &AlternateChoice{Requires: "wps", Drawing: &DrawingElement{Anchor: anchor}},
+					Fallback: &AlternateFallback{Pict: &Pict{Shape: fallback}},
+				},
+			},
+		},
+	}
+	d.Body.Elements = append(d.Body.Elements, paragraph)
+	return paragraph, id, nil
+}
+
+// vmlShapeStyle returns the VML style placing a shape like its DrawingML anchor
+func vmlShapeStyle(config *ShapeConfig, zIndex int, sized bool) string {
+	style := []string{"position:absolute"}
+	if config.OffsetX != 0 {
+		style = append(style, "margin-left:"+formatVMLPoints(config.OffsetX))
+	}
+	if config.OffsetY != 0 {
+		style = append(style, "margin-top:"+formatVMLPoints(config.OffsetY))
+	}
+	if sized {
+		style = append(style, "width:"+formatVMLPoints(config.Width), "height:"+formatVMLPoints(config.Height))
+	}
+	style = append(style, fmt.Sprintf("z-index:%d", zIndex))
+	if config.OffsetX == 0 {
+		horizontal := "center"
+		switch config.Position {
+		case ImagePositionFloatLeft:
+			horizontal = "left"
+		case ImagePositionFloatRight:
+			horizontal = "right"
+		}
+		style = append(style, "mso-position-horizontal:"+horizontal)
+	}
+	style = append(style, "mso-position-horizontal-relative:margin")
+	if config.OffsetY == 0 {
+		style = append(style, "mso-position-vertical:top")
+	}
+	style = append(style, "mso-position-vertical-relative:margin")
+	return strings.Join(style, ";")
+}
+
+// vmlWrapType returns the VML wrapping matching the wrapping of an anchor
+func vmlWrapType(anchor *AnchorDrawing) string {
+	switch {
+	case anchor.WrapNone != nil:
+		return "none"
+	case anchor.WrapTight != nil:
+		return "tight"
+	case anchor.WrapTopAndBottom != nil:
+		return "topAndBottom"
+	}
+	return "square"
+}
+
+// vmlDashStyle returns the VML dash style of a DrawingML dash style, empty for solid lines
+func vmlDashStyle(dash ImageBorderDash) string {
+	switch dash {
+	case ImageDashDot:
+		return "dot"
+	case ImageDashDash:
+		return "dash"
+	case ImageDashLongDash:
+		return "longDash"
+	case ImageDashDashDot:
+		return "dashDot"
+	case ImageDashSquareDot:
+		return "1 1"
+	case ImageDashSquareDash:
+		return "shortdash"
+	}
+	return ""
+}
+
+// formatVMLPoints formats a length in millimeters as VML points
+func formatVMLPoints(mm float64) string {
+	return strconv.FormatFloat(math.Round(mm/mmPerPoint*100)/100, 'f', -1, 64) + "pt"
+}
@@ -0,0 +1,208 @@
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// savedDocumentXML saves a document and returns its document.xml, checking that it is well formed
+func savedDocumentXML(t *testing.T, doc *Document, filename string) string {
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	data := doc.parts["word/document.xml"]
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("document.xml is not well formed: %v", err)
+		}
+	}
+	return string(data)
+}
+
+// TestAddTextBox tests a floating text box with paragraphs and its VML fallback
+func TestAddTextBox(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Brochure text")
+	box, err := doc.AddTextBox(&TextBoxConfig{
+		ShapeConfig: ShapeConfig{Width: 60, Height: 40, Position: ImagePositionFloatRight, WrapText: ImageWrapSquare},
+		Fill:        "#FFF2CC",
+		Outline:     &ShapeOutline{Color: "BF9000", Width: 1.5, Dash: ImageDashDash},
+		Padding:     3,
+	})
+	if err != nil {
+		t.Fatalf("failed to add text box: %v", err)
+	}
+	box.AddFormattedParagraph("Did you know?", &TextFormat{Bold: true})
+	box.AddParagraph("Sidebars float beside the text.")
+	if len(box.Paragraphs()) != 2 {
+		t.Errorf("expected 2 paragraphs, got %d", len(box.Paragraphs()))
+	}
+
+	filename := "test_text_box.docx"
+	defer os.Remove(filename)
+	content := savedDocumentXML(t, doc, filename)
+
+	for _, expected := range []string{
+		`<mc:Choice Requires="wps">`,
+		`<a:graphicData uri="http://schemas.microsoft.com/office/word/2010/wordprocessingShape">`,
+		`<wps:cNvSpPr txBox="1">`,
+		`<a:srgbClr val="FFF2CC">`,
+		`<a:ln w="19050">`,
+		`<a:prstDash val="dash">`,
+		`lIns="108000"`,
+		`<wp:align>right</wp:align>`,
+		`<wp:wrapSquare wrapText="left"`,
+		`<v:rect id="Text Box 0"`,
+		`mso-position-horizontal:right`,
+		`fillcolor="#FFF2CC"`,
+		`<v:stroke dashstyle="dash">`,
+		`<w10:wrap type="square">`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("document.xml should contain %s", expected)
+		}
+	}
+	// the paragraphs are written for Word 2010 and later and for the fallback
+	if count := strings.Count(content, "Sidebars float beside the text."); count != 2 {
+		t.Errorf("expected the text box content twice, got %d", count)
+	}
+
+	if _, err := Open(filename); err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+
+	if _, err := doc.AddTextBox(&TextBoxConfig{}); err != nil {
+		t.Fatalf("a text box without size should get the default size: %v", err)
+	}
+	if content = savedDocumentXML(t, doc, filename); !strings.Contains(content, `<wp:extent cx="2160000" cy="1080000"`) {
+		t.Error("the default text box size should be 60x30 mm")
+	}
+	if _, err := doc.AddTextBox(&TextBoxConfig{ShapeConfig: ShapeConfig{Width: -1}}); err == nil {
+		t.Error("expected error for a negative size")
+	}
+}
+
+// TestAddShape tests the supported shape kinds
+func TestAddShape(t *testing.T) {
+	doc := New()
+	tests := []struct {
+		kind     ShapeKind
+		geometry string
+		fallback string
+	}{
+		{ShapeRect, `prst="rect"`, "<v:rect "},
+		{ShapeRoundRect, `prst="roundRect"`, "<v:roundrect "},
+		{ShapeEllipse, `prst="ellipse"`, "<v:oval "},
+		{ShapeLine, `prst="line"`, "<v:line "},
+	}
+	for _, tt := range tests {
+		if _, err := doc.AddShape(tt.kind, "4472C4", nil, &ShapeConfig{Width: 30, Height: 20, OffsetX: 10, OffsetY: 5}); err != nil {
+			t.Fatalf("failed to add %s: %v", tt.kind, err)
+		}
+	}
+	arrow, err := doc.AddShape(ShapeArrow, "", &ShapeOutline{Color: "FF0000"}, &ShapeConfig{Width: 50})
+	if err != nil {
+		t.Fatalf("failed to add arrow: %v", err)
+	}
+	if arrow.Kind != ShapeArrow || arrow.Paragraph == nil {
+		t.Errorf("unexpected arrow: %+v", arrow)
+	}
+	empty, err := doc.AddTextBox(&TextBoxConfig{ShapeConfig: ShapeConfig{Width: 20, Height: 10}})
+	if err != nil {
+		t.Fatalf("failed to add text box: %v", err)
+	}
+	if empty.ID == arrow.ID {
+		t.Error("shapes should have unique IDs")
+	}
+
+	filename := "test_shapes.docx"
+	defer os.Remove(filename)
+	content := savedDocumentXML(t, doc, filename)
+	for _, tt := range tests {
+		if !strings.Contains(content, tt.geometry) || !strings.Contains(content, tt.fallback) {
+			t.Errorf("%s shape should be written with %s and %s", tt.kind, tt.geometry, tt.fallback)
+		}
+	}
+	for _, expected := range []string{
+		`<wp:posOffset>360000</wp:posOffset>`,
+		`margin-left:28.35pt;margin-top:14.17pt`,
+		`<a:tailEnd type="triangle">`,
+		`<v:stroke endarrow="block">`,
+		`to="141.73pt,0pt"`,
+		`stroked="f"`,
+		`<w:p></w:p>`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("document.xml should contain %s", expected)
+		}
+	}
+
+	if _, err := doc.AddShape("star", "", nil, &ShapeConfig{Width: 10, Height: 10}); err == nil {
+		t.Error("expected error for unsupported shape")
+	}
+	if _, err := doc.AddShape(ShapeRect, "", nil, &ShapeConfig{Width: 10}); err == nil {
+		t.Error("expected error for a rectangle without height")
+	}
+	if _, err := doc.AddShape(ShapeRect, "blue", nil, &ShapeConfig{Width: 10, Height: 10}); err == nil {
+		t.Error("expected error for invalid fill color")
+	}
+	if _, err := doc.AddTextBox(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+}
+
+// TestShapesRoundTrip tests that shapes and text boxes of an opened document are saved again
+func TestShapesRoundTrip(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Brochure text")
+	box, err := doc.AddTextBox(&TextBoxConfig{ShapeConfig: ShapeConfig{Width: 60, Height: 40}})
+	if err != nil {
+		t.Fatalf("failed to add text box: %v", err)
+	}
+	box.AddParagraph("Did you know?")
+	if _, err := doc.AddShape(ShapeEllipse, "FF0000", nil, &ShapeConfig{Width: 20, Height: 20}); err != nil {
+		t.Fatalf("failed to add shape: %v", err)
+	}
+
+	filename := "test_shapes_round_trip.docx"
+	defer os.Remove(filename)
+	content := savedDocumentXML(t, doc, filename)
+
+	// Word adds elements of namespaces the document does not declare itself
+	content = strings.Replace(content, `<w:document `, `<w:document xmlns:wp14="http://schemas.microsoft.com/office/word/2010/wordprocessingDrawing" `, 1)
+	content = strings.Replace(content, `</wp:anchor>`, `<wp14:sizeRelH relativeFrom="margin"><wp14:pctWidth>0</wp14:pctWidth></wp14:sizeRelH></wp:anchor>`, 1)
+	doc.parts["word/document.xml"] = []byte(content)
+	if err := doc.parseDocument(); err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+
+	saved := savedDocumentXML(t, doc, filename)
+	for _, expected := range []string{
+		`<wps:cNvSpPr txBox="1">`,
+		`<a:prstGeom prst="ellipse">`,
+		`<v:oval`,
+		`<wp14:pctWidth>0</wp14:pctWidth>`,
+	} {
+		if !strings.Contains(saved, expected) {
+			t.Errorf("saved document.xml should contain %s", expected)
+		}
+	}
+	if count := strings.Count(saved, "Did you know?"); count != 2 {
+		t.Errorf("expected the text box content twice, got %d", count)
+	}
+
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	if reopened := savedDocumentXML(t, opened, filename); !strings.Contains(reopened, "Did you know?") {
+		t.Error("the text box should survive a second round trip")
+	}
+}
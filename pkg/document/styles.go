@@ -0,0 +1,554 @@
+// Package document provides style sheet persistence and import support
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/drumkitai/go-word/pkg/style"
+)
+
+// ImportStylesFrom copies styles from another document
+//
+// Each requested style is copied together with its basedOn chain, its next paragraph style
+// and its linked style. When styleIDs is empty all styles of the source document are copied.
+// Existing styles are replaced only when overwrite is true.
+//
+// Paragraph styles that reference a numbering definition (w:numPr) bring the definition along,
+// renumbered so it does not collide with the lists of this document.
+func (d *Document) ImportStylesFrom(source *Document, styleIDs []string, overwrite bool) error {
+	if source == nil || source.styleManager == nil {
+		return NewValidationError("source", "", "source document cannot be nil")
+	}
+
+	imported, err := d.styleManager.ImportStyles(source.styleManager, styleIDs, overwrite)
+	if err != nil {
+		return WrapError("import_styles", err)
+	}
+
+	numIDs := make(map[string]string)
+	for _, styleID := range imported {
+		s := d.styleManager.GetStyle(styleID)
+		if s.ParagraphPr == nil || s.ParagraphPr.NumberingProperties == nil || s.ParagraphPr.NumberingProperties.NumID == nil {
+			continue
+		}
+
+		numID := s.ParagraphPr.NumberingProperties.NumID.Val
+		if numID == "" || numID == "0" {
+			continue
+		}
+
+		newNumID, ok := numIDs[numID]
+		if !ok {
+			newNumID, err = d.importNumbering(source, numID)
+			if err != nil {
+				return WrapErrorWithContext("import_styles", err, styleID)
+			}
+			numIDs[numID] = newNumID
+		}
+		s.ParagraphPr.NumberingProperties.NumID.Val = newNumID
+	}
+
+	Infof("Imported %d styles", len(imported))
+	return nil
+}
+
+// importNumbering copies a numbering instance and its abstract definition from another document
+// and returns the ID of the new instance
+func (d *Document) importNumbering(source *Document, numID string) (string, error) {
+	data, ok := source.parts["word/numbering.xml"]
+	if !ok {
+		return "", fmt.Errorf("numbering definitions not found in source document")
+	}
+
+	var numbering Numbering
+	if err := style.UnmarshalPrefixedXML(data, &numbering); err != nil {
+		return "", fmt.Errorf("failed to parse numbering definitions: %v", err)
+	}
+
+	var instance *NumInstance
+	for _, num := range numbering.NumberingInstances {
+		if num.NumID == numID {
+			instance = num
+			break
+		}
+	}
+	if instance == nil || instance.AbstractNumID == nil {
+		return "", fmt.Errorf("numbering instance %s not found", numID)
+	}
+
+	var abstractNum *AbstractNum
+	for _, abs := range numbering.AbstractNums {
+		if abs.AbstractNumID == instance.AbstractNumID.Val {
+			abstractNum = abs
+			break
+		}
+	}
+	if abstractNum == nil {
+		return "", fmt.Errorf("abstract numbering %s not found", instance.AbstractNumID.Val)
+	}
+
+	// the IDs of the source document are replaced by free IDs of this document
+	levels := abstractNum.Levels
+	for _, override := range instance.LevelOverrides {
+		if override.Level != nil {
+			levels = append(levels, override.Level)
+		}
+	}
+	picBulletIDs := make(map[string]string)
+	for _, level := range levels {
+		if level.PicBullet == nil {
+			continue
+		}
+		id, ok := picBulletIDs[level.PicBullet.Val]
+		if !ok {
+			data := source.pictureBulletData(level.PicBullet.Val)
+			if data == nil {
+				return "", fmt.Errorf("picture bullet %s not found", level.PicBullet.Val)
+			}
+			var err error
+			if id, err = d.addPictureBullet(data); err != nil {
+				return "", err
+			}
+			picBulletIDs[level.PicBullet.Val] = id
+		}
+		level.PicBullet.Val = id
+	}
+
+	manager := d.numberingManager()
+
+	abstractNum.AbstractNumID = manager.newAbstractNumID()
+	manager.abstractNums["imported_"+abstractNum.AbstractNumID] = abstractNum
+
+	newNumID := manager.newNumID()
+	manager.numInstances[newNumID] = &NumInstance{
+		NumID:          newNumID,
+		AbstractNumID:  &AbstractNumReference{Val: abstractNum.AbstractNumID},
+		LevelOverrides: instance.LevelOverrides,
+	}
+
+	d.ensureNumberingInitialized()
+	d.updateNumberingFile()
+
+	return newNumID, nil
+}
+
+// StyleUsage reports how often a style is referenced from document content
+type StyleUsage struct {
+	StyleID    string
+	Defined    bool // whether the style exists in the style manager
+	Paragraphs int  // paragraphs referencing the style through w:pStyle
+	Runs       int  // runs referencing the style through w:rStyle
+	Tables     int  // tables referencing the style through w:tblStyle
+	Numbering  int  // references from numbering definitions
+}
+
+// Total returns the total number of references to the style
+func (u *StyleUsage) Total() int {
+	return u.Paragraphs + u.Runs + u.Tables + u.Numbering
+}
+
+// style reference kinds
+const (
+	styleRefParagraph = "paragraph"
+	styleRefRun       = "run"
+	styleRefTable     = "table"
+	styleRefNumbering = "numbering"
+)
+
+// styleRefElements maps the elements referencing styles to their reference kind
+var styleRefElements = map[string]string{
+	"pStyle":       styleRefParagraph,
+	"rStyle":       styleRefRun,
+	"tblStyle":     styleRefTable,
+	"styleLink":    styleRefNumbering,
+	"numStyleLink": styleRefNumbering,
+}
+
+// styleRefPattern matches style references in raw part XML
+var styleRefPattern = regexp.MustCompile(`(<w:(?:pStyle|rStyle|tblStyle|styleLink|numStyleLink)\b[^>]*?\bw:val=")([^"]*)(")`)
+
+// StyleUsage reports the styles referenced by paragraphs, runs and tables of the document body,
+// headers, footers, footnotes, endnotes and comments, and by numbering definitions.
+// Every defined style is included, so unused styles are reported with a total of zero.
+// Referenced styles that are not defined are reported with Defined set to false.
+func (d *Document) StyleUsage() map[string]*StyleUsage {
+	usage := make(map[string]*StyleUsage)
+	get := func(styleID string) *StyleUsage {
+		u, ok := usage[styleID]
+		if !ok {
+			u = &StyleUsage{StyleID: styleID}
+			usage[styleID] = u
+		}
+		return u
+	}
+
+	for _, s := range d.styleManager.GetAllStyles() {
+		get(s.StyleID).Defined = true
+	}
+
+	count := func(kind, styleID string) {
+		if styleID == "" {
+			return
+		}
+		u := get(styleID)
+		switch kind {
+		case styleRefParagraph:
+			u.Paragraphs++
+		case styleRefRun:
+			u.Runs++
+		case styleRefTable:
+			u.Tables++
+		}
+	}
+
+	if d.Body != nil {
+		walkElementStyles(d.Body.Elements, func(kind string, val *string) {
+			count(kind, *val)
+		})
+	}
+
+	for _, partName := range d.styleReferencingParts() {
+		isNumbering := partName == "word/numbering.xml"
+		for _, match := range styleRefPattern.FindAllSubmatch(d.parts[partName], -1) {
+			styleID := string(match[2])
+			if isNumbering {
+				if styleID != "" {
+					get(styleID).Numbering++
+				}
+				continue
+			}
+			count(styleRefKind(match[1]), styleID)
+		}
+	}
+
+	return usage
+}
+
+// RemoveUnusedStyles removes styles that are not referenced anywhere in the document
+// Default styles and the styles used ones depend on (basedOn, next and linked styles) are kept.
+// The IDs of the removed styles are returned sorted.
+func (d *Document) RemoveUnusedStyles() []string {
+	keep := make(map[string]bool)
+	var visit func(styleID string)
+	visit = func(styleID string) {
+		if styleID == "" || keep[styleID] {
+			return
+		}
+		keep[styleID] = true
+		s := d.styleManager.GetStyle(styleID)
+		if s == nil {
+			return
+		}
+		if s.BasedOn != nil {
+			visit(s.BasedOn.Val)
+		}
+		if s.Next != nil {
+			visit(s.Next.Val)
+		}
+		if s.Link != nil {
+			visit(s.Link.Val)
+		}
+	}
+
+	for styleID, u := range d.StyleUsage() {
+		if u.Total() > 0 {
+			visit(styleID)
+		}
+	}
+	for _, s := range d.styleManager.GetAllStyles() {
+		if s.Default {
+			visit(s.StyleID)
+		}
+	}
+
+	var removed []string
+	for _, s := range d.styleManager.GetAllStyles() {
+		if !keep[s.StyleID] {
+			removed = append(removed, s.StyleID)
+		}
+	}
+	sort.Strings(removed)
+
+	for _, styleID := range removed {
+		d.styleManager.RemoveStyle(styleID)
+	}
+
+	Infof("Removed %d unused styles", len(removed))
+	return removed
+}
+
+// ReplaceStyle rewrites every reference to oldID so it points to newID and removes the old style
+// References in content, numbering definitions and other styles (basedOn, next, link) are updated.
+// Both styles must be of the same type when the old style is defined.
+func (d *Document) ReplaceStyle(oldID, newID string) error {
+	if oldID == "" || newID == "" || oldID == newID {
+		return NewValidationError("styleID", oldID, "old and new style IDs must be different and not empty")
+	}
+
+	newStyle := d.styleManager.GetStyle(newID)
+	if newStyle == nil {
+		return NewValidationError("newID", newID, "style not found")
+	}
+	oldStyle := d.styleManager.GetStyle(oldID)
+	if oldStyle != nil && oldStyle.Type != newStyle.Type {
+		return NewValidationError("newID", newID, fmt.Sprintf("style type %s does not match %s", newStyle.Type, oldStyle.Type))
+	}
+
+	if d.Body != nil {
+		walkElementStyles(d.Body.Elements, func(kind string, val *string) {
+			if *val == oldID {
+				*val = newID
+			}
+		})
+	}
+
+	for _, partName := range d.styleReferencingParts() {
+		d.parts[partName] = styleRefPattern.ReplaceAllFunc(d.parts[partName], func(match []byte) []byte {
+			sub := styleRefPattern.FindSubmatch(match)
+			if string(sub[2]) != oldID {
+				return match
+			}
+			return append(append(append([]byte{}, sub[1]...), newID...), sub[3]...)
+		})
+	}
+	// the numbering definitions are read again from the rewritten numbering.xml
+	d.numbering = nil
+
+	for _, s := range d.styleManager.GetAllStyles() {
+		if s.BasedOn != nil && s.BasedOn.Val == oldID {
+			// the replacement style takes over the parent of the style it replaces
+			if s.StyleID == newID {
+				s.BasedOn = nil
+				if oldStyle != nil && oldStyle.BasedOn != nil && oldStyle.BasedOn.Val != newID {
+					s.BasedOn = &style.BasedOn{Val: oldStyle.BasedOn.Val}
+				}
+			} else {
+				s.BasedOn.Val = newID
+			}
+		}
+		if s.Next != nil && s.Next.Val == oldID {
+			s.Next.Val = newID
+		}
+		if s.Link != nil && s.Link.Val == oldID {
+			s.Link.Val = newID
+		}
+	}
+
+	d.styleManager.RemoveStyle(oldID)
+
+	Infof("Replaced style %s with %s", oldID, newID)
+	return nil
+}
+
+// styleRefKind returns the reference kind of a matched style reference prefix
+func styleRefKind(prefix []byte) string {
+	name := strings.TrimPrefix(string(prefix), "<w:")
+	if i := strings.IndexAny(name, " \t\r\n"); i >= 0 {
+		name = name[:i]
+	}
+	return styleRefElements[name]
+}
+
+// styleReferencingParts returns the raw parts that may reference styles, sorted by name
+func (d *Document) styleReferencingParts() []string {
+	var names []string
+	for name := range d.parts {
+		switch {
+		case strings.HasPrefix(name, "word/header") && strings.HasSuffix(name, ".xml"),
+			strings.HasPrefix(name, "word/footer") && strings.HasSuffix(name, ".xml"),
+			name == "word/footnotes.xml", name == "word/endnotes.xml",
+			name == "word/comments.xml", name == "word/numbering.xml":
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// walkElementStyles calls fn for every style reference in body elements
+func walkElementStyles(elements []interface{}, fn func(kind string, val *string)) {
+	for _, element := range elements {
+		switch elem := element.(type) {
+		case *Paragraph:
+			walkParagraphStyles(elem, fn)
+		case *Table:
+			walkTableStyles(elem, fn)
+		case *SDT:
+			if elem.Content != nil {
+				walkElementStyles(elem.Content.Elements, fn)
+			}
+		}
+	}
+}
+
+// walkParagraphStyles calls fn for the paragraph style and run styles of a paragraph
+func walkParagraphStyles(p *Paragraph, fn func(kind string, val *string)) {
+	if p.Properties != nil && p.Properties.ParagraphStyle != nil {
+		fn(styleRefParagraph, &p.Properties.ParagraphStyle.Val)
+	}
+	for i := range p.Runs {
+		if p.Runs[i].Properties != nil && p.Runs[i].Properties.RunStyle != nil {
+			fn(styleRefRun, &p.Runs[i].Properties.RunStyle.Val)
+		}
+	}
+}
+
+// walkTableStyles calls fn for the table style and all styles used inside its cells
+func walkTableStyles(t *Table, fn func(kind string, val *string)) {
+	if t.Properties != nil && t.Properties.TableStyle != nil {
+		fn(styleRefTable, &t.Properties.TableStyle.Val)
+	}
+	for i := range t.Rows {
+		for j := range t.Rows[i].Cells {
+			cell := &t.Rows[i].Cells[j]
+			for k := range cell.Paragraphs {
+				walkParagraphStyles(&cell.Paragraphs[k], fn)
+			}
+			for k := range cell.Tables {
+				walkTableStyles(&cell.Tables[k], fn)
+			}
+		}
+	}
+}
+
+// mergeStylesPart writes the styles of the style manager into an existing styles.xml
+//
+// The document defaults, latent styles and the styles left unchanged keep their XML as read.
+// Changed styles are rewritten in place, removed styles are dropped and new styles are added
+// after the last style. Predefined styles that styles.xml did not contain are only added when
+// they are changed or referenced, so that saving an opened document does not grow its styles.
+func (d *Document) mergeStylesPart(data []byte) ([]byte, error) {
+	type stylesXML struct {
+		XMLName xml.Name      `xml:"w:styles"`
+		Styles  []style.Style `xml:"w:style"`
+	}
+	var original stylesXML
+	if err := style.UnmarshalPrefixedXML(data, &original); err != nil {
+		return nil, WrapError("parse_styles", err)
+	}
+	originalXML := make(map[string][]byte, len(original.Styles))
+	for i := range original.Styles {
+		marshaled, err := xml.Marshal(&original.Styles[i])
+		if err != nil {
+			return nil, WrapError("marshal_styles", err)
+		}
+		originalXML[original.Styles[i].StyleID] = marshaled
+	}
+	current := make(map[string][]byte)
+	for _, s := range d.styleManager.GetAllStyles() {
+		marshaled, err := xml.Marshal(s)
+		if err != nil {
+			return nil, WrapError("marshal_styles", err)
+		}
+		current[s.StyleID] = marshaled
+	}
+
+	// byte ranges of the style elements, and the end of the last one or else the root end tag
+	type styleRange struct {
+		id         string
+		start, end int
+	}
+	var ranges []styleRange
+	insertAt, depth := -1, 0
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, WrapError("parse_styles", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 && t.Name.Local == "style" {
+				ranges = append(ranges, styleRange{id: getAttributeValue(t.Attr, "styleId"), start: offset})
+			}
+		case xml.EndElement:
+			if depth == 2 && t.Name.Local == "style" && len(ranges) > 0 {
+				ranges[len(ranges)-1].end = int(decoder.InputOffset())
+				insertAt = ranges[len(ranges)-1].end
+			} else if depth == 1 && len(ranges) == 0 {
+				insertAt = offset
+			}
+			depth--
+		}
+	}
+	if insertAt < 0 {
+		return nil, fmt.Errorf("styles.xml has no root element")
+	}
+
+	// new styles, leaving out the unchanged predefined styles nothing refers to
+	predefined := style.NewStyleManager()
+	usage := d.StyleUsage()
+	pending := make(map[string]bool)
+	written := make(map[string]bool)
+	for id := range current {
+		if _, ok := originalXML[id]; ok {
+			written[id] = true
+			continue
+		}
+		if p := predefined.GetStyle(id); p != nil && usage[id].Total() == 0 {
+			if marshaled, err := xml.Marshal(p); err == nil && bytes.Equal(marshaled, current[id]) {
+				pending[id] = true
+				continue
+			}
+		}
+		written[id] = true
+	}
+	for added := true; added; {
+		added = false
+		for id := range written {
+			s := d.styleManager.GetStyle(id)
+			var refs []string
+			if s.BasedOn != nil {
+				refs = append(refs, s.BasedOn.Val)
+			}
+			if s.Next != nil {
+				refs = append(refs, s.Next.Val)
+			}
+			if s.Link != nil {
+				refs = append(refs, s.Link.Val)
+			}
+			for _, val := range refs {
+				if pending[val] {
+					delete(pending, val)
+					written[val], added = true, true
+				}
+			}
+		}
+	}
+	var newIDs []string
+	for id := range written {
+		if _, ok := originalXML[id]; !ok {
+			newIDs = append(newIDs, id)
+		}
+	}
+	sort.Strings(newIDs)
+
+	var buf bytes.Buffer
+	kept := 0
+	for _, r := range ranges {
+		if cur, ok := current[r.id]; ok && bytes.Equal(cur, originalXML[r.id]) {
+			continue
+		}
+		buf.Write(data[kept:r.start])
+		buf.Write(current[r.id])
+		kept = r.end
+	}
+	buf.Write(data[kept:insertAt])
+	for _, id := range newIDs {
+		buf.Write(current[id])
+	}
+	buf.Write(data[insertAt:])
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,366 @@
+package document
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/drumkitai/go-word/pkg/style"
+)
+
+const testStylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults><w:rPrDefault><w:rPr><w:sz w:val="21"/></w:rPr></w:rPrDefault></w:docDefaults>
+  <w:latentStyles w:defLockedState="0"/>
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>
+  <w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:uiPriority w:val="9"/><w:rPr><w:b/></w:rPr></w:style>
+  <w:style w:type="paragraph" w:styleId="Obsolete"><w:name w:val="Obsolete"/></w:style>
+</w:styles>`
+
+// newDocumentWithStyles creates a document that behaves as if it was opened with the given styles.xml
+func newDocumentWithStyles(t *testing.T, stylesXML string) *Document {
+	doc := New()
+	doc.parts["word/styles.xml"] = []byte(stylesXML)
+	doc.styleManager = style.NewStyleManager()
+	if err := doc.parseStyles(); err != nil {
+		t.Fatalf("failed to parse styles: %v", err)
+	}
+	return doc
+}
+
+// TestOpenedStylesLoaded tests that the styles of an opened document are read from styles.xml
+func TestOpenedStylesLoaded(t *testing.T) {
+	doc := newDocumentWithStyles(t, testStylesXML)
+	sm := doc.GetStyleManager()
+
+	if !sm.StyleExists("Obsolete") {
+		t.Fatal("styles of the opened document should be loaded")
+	}
+	heading := sm.GetStyle("Heading1")
+	if heading.Name == nil || heading.Name.Val != "heading 1" {
+		t.Errorf("style name should be read, got %+v", heading.Name)
+	}
+	if heading.BasedOn == nil || heading.BasedOn.Val != "Normal" {
+		t.Error("basedOn should be read")
+	}
+	if heading.RunPr == nil || heading.RunPr.Bold == nil {
+		t.Error("run properties should be read")
+	}
+	if normal := sm.GetStyle("Normal"); normal.Type != string(style.StyleTypeParagraph) || !normal.Default {
+		t.Errorf("type and default attributes should be read, got %q %v", normal.Type, normal.Default)
+	}
+
+}
+
+// TestOpenedStylesSaved tests that style changes of an opened document are written to styles.xml
+func TestOpenedStylesSaved(t *testing.T) {
+	filename := "test_opened_styles_saved.docx"
+	defer os.Remove(filename)
+
+	source := New()
+	source.GetStyleManager().CreateCustomStyle("Imported", "Imported", style.StyleTypeParagraph, "Heading2")
+	doc := newDocumentWithStyles(t, testStylesXML)
+	if err := doc.ImportStylesFrom(source, []string{"Imported"}, false); err != nil {
+		t.Fatalf("failed to import styles: %v", err)
+	}
+	doc.GetStyleManager().GetStyle("Obsolete").Name.Val = "Renamed"
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	reopened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	reopened.GetStyleManager().CreateCustomStyle("Later", "Later", style.StyleTypeParagraph, "Normal")
+	if err := reopened.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	if reopened, err = Open(filename); err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+
+	sm := reopened.GetStyleManager()
+	for _, id := range []string{"Imported", "Heading2", "Later"} {
+		if !sm.StyleExists(id) || !strings.Contains(string(reopened.parts["word/styles.xml"]), `w:styleId="`+id+`"`) {
+			t.Errorf("style %s should be saved", id)
+		}
+	}
+	if s := sm.GetStyle("Obsolete"); s == nil || s.Name.Val != "Renamed" {
+		t.Error("changed styles should be saved")
+	}
+	stylesXML := string(reopened.parts["word/styles.xml"])
+	for _, expected := range []string{
+		`<w:docDefaults><w:rPrDefault><w:rPr><w:sz w:val="21"/></w:rPr></w:rPrDefault></w:docDefaults>`,
+		`<w:latentStyles w:defLockedState="0"/>`,
+		`<w:uiPriority w:val="9"/>`,
+	} {
+		if !strings.Contains(stylesXML, expected) {
+			t.Errorf("styles.xml should keep %s", expected)
+		}
+	}
+	if strings.Contains(stylesXML, `w:styleId="Heading3"`) {
+		t.Error("unused predefined styles should not be added")
+	}
+}
+
+// TestImportStylesFrom tests importing styles and their numbering from another document
+func TestImportStylesFrom(t *testing.T) {
+	source := New()
+	item := source.AddNumberedList("item", 0, ListTypeDecimal)
+	numID := item.Properties.NumberingProperties.NumID.Val
+
+	api := style.NewQuickStyleAPI(source.GetStyleManager())
+	if _, err := api.CreateQuickStyle(style.QuickStyleConfig{
+		ID:      "Clause",
+		Name:    "Clause",
+		Type:    style.StyleTypeParagraph,
+		BasedOn: "Heading1",
+		RunConfig: &style.QuickRunConfig{
+			FontColor: "C00000",
+		},
+	}); err != nil {
+		t.Fatalf("failed to create style: %v", err)
+	}
+	source.GetStyleManager().GetStyle("Clause").ParagraphPr = &style.ParagraphProperties{
+		NumberingProperties: &style.NumberingProperties{
+			ILevel: &style.ILevel{Val: "0"},
+			NumID:  &style.NumID{Val: numID},
+		},
+	}
+
+	target := newDocumentWithStyles(t, testStylesXML)
+	own := target.AddNumberedList("own item", 0, ListTypeDecimal).Properties.NumberingProperties.NumID.Val
+	if err := target.ImportStylesFrom(source, []string{"Clause"}, false); err != nil {
+		t.Fatalf("failed to import styles: %v", err)
+	}
+
+	clause := target.GetStyleManager().GetStyle("Clause")
+	if clause == nil || clause.RunPr == nil || clause.RunPr.Color.Val != "C00000" {
+		t.Fatal("style should be imported with its formatting")
+	}
+	newNumID := clause.ParagraphPr.NumberingProperties.NumID.Val
+	if newNumID == own {
+		t.Error("imported numbering should not take the ID of a list of the target")
+	}
+	instance := target.numberingManager().numInstances[newNumID]
+	if instance == nil || instance.AbstractNumID.Val == target.numberingManager().numInstances[own].AbstractNumID.Val {
+		t.Error("imported numbering should get its own abstract numbering")
+	}
+	if _, ok := target.numberingManager().numInstances[newNumID]; !ok {
+		t.Error("imported numbering instance should be registered")
+	}
+	if !strings.Contains(string(target.parts["word/numbering.xml"]), `w:numId="`+newNumID+`"`) {
+		t.Error("imported numbering should be written to numbering.xml")
+	}
+
+	// Heading1 already exists in the target and is kept
+	heading := target.GetStyleManager().GetStyle("Heading1")
+	if heading.Name.Val != "heading 1" {
+		t.Error("existing style should not be replaced without overwrite")
+	}
+
+	if err := target.ImportStylesFrom(source, []string{"Missing"}, false); err == nil {
+		t.Error("expected error for missing style")
+	}
+}
+
+// TestStyleUsage tests collecting style references from content and raw parts
+func TestStyleUsage(t *testing.T) {
+	doc := New()
+	doc.AddHeadingParagraph("Title", 1)
+	para := doc.AddParagraph("body ")
+	para.AddFormattedText("code", &TextFormat{StyleID: "Emphasis"})
+
+	table, err := doc.AddTable(&TableConfig{Rows: 1, Cols: 1, Width: 4000})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	table.ApplyTableStyle(&TableStyleConfig{StyleID: "TableGrid"})
+	table.Rows[0].Cells[0].Paragraphs[0].Properties = &ParagraphProperties{ParagraphStyle: &ParagraphStyle{Val: "Heading2"}}
+
+	doc.parts["word/header1.xml"] = []byte(`<w:hdr><w:p><w:pPr><w:pStyle w:val="Header"/></w:pPr></w:p></w:hdr>`)
+	doc.parts["word/numbering.xml"] = []byte(`<w:numbering><w:abstractNum><w:lvl w:ilvl="0"><w:pStyle w:val="Heading1"/></w:lvl></w:abstractNum></w:numbering>`)
+
+	usage := doc.StyleUsage()
+
+	if usage["Heading1"].Paragraphs != 1 || usage["Heading1"].Numbering != 1 {
+		t.Errorf("unexpected Heading1 usage: %+v", usage["Heading1"])
+	}
+	if usage["Heading2"].Paragraphs != 1 {
+		t.Errorf("paragraphs in table cells should be counted: %+v", usage["Heading2"])
+	}
+	if usage["Emphasis"].Runs != 1 {
+		t.Errorf("run styles should be counted: %+v", usage["Emphasis"])
+	}
+	if usage["TableGrid"].Tables != 1 || usage["TableGrid"].Defined {
+		t.Errorf("unexpected TableGrid usage: %+v", usage["TableGrid"])
+	}
+	if usage["Header"].Paragraphs != 1 {
+		t.Errorf("header parts should be scanned: %+v", usage["Header"])
+	}
+	if usage["Heading5"].Total() != 0 || !usage["Heading5"].Defined {
+		t.Errorf("unused defined styles should be reported: %+v", usage["Heading5"])
+	}
+}
+
+// TestRemoveUnusedStyles tests removing styles that nothing references
+func TestRemoveUnusedStyles(t *testing.T) {
+	doc := New()
+	sm := doc.GetStyleManager()
+	sm.CreateCustomStyle("Base", "Base", style.StyleTypeParagraph, "Normal")
+	sm.CreateCustomStyle("Used", "Used", style.StyleTypeParagraph, "Base")
+	sm.CreateCustomStyle("Junk", "Junk", style.StyleTypeParagraph, "Normal")
+
+	para := doc.AddParagraph("text")
+	para.SetStyle("Used")
+
+	removed := doc.RemoveUnusedStyles()
+
+	for _, styleID := range []string{"Used", "Base", "Normal"} {
+		if !sm.StyleExists(styleID) {
+			t.Errorf("style %s should be kept", styleID)
+		}
+	}
+	if sm.StyleExists("Junk") || sm.StyleExists("Heading1") {
+		t.Error("unused styles should be removed")
+	}
+
+	found := false
+	for _, styleID := range removed {
+		if styleID == "Junk" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("removed styles should be reported, got %v", removed)
+	}
+}
+
+// TestReplaceStyle tests rewriting style references
+func TestReplaceStyle(t *testing.T) {
+	doc := New()
+	sm := doc.GetStyleManager()
+	sm.CreateCustomStyle("VendorHeading", "Vendor Heading", style.StyleTypeParagraph, "Normal")
+	sm.CreateCustomStyle("VendorChild", "Vendor Child", style.StyleTypeParagraph, "VendorHeading")
+
+	para := doc.AddParagraph("text")
+	para.SetStyle("VendorHeading")
+	doc.parts["word/footer1.xml"] = []byte(`<w:ftr><w:p><w:pPr><w:pStyle w:val="VendorHeading"/></w:pPr></w:p></w:ftr>`)
+	doc.parts["word/numbering.xml"] = []byte(`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:pStyle w:val="VendorHeading"/></w:lvl></w:abstractNum>` +
+		`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num></w:numbering>`)
+	if level := doc.numberingLevels("1")[0]; level.PStyle == nil || level.PStyle.Val != "VendorHeading" {
+		t.Fatal("the numbering definition should be read")
+	}
+
+	if err := doc.ReplaceStyle("VendorHeading", "Heading1"); err != nil {
+		t.Fatalf("failed to replace style: %v", err)
+	}
+
+	if para.Properties.ParagraphStyle.Val != "Heading1" {
+		t.Error("paragraph reference should be rewritten")
+	}
+	if !strings.Contains(string(doc.parts["word/footer1.xml"]), `<w:pStyle w:val="Heading1"/>`) {
+		t.Errorf("footer reference should be rewritten: %s", doc.parts["word/footer1.xml"])
+	}
+	if level := doc.numberingLevels("1")[0]; level.PStyle == nil || level.PStyle.Val != "Heading1" {
+		t.Error("numbering reference should be rewritten")
+	}
+	if sm.GetStyle("VendorChild").BasedOn.Val != "Heading1" {
+		t.Error("basedOn reference should be rewritten")
+	}
+	if sm.StyleExists("VendorHeading") {
+		t.Error("replaced style should be removed")
+	}
+
+	if err := doc.ReplaceStyle("Heading1", "Emphasis"); err == nil {
+		t.Error("expected error when replacing with a style of another type")
+	}
+	if err := doc.ReplaceStyle("Heading1", "Missing"); err == nil {
+		t.Error("expected error when replacing with a missing style")
+	}
+}
+
+// TestCleanUpOpenedStyles tests that removed and replaced styles of an opened document are saved
+func TestCleanUpOpenedStyles(t *testing.T) {
+	filename := "test_clean_up_opened_styles.docx"
+	defer os.Remove(filename)
+
+	doc := newDocumentWithStyles(t, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+  <w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/></w:style>
+  <w:style w:type="paragraph" w:styleId="VendorTitle"><w:name w:val="Vendor Title"/><w:basedOn w:val="Normal"/></w:style>
+  <w:style w:type="paragraph" w:styleId="VendorBody"><w:name w:val="Vendor Body"/><w:basedOn w:val="VendorTitle"/><w:next w:val="VendorTitle"/></w:style>
+  <w:style w:type="paragraph" w:styleId="Junk"><w:name w:val="Junk"/></w:style>
+</w:styles>`)
+	doc.AddParagraph("title").SetStyle("VendorTitle")
+	doc.AddParagraph("body").SetStyle("VendorBody")
+
+	if err := doc.ReplaceStyle("VendorTitle", "Heading1"); err != nil {
+		t.Fatalf("failed to replace style: %v", err)
+	}
+	removed := doc.RemoveUnusedStyles()
+	if len(removed) == 0 {
+		t.Fatal("unused styles should be removed")
+	}
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	reopened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	stylesXML := string(reopened.parts["word/styles.xml"])
+	for _, id := range append(removed, "VendorTitle", "Junk") {
+		if strings.Contains(stylesXML, `w:styleId="`+id+`"`) {
+			t.Errorf("style %s should be removed from styles.xml", id)
+		}
+	}
+	body := reopened.GetStyleManager().GetStyle("VendorBody")
+	if body == nil || body.BasedOn == nil || body.BasedOn.Val != "Heading1" || body.Next == nil || body.Next.Val != "Heading1" {
+		t.Error("references to the replaced style should be rewritten in styles.xml")
+	}
+	if paragraphs := reopened.Body.GetParagraphs(); paragraphs[len(paragraphs)-2].Properties.ParagraphStyle.Val != "Heading1" {
+		t.Error("paragraphs should use the replacement style")
+	}
+}
+
+// TestImportPictureBulletNumbering tests that imported picture bullets get IDs of the target
+func TestImportPictureBulletNumbering(t *testing.T) {
+	source := New()
+	sourceBullet := createTestImage(16, 16)
+	numID, err := source.AddListDefinition(&ListDefinition{Levels: []ListLevelDefinition{{PictureBullet: sourceBullet}}})
+	if err != nil {
+		t.Fatalf("failed to add list definition: %v", err)
+	}
+	source.GetStyleManager().AddStyle(&style.Style{
+		Type:    string(style.StyleTypeParagraph),
+		StyleID: "Checklist",
+		Name:    &style.StyleName{Val: "Checklist"},
+		ParagraphPr: &style.ParagraphProperties{
+			NumberingProperties: &style.NumberingProperties{ILevel: &style.ILevel{Val: "0"}, NumID: &style.NumID{Val: numID}},
+		},
+	})
+
+	target := newDocumentWithStyles(t, testStylesXML)
+	if _, err := target.AddListDefinition(&ListDefinition{Levels: []ListLevelDefinition{{PictureBullet: createTestImage(8, 8)}}}); err != nil {
+		t.Fatalf("failed to add list definition: %v", err)
+	}
+	if err := target.ImportStylesFrom(source, []string{"Checklist"}, false); err != nil {
+		t.Fatalf("failed to import styles: %v", err)
+	}
+
+	manager := target.numberingManager()
+	instance := manager.numInstances[target.GetStyleManager().GetStyle("Checklist").ParagraphPr.NumberingProperties.NumID.Val]
+	level := manager.abstractNum(instance.AbstractNumID.Val).Levels[0]
+	if level.PicBullet == nil || level.PicBullet.Val != "1" {
+		t.Fatalf("imported picture bullet should get a free ID, got %+v", level.PicBullet)
+	}
+	if !bytes.Equal(target.pictureBulletData(level.PicBullet.Val), sourceBullet) {
+		t.Error("imported picture bullet should keep its image")
+	}
+}
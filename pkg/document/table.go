@@ -13,20 +13,26 @@ type Table struct {
 	Properties *TableProperties `xml:"w:tblPr,omitempty"`
 	Grid       *TableGrid       `xml:"w:tblGrid,omitempty"`
 	Rows       []TableRow       `xml:"w:tr"`
+
+	conditionalFormats  []*conditionalFormat              // rules added with AddConditionalFormat
+	conditionalBaseline map[cellIndex]*cellFormatBaseline // cell formatting before the rules applied, by position
+	doc                 *Document                         // document the table was created in or read from
 }
 
 // TableProperties represents table properties
+// Note: the field order must conform to the OpenXML standard, w:tblStyle first and w:tblLook last,
+// otherwise Word ignores the referenced table style
 type TableProperties struct {
 	XMLName      xml.Name          `xml:"w:tblPr"`
+	TableStyle   *TableStyle       `xml:"w:tblStyle,omitempty"`
 	TableW       *TableWidth       `xml:"w:tblW,omitempty"`
 	TableJc      *TableJc          `xml:"w:jc,omitempty"`
-	TableLook    *TableLook        `xml:"w:tblLook,omitempty"`
-	TableStyle   *TableStyle       `xml:"w:tblStyle,omitempty"`
+	TableInd     *TableIndentation `xml:"w:tblInd,omitempty"`
 	TableBorders *TableBorders     `xml:"w:tblBorders,omitempty"`
 	Shd          *TableShading     `xml:"w:shd,omitempty"`
-	TableCellMar *TableCellMargins `xml:"w:tblCellMar,omitempty"`
 	TableLayout  *TableLayoutType  `xml:"w:tblLayout,omitempty"`
-	TableInd     *TableIndentation `xml:"w:tblInd,omitempty"`
+	TableCellMar *TableCellMargins `xml:"w:tblCellMar,omitempty"`
+	TableLook    *TableLook        `xml:"w:tblLook,omitempty"`
 }
 
 // TableWidth represents table width
@@ -131,17 +137,19 @@ func (tc *TableCell) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 }
 
 // TableCellProperties represents table cell properties
+// Note: the field order must conform to the OpenXML standard (CT_TcPr)
 type TableCellProperties struct {
 	XMLName       xml.Name              `xml:"w:tcPr"`
 	TableCellW    *TableCellW           `xml:"w:tcW,omitempty"`
-	VAlign        *VAlign               `xml:"w:vAlign,omitempty"`
 	GridSpan      *GridSpan             `xml:"w:gridSpan,omitempty"`
 	VMerge        *VMerge               `xml:"w:vMerge,omitempty"`
-	TextDirection *TextDirection        `xml:"w:textDirection,omitempty"`
-	Shd           *TableCellShading     `xml:"w:shd,omitempty"`
 	TcBorders     *TableCellBorders     `xml:"w:tcBorders,omitempty"`
-	TcMar         *TableCellMarginsCell `xml:"w:tcMar,omitempty"`
+	Shd           *TableCellShading     `xml:"w:shd,omitempty"`
 	NoWrap        *NoWrap               `xml:"w:noWrap,omitempty"`
+	TcMar         *TableCellMarginsCell `xml:"w:tcMar,omitempty"`
+	TextDirection *TextDirection        `xml:"w:textDirection,omitempty"`
+	TcFitText     *TcFitText            `xml:"w:tcFitText,omitempty"`
+	VAlign        *VAlign               `xml:"w:vAlign,omitempty"`
 	HideMark      *HideMark             `xml:"w:hideMark,omitempty"`
 }
 
@@ -282,6 +290,7 @@ func (d *Document) CreateTable(config *TableConfig) (*Table, error) {
 		},
 		Grid: &TableGrid{},
 		Rows: make([]TableRow, 0, config.Rows),
+		doc:  d,
 	}
 
 	colWidths := config.ColWidths
@@ -302,7 +311,6 @@ func (d *Document) CreateTable(config *TableConfig) (*Table, error) {
 		})
 	}
 
-
 	for i := 0; i < config.Rows; i++ {
 		row := TableRow{
 			Cells: make([]TableCell, 0, config.Cols),
@@ -732,6 +740,7 @@ func (t *Table) CopyTable() *Table {
 		Properties: t.Properties,
 		Grid:       t.Grid,
 		Rows:       make([]TableRow, len(t.Rows)),
+		doc:        t.doc,
 	}
 
 	// 复制所有行和单元格
@@ -821,6 +830,20 @@ type CellFormat struct {
 	BackgroundColor string                // 背景颜色
 	BorderStyle     string                // border style
 	Padding         int                   // 内边距（points）
+	Margins         *CellMargins          // per-side cell margins, override Padding
+	NoWrap          bool                  // do not wrap the cell text
+	FitText         bool                  // fit the text to the cell width
+	HideMark        bool                  // ignore the end of cell mark when computing the row height
+	DiagonalDown    *BorderConfig         // diagonal border from top left to bottom right
+	DiagonalUp      *BorderConfig         // diagonal border from top right to bottom left
+}
+
+// CellMargins per-side cell margins in twips (1 point = 20 twips)
+type CellMargins struct {
+	Top    int
+	Left   int
+	Bottom int
+	Right  int
 }
 
 // SetCellFormat sets cell format
@@ -849,6 +872,38 @@ func (t *Table) SetCellFormat(row, col int, format *CellFormat) error {
 		}
 	}
 
+	// set cell margins
+	if format.Margins != nil {
+		cell.Properties.TcMar = newTableCellMargins(format.Margins)
+	} else if format.Padding > 0 {
+		padding := format.Padding * 20
+		cell.Properties.TcMar = newTableCellMargins(&CellMargins{Top: padding, Left: padding, Bottom: padding, Right: padding})
+	}
+
+	// set cell layout options
+	if format.NoWrap {
+		cell.Properties.NoWrap = &NoWrap{}
+	}
+	if format.FitText {
+		cell.Properties.TcFitText = &TcFitText{}
+	}
+	if format.HideMark {
+		cell.Properties.HideMark = &HideMark{}
+	}
+
+	// set diagonal borders, keeping the other borders of the cell
+	if format.DiagonalDown != nil || format.DiagonalUp != nil {
+		if cell.Properties.TcBorders == nil {
+			cell.Properties.TcBorders = &TableCellBorders{}
+		}
+		if format.DiagonalDown != nil {
+			cell.Properties.TcBorders.TL2BR = createTableCellBorder(format.DiagonalDown)
+		}
+		if format.DiagonalUp != nil {
+			cell.Properties.TcBorders.TR2BL = createTableCellBorder(format.DiagonalUp)
+		}
+	}
+
 	// 确保单元格有段落
 	if len(cell.Paragraphs) == 0 {
 		cell.Paragraphs = []Paragraph{{}}
@@ -952,6 +1007,8 @@ func (t *Table) SetCellFormattedText(row, col int, text string, format *TextForm
 				Val: fmt.Sprintf("%d", format.FontSize*2),
 			}
 		}
+
+		run.Properties.RunStyle = format.runStyle()
 	}
 
 	// set cell content
@@ -1010,6 +1067,8 @@ func (t *Table) AddCellFormattedText(row, col int, text string, format *TextForm
 				Val: fmt.Sprintf("%d", format.FontSize*2),
 			}
 		}
+
+		run.Properties.RunStyle = format.runStyle()
 	}
 
 	// 添加运行到第一个段落
@@ -1323,17 +1382,64 @@ func (t *Table) ClearCellFormat(row, col int) error {
 
 // SetCellPadding sets cell padding
 func (t *Table) SetCellPadding(row, col int, padding int) error {
-	_, err := t.GetCell(row, col)
+	cell, err := t.GetCell(row, col)
 	if err != nil {
 		return err
 	}
 
-	// 单元格内边距通过表格属性设置，这里先预留接口
-	// 实际实现需要在表格级别设置默认内边距
+	if cell.Properties == nil {
+		cell.Properties = &TableCellProperties{}
+	}
+	twips := padding * 20
+	cell.Properties.TcMar = newTableCellMargins(&CellMargins{Top: twips, Left: twips, Bottom: twips, Right: twips})
+
 	Info(fmt.Sprintf("set cell (%d,%d)padding is %dpoints", row, col, padding))
 	return nil
 }
 
+// newTableCellMargins creates cell margins from a margin configuration in twips
+func newTableCellMargins(margins *CellMargins) *TableCellMarginsCell {
+	space := func(w int) *TableCellSpaceCell {
+		return &TableCellSpaceCell{W: fmt.Sprintf("%d", w), Type: "dxa"}
+	}
+	return &TableCellMarginsCell{
+		Top:    space(margins.Top),
+		Left:   space(margins.Left),
+		Bottom: space(margins.Bottom),
+		Right:  space(margins.Right),
+	}
+}
+
+// cellMarginWidth returns a cell margin in twips, 0 when it is not set
+func cellMarginWidth(space *TableCellSpaceCell) int {
+	if space == nil {
+		return 0
+	}
+	var w int
+	fmt.Sscanf(space.W, "%d", &w)
+	return w
+}
+
+// borderConfigFromCellBorder converts a cell border back to a border configuration
+func borderConfigFromCellBorder(border *TableCellBorder) *BorderConfig {
+	if border == nil {
+		return nil
+	}
+	config := &BorderConfig{Style: BorderStyle(border.Val), Color: border.Color}
+	fmt.Sscanf(border.Sz, "%d", &config.Width)
+	fmt.Sscanf(border.Space, "%d", &config.Space)
+	return config
+}
+
+// isOnOffTrue reports whether the value of an OOXML on/off element switches it on
+func isOnOffTrue(val string) bool {
+	switch val {
+	case "", "1", "true", "on":
+		return true
+	}
+	return false
+}
+
 // SetCellTextDirection sets cell text direction
 func (t *Table) SetCellTextDirection(row, col int, direction CellTextDirection) error {
 	cell, err := t.GetCell(row, col)
@@ -1389,6 +1495,25 @@ func (t *Table) GetCellFormat(row, col int) (*CellFormat, error) {
 		format.TextDirection = CellTextDirection(cell.Properties.TextDirection.Val)
 	}
 
+	// get cell margins, layout options and diagonal borders
+	if props := cell.Properties; props != nil {
+		if props.TcMar != nil {
+			format.Margins = &CellMargins{
+				Top:    cellMarginWidth(props.TcMar.Top),
+				Left:   cellMarginWidth(props.TcMar.Left),
+				Bottom: cellMarginWidth(props.TcMar.Bottom),
+				Right:  cellMarginWidth(props.TcMar.Right),
+			}
+		}
+		format.NoWrap = props.NoWrap != nil && isOnOffTrue(props.NoWrap.Val)
+		format.FitText = props.TcFitText != nil && isOnOffTrue(props.TcFitText.Val)
+		format.HideMark = props.HideMark != nil && isOnOffTrue(props.HideMark.Val)
+		if props.TcBorders != nil {
+			format.DiagonalDown = borderConfigFromCellBorder(props.TcBorders.TL2BR)
+			format.DiagonalUp = borderConfigFromCellBorder(props.TcBorders.TR2BL)
+		}
+	}
+
 	// 获取水平对齐
 	if len(cell.Paragraphs) > 0 && cell.Paragraphs[0].Properties != nil && cell.Paragraphs[0].Properties.Justification != nil {
 		format.HorizontalAlign = CellAlignment(cell.Paragraphs[0].Properties.Justification.Val)
@@ -1577,6 +1702,7 @@ type TableLayoutConfig struct {
 	TextWrap    TableTextWrap     // 文字环绕类型
 	Position    TablePosition     // 定位类型
 	Positioning *TablePositioning // 定位详细配置（仅在Position为Floating时有效）
+	AutoFit     AutoFitMode       // optional auto-fit mode, see Table.AutoFit
 }
 
 // SetTableLayout 设置表格布局和定位
@@ -1600,6 +1726,12 @@ func (t *Table) SetTableLayout(config *TableLayoutConfig) error {
 		// 注意：完整的浮动定位实现需要更复杂的XML结构支持
 	}
 
+	if config.AutoFit != "" {
+		if err := t.AutoFit(config.AutoFit); err != nil {
+			return err
+		}
+	}
+
 	Info(fmt.Sprintf("设置表格布局：对齐=%s，环绕=%s，定位=%s",
 		config.Alignment, config.TextWrap, config.Position))
 	return nil
@@ -1948,6 +2080,12 @@ type HideMark struct {
 	Val     string   `xml:"w:val,attr,omitempty"`
 }
 
+// TcFitText fits the cell text to the cell width by adjusting character spacing
+type TcFitText struct {
+	XMLName xml.Name `xml:"w:tcFitText"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
 // ============== 表格样式和外观功能 ==============
 
 // BorderStyle 边框样式常量
@@ -2731,6 +2869,8 @@ func (t *Table) AddCellFormattedParagraph(row, col int, text string, format *Tex
 		if format.Highlight != "" {
 			runProps.Highlight = &Highlight{Val: format.Highlight}
 		}
+
+		runProps.RunStyle = format.runStyle()
 	}
 
 	// 创建新段落
@@ -2855,6 +2995,7 @@ func (t *Table) AddNestedTable(row, col int, config *TableConfig) (*Table, error
 		},
 		Grid: &TableGrid{},
 		Rows: make([]TableRow, 0, config.Rows),
+		doc:  t.doc,
 	}
 
 	// 设置列宽
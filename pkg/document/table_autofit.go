@@ -0,0 +1,332 @@
+// Package document provides automatic table column width computation
+package document
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// AutoFitMode table auto-fit mode
+type AutoFitMode string
+
+const (
+	// AutoFitFixed keeps the current column widths and fixes the layout, so Word does not resize columns
+	AutoFitFixed AutoFitMode = "fixed"
+	// AutoFitContents sizes each column to its content, wrapping only when the table would exceed the available width
+	AutoFitContents AutoFitMode = "contents"
+	// AutoFitWindow sizes the columns to their content and stretches the table to the available width
+	AutoFitWindow AutoFitMode = "window"
+)
+
+// defaultCellMargin is the left and right cell margin in twips Word uses when the table sets none
+const defaultCellMargin = 108
+
+// autoFitAllowance is added to measured column widths in twips, as the font metrics are approximate
+const autoFitAllowance = 30
+
+// minAutoFitColumnWidth is the width in twips given to columns without content
+const minAutoFitColumnWidth = 360
+
+// columnExtent holds the minimum and preferred width of a grid column in twips
+type columnExtent struct {
+	min int // width of the longest word, below which text would break inside words
+	max int // width of the longest paragraph without wrapping
+}
+
+// AutoFit sets the table layout and computes the column widths from the cell contents
+// The available width is the text width of the section containing the table, from its page size
+// and margins. Tables built without a document use the text width of a default A4 page.
+func (t *Table) AutoFit(mode AutoFitMode) error {
+	settings := DefaultPageSettings()
+	if t.doc != nil {
+		settings = sectionPageSettings(t.doc.sectionOf(t))
+	}
+	return t.AutoFitWithin(mode, pageTextWidth(settings))
+}
+
+// AutoFitTable auto-fits a table within the text width of the section that contains it
+func (d *Document) AutoFitTable(table *Table, mode AutoFitMode) error {
+	if table == nil {
+		return NewValidationError("table", "", "table cannot be nil")
+	}
+	return table.AutoFitWithin(mode, pageTextWidth(sectionPageSettings(d.sectionOf(table))))
+}
+
+// AutoFitWithin works like AutoFit with the given available width in twips
+//
+// Text is measured with built-in metrics of Calibri, Arial, Times New Roman and common monospace
+// fonts, using the font and size of each run; other fonts are measured as Calibri. Runs without
+// direct formatting are measured in 10.5pt Calibri, the default of new documents.
+func (t *Table) AutoFitWithin(mode AutoFitMode, availableWidth int) error {
+	if availableWidth <= 0 {
+		return NewValidationError("availableWidth", fmt.Sprintf("%d", availableWidth), "available width must be positive")
+	}
+	if len(t.Rows) == 0 {
+		return fmt.Errorf("table has no rows")
+	}
+	if t.Properties == nil {
+		t.Properties = &TableProperties{}
+	}
+
+	var widths []int
+	switch mode {
+	case AutoFitFixed:
+		widths = t.gridColumnWidths()
+		total := 0
+		for _, w := range widths {
+			total += w
+		}
+		t.Properties.TableLayout = &TableLayoutType{Type: "fixed"}
+		t.Properties.TableW = &TableWidth{W: fmt.Sprintf("%d", total), Type: "dxa"}
+	case AutoFitContents, AutoFitWindow:
+		extents := t.measureColumns()
+		widths = distributeColumnWidths(extents, availableWidth, mode == AutoFitWindow)
+		t.Properties.TableLayout = &TableLayoutType{Type: "autofit"}
+		if mode == AutoFitWindow {
+			t.Properties.TableW = &TableWidth{W: "5000", Type: "pct"}
+		} else {
+			t.Properties.TableW = &TableWidth{W: "0", Type: "auto"}
+		}
+	default:
+		return NewValidationError("mode", string(mode), "unsupported auto-fit mode")
+	}
+
+	t.setGridColumnWidths(widths)
+
+	Info(fmt.Sprintf("table auto-fit (%s): column widths %v", mode, widths))
+	return nil
+}
+
+// GetTextWidth returns the width in twips between the left and right page margins of the last section
+func (d *Document) GetTextWidth() int {
+	return pageTextWidth(d.GetPageSettings())
+}
+
+// pageTextWidth returns the width in twips between the page margins
+func pageTextWidth(settings *PageSettings) int {
+	width, _ := getPageDimensions(settings)
+	textWidth := width - settings.MarginLeft - settings.MarginRight - settings.GutterWidth
+	return int(math.Round(mmToTwips(textWidth)))
+}
+
+// gridColumnCount returns the number of grid columns, also when tblGrid is missing
+func (t *Table) gridColumnCount() int {
+	cols := 0
+	if t.Grid != nil {
+		cols = len(t.Grid.Cols)
+	}
+	for _, row := range t.Rows {
+		total := 0
+		for i := range row.Cells {
+			total += cellGridSpan(&row.Cells[i])
+		}
+		if total > cols {
+			cols = total
+		}
+	}
+	return cols
+}
+
+// gridColumnWidths returns the current grid column widths in twips
+func (t *Table) gridColumnWidths() []int {
+	widths := make([]int, t.gridColumnCount())
+	for c := range widths {
+		widths[c] = t.gridWidth(c, c+1)
+		if widths[c] <= 0 {
+			widths[c] = minAutoFitColumnWidth
+		}
+	}
+	return widths
+}
+
+// setGridColumnWidths writes the grid column widths and the matching cell widths
+func (t *Table) setGridColumnWidths(widths []int) {
+	if t.Grid == nil {
+		t.Grid = &TableGrid{}
+	}
+	t.Grid.Cols = make([]TableGridCol, len(widths))
+	for c, w := range widths {
+		t.Grid.Cols[c] = TableGridCol{W: fmt.Sprintf("%d", w)}
+	}
+
+	for r := range t.Rows {
+		gridCol := 0
+		for i := range t.Rows[r].Cells {
+			cell := &t.Rows[r].Cells[i]
+			span := cellGridSpan(cell)
+			if cell.Properties == nil {
+				cell.Properties = &TableCellProperties{}
+			}
+			cell.Properties.TableCellW = &TableCellW{W: fmt.Sprintf("%d", t.gridWidth(gridCol, gridCol+span)), Type: "dxa"}
+			gridCol += span
+		}
+	}
+}
+
+// measureColumns measures the minimum and preferred widths of all grid columns
+func (t *Table) measureColumns() []columnExtent {
+	extents := make([]columnExtent, t.gridColumnCount())
+	for c := range extents {
+		extents[c] = columnExtent{min: minAutoFitColumnWidth, max: minAutoFitColumnWidth}
+	}
+
+	type spanned struct {
+		start, span int
+		extent      columnExtent
+	}
+	var spannedCells []spanned
+
+	for r := range t.Rows {
+		gridCol := 0
+		for i := range t.Rows[r].Cells {
+			cell := &t.Rows[r].Cells[i]
+			span := cellGridSpan(cell)
+			extent := t.measureCell(cell)
+			if span == 1 {
+				extents[gridCol].min = max(extents[gridCol].min, extent.min)
+				extents[gridCol].max = max(extents[gridCol].max, extent.max)
+			} else {
+				spannedCells = append(spannedCells, spanned{start: gridCol, span: span, extent: extent})
+			}
+			gridCol += span
+		}
+	}
+
+	// merged cells that do not fit the columns they span widen those columns evenly
+	for _, s := range spannedCells {
+		end := min(s.start+s.span, len(extents))
+		minSum, maxSum := 0, 0
+		for c := s.start; c < end; c++ {
+			minSum += extents[c].min
+			maxSum += extents[c].max
+		}
+		for c := s.start; c < end; c++ {
+			if s.extent.min > minSum {
+				extents[c].min += (s.extent.min - minSum) / (end - s.start)
+			}
+			if s.extent.max > maxSum {
+				extents[c].max += (s.extent.max - maxSum) / (end - s.start)
+			}
+			extents[c].max = max(extents[c].max, extents[c].min)
+		}
+	}
+
+	return extents
+}
+
+// measureCell measures the minimum and preferred width of a cell including its margins
+func (t *Table) measureCell(cell *TableCell) columnExtent {
+	extent := columnExtent{}
+	for i := range cell.Paragraphs {
+		longestWord, line := measureParagraph(&cell.Paragraphs[i])
+		extent.min = max(extent.min, longestWord)
+		extent.max = max(extent.max, line)
+	}
+	for i := range cell.Tables {
+		nested := 0
+		for _, w := range cell.Tables[i].gridColumnWidths() {
+			nested += w
+		}
+		extent.min = max(extent.min, nested)
+		extent.max = max(extent.max, nested)
+	}
+
+	padding := t.cellHorizontalMargins(cell) + autoFitAllowance
+	extent.min += padding
+	extent.max += padding
+	return extent
+}
+
+// measureParagraph returns the width of the longest word and of the whole paragraph in twips
+func measureParagraph(para *Paragraph) (longestWord, line int) {
+	word := 0
+	for _, run := range para.Runs {
+		for i, segment := range strings.Split(run.Text.Content, " ") {
+			if i > 0 {
+				longestWord = max(longestWord, word)
+				word = 0
+				line += measureRunText(" ", run.Properties)
+			}
+			w := measureRunText(segment, run.Properties)
+			word += w
+			line += w
+		}
+	}
+	return max(longestWord, word), line
+}
+
+// cellHorizontalMargins returns the left plus right margin of a cell in twips
+func (t *Table) cellHorizontalMargins(cell *TableCell) int {
+	left, right := defaultCellMargin, defaultCellMargin
+	if t.Properties != nil && t.Properties.TableCellMar != nil {
+		if m := t.Properties.TableCellMar.Left; m != nil {
+			left = int(parseFloat(m.W))
+		}
+		if m := t.Properties.TableCellMar.Right; m != nil {
+			right = int(parseFloat(m.W))
+		}
+	}
+	if cell.Properties != nil && cell.Properties.TcMar != nil {
+		if m := cell.Properties.TcMar.Left; m != nil {
+			left = int(parseFloat(m.W))
+		}
+		if m := cell.Properties.TcMar.Right; m != nil {
+			right = int(parseFloat(m.W))
+		}
+	}
+	return left + right
+}
+
+// distributeColumnWidths computes column widths from the measured extents
+// Columns get their preferred width when it fits; otherwise the space above the minimum widths
+// is shared in proportion to how much each column would need to avoid wrapping. With stretch set
+// the table always fills the available width.
+func distributeColumnWidths(extents []columnExtent, available int, stretch bool) []int {
+	minSum, maxSum := 0, 0
+	for _, e := range extents {
+		minSum += e.min
+		maxSum += e.max
+	}
+
+	widths := make([]int, len(extents))
+	switch {
+	case maxSum <= available:
+		for c, e := range extents {
+			widths[c] = e.max
+			if stretch {
+				widths[c] = e.max * available / maxSum
+			}
+		}
+	case minSum >= available:
+		for c, e := range extents {
+			widths[c] = e.min
+		}
+		return widths
+	default:
+		for c, e := range extents {
+			widths[c] = e.min + (e.max-e.min)*(available-minSum)/(maxSum-minSum)
+		}
+		stretch = true
+	}
+
+	if stretch {
+		// hand out the rounding remainder so the columns add up to the available width
+		total := 0
+		for _, w := range widths {
+			total += w
+		}
+		widths[len(widths)-1] += available - total
+	}
+	return widths
+}
+
+// cellGridSpan returns the number of grid columns a cell spans
+func cellGridSpan(cell *TableCell) int {
+	if cell.Properties != nil && cell.Properties.GridSpan != nil {
+		if span := int(parseFloat(cell.Properties.GridSpan.Val)); span > 1 {
+			return span
+		}
+	}
+	return 1
+}
@@ -0,0 +1,180 @@
+package document
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// gridWidths returns the grid column widths of a table
+func gridWidths(t *testing.T, table *Table) []int {
+	widths := make([]int, len(table.Grid.Cols))
+	for i, col := range table.Grid.Cols {
+		w, err := strconv.Atoi(col.W)
+		if err != nil {
+			t.Fatalf("invalid grid column width %q", col.W)
+		}
+		widths[i] = w
+	}
+	return widths
+}
+
+// newProductTable creates a table with a short code column and a long description column
+func newProductTable(t *testing.T, doc *Document, description string) *Table {
+	table, err := doc.AddTable(&TableConfig{
+		Rows:  2,
+		Cols:  3,
+		Width: 9000,
+		Data: [][]string{
+			{"ID", "Description", "Price"},
+			{"7", description, "1,250.00"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	return table
+}
+
+// TestAutoFitContents tests sizing columns to their content
+func TestAutoFitContents(t *testing.T) {
+	doc := New()
+	table := newProductTable(t, doc, "Stapler")
+
+	if err := table.AutoFit(AutoFitContents); err != nil {
+		t.Fatalf("failed to auto-fit table: %v", err)
+	}
+
+	widths := gridWidths(t, table)
+	total := widths[0] + widths[1] + widths[2]
+	if total >= 9000 {
+		t.Errorf("short content should give a narrow table, got %v", widths)
+	}
+	if widths[0] >= widths[1] || widths[0] >= widths[2] {
+		t.Errorf("ID column should be the narrowest, got %v", widths)
+	}
+	if table.Properties.TableLayout.Type != "autofit" || table.Properties.TableW.Type != "auto" {
+		t.Error("table should use the autofit layout with automatic width")
+	}
+	if table.Rows[1].Cells[1].Properties.TableCellW.W != table.Grid.Cols[1].W {
+		t.Error("cell widths should follow the grid")
+	}
+
+	// long text wraps instead of pushing the table past the available width
+	long := newProductTable(t, doc, strings.Repeat("a long product description ", 20))
+	if err := long.AutoFitWithin(AutoFitContents, 8000); err != nil {
+		t.Fatalf("failed to auto-fit table: %v", err)
+	}
+	widths = gridWidths(t, long)
+	if total := widths[0] + widths[1] + widths[2]; total != 8000 {
+		t.Errorf("wrapped table should fill the available width, got %d", total)
+	}
+	if widths[1] < 5000 {
+		t.Errorf("description column should get most of the width, got %v", widths)
+	}
+	if price := measureRunText("1,250.00", nil) + 2*defaultCellMargin; widths[2] < price {
+		t.Errorf("price column should not wrap: width %d, text needs %d", widths[2], price)
+	}
+}
+
+// TestAutoFitWindowAndFixed tests stretching to the page and fixing column widths
+func TestAutoFitWindowAndFixed(t *testing.T) {
+	doc := New()
+	table := newProductTable(t, doc, "Stapler")
+	table.MergeCellsHorizontal(0, 1, 2)
+
+	if err := table.AutoFit(AutoFitWindow); err != nil {
+		t.Fatalf("failed to auto-fit table: %v", err)
+	}
+	widths := gridWidths(t, table)
+	if total := widths[0] + widths[1] + widths[2]; total != doc.GetTextWidth() {
+		t.Errorf("table should fill the text width %d, got %d", doc.GetTextWidth(), total)
+	}
+	if table.Properties.TableW.Type != "pct" || table.Properties.TableW.W != "5000" {
+		t.Error("table width should be 100 percent")
+	}
+	merged := table.Rows[0].Cells[1].Properties.TableCellW.W
+	if merged != strconv.Itoa(widths[1]+widths[2]) {
+		t.Errorf("merged cell should span two columns, got width %s", merged)
+	}
+
+	if err := table.AutoFit(AutoFitFixed); err != nil {
+		t.Fatalf("failed to fix table layout: %v", err)
+	}
+	if table.Properties.TableLayout.Type != "fixed" {
+		t.Error("table layout should be fixed")
+	}
+	if fixed := gridWidths(t, table); fixed[1] != widths[1] {
+		t.Errorf("fixed layout should keep the column widths, got %v", fixed)
+	}
+
+	if err := table.AutoFit("shrink"); err == nil {
+		t.Error("expected error for unsupported mode")
+	}
+}
+
+// TestAutoFitTableSection tests fitting tables to the page of their own section
+func TestAutoFitTableSection(t *testing.T) {
+	doc := New()
+	if err := doc.SetPageOrientation(OrientationLandscape); err != nil {
+		t.Fatalf("failed to set orientation: %v", err)
+	}
+	first := newProductTable(t, doc, "Stapler")
+	doc.AddParagraph("end of the narrow section").Properties = &ParagraphProperties{
+		SectionProperties: &SectionProperties{
+			PageSize:    &PageSizeXML{W: "11906", H: "16838"},
+			PageMargins: &PageMargin{Left: "1800", Right: "1800"},
+		},
+	}
+	last := newProductTable(t, doc, "Stapler")
+
+	// tables of a document are fitted to their section by Table.AutoFit too
+	if err := first.AutoFit(AutoFitWindow); err != nil {
+		t.Fatalf("failed to auto-fit table: %v", err)
+	}
+	if err := doc.AutoFitTable(last, AutoFitWindow); err != nil {
+		t.Fatalf("failed to auto-fit table: %v", err)
+	}
+
+	sum := func(widths []int) int {
+		total := 0
+		for _, w := range widths {
+			total += w
+		}
+		return total
+	}
+	if total := sum(gridWidths(t, first)); total != 11906-2*1800 {
+		t.Errorf("table should fill the text width of its section, got %d", total)
+	}
+	if total := sum(gridWidths(t, last)); total != doc.GetTextWidth() || total <= 11906 {
+		t.Errorf("table should fill the landscape text width %d, got %d", doc.GetTextWidth(), total)
+	}
+
+	if err := doc.AutoFitTable(nil, AutoFitWindow); err == nil {
+		t.Error("expected error for nil table")
+	}
+}
+
+// TestMeasureRunText tests text measurement with font metrics
+func TestMeasureRunText(t *testing.T) {
+	// 10 digits of 507/1000 em at 10.5pt Calibri
+	if w := measureRunText("0123456789", nil); w != 1065 {
+		t.Errorf("expected 1065 twips, got %d", w)
+	}
+
+	courier := &RunProperties{FontFamily: &FontFamily{ASCII: "Courier New"}, FontSize: &FontSize{Val: "24"}}
+	if w := measureRunText("iiii", courier); w != measureRunText("MMMM", courier) {
+		t.Error("monospace characters should have the same width")
+	}
+
+	arial := &RunProperties{FontFamily: &FontFamily{ASCII: "Arial"}}
+	bold := &RunProperties{FontFamily: &FontFamily{ASCII: "Arial"}, Bold: &Bold{}}
+	if measureRunText("Total", bold) <= measureRunText("Total", arial) {
+		t.Error("bold text should be wider")
+	}
+
+	// two ideographs of one em at 10.5pt
+	if w := measureRunText("表格", nil); w != 420 {
+		t.Errorf("CJK characters should be one em wide, got %d", w)
+	}
+}
@@ -0,0 +1,447 @@
+// Package document provides conditional formatting of table cells
+package document
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConditionType conditional format rule type
+type ConditionType string
+
+const (
+	// ConditionGreaterThan matches numbers greater than Value
+	ConditionGreaterThan ConditionType = "greaterThan"
+	// ConditionGreaterOrEqual matches numbers greater than or equal to Value
+	ConditionGreaterOrEqual ConditionType = "greaterOrEqual"
+	// ConditionLessThan matches numbers less than Value
+	ConditionLessThan ConditionType = "lessThan"
+	// ConditionLessOrEqual matches numbers less than or equal to Value
+	ConditionLessOrEqual ConditionType = "lessOrEqual"
+	// ConditionEqual matches numbers equal to Value
+	ConditionEqual ConditionType = "equal"
+	// ConditionNotEqual matches numbers not equal to Value
+	ConditionNotEqual ConditionType = "notEqual"
+	// ConditionBetween matches numbers from Value to Value2, inclusive
+	ConditionBetween ConditionType = "between"
+	// ConditionMatches matches cell texts against the regular expression Pattern
+	ConditionMatches ConditionType = "matches"
+	// ConditionTopN matches the N largest numbers of the range, ties included
+	ConditionTopN ConditionType = "topN"
+	// ConditionBottomN matches the N smallest numbers of the range, ties included
+	ConditionBottomN ConditionType = "bottomN"
+	// ConditionColorScale shades numeric cells with a color between MinColor, MidColor and MaxColor
+	ConditionColorScale ConditionType = "colorScale"
+)
+
+// CellRange is a rectangular range of cells, the end row and column are inclusive
+type CellRange struct {
+	StartRow int
+	StartCol int
+	EndRow   int
+	EndCol   int
+}
+
+// ConditionalRule describes when a conditional format applies
+// Numeric rules only match cells whose text is a number such as "1,234.50", "$99" or "85%".
+type ConditionalRule struct {
+	Type     ConditionType
+	Value    float64 // threshold of comparison rules, lower bound of ConditionBetween
+	Value2   float64 // upper bound of ConditionBetween
+	Pattern  string  // regular expression of ConditionMatches
+	N        int     // number of cells of ConditionTopN and ConditionBottomN
+	MinColor string  // color of the smallest value of ConditionColorScale, e.g. "F8696B"
+	MidColor string  // optional color of the midpoint of ConditionColorScale
+	MaxColor string  // color of the largest value of ConditionColorScale
+}
+
+// conditionalFormat is a rule registered on a table
+type conditionalFormat struct {
+	cellRange CellRange
+	rule      ConditionalRule
+	format    *CellFormat
+	pattern   *regexp.Regexp
+}
+
+// cellIndex identifies a cell by row index and cell index within the row
+type cellIndex struct {
+	row, col int
+}
+
+// cellFormatBaseline holds the formatting a cell had before conditional formats were applied
+type cellFormatBaseline struct {
+	shading *TableCellShading
+	runs    [][]runFormatBaseline // per paragraph and run
+}
+
+// runFormatBaseline holds the run formatting conditional formats may change
+type runFormatBaseline struct {
+	bold   bool
+	italic bool
+	color  *Color
+}
+
+// AddConditionalFormat adds a conditional format rule for a range of cells and applies it
+//
+// Column indexes are cell indexes within the row, as for GetCellRange. The background color,
+// bold, italic and font color of format are applied to matching cells; ConditionColorScale
+// rules compute the background color themselves and take a nil format. Rules are kept with the
+// table and are evaluated in the order they were added, so later rules win. Call
+// ApplyConditionalFormats after changing cell texts to evaluate all rules again.
+func (t *Table) AddConditionalFormat(cellRange CellRange, rule ConditionalRule, format *CellFormat) error {
+	if _, err := t.GetCellRange(cellRange.StartRow, cellRange.StartCol, cellRange.EndRow, cellRange.EndCol); err != nil {
+		return WrapError("add_conditional_format", err)
+	}
+
+	cf := &conditionalFormat{cellRange: cellRange, rule: rule, format: format}
+	switch rule.Type {
+	case ConditionGreaterThan, ConditionGreaterOrEqual, ConditionLessThan, ConditionLessOrEqual,
+		ConditionEqual, ConditionNotEqual:
+	case ConditionBetween:
+		if rule.Value > rule.Value2 {
+			return NewValidationError("Value2", fmt.Sprintf("%v", rule.Value2), "upper bound must not be less than the lower bound")
+		}
+	case ConditionMatches:
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return NewValidationError("Pattern", rule.Pattern, err.Error())
+		}
+		cf.pattern = pattern
+	case ConditionTopN, ConditionBottomN:
+		if rule.N <= 0 {
+			return NewValidationError("N", fmt.Sprintf("%d", rule.N), "N must be positive")
+		}
+	case ConditionColorScale:
+		for _, color := range []string{rule.MinColor, rule.MaxColor} {
+			if _, ok := parseHexColor(color); !ok {
+				return NewValidationError("ConditionalRule", color, "color scale needs hexadecimal min and max colors")
+			}
+		}
+		if _, ok := parseHexColor(rule.MidColor); rule.MidColor != "" && !ok {
+			return NewValidationError("MidColor", rule.MidColor, "invalid hexadecimal color")
+		}
+	default:
+		return NewValidationError("Type", string(rule.Type), "unsupported condition type")
+	}
+	if rule.Type != ConditionColorScale && format == nil {
+		return NewValidationError("format", "", "format is required")
+	}
+
+	t.conditionalFormats = append(t.conditionalFormats, cf)
+	return t.ApplyConditionalFormats()
+}
+
+// ApplyConditionalFormats evaluates all conditional format rules of the table again
+// Cells in the rule ranges first get back the formatting they had before any rule applied,
+// cells that are no longer in any range get it back for good.
+func (t *Table) ApplyConditionalFormats() error {
+	if t.conditionalBaseline == nil {
+		t.conditionalBaseline = make(map[cellIndex]*cellFormatBaseline)
+	}
+
+	var ranges [][]*CellInfo
+	covered := make(map[cellIndex]bool)
+	for _, cf := range t.conditionalFormats {
+		r := cf.cellRange
+		cells, err := t.GetCellRange(r.StartRow, r.StartCol, r.EndRow, r.EndCol)
+		if err != nil {
+			return WrapError("apply_conditional_formats", err)
+		}
+		for _, info := range cells {
+			covered[cellIndex{info.Row, info.Col}] = true
+		}
+		ranges = append(ranges, cells)
+	}
+
+	for pos, baseline := range t.conditionalBaseline {
+		if !covered[pos] {
+			if cell := t.cellAt(pos); cell != nil {
+				baseline.restore(cell)
+			}
+			delete(t.conditionalBaseline, pos)
+		}
+	}
+	for _, cells := range ranges {
+		for _, info := range cells {
+			pos := cellIndex{info.Row, info.Col}
+			baseline, ok := t.conditionalBaseline[pos]
+			if !ok {
+				t.conditionalBaseline[pos] = captureCellFormat(info.Cell)
+				continue
+			}
+			baseline.restore(info.Cell)
+		}
+	}
+
+	matched := 0
+	for i, cf := range t.conditionalFormats {
+		matched += cf.apply(ranges[i])
+	}
+
+	Info(fmt.Sprintf("conditional formats applied: %d rules, %d cells formatted", len(t.conditionalFormats), matched))
+	return nil
+}
+
+// ClearConditionalFormats removes all conditional format rules and restores the cell formatting
+func (t *Table) ClearConditionalFormats() {
+	for pos, baseline := range t.conditionalBaseline {
+		if cell := t.cellAt(pos); cell != nil {
+			baseline.restore(cell)
+		}
+	}
+	t.conditionalFormats = nil
+	t.conditionalBaseline = nil
+}
+
+// cellAt returns the cell at a position, or nil when the table no longer has it
+func (t *Table) cellAt(pos cellIndex) *TableCell {
+	if pos.row >= len(t.Rows) || pos.col >= len(t.Rows[pos.row].Cells) {
+		return nil
+	}
+	return &t.Rows[pos.row].Cells[pos.col]
+}
+
+// apply formats the matching cells of a rule and returns how many cells were formatted
+func (cf *conditionalFormat) apply(cells []*CellInfo) int {
+	rule := cf.rule
+	numbers := make(map[*CellInfo]float64, len(cells))
+	var values []float64
+	for _, info := range cells {
+		if value, ok := parseCellNumber(info.Text); ok {
+			numbers[info] = value
+			values = append(values, value)
+		}
+	}
+
+	if rule.Type == ConditionColorScale {
+		return cf.applyColorScale(numbers, values)
+	}
+
+	// top and bottom rules match values beyond the N-th value
+	threshold := 0.0
+	if rule.Type == ConditionTopN || rule.Type == ConditionBottomN {
+		if len(values) == 0 {
+			return 0
+		}
+		sort.Float64s(values)
+		n := rule.N
+		if n > len(values) {
+			n = len(values)
+		}
+		if rule.Type == ConditionTopN {
+			threshold = values[len(values)-n]
+		} else {
+			threshold = values[n-1]
+		}
+	}
+
+	matched := 0
+	for _, info := range cells {
+		value, isNumber := numbers[info]
+		match := false
+		switch rule.Type {
+		case ConditionMatches:
+			match = cf.pattern.MatchString(info.Text)
+		case ConditionGreaterThan:
+			match = isNumber && value > rule.Value
+		case ConditionGreaterOrEqual:
+			match = isNumber && value >= rule.Value
+		case ConditionLessThan:
+			match = isNumber && value < rule.Value
+		case ConditionLessOrEqual:
+			match = isNumber && value <= rule.Value
+		case ConditionEqual:
+			match = isNumber && value == rule.Value
+		case ConditionNotEqual:
+			match = isNumber && value != rule.Value
+		case ConditionBetween:
+			match = isNumber && value >= rule.Value && value <= rule.Value2
+		case ConditionTopN:
+			match = isNumber && value >= threshold
+		case ConditionBottomN:
+			match = isNumber && value <= threshold
+		}
+		if match {
+			applyConditionalCellFormat(info.Cell, cf.format)
+			matched++
+		}
+	}
+	return matched
+}
+
+// applyColorScale shades numeric cells with colors interpolated between the scale colors
+func (cf *conditionalFormat) applyColorScale(numbers map[*CellInfo]float64, values []float64) int {
+	if len(values) == 0 {
+		return 0
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	minColor, _ := parseHexColor(cf.rule.MinColor)
+	maxColor, _ := parseHexColor(cf.rule.MaxColor)
+	midColor, hasMid := parseHexColor(cf.rule.MidColor)
+
+	for info, value := range numbers {
+		position := 0.0
+		if max > min {
+			position = (value - min) / (max - min)
+		}
+
+		var color [3]float64
+		switch {
+		case hasMid && position <= 0.5:
+			color = interpolateColor(minColor, midColor, position*2)
+		case hasMid:
+			color = interpolateColor(midColor, maxColor, (position-0.5)*2)
+		default:
+			color = interpolateColor(minColor, maxColor, position)
+		}
+
+		format := &CellFormat{BackgroundColor: formatHexColor(color)}
+		if cf.format != nil {
+			merged := *cf.format
+			merged.BackgroundColor = format.BackgroundColor
+			format = &merged
+		}
+		applyConditionalCellFormat(info.Cell, format)
+	}
+	return len(numbers)
+}
+
+// applyConditionalCellFormat applies the background and text formatting of a format to a cell
+func applyConditionalCellFormat(cell *TableCell, format *CellFormat) {
+	if format.BackgroundColor != "" {
+		if cell.Properties == nil {
+			cell.Properties = &TableCellProperties{}
+		}
+		cell.Properties.Shd = &TableCellShading{
+			Val:   string(ShadingPatternClear),
+			Color: "auto",
+			Fill:  format.BackgroundColor,
+		}
+	}
+
+	tf := format.TextFormat
+	if tf == nil || (!tf.Bold && !tf.Italic && tf.FontColor == "") {
+		return
+	}
+	for p := range cell.Paragraphs {
+		for r := range cell.Paragraphs[p].Runs {
+			run := &cell.Paragraphs[p].Runs[r]
+			if run.Properties == nil {
+				run.Properties = &RunProperties{}
+			}
+			if tf.Bold {
+				run.Properties.Bold = &Bold{}
+			}
+			if tf.Italic {
+				run.Properties.Italic = &Italic{}
+			}
+			if tf.FontColor != "" {
+				run.Properties.Color = &Color{Val: strings.TrimPrefix(tf.FontColor, "#")}
+			}
+		}
+	}
+}
+
+// captureCellFormat records the formatting of a cell that conditional formats may change
+func captureCellFormat(cell *TableCell) *cellFormatBaseline {
+	baseline := &cellFormatBaseline{runs: make([][]runFormatBaseline, len(cell.Paragraphs))}
+	if cell.Properties != nil && cell.Properties.Shd != nil {
+		shading := *cell.Properties.Shd
+		baseline.shading = &shading
+	}
+	for p, para := range cell.Paragraphs {
+		baseline.runs[p] = make([]runFormatBaseline, len(para.Runs))
+		for r, run := range para.Runs {
+			if run.Properties == nil {
+				continue
+			}
+			baseline.runs[p][r] = runFormatBaseline{bold: run.Properties.Bold != nil, italic: run.Properties.Italic != nil}
+			if run.Properties.Color != nil {
+				color := *run.Properties.Color
+				baseline.runs[p][r].color = &color
+			}
+		}
+	}
+	return baseline
+}
+
+// restore puts back the recorded formatting, runs added since take the format of the first run
+func (b *cellFormatBaseline) restore(cell *TableCell) {
+	if cell.Properties != nil {
+		cell.Properties.Shd = nil
+		if b.shading != nil {
+			shading := *b.shading
+			cell.Properties.Shd = &shading
+		}
+	}
+
+	for p := range cell.Paragraphs {
+		for r := range cell.Paragraphs[p].Runs {
+			run := &cell.Paragraphs[p].Runs[r]
+			if run.Properties == nil {
+				continue
+			}
+			saved := runFormatBaseline{}
+			switch {
+			case p < len(b.runs) && r < len(b.runs[p]):
+				saved = b.runs[p][r]
+			case len(b.runs) > 0 && len(b.runs[0]) > 0:
+				saved = b.runs[0][0]
+			}
+
+			run.Properties.Bold = nil
+			if saved.bold {
+				run.Properties.Bold = &Bold{}
+			}
+			run.Properties.Italic = nil
+			if saved.italic {
+				run.Properties.Italic = &Italic{}
+			}
+			run.Properties.Color = nil
+			if saved.color != nil {
+				color := *saved.color
+				run.Properties.Color = &color
+			}
+		}
+	}
+}
+
+// parseHexColor parses a color such as "FF0000" or "#FF0000"
+func parseHexColor(s string) ([3]float64, bool) {
+	s = strings.TrimPrefix(s, "#")
+	var color [3]float64
+	if len(s) != 6 {
+		return color, false
+	}
+	for i := 0; i < 3; i++ {
+		v, err := strconv.ParseUint(s[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return color, false
+		}
+		color[i] = float64(v)
+	}
+	return color, true
+}
+
+// formatHexColor formats a color as six hexadecimal digits
+func formatHexColor(color [3]float64) string {
+	return fmt.Sprintf("%02X%02X%02X", int(math.Round(color[0])), int(math.Round(color[1])), int(math.Round(color[2])))
+}
+
+// interpolateColor returns the color at position between from (0) and to (1)
+func interpolateColor(from, to [3]float64, position float64) [3]float64 {
+	var color [3]float64
+	for i := range color {
+		color[i] = from[i] + (to[i]-from[i])*position
+	}
+	return color
+}
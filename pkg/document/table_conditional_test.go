@@ -0,0 +1,173 @@
+package document
+
+import (
+	"testing"
+)
+
+// cellFill returns the background color of a cell
+func cellFill(table *Table, row, col int) string {
+	cell, _ := table.GetCell(row, col)
+	if cell.Properties == nil || cell.Properties.Shd == nil {
+		return ""
+	}
+	return cell.Properties.Shd.Fill
+}
+
+// newKPITable creates a table with a header row and a score column
+func newKPITable(t *testing.T, doc *Document) *Table {
+	table, err := doc.AddTable(&TableConfig{
+		Rows:  5,
+		Cols:  2,
+		Width: 4000,
+		Data:  [][]string{{"Team", "Score"}, {"Alpha", "92%"}, {"Beta", "64%"}, {"Gamma", "31%"}, {"Delta", "n/a"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	return table
+}
+
+// TestConditionalFormatThresholds tests red/amber/green thresholds and re-applying after updates
+func TestConditionalFormatThresholds(t *testing.T) {
+	doc := New()
+	table := newKPITable(t, doc)
+	scores := CellRange{StartRow: 1, StartCol: 1, EndRow: 4, EndCol: 1}
+
+	rules := []struct {
+		rule  ConditionalRule
+		color string
+	}{
+		{ConditionalRule{Type: ConditionLessThan, Value: 50}, "F8696B"},
+		{ConditionalRule{Type: ConditionBetween, Value: 50, Value2: 79.99}, "FFEB84"},
+		{ConditionalRule{Type: ConditionGreaterOrEqual, Value: 80}, "63BE7B"},
+	}
+	for _, r := range rules {
+		format := &CellFormat{BackgroundColor: r.color, TextFormat: &TextFormat{Bold: true}}
+		if err := table.AddConditionalFormat(scores, r.rule, format); err != nil {
+			t.Fatalf("failed to add conditional format: %v", err)
+		}
+	}
+
+	for row, expected := range map[int]string{1: "63BE7B", 2: "FFEB84", 3: "F8696B", 4: ""} {
+		if fill := cellFill(table, row, 1); fill != expected {
+			t.Errorf("row %d: expected fill %q, got %q", row, expected, fill)
+		}
+	}
+	if run := table.Rows[1].Cells[1].Paragraphs[0].Runs[0]; run.Properties == nil || run.Properties.Bold == nil {
+		t.Error("matching cells should be bold")
+	}
+
+	// after a data update the old formatting is removed before the rules are evaluated again
+	table.SetCellText(1, 1, "45%")
+	table.SetCellText(4, 1, "85%")
+	if err := table.ApplyConditionalFormats(); err != nil {
+		t.Fatalf("failed to re-apply conditional formats: %v", err)
+	}
+	if fill := cellFill(table, 1, 1); fill != "F8696B" {
+		t.Errorf("updated cell should turn red, got %q", fill)
+	}
+	if fill := cellFill(table, 4, 1); fill != "63BE7B" {
+		t.Errorf("updated cell should turn green, got %q", fill)
+	}
+
+	table.ClearConditionalFormats()
+	if fill := cellFill(table, 1, 1); fill != "" {
+		t.Errorf("clearing rules should restore the cell, got fill %q", fill)
+	}
+	if run := table.Rows[1].Cells[1].Paragraphs[0].Runs[0]; run.Properties != nil && run.Properties.Bold != nil {
+		t.Error("clearing rules should remove the bold formatting")
+	}
+}
+
+// TestConditionalFormatRules tests regular expression, top-N and color scale rules
+func TestConditionalFormatRules(t *testing.T) {
+	doc := New()
+	table := newKPITable(t, doc)
+	table.SetCellShading(2, 0, &ShadingConfig{Pattern: ShadingPatternClear, BackgroundColor: "DDDDDD"})
+
+	red := &CellFormat{TextFormat: &TextFormat{FontColor: "C00000"}}
+	if err := table.AddConditionalFormat(CellRange{StartRow: 1, EndRow: 4}, ConditionalRule{Type: ConditionMatches, Pattern: "^(Beta|Delta)$"}, red); err != nil {
+		t.Fatalf("failed to add regex rule: %v", err)
+	}
+	for row, expected := range map[int]bool{1: false, 2: true, 3: false, 4: true} {
+		run := table.Rows[row].Cells[0].Paragraphs[0].Runs[0]
+		colored := run.Properties != nil && run.Properties.Color != nil && run.Properties.Color.Val == "C00000"
+		if colored != expected {
+			t.Errorf("row %d: expected colored=%v", row, expected)
+		}
+	}
+
+	scores := CellRange{StartRow: 1, StartCol: 1, EndRow: 4, EndCol: 1}
+	if err := table.AddConditionalFormat(scores, ConditionalRule{Type: ConditionTopN, N: 1}, &CellFormat{BackgroundColor: "00B050"}); err != nil {
+		t.Fatalf("failed to add top-N rule: %v", err)
+	}
+	if cellFill(table, 1, 1) != "00B050" || cellFill(table, 2, 1) != "" {
+		t.Error("only the largest value should match the top-1 rule")
+	}
+
+	table.ClearConditionalFormats()
+	if fill := cellFill(table, 2, 0); fill != "DDDDDD" {
+		t.Errorf("original shading should be restored, got %q", fill)
+	}
+
+	scale := ConditionalRule{Type: ConditionColorScale, MinColor: "FF0000", MidColor: "FFFF00", MaxColor: "00FF00"}
+	if err := table.AddConditionalFormat(scores, scale, nil); err != nil {
+		t.Fatalf("failed to add color scale: %v", err)
+	}
+	for row, expected := range map[int]string{1: "00FF00", 2: "EAFF00", 3: "FF0000", 4: ""} {
+		if fill := cellFill(table, row, 1); fill != expected {
+			t.Errorf("row %d: expected scale color %q, got %q", row, expected, fill)
+		}
+	}
+
+	invalid := []ConditionalRule{
+		{Type: ConditionMatches, Pattern: "("},
+		{Type: ConditionTopN},
+		{Type: ConditionBetween, Value: 5, Value2: 1},
+		{Type: ConditionColorScale, MinColor: "red"},
+		{Type: "unknown"},
+	}
+	for _, rule := range invalid {
+		if err := table.AddConditionalFormat(scores, rule, red); err == nil {
+			t.Errorf("expected error for rule %+v", rule)
+		}
+	}
+	if err := table.AddConditionalFormat(CellRange{StartRow: 0, EndRow: 9}, ConditionalRule{Type: ConditionEqual}, red); err == nil {
+		t.Error("expected error for range outside the table")
+	}
+}
+
+// TestConditionalFormatAfterTableChange tests restoring cells after the cell slices were reallocated
+func TestConditionalFormatAfterTableChange(t *testing.T) {
+	doc := New()
+	table := newKPITable(t, doc)
+	scores := CellRange{StartRow: 1, StartCol: 1, EndRow: 2, EndCol: 1}
+	high := &CellFormat{BackgroundColor: "63BE7B", TextFormat: &TextFormat{Bold: true}}
+	if err := table.AddConditionalFormat(scores, ConditionalRule{Type: ConditionGreaterThan, Value: 50}, high); err != nil {
+		t.Fatalf("failed to add conditional format: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := table.AppendColumn(nil, 1000); err != nil {
+			t.Fatalf("failed to append column: %v", err)
+		}
+	}
+	table.SetCellText(1, 1, "10%")
+	if err := table.ApplyConditionalFormats(); err != nil {
+		t.Fatalf("failed to re-apply conditional formats: %v", err)
+	}
+	if fill := cellFill(table, 1, 1); fill != "" {
+		t.Errorf("cell that no longer matches should be restored, got fill %q", fill)
+	}
+	if fill := cellFill(table, 2, 1); fill != "63BE7B" {
+		t.Errorf("matching cell should keep the rule formatting, got fill %q", fill)
+	}
+
+	table.ClearConditionalFormats()
+	if fill := cellFill(table, 2, 1); fill != "" {
+		t.Errorf("clearing rules should restore cells after columns were added, got fill %q", fill)
+	}
+	if run := table.Rows[2].Cells[1].Paragraphs[0].Runs[0]; run.Properties != nil && run.Properties.Bold != nil {
+		t.Error("clearing rules should remove the bold formatting after columns were added")
+	}
+}
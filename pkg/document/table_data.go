@@ -0,0 +1,406 @@
+// Package document provides table import and export for CSV data and struct slices
+package document
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultImportTableWidth is the table width used when an import configuration sets none
+const defaultImportTableWidth = 9000
+
+// TableImportConfig holds the table settings shared by CSV and struct imports
+type TableImportConfig struct {
+	Width        int               // table total width, default 9000
+	ColWidths    []int             // column widths, if empty then distribute evenly
+	HeaderFormat *CellFormat       // format of header cells, default bold
+	TableStyle   *TableStyleConfig // optional table style applied after creation
+}
+
+// CSVTableConfig configures AddTableFromCSV
+type CSVTableConfig struct {
+	TableImportConfig
+	Delimiter  rune // field delimiter, default ','; use '\t' for TSV
+	Comment    rune // lines starting with this character are ignored, 0 disables comments
+	HasHeader  bool // the first record is a header row, repeated on each page
+	LazyQuotes bool // allow quotes in unquoted fields
+}
+
+// StructTableConfig configures AddTableFromStructs
+type StructTableConfig struct {
+	TableImportConfig
+	OmitHeader bool // do not add the header row built from field names and tags
+}
+
+// AddTableFromCSV reads CSV or TSV records and adds them to the document as a table
+// Records may have different lengths; the table gets as many columns as the longest record.
+func (d *Document) AddTableFromCSV(r io.Reader, config *CSVTableConfig) (*Table, error) {
+	if config == nil {
+		config = &CSVTableConfig{}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = config.LazyQuotes
+	reader.Comment = config.Comment
+	if config.Delimiter != 0 {
+		reader.Comma = config.Delimiter
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, WrapError("read_csv", err)
+	}
+	if len(records) == 0 {
+		return nil, NewValidationError("csv", "", "CSV data contains no records")
+	}
+
+	// files saved by Excel often start with a byte order mark
+	if len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+
+	headerRows := 0
+	if config.HasHeader {
+		headerRows = 1
+	}
+
+	table, err := d.addImportedTable(records, headerRows, &config.TableImportConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	Info(fmt.Sprintf("table imported from CSV: %d records", len(records)))
+	return table, nil
+}
+
+// structColumn describes a table column taken from a struct field
+type structColumn struct {
+	index  []int
+	header string
+	width  int
+	format string
+	align  CellAlignment
+}
+
+// AddTableFromStructs adds a table built from a slice of structs or struct pointers
+//
+// Each exported field becomes a column. Columns are configured with the docx struct tag:
+//
+//	type Order struct {
+//	    ID     string    `docx:"Order No,width=1500"`
+//	    Amount float64   `docx:"Amount,format=%.2f,align=right"`
+//	    Date   time.Time `docx:"Date,format=2006-01-02"`
+//	    Notes  string    `docx:"-"`
+//	}
+//
+// The first tag value is the header text (default: the field name). width sets the column width,
+// format is a fmt verb, or a time layout for time.Time fields, and align is left, center, right
+// or both. Fields tagged "-" are skipped and embedded structs are flattened.
+func (d *Document) AddTableFromStructs(slice interface{}, config *StructTableConfig) (*Table, error) {
+	if config == nil {
+		config = &StructTableConfig{}
+	}
+
+	value := reflect.ValueOf(slice)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, NewValidationError("slice", fmt.Sprintf("%T", slice), "a slice of structs is required")
+	}
+
+	elemType := value.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, NewValidationError("slice", fmt.Sprintf("%T", slice), "a slice of structs is required")
+	}
+
+	columns, err := structColumns(elemType)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, NewValidationError("slice", elemType.Name(), "struct has no exported fields")
+	}
+
+	var records [][]string
+	headerRows := 0
+	if !config.OmitHeader {
+		header := make([]string, len(columns))
+		for i, col := range columns {
+			header[i] = col.header
+		}
+		records = append(records, header)
+		headerRows = 1
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		item := value.Index(i)
+		if item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+
+		record := make([]string, len(columns))
+		for j, col := range columns {
+			field, err := item.FieldByIndexErr(col.index)
+			if err != nil {
+				// a nil embedded struct pointer leaves the cell empty
+				continue
+			}
+			record[j] = formatStructField(field, col.format)
+		}
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return nil, NewValidationError("slice", "", "no rows to add")
+	}
+
+	importConfig := config.TableImportConfig
+	if len(importConfig.ColWidths) == 0 {
+		importConfig.ColWidths = structColumnWidths(columns, importConfig.Width)
+	}
+
+	table, err := d.addImportedTable(records, headerRows, &importConfig, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	Info(fmt.Sprintf("table imported from structs: %d rows", value.Len()))
+	return table, nil
+}
+
+// structColumns collects the table columns of a struct type from its fields and tags
+func structColumns(structType reflect.Type) ([]structColumn, error) {
+	var columns []structColumn
+
+	for _, field := range reflect.VisibleFields(structType) {
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+
+		col := structColumn{index: field.Index, header: field.Name}
+		tag, hasTag := field.Tag.Lookup("docx")
+		if tag == "-" {
+			continue
+		}
+
+		if hasTag {
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" {
+				col.header = parts[0]
+			}
+			lastKey := ""
+			for _, option := range parts[1:] {
+				key, val, found := strings.Cut(option, "=")
+				key = strings.TrimSpace(key)
+				if !found || (key != "width" && key != "format" && key != "align") {
+					// a comma inside a format, e.g. the time layout "Jan 2, 2006"
+					if lastKey == "format" {
+						col.format += "," + option
+						continue
+					}
+					return nil, NewValidationError(field.Name, option, "unknown option in docx tag")
+				}
+
+				lastKey = key
+				switch key {
+				case "width":
+					width, err := strconv.Atoi(val)
+					if err != nil || width <= 0 {
+						return nil, NewValidationError(field.Name, val, "invalid column width in docx tag")
+					}
+					col.width = width
+				case "format":
+					col.format = val
+				case "align":
+					col.align = CellAlignment(val)
+				}
+			}
+		}
+
+		columns = append(columns, col)
+	}
+
+	return columns, nil
+}
+
+// structColumnWidths computes column widths, sharing the space left by fixed widths evenly
+func structColumnWidths(columns []structColumn, totalWidth int) []int {
+	if totalWidth <= 0 {
+		totalWidth = defaultImportTableWidth
+	}
+
+	fixed, flexible := 0, 0
+	for _, col := range columns {
+		if col.width > 0 {
+			fixed += col.width
+		} else {
+			flexible++
+		}
+	}
+	if fixed == 0 {
+		return nil
+	}
+
+	shared := totalWidth / len(columns)
+	if flexible > 0 && totalWidth > fixed {
+		shared = (totalWidth - fixed) / flexible
+	}
+
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = col.width
+		if widths[i] == 0 {
+			widths[i] = shared
+		}
+	}
+	return widths
+}
+
+// formatStructField formats a struct field value for a table cell
+func formatStructField(field reflect.Value, format string) string {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+
+	if t, ok := field.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		if format == "" {
+			format = "2006-01-02"
+		}
+		return t.Format(format)
+	}
+
+	if format == "" {
+		format = "%v"
+	}
+	return fmt.Sprintf(format, field.Interface())
+}
+
+// addImportedTable creates a table from records and applies the header and column settings
+func (d *Document) addImportedTable(records [][]string, headerRows int, config *TableImportConfig, columns []structColumn) (*Table, error) {
+	cols := 0
+	for _, record := range records {
+		if len(record) > cols {
+			cols = len(record)
+		}
+	}
+	if cols == 0 {
+		return nil, NewValidationError("records", "", "records contain no fields")
+	}
+
+	width := config.Width
+	if width <= 0 {
+		width = defaultImportTableWidth
+	}
+	if len(config.ColWidths) > 0 {
+		width = 0
+		for _, w := range config.ColWidths {
+			width += w
+		}
+	}
+
+	table, err := d.AddTable(&TableConfig{
+		Rows:      len(records),
+		Cols:      cols,
+		Width:     width,
+		ColWidths: config.ColWidths,
+		Data:      records,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for c, col := range columns {
+		if col.align == "" {
+			continue
+		}
+		for r := range table.Rows {
+			if err := table.SetCellFormat(r, c, &CellFormat{HorizontalAlign: col.align}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if headerRows > 0 {
+		headerFormat := config.HeaderFormat
+		if headerFormat == nil {
+			headerFormat = &CellFormat{TextFormat: &TextFormat{Bold: true}}
+		}
+		for r := 0; r < headerRows; r++ {
+			for c := range table.Rows[r].Cells {
+				if err := table.SetCellFormat(r, c, headerFormat); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := table.SetHeaderRows(0, headerRows-1); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.TableStyle != nil {
+		if err := table.ApplyTableStyle(config.TableStyle); err != nil {
+			return nil, err
+		}
+	}
+
+	return table, nil
+}
+
+// ToRecords returns the cell texts of the table as rectangular records
+// Every record has one field per grid column. The text of a merged cell is placed in its top-left
+// position and the other positions it covers are left empty.
+func (t *Table) ToRecords() [][]string {
+	cols := t.gridColumnCount()
+
+	records := make([][]string, len(t.Rows))
+	for r := range t.Rows {
+		record := make([]string, cols)
+		gridCol := 0
+		for c := range t.Rows[r].Cells {
+			info, _ := t.GetMergedCellInfo(r, c)
+			if continued, _ := info["vertical_merge_continue"].(bool); !continued {
+				record[gridCol], _ = t.GetCellText(r, c)
+			}
+			gridCol += cellGridSpan(&t.Rows[r].Cells[c])
+		}
+		records[r] = record
+	}
+
+	return records
+}
+
+// ExportCSV writes the table as CSV, see ToRecords for how merged cells are written
+func (t *Table) ExportCSV(w io.Writer) error {
+	return t.exportDelimited(w, ',')
+}
+
+// ExportTSV writes the table as tab separated values, see ToRecords for how merged cells are written
+func (t *Table) ExportTSV(w io.Writer) error {
+	return t.exportDelimited(w, '\t')
+}
+
+// exportDelimited writes the table records with the given field delimiter
+func (t *Table) exportDelimited(w io.Writer, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+	if err := writer.WriteAll(t.ToRecords()); err != nil {
+		return WrapError("export_csv", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package document
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAddTableFromCSV tests creating tables from CSV and TSV data
+func TestAddTableFromCSV(t *testing.T) {
+	doc := New()
+	data := "\ufeffName,Qty,Note\nPaper,2,\"A4, white\"\nInk,3\n"
+
+	table, err := doc.AddTableFromCSV(strings.NewReader(data), &CSVTableConfig{HasHeader: true})
+	if err != nil {
+		t.Fatalf("failed to import CSV: %v", err)
+	}
+
+	if table.GetRowCount() != 3 || table.GetColumnCount() != 3 {
+		t.Fatalf("expected 3x3 table, got %dx%d", table.GetRowCount(), table.GetColumnCount())
+	}
+	if text, _ := table.GetCellText(0, 0); text != "Name" {
+		t.Errorf("byte order mark should be removed, got %q", text)
+	}
+	if text, _ := table.GetCellText(1, 2); text != "A4, white" {
+		t.Errorf("quoted field should be kept, got %q", text)
+	}
+	if isHeader, _ := table.IsRowHeader(0); !isHeader {
+		t.Error("first row should be a header row")
+	}
+	if run := table.Rows[0].Cells[0].Paragraphs[0].Runs[0]; run.Properties == nil || run.Properties.Bold == nil {
+		t.Error("header cells should be bold by default")
+	}
+
+	tsv, err := doc.AddTableFromCSV(strings.NewReader("a\tb\nc\td\n"), &CSVTableConfig{Delimiter: '\t'})
+	if err != nil {
+		t.Fatalf("failed to import TSV: %v", err)
+	}
+	if text, _ := tsv.GetCellText(1, 1); text != "d" {
+		t.Errorf("expected d, got %q", text)
+	}
+
+	if _, err := doc.AddTableFromCSV(strings.NewReader(""), nil); err == nil {
+		t.Error("expected error for empty CSV")
+	}
+}
+
+type testOrderBase struct {
+	ID string `docx:"Order No,width=1500"`
+}
+
+type testOrder struct {
+	testOrderBase
+	Customer string
+	Amount   float64   `docx:"Amount,format=%.2f,align=right"`
+	Date     time.Time `docx:"Date,width=2000,format=Jan 2, 2006"`
+	Internal string    `docx:"-"`
+	Discount *float64
+	secret   string
+}
+
+// TestAddTableFromStructs tests creating tables from struct slices
+func TestAddTableFromStructs(t *testing.T) {
+	doc := New()
+	discount := 5.0
+	orders := []*testOrder{
+		{testOrderBase: testOrderBase{ID: "A-1"}, Customer: "Acme", Amount: 1200.5, Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Discount: &discount},
+		nil,
+		{testOrderBase: testOrderBase{ID: "A-2"}, Customer: "Globex", Amount: 99, secret: "x"},
+	}
+
+	table, err := doc.AddTableFromStructs(orders, &StructTableConfig{TableImportConfig: TableImportConfig{Width: 9000}})
+	if err != nil {
+		t.Fatalf("failed to import structs: %v", err)
+	}
+
+	expected := [][]string{
+		{"Order No", "Customer", "Amount", "Date", "Discount"},
+		{"A-1", "Acme", "1200.50", "Mar 1, 2024", "5"},
+		{"A-2", "Globex", "99.00", "", ""},
+	}
+	records := table.ToRecords()
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(records))
+	}
+	for r := range expected {
+		for c := range expected[r] {
+			if records[r][c] != expected[r][c] {
+				t.Errorf("cell (%d,%d): expected %q, got %q", r, c, expected[r][c], records[r][c])
+			}
+		}
+	}
+
+	widths := []string{"1500", "1833", "1833", "2000", "1833"}
+	for i, col := range table.Grid.Cols {
+		if col.W != widths[i] {
+			t.Errorf("column %d: expected width %s, got %s", i, widths[i], col.W)
+		}
+	}
+
+	jc := table.Rows[1].Cells[2].Paragraphs[0].Properties
+	if jc == nil || jc.Justification == nil || jc.Justification.Val != "right" {
+		t.Error("aligned column should be right justified")
+	}
+
+	if _, err := doc.AddTableFromStructs([]int{1, 2}, nil); err == nil {
+		t.Error("expected error for non-struct slice")
+	}
+	type badTag struct {
+		A string `docx:"A,color=red"`
+	}
+	if _, err := doc.AddTableFromStructs([]badTag{{A: "x"}}, nil); err == nil {
+		t.Error("expected error for unknown tag option")
+	}
+}
+
+// TestExportCSV tests exporting tables with merged cells
+func TestExportCSV(t *testing.T) {
+	doc := New()
+	table, err := doc.AddTable(&TableConfig{
+		Rows:  3,
+		Cols:  3,
+		Width: 6000,
+		Data:  [][]string{{"Region", "Q1", "Q2"}, {"North", "1", "2"}, {"South", "3", "4"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	if err := table.MergeCellsHorizontal(0, 1, 2); err != nil {
+		t.Fatalf("failed to merge cells: %v", err)
+	}
+	if err := table.MergeCellsVertical(1, 2, 0); err != nil {
+		t.Fatalf("failed to merge cells: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := table.ExportCSV(&buf); err != nil {
+		t.Fatalf("failed to export CSV: %v", err)
+	}
+	expected := "Region,Q1,\nNorth,1,2\n,3,4\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := table.ExportTSV(&buf); err != nil {
+		t.Fatalf("failed to export TSV: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "Region\tQ1\t\n") {
+		t.Errorf("unexpected TSV output %q", buf.String())
+	}
+}
+
+// TestExportTableWithoutGrid tests exporting and sorting a table without w:tblGrid, as found in opened documents
+func TestExportTableWithoutGrid(t *testing.T) {
+	doc := New()
+	table, err := doc.AddTable(&TableConfig{
+		Rows:  3,
+		Cols:  2,
+		Width: 4000,
+		Data:  [][]string{{"Name", "Count"}, {"b", "2"}, {"a", "1"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	table.Grid = nil
+
+	if err := table.SortRows(1, []SortKey{{Col: 0}}); err != nil {
+		t.Fatalf("failed to sort rows: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := table.ExportCSV(&buf); err != nil {
+		t.Fatalf("failed to export CSV: %v", err)
+	}
+	if expected := "Name,Count\na,1\nb,2\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
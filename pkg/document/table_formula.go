@@ -0,0 +1,699 @@
+// Package document provides table formula fields and computed cells
+package document
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// formulaInstrPattern splits a formula field instruction into expression and number format
+var formulaInstrPattern = regexp.MustCompile(`^\s*=(.*?)\s*(?:\\#\s*(?:"([^"]*)"|(\S+)))?\s*$`)
+
+// SetCellFormula inserts a formula field such as =SUM(ABOVE) or =AVERAGE(B2:B9) into a cell
+//
+// The expression may be given with or without the leading "=". numberFormat is a Word numeric
+// picture such as "0.00" or "#,##0.00" and may be empty for general formatting.
+// The field result is computed from the current cell texts so the value is visible without
+// updating fields in Word. Cell references use spreadsheet notation (A1 is the first cell of
+// the first row) and count cells within a row.
+//
+// Supported functions: SUM, AVERAGE, MIN, MAX, COUNT, PRODUCT, ABS, INT, MOD, ROUND.
+// Supported arguments: numbers, cell references, ranges (A1:B3) and ABOVE, BELOW, LEFT, RIGHT.
+func (t *Table) SetCellFormula(row, col int, expr string, numberFormat string) error {
+	cell, err := t.GetCell(row, col)
+	if err != nil {
+		return err
+	}
+
+	expr = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(expr), "="))
+	if expr == "" {
+		return NewValidationError("expr", expr, "formula cannot be empty")
+	}
+
+	value, err := t.evaluateFormula(expr, row, col)
+	if err != nil {
+		return WrapErrorWithContext("set_cell_formula", err, fmt.Sprintf("row %d, col %d", row, col))
+	}
+
+	// keep the paragraph and run formatting of the cell for the result
+	var paraProps *ParagraphProperties
+	var runProps *RunProperties
+	if len(cell.Paragraphs) > 0 {
+		paraProps = cell.Paragraphs[0].Properties
+		if len(cell.Paragraphs[0].Runs) > 0 {
+			runProps = cell.Paragraphs[0].Runs[0].Properties
+		}
+	}
+
+	instr := " =" + expr + " "
+	if numberFormat != "" {
+		instr += fmt.Sprintf("\\# \"%s\" ", numberFormat)
+	}
+
+	cell.Paragraphs = []Paragraph{
+		{
+			Properties: paraProps,
+			Runs: []Run{
+				{FieldChar: &FieldChar{FieldCharType: "begin"}},
+				{InstrText: &InstrText{Space: "preserve", Content: instr}},
+				{FieldChar: &FieldChar{FieldCharType: "separate"}},
+				{Properties: runProps, Text: Text{Content: formatFormulaNumber(value, numberFormat)}},
+				{FieldChar: &FieldChar{FieldCharType: "end"}},
+			},
+		},
+	}
+
+	Debugf("Set formula =%s in cell (%d, %d)", expr, row, col)
+	return nil
+}
+
+// GetCellFormula returns the formula expression and number format of a formula cell
+// The expression is returned without the leading "="; ok is false when the cell has no formula.
+func (t *Table) GetCellFormula(row, col int) (expr string, numberFormat string, ok bool) {
+	cell, err := t.GetCell(row, col)
+	if err != nil {
+		return "", "", false
+	}
+
+	for i := range cell.Paragraphs {
+		if field := findFormulaField(&cell.Paragraphs[i]); field != nil {
+			return field.expr, field.numberFormat, true
+		}
+	}
+	return "", "", false
+}
+
+// RecalculateFormulas recomputes the results of all formula fields in the table
+// Cells are processed row by row, so formulas can use the results of formulas above or to the left.
+// All formulas are processed even if some fail; the first error is returned.
+func (t *Table) RecalculateFormulas() error {
+	var firstErr error
+
+	for r := range t.Rows {
+		for c := range t.Rows[r].Cells {
+			cell := &t.Rows[r].Cells[c]
+			for p := range cell.Paragraphs {
+				para := &cell.Paragraphs[p]
+				field := findFormulaField(para)
+				if field == nil {
+					continue
+				}
+
+				value, err := t.evaluateFormula(field.expr, r, c)
+				if err != nil {
+					if firstErr == nil {
+						firstErr = WrapErrorWithContext("recalculate_formulas", err, fmt.Sprintf("row %d, col %d", r, c))
+					}
+					continue
+				}
+				field.setResult(para, formatFormulaNumber(value, field.numberFormat))
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// formulaField locates a formula field inside a paragraph
+type formulaField struct {
+	expr         string
+	numberFormat string
+	separate     int // index of the separate run, -1 if the field has no result yet
+	end          int // index of the end run
+}
+
+// findFormulaField returns the first formula field of a paragraph, or nil
+func findFormulaField(para *Paragraph) *formulaField {
+	for i := 0; i < len(para.Runs); i++ {
+		if para.Runs[i].FieldChar == nil || para.Runs[i].FieldChar.FieldCharType != "begin" {
+			continue
+		}
+
+		var instr strings.Builder
+		field := &formulaField{separate: -1, end: -1}
+		for j := i + 1; j < len(para.Runs); j++ {
+			run := &para.Runs[j]
+			if run.InstrText != nil && field.separate < 0 {
+				instr.WriteString(run.InstrText.Content)
+			}
+			if run.FieldChar != nil {
+				if run.FieldChar.FieldCharType == "separate" {
+					field.separate = j
+				} else if run.FieldChar.FieldCharType == "end" {
+					field.end = j
+					break
+				}
+			}
+		}
+		if field.end < 0 {
+			return nil
+		}
+
+		match := formulaInstrPattern.FindStringSubmatch(instr.String())
+		if match == nil {
+			i = field.end
+			continue
+		}
+		field.expr = match[1]
+		field.numberFormat = match[2]
+		if field.numberFormat == "" {
+			field.numberFormat = match[3]
+		}
+		return field
+	}
+	return nil
+}
+
+// setResult replaces the displayed result of the field
+func (f *formulaField) setResult(para *Paragraph, result string) {
+	if f.separate < 0 {
+		runs := make([]Run, 0, len(para.Runs)+2)
+		runs = append(runs, para.Runs[:f.end]...)
+		runs = append(runs, Run{FieldChar: &FieldChar{FieldCharType: "separate"}}, Run{Text: Text{Content: result}})
+		para.Runs = append(runs, para.Runs[f.end:]...)
+		return
+	}
+
+	written := false
+	for j := f.separate + 1; j < f.end; j++ {
+		run := &para.Runs[j]
+		if run.FieldChar != nil || run.InstrText != nil {
+			continue
+		}
+		if !written {
+			run.Text.Content = result
+			written = true
+		} else {
+			run.Text.Content = ""
+		}
+	}
+	if !written {
+		runs := make([]Run, 0, len(para.Runs)+1)
+		runs = append(runs, para.Runs[:f.end]...)
+		runs = append(runs, Run{Text: Text{Content: result}})
+		para.Runs = append(runs, para.Runs[f.end:]...)
+	}
+}
+
+// formatFormulaNumber formats a value with a Word numeric picture such as "#,##0.00"
+// A picture may have a second section for negative values, e.g. "#,##0.00;(#,##0.00)".
+func formatFormulaNumber(value float64, picture string) string {
+	// avoid artifacts such as 0.30000000000000004
+	value = math.Round(value*1e10) / 1e10
+
+	if picture == "" {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	negative := value < 0
+	sections := strings.Split(picture, ";")
+	picture = sections[0]
+	if negative && len(sections) > 1 {
+		picture = sections[1]
+		value = -value
+		negative = false
+	}
+
+	start := strings.IndexAny(picture, "#0")
+	if start < 0 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	end := strings.LastIndexAny(picture, "#0")
+	prefix, pattern, suffix := picture[:start], picture[start:end+1], picture[end+1:]
+
+	decimals := 0
+	if dot := strings.Index(pattern, "."); dot >= 0 {
+		decimals = strings.Count(pattern[dot+1:], "0") + strings.Count(pattern[dot+1:], "#")
+	}
+
+	// round half away from zero like Word instead of strconv's round half to even
+	scale := math.Pow(10, float64(decimals))
+	rounded := math.Round(math.Abs(value)*scale) / scale
+	text := strconv.FormatFloat(rounded, 'f', decimals, 64)
+	if strings.Contains(pattern, ",") {
+		intPart, fracPart := text, ""
+		if dot := strings.Index(text, "."); dot >= 0 {
+			intPart, fracPart = text[:dot], text[dot:]
+		}
+		var grouped strings.Builder
+		for i, ch := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				grouped.WriteByte(',')
+			}
+			grouped.WriteRune(ch)
+		}
+		text = grouped.String() + fracPart
+	}
+
+	if negative && rounded != 0 {
+		return "-" + prefix + text + suffix
+	}
+	return prefix + text + suffix
+}
+
+// parseCellNumber extracts a number from cell text such as "1,234.50", "$99" or "(12)"
+func parseCellNumber(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, false
+	}
+
+	negative := false
+	if strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")") {
+		negative = true
+		text = text[1 : len(text)-1]
+	}
+
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r == ',' || r == '%' || unicode.IsSpace(r) || unicode.Is(unicode.Sc, r):
+			return -1
+		}
+		return r
+	}, text)
+
+	value, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		value = -value
+	}
+	return value, true
+}
+
+// evaluateFormula evaluates a formula expression for the cell at row, col
+func (t *Table) evaluateFormula(expr string, row, col int) (float64, error) {
+	tokens, err := tokenizeFormula(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	p := &formulaParser{table: t, row: row, col: col, tokens: tokens}
+	value, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, fmt.Errorf("unexpected %q in formula", p.tokens[p.pos].text)
+	}
+	return value, nil
+}
+
+// formula token kinds
+const (
+	formulaTokenNumber = iota
+	formulaTokenIdent
+	formulaTokenOp
+)
+
+// formulaToken is a lexical token of a formula expression
+type formulaToken struct {
+	kind int
+	text string
+}
+
+// tokenizeFormula splits a formula expression into tokens
+func tokenizeFormula(expr string) ([]formulaToken, error) {
+	var tokens []formulaToken
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case unicode.IsDigit(ch) || ch == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenNumber, text: string(runes[start:i])})
+		case unicode.IsLetter(ch):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: formulaTokenIdent, text: strings.ToUpper(string(runes[start:i]))})
+		case strings.ContainsRune("+-*/^(),;:", ch):
+			tokens = append(tokens, formulaToken{kind: formulaTokenOp, text: string(ch)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q in formula", ch)
+		}
+	}
+
+	return tokens, nil
+}
+
+// cellRefPattern matches spreadsheet style cell references such as B12
+var cellRefPattern = regexp.MustCompile(`^([A-Z]+)([0-9]+)$`)
+
+// formulaParser is a recursive descent evaluator for table formulas
+type formulaParser struct {
+	table    *Table
+	row, col int
+	tokens   []formulaToken
+	pos      int
+}
+
+// peek returns the current token, or nil at the end of input
+func (p *formulaParser) peek() *formulaToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+// acceptOp consumes the current token if it is the operator op
+func (p *formulaParser) acceptOp(op string) bool {
+	if tok := p.peek(); tok != nil && tok.kind == formulaTokenOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseExpr parses additions and subtractions
+func (p *formulaParser) parseExpr() (float64, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.acceptOp("+"):
+			right, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			left += right
+		case p.acceptOp("-"):
+			right, err := p.parseTerm()
+			if err != nil {
+				return 0, err
+			}
+			left -= right
+		default:
+			return left, nil
+		}
+	}
+}
+
+// parseTerm parses multiplications and divisions
+func (p *formulaParser) parseTerm() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.acceptOp("*"):
+			right, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			left *= right
+		case p.acceptOp("/"):
+			right, err := p.parseUnary()
+			if err != nil {
+				return 0, err
+			}
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			left /= right
+		default:
+			return left, nil
+		}
+	}
+}
+
+// parseUnary parses signs and powers
+func (p *formulaParser) parseUnary() (float64, error) {
+	if p.acceptOp("-") {
+		value, err := p.parseUnary()
+		return -value, err
+	}
+	if p.acceptOp("+") {
+		return p.parseUnary()
+	}
+
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	if p.acceptOp("^") {
+		exp, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exp), nil
+	}
+	return base, nil
+}
+
+// parsePrimary parses numbers, cell references, function calls and parentheses
+func (p *formulaParser) parsePrimary() (float64, error) {
+	tok := p.peek()
+	if tok == nil {
+		return 0, fmt.Errorf("unexpected end of formula")
+	}
+
+	switch tok.kind {
+	case formulaTokenNumber:
+		p.pos++
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return value, nil
+	case formulaTokenIdent:
+		p.pos++
+		if p.acceptOp("(") {
+			return p.parseCall(tok.text)
+		}
+		row, col, err := parseCellRef(tok.text)
+		if err != nil {
+			return 0, err
+		}
+		value, _ := p.cellValue(row, col)
+		return value, nil
+	default:
+		if p.acceptOp("(") {
+			value, err := p.parseExpr()
+			if err != nil {
+				return 0, err
+			}
+			if !p.acceptOp(")") {
+				return 0, fmt.Errorf("missing closing parenthesis")
+			}
+			return value, nil
+		}
+		return 0, fmt.Errorf("unexpected %q in formula", tok.text)
+	}
+}
+
+// parseCall parses the arguments of a function call and applies the function
+func (p *formulaParser) parseCall(name string) (float64, error) {
+	var values []float64
+	argCount := 0
+
+	if !p.acceptOp(")") {
+		for {
+			args, err := p.parseArg()
+			if err != nil {
+				return 0, err
+			}
+			values = append(values, args...)
+			argCount++
+			if p.acceptOp(")") {
+				break
+			}
+			if !p.acceptOp(",") && !p.acceptOp(";") {
+				return 0, fmt.Errorf("expected , or ) in call to %s", name)
+			}
+		}
+	}
+
+	switch name {
+	case "SUM":
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum, nil
+	case "AVERAGE":
+		if len(values) == 0 {
+			return 0, nil
+		}
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), nil
+	case "MIN", "MAX":
+		if len(values) == 0 {
+			return 0, nil
+		}
+		result := values[0]
+		for _, v := range values[1:] {
+			if (name == "MIN" && v < result) || (name == "MAX" && v > result) {
+				result = v
+			}
+		}
+		return result, nil
+	case "COUNT":
+		return float64(len(values)), nil
+	case "PRODUCT":
+		if len(values) == 0 {
+			return 0, nil
+		}
+		product := 1.0
+		for _, v := range values {
+			product *= v
+		}
+		return product, nil
+	case "ABS", "INT":
+		if argCount != 1 || len(values) != 1 {
+			return 0, fmt.Errorf("%s takes one argument", name)
+		}
+		if name == "ABS" {
+			return math.Abs(values[0]), nil
+		}
+		return math.Trunc(values[0]), nil
+	case "MOD", "ROUND":
+		if argCount != 2 || len(values) != 2 {
+			return 0, fmt.Errorf("%s takes two arguments", name)
+		}
+		if name == "MOD" {
+			if values[1] == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return math.Mod(values[0], values[1]), nil
+		}
+		scale := math.Pow(10, math.Trunc(values[1]))
+		return math.Round(values[0]*scale) / scale, nil
+	}
+
+	return 0, fmt.Errorf("unsupported function %s", name)
+}
+
+// parseArg parses a function argument, which may expand to several values
+func (p *formulaParser) parseArg() ([]float64, error) {
+	if tok := p.peek(); tok != nil && tok.kind == formulaTokenIdent {
+		switch tok.text {
+		case "ABOVE", "BELOW", "LEFT", "RIGHT":
+			p.pos++
+			return p.directionValues(tok.text), nil
+		}
+
+		// a range such as A1:B3
+		if p.pos+2 < len(p.tokens) && p.tokens[p.pos+1].text == ":" && p.tokens[p.pos+2].kind == formulaTokenIdent {
+			r1, c1, err := parseCellRef(tok.text)
+			if err != nil {
+				return nil, err
+			}
+			r2, c2, err := parseCellRef(p.tokens[p.pos+2].text)
+			if err != nil {
+				return nil, err
+			}
+			p.pos += 3
+			return p.rangeValues(r1, c1, r2, c2), nil
+		}
+	}
+
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return []float64{value}, nil
+}
+
+// parseCellRef converts a reference such as B3 to zero based row and column indexes
+func parseCellRef(ref string) (int, int, error) {
+	match := cellRefPattern.FindStringSubmatch(ref)
+	if match == nil {
+		return 0, 0, fmt.Errorf("unknown name %s in formula", ref)
+	}
+
+	col := 0
+	for _, ch := range match[1] {
+		col = col*26 + int(ch-'A'+1)
+	}
+	row, _ := strconv.Atoi(match[2])
+	if row < 1 {
+		return 0, 0, fmt.Errorf("invalid cell reference %s", ref)
+	}
+	return row - 1, col - 1, nil
+}
+
+// cellValue returns the numeric value of a cell; ok is false for empty or non-numeric cells
+func (p *formulaParser) cellValue(row, col int) (float64, bool) {
+	text, err := p.table.GetCellText(row, col)
+	if err != nil {
+		return 0, false
+	}
+	return parseCellNumber(text)
+}
+
+// rangeValues returns the numeric values of a rectangular range of cells
+func (p *formulaParser) rangeValues(r1, c1, r2, c2 int) []float64 {
+	if r1 > r2 {
+		r1, r2 = r2, r1
+	}
+	if c1 > c2 {
+		c1, c2 = c2, c1
+	}
+
+	var values []float64
+	for r := r1; r <= r2; r++ {
+		for c := c1; c <= c2; c++ {
+			if r == p.row && c == p.col {
+				continue
+			}
+			if value, ok := p.cellValue(r, c); ok {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// directionValues returns the numeric values of the cells in a direction from the current cell
+// Like Word, collection stops at the first empty cell; cells with other text are skipped.
+func (p *formulaParser) directionValues(direction string) []float64 {
+	dr, dc := 0, 0
+	switch direction {
+	case "ABOVE":
+		dr = -1
+	case "BELOW":
+		dr = 1
+	case "LEFT":
+		dc = -1
+	case "RIGHT":
+		dc = 1
+	}
+
+	var values []float64
+	for r, c := p.row+dr, p.col+dc; r >= 0 && r < len(p.table.Rows) && c >= 0; r, c = r+dr, c+dc {
+		if c >= len(p.table.Rows[r].Cells) {
+			if dc != 0 {
+				break
+			}
+			continue
+		}
+		text, _ := p.table.GetCellText(r, c)
+		if strings.TrimSpace(text) == "" {
+			break
+		}
+		if value, ok := parseCellNumber(text); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
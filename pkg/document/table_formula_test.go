@@ -0,0 +1,180 @@
+package document
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// newInvoiceTable creates a table with a header row and amount rows
+func newInvoiceTable(t *testing.T, doc *Document) *Table {
+	table, err := doc.AddTable(&TableConfig{
+		Rows:  1,
+		Cols:  3,
+		Width: 6000,
+		Data:  [][]string{{"Item", "Qty", "Amount"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	for _, row := range [][]string{{"Paper", "2", "1,200.50"}, {"Ink", "3", "$99.50"}, {"Total", "", ""}} {
+		if err := table.AppendRow(row); err != nil {
+			t.Fatalf("failed to append row: %v", err)
+		}
+	}
+	return table
+}
+
+// TestSetCellFormula tests inserting formula fields with pre-computed results
+func TestSetCellFormula(t *testing.T) {
+	doc := New()
+	table := newInvoiceTable(t, doc)
+
+	if err := table.SetCellFormula(3, 2, "=SUM(ABOVE)", "#,##0.00"); err != nil {
+		t.Fatalf("failed to set formula: %v", err)
+	}
+	if err := table.SetCellFormula(3, 1, "SUM(B2:B3)", ""); err != nil {
+		t.Fatalf("failed to set formula: %v", err)
+	}
+
+	if text, _ := table.GetCellText(3, 2); text != "1,300.00" {
+		t.Errorf("expected 1,300.00, got %q", text)
+	}
+	if text, _ := table.GetCellText(3, 1); text != "5" {
+		t.Errorf("expected 5, got %q", text)
+	}
+
+	expr, format, ok := table.GetCellFormula(3, 2)
+	if !ok || expr != "SUM(ABOVE)" || format != "#,##0.00" {
+		t.Errorf("unexpected formula %q with format %q", expr, format)
+	}
+
+	if err := doc.serializeDocument(); err != nil {
+		t.Fatalf("failed to serialize document: %v", err)
+	}
+	xmlStr := string(doc.parts["word/document.xml"])
+	if !strings.Contains(xmlStr, `=SUM(ABOVE) \# &#34;#,##0.00&#34;`) {
+		t.Errorf("formula field instruction not found in XML")
+	}
+
+	if err := table.SetCellFormula(3, 2, "=SUM(", ""); err == nil {
+		t.Error("expected error for invalid formula")
+	}
+	if err := table.SetCellFormula(3, 2, "=FOO(1)", ""); err == nil {
+		t.Error("expected error for unsupported function")
+	}
+}
+
+// TestRecalculateFormulas tests recomputing formula results after data changes
+func TestRecalculateFormulas(t *testing.T) {
+	doc := New()
+	table := newInvoiceTable(t, doc)
+
+	if err := table.SetCellFormula(3, 2, "SUM(ABOVE)", "0.00"); err != nil {
+		t.Fatalf("failed to set formula: %v", err)
+	}
+	if err := table.SetCellText(1, 2, "10"); err != nil {
+		t.Fatalf("failed to set cell text: %v", err)
+	}
+	if err := table.RecalculateFormulas(); err != nil {
+		t.Fatalf("failed to recalculate: %v", err)
+	}
+	if text, _ := table.GetCellText(3, 2); text != "109.50" {
+		t.Errorf("expected 109.50, got %q", text)
+	}
+
+	// formulas survive a save/open round trip and can be recalculated
+	filename := "test_table_formula.docx"
+	defer os.Remove(filename)
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	loaded, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	tables := loaded.Body.GetTables()
+	if len(tables) != 1 {
+		t.Fatalf("expected 1 table after reopening, got %d", len(tables))
+	}
+	loadedTable := tables[0]
+	if _, _, ok := loadedTable.GetCellFormula(3, 2); !ok {
+		t.Fatal("formula should survive a save/open round trip")
+	}
+	loadedTable.SetCellText(2, 2, "0.5")
+	if err := loadedTable.RecalculateFormulas(); err != nil {
+		t.Fatalf("failed to recalculate: %v", err)
+	}
+	if text, _ := loadedTable.GetCellText(3, 2); text != "10.50" {
+		t.Errorf("expected 10.50, got %q", text)
+	}
+}
+
+// TestEvaluateFormula tests the supported formula syntax
+func TestEvaluateFormula(t *testing.T) {
+	doc := New()
+	table := newInvoiceTable(t, doc)
+
+	tests := []struct {
+		expr     string
+		expected float64
+	}{
+		{"1+2*3", 7},
+		{"(1+2)*3", 9},
+		{"-2^2", -4},
+		{"B2*C2", 2401},
+		{"AVERAGE(B2:B3)", 2.5},
+		{"MAX(B2,B3,10)", 10},
+		{"MIN(C2:C3)", 99.5},
+		{"COUNT(A1:C3)", 4},
+		{"PRODUCT(B2:B3)", 6},
+		{"ROUND(2/3, 2)", 0.67},
+		{"MOD(7;4)", 3},
+		{"ABS(-3)", 3},
+		{"INT(3.9)", 3},
+	}
+
+	for _, tt := range tests {
+		value, err := table.evaluateFormula(tt.expr, 3, 2)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.expr, err)
+			continue
+		}
+		if value != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.expr, tt.expected, value)
+		}
+	}
+
+	if value, _ := table.evaluateFormula("SUM(LEFT)", 1, 2); value != 2 {
+		t.Errorf("SUM(LEFT) should skip text cells, got %v", value)
+	}
+	for _, expr := range []string{"1/0", "SUM(1", "B2 B3", "XYZ", "1 & 2"} {
+		if _, err := table.evaluateFormula(expr, 3, 2); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+}
+
+// TestFormatFormulaNumber tests Word numeric pictures
+func TestFormatFormulaNumber(t *testing.T) {
+	tests := []struct {
+		value    float64
+		picture  string
+		expected string
+	}{
+		{1234.5, "", "1234.5"},
+		{0.1 + 0.2, "", "0.3"},
+		{1234.5, "0", "1235"},
+		{1234567.891, "#,##0.00", "1,234,567.89"},
+		{99, "$#,##0.00", "$99.00"},
+		{-5, "0.0", "-5.0"},
+		{-5, "#,##0.00;(#,##0.00)", "(5.00)"},
+		{12, "0%", "12%"},
+	}
+
+	for _, tt := range tests {
+		if got := formatFormulaNumber(tt.value, tt.picture); got != tt.expected {
+			t.Errorf("formatFormulaNumber(%v, %q) = %q, expected %q", tt.value, tt.picture, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Package document provides splitting, joining and transposing of tables
+package document
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitAt splits the table before the given row and returns the rows from there on as a new table
+//
+// The new table gets copies of the table properties, the grid and the header rows repeated at the
+// top of the table (see SetHeaderRows), so it starts with the same header. Vertical merges that
+// cross the split are cut in two. The new table is not added to a document, use
+// Document.SplitTable to split a table in place.
+func (t *Table) SplitAt(row int) (*Table, error) {
+	headerRows := 0
+	for headerRows < len(t.Rows) && isRepeatedHeaderRow(&t.Rows[headerRows]) {
+		headerRows++
+	}
+	if row <= headerRows || row >= len(t.Rows) {
+		return nil, fmt.Errorf("invalid split row: %d, must be between %d and %d", row, headerRows+1, len(t.Rows)-1)
+	}
+
+	te := &TemplateEngine{}
+	newTable := &Table{
+		Properties: te.cloneTableProperties(t.Properties),
+		Grid:       te.cloneTableGrid(t.Grid),
+		Rows:       make([]TableRow, 0, headerRows+len(t.Rows)-row),
+		doc:        t.doc,
+	}
+	for r := 0; r < headerRows; r++ {
+		newTable.Rows = append(newTable.Rows, *te.cloneTableRow(&t.Rows[r]))
+	}
+	newTable.Rows = append(newTable.Rows, t.Rows[row:]...)
+	t.Rows = t.Rows[:row:row]
+
+	// a merge continued in the first moved row restarts there, and merges left without continuation end
+	newTable.restartVerticalMerges(headerRows)
+	t.endVerticalMerges()
+
+	Info(fmt.Sprintf("table split at row %d: %d rows kept, %d rows moved", row, len(t.Rows), len(newTable.Rows)-headerRows))
+	return newTable, nil
+}
+
+// SplitTable splits a table of the document before the given row
+// The second part is inserted after the first one, separated by an empty paragraph so Word
+// does not display them as a single table. It returns the new table.
+func (d *Document) SplitTable(table *Table, row int) (*Table, error) {
+	index := d.elementIndex(table)
+	if index < 0 {
+		return nil, fmt.Errorf("table is not part of the document body")
+	}
+
+	newTable, err := table.SplitAt(row)
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]interface{}, 0, len(d.Body.Elements)+2)
+	elements = append(elements, d.Body.Elements[:index+1]...)
+	elements = append(elements, &Paragraph{}, newTable)
+	elements = append(elements, d.Body.Elements[index+1:]...)
+	d.Body.Elements = elements
+
+	return newTable, nil
+}
+
+// JoinTables appends the rows of table b to table a and removes b from the document
+//
+// b must follow a in the document body with at most empty paragraphs in between, and both tables
+// must have the same number of grid columns. The cells of b take the column widths of a. Header
+// rows at the top of b that repeat the header of a are dropped; other header rows of b become
+// regular rows.
+func (d *Document) JoinTables(a, b *Table) error {
+	indexA, indexB := d.elementIndex(a), d.elementIndex(b)
+	if indexA < 0 || indexB < 0 {
+		return fmt.Errorf("both tables must be part of the document body")
+	}
+	if indexB <= indexA {
+		return fmt.Errorf("the second table must follow the first one")
+	}
+	for i := indexA + 1; i < indexB; i++ {
+		para, ok := d.Body.Elements[i].(*Paragraph)
+		if !ok || !isEmptyParagraph(para) {
+			return fmt.Errorf("tables are not adjacent, element %d separates them", i)
+		}
+	}
+
+	colsA, colsB := a.gridColumnCount(), b.gridColumnCount()
+	if colsA != colsB {
+		return NewValidationError("tables", fmt.Sprintf("%d/%d", colsA, colsB), "tables have incompatible grids")
+	}
+
+	headerA := 0
+	for headerA < len(a.Rows) && isRepeatedHeaderRow(&a.Rows[headerA]) {
+		headerA++
+	}
+	recordsA, recordsB := a.ToRecords(), b.ToRecords()
+	skip := 0
+	for skip < len(b.Rows) && isRepeatedHeaderRow(&b.Rows[skip]) {
+		skip++
+	}
+	if skip != headerA || !equalRecords(recordsA[:headerA], recordsB[:skip]) {
+		skip = 0
+	}
+
+	for _, row := range b.Rows[skip:] {
+		if row.Properties != nil {
+			row.Properties.TblHeader = nil
+		}
+		a.Rows = append(a.Rows, row)
+	}
+	a.setGridColumnWidths(a.gridColumnWidths())
+
+	elements := make([]interface{}, 0, len(d.Body.Elements))
+	elements = append(elements, d.Body.Elements[:indexA+1]...)
+	elements = append(elements, d.Body.Elements[indexB+1:]...)
+	d.Body.Elements = elements
+
+	Info(fmt.Sprintf("tables joined: %d rows appended", len(b.Rows)-skip))
+	return nil
+}
+
+// Transpose swaps the rows and columns of the table
+//
+// Cells keep their content and formatting. Horizontal merges become vertical merges and vice
+// versa. The table keeps its total width, which is shared evenly by the new columns; row
+// properties such as heights and header rows do not carry over, as rows become columns.
+func (t *Table) Transpose() error {
+	if len(t.Rows) == 0 {
+		return fmt.Errorf("table has no rows")
+	}
+
+	cols := t.gridColumnCount()
+	positions, err := t.cellPositions(cols)
+	if err != nil {
+		return err
+	}
+
+	totalWidth := 0
+	for _, w := range t.gridColumnWidths() {
+		totalWidth += w
+	}
+
+	newRows := make([]TableRow, cols)
+	for i := range newRows {
+		for j := 0; j < len(t.Rows); j++ {
+			// a cell spanning several rows is written once per new row, at the column of its origin row
+			pos := positions[j][i]
+			if pos.row != j {
+				continue
+			}
+			source := &t.Rows[pos.row].Cells[pos.cell]
+			var cell TableCell
+			if pos.gridCol == i {
+				cell = *source
+				if cell.Properties != nil {
+					props := *cell.Properties
+					cell.Properties = &props
+				}
+			} else {
+				// continuation of a cell that spanned several columns
+				cell = TableCell{Paragraphs: []Paragraph{{}}}
+				if source.Properties != nil {
+					props := *source.Properties
+					cell.Properties = &props
+				}
+			}
+			if cell.Properties == nil {
+				cell.Properties = &TableCellProperties{}
+			}
+
+			cell.Properties.GridSpan = nil
+			if pos.rowSpan > 1 {
+				cell.Properties.GridSpan = &GridSpan{Val: fmt.Sprintf("%d", pos.rowSpan)}
+			}
+			cell.Properties.VMerge = nil
+			if pos.colSpan > 1 {
+				if pos.gridCol == i {
+					cell.Properties.VMerge = &VMerge{Val: "restart"}
+				} else {
+					cell.Properties.VMerge = &VMerge{Val: "continue"}
+				}
+			}
+			newRows[i].Cells = append(newRows[i].Cells, cell)
+		}
+	}
+
+	t.Rows = newRows
+	widths := make([]int, len(positions))
+	for c := range widths {
+		widths[c] = totalWidth / len(widths)
+	}
+	t.setGridColumnWidths(widths)
+
+	Info(fmt.Sprintf("table transposed: %d rows x %d columns", len(t.Rows), len(widths)))
+	return nil
+}
+
+// cellPosition locates the cell covering a grid position and the area the cell spans
+type cellPosition struct {
+	row, cell        int // origin row and cell index of the covering cell
+	gridCol          int // grid column where the covering cell starts
+	rowSpan, colSpan int // rows and grid columns the covering cell spans
+}
+
+// cellPositions maps every grid position to the cell covering it, resolving gridSpan and vMerge
+func (t *Table) cellPositions(cols int) ([][]cellPosition, error) {
+	positions := make([][]cellPosition, len(t.Rows))
+	for r := range t.Rows {
+		positions[r] = make([]cellPosition, cols)
+		gridCol := 0
+		for i := range t.Rows[r].Cells {
+			cell := &t.Rows[r].Cells[i]
+			span := cellGridSpan(cell)
+			if gridCol+span > cols {
+				return nil, fmt.Errorf("row %d spans more than %d grid columns", r, cols)
+			}
+
+			pos := cellPosition{row: r, cell: i, gridCol: gridCol, rowSpan: 1, colSpan: span}
+			if cell.Properties != nil && cell.Properties.VMerge != nil && cell.Properties.VMerge.Val != "restart" && r > 0 {
+				// continued cells belong to the cell above
+				above := positions[r-1][gridCol]
+				if above.gridCol == gridCol && above.colSpan == span {
+					pos = above
+					pos.rowSpan++
+					for rr := pos.row; rr < r; rr++ {
+						for c := gridCol; c < gridCol+span; c++ {
+							positions[rr][c].rowSpan = pos.rowSpan
+						}
+					}
+				}
+			}
+			for c := gridCol; c < gridCol+span; c++ {
+				positions[r][c] = pos
+			}
+			gridCol += span
+		}
+		if gridCol < cols {
+			return nil, fmt.Errorf("row %d covers %d of %d grid columns", r, gridCol, cols)
+		}
+	}
+	return positions, nil
+}
+
+// restartVerticalMerges turns merge continuations in the given row into merge starts
+func (t *Table) restartVerticalMerges(row int) {
+	if row >= len(t.Rows) {
+		return
+	}
+	for i := range t.Rows[row].Cells {
+		props := t.Rows[row].Cells[i].Properties
+		if props != nil && props.VMerge != nil && props.VMerge.Val != "restart" {
+			props.VMerge = &VMerge{Val: "restart"}
+		}
+	}
+	t.endVerticalMerges()
+}
+
+// endVerticalMerges removes merge starts that no following row continues
+func (t *Table) endVerticalMerges() {
+	for r := range t.Rows {
+		gridCol := 0
+		for i := range t.Rows[r].Cells {
+			cell := &t.Rows[r].Cells[i]
+			span := cellGridSpan(cell)
+			if cell.Properties != nil && cell.Properties.VMerge != nil && cell.Properties.VMerge.Val == "restart" {
+				if !t.continuesMergeAt(r+1, gridCol) {
+					cell.Properties.VMerge = nil
+				}
+			}
+			gridCol += span
+		}
+	}
+}
+
+// continuesMergeAt reports whether the cell of a row starting at gridCol continues a vertical merge
+func (t *Table) continuesMergeAt(row, gridCol int) bool {
+	if row >= len(t.Rows) {
+		return false
+	}
+	col := 0
+	for i := range t.Rows[row].Cells {
+		cell := &t.Rows[row].Cells[i]
+		if col == gridCol {
+			return cell.Properties != nil && cell.Properties.VMerge != nil && cell.Properties.VMerge.Val != "restart"
+		}
+		col += cellGridSpan(cell)
+	}
+	return false
+}
+
+// elementIndex returns the index of an element in the document body, or -1
+func (d *Document) elementIndex(element interface{}) int {
+	for i, e := range d.Body.Elements {
+		if e == element {
+			return i
+		}
+	}
+	return -1
+}
+
+// isEmptyParagraph reports whether a paragraph has no text and no other run content
+func isEmptyParagraph(para *Paragraph) bool {
+	for _, run := range para.Runs {
+		if strings.TrimSpace(run.Text.Content) != "" || run.Drawing != nil || run.Break != nil || run.FieldChar != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// equalRecords reports whether two record sets contain the same texts
+func equalRecords(a, b [][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for r := range a {
+		if strings.Join(a[r], "\x00") != strings.Join(b[r], "\x00") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package document
+
+import (
+	"strings"
+	"testing"
+)
+
+// recordsText joins table records into a compact string for comparisons
+func recordsText(table *Table) string {
+	var rows []string
+	for _, record := range table.ToRecords() {
+		rows = append(rows, strings.Join(record, ","))
+	}
+	return strings.Join(rows, "|")
+}
+
+// TestSplitTable tests splitting a table with a repeated header row
+func TestSplitTable(t *testing.T) {
+	doc := New()
+	table, err := doc.AddTable(&TableConfig{
+		Rows:  5,
+		Cols:  2,
+		Width: 4000,
+		Data:  [][]string{{"Name", "Qty"}, {"a", "1"}, {"b", "2"}, {"c", "3"}, {"d", "4"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	table.SetHeaderRows(0, 0)
+	table.MergeCellsVertical(2, 3, 1)
+	doc.AddParagraph("after")
+
+	second, err := doc.SplitTable(table, 3)
+	if err != nil {
+		t.Fatalf("failed to split table: %v", err)
+	}
+
+	if got := recordsText(table); got != "Name,Qty|a,1|b,2" {
+		t.Errorf("unexpected first part: %s", got)
+	}
+	if got := recordsText(second); got != "Name,Qty|c,|d,4" {
+		t.Errorf("unexpected second part: %s", got)
+	}
+	if isHeader, _ := second.IsRowHeader(0); !isHeader {
+		t.Error("header row should be repeated in the new table")
+	}
+	second.SetCellText(0, 0, "Item")
+	if text, _ := table.GetCellText(0, 0); text != "Name" {
+		t.Error("header rows should be copied, not shared")
+	}
+	if table.Rows[2].Cells[1].Properties.VMerge != nil {
+		t.Error("merge without continuation should be removed from the first part")
+	}
+	if second.Rows[1].Cells[1].Properties.VMerge != nil {
+		t.Error("merge continued in a single row should be removed from the second part")
+	}
+
+	tables := doc.Body.GetTables()
+	if len(tables) != 2 || tables[1] != second {
+		t.Fatal("second part should be added to the document")
+	}
+	if _, ok := doc.Body.Elements[1].(*Paragraph); !ok {
+		t.Error("tables should be separated by a paragraph")
+	}
+
+	if _, err := table.SplitAt(1); err == nil {
+		t.Error("expected error when splitting right after the header")
+	}
+}
+
+// TestJoinTables tests joining adjacent tables and dropping the repeated header
+func TestJoinTables(t *testing.T) {
+	doc := New()
+	newPart := func(data [][]string) *Table {
+		table, err := doc.AddTable(&TableConfig{Rows: len(data), Cols: 2, Width: 4000, Data: data})
+		if err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+		table.SetHeaderRows(0, 0)
+		return table
+	}
+
+	first := newPart([][]string{{"Name", "Qty"}, {"a", "1"}})
+	doc.AddParagraph("")
+	second := newPart([][]string{{"Name", "Qty"}, {"b", "2"}})
+
+	if err := doc.JoinTables(first, second); err != nil {
+		t.Fatalf("failed to join tables: %v", err)
+	}
+	if got := recordsText(first); got != "Name,Qty|a,1|b,2" {
+		t.Errorf("unexpected joined table: %s", got)
+	}
+	if len(doc.Body.Elements) != 1 {
+		t.Errorf("second table and separator should be removed, %d elements left", len(doc.Body.Elements))
+	}
+
+	doc.AddParagraph("text")
+	third := newPart([][]string{{"x", "y"}})
+	if err := doc.JoinTables(first, third); err == nil {
+		t.Error("expected error for tables that are not adjacent")
+	}
+
+	wide, _ := doc.AddTable(&TableConfig{Rows: 1, Cols: 3, Width: 4000})
+	if err := doc.JoinTables(third, wide); err == nil {
+		t.Error("expected error for incompatible grids")
+	}
+}
+
+// TestTransposeTable tests swapping rows and columns with merged cells
+func TestTransposeTable(t *testing.T) {
+	doc := New()
+	table, err := doc.AddTable(&TableConfig{
+		Rows:  3,
+		Cols:  3,
+		Width: 6000,
+		Data:  [][]string{{"Q", "Jan", "Feb"}, {"North", "1", "2"}, {"South", "3", "4"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	table.SetCellFormat(1, 0, &CellFormat{TextFormat: &TextFormat{Bold: true}})
+	table.MergeCellsHorizontal(0, 1, 2) // "Jan" spans two columns
+	table.MergeCellsVertical(1, 2, 0)   // "North" spans two rows
+
+	if err := table.Transpose(); err != nil {
+		t.Fatalf("failed to transpose table: %v", err)
+	}
+
+	if got := recordsText(table); got != "Q,North,|Jan,1,3|,2,4" {
+		t.Errorf("unexpected transposed table: %s", got)
+	}
+
+	north := table.Rows[0].Cells[1]
+	if north.Properties.GridSpan == nil || north.Properties.GridSpan.Val != "2" {
+		t.Error("vertical merge should become a horizontal merge")
+	}
+	if run := north.Paragraphs[0].Runs[0]; run.Properties == nil || run.Properties.Bold == nil {
+		t.Error("cell formatting should be preserved")
+	}
+	jan, below := table.Rows[1].Cells[0], table.Rows[2].Cells[0]
+	if jan.Properties.VMerge == nil || jan.Properties.VMerge.Val != "restart" ||
+		below.Properties.VMerge == nil || below.Properties.VMerge.Val != "continue" {
+		t.Error("horizontal merge should become a vertical merge")
+	}
+	if table.Grid.Cols[0].W != "2000" || north.Properties.TableCellW.W != "4000" {
+		t.Errorf("columns should share the table width, got %s and %s", table.Grid.Cols[0].W, north.Properties.TableCellW.W)
+	}
+}
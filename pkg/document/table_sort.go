@@ -0,0 +1,395 @@
+// Package document provides sorting, filtering and grouping of table rows
+package document
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortType selects how the values of a sort key are compared
+type SortType int
+
+const (
+	// SortText compares cell texts case-insensitively
+	SortText SortType = iota
+	// SortNumber compares cell texts as numbers, e.g. "1,234.50", "$99" or "(12)"
+	SortNumber
+	// SortDate compares cell texts as dates
+	SortDate
+)
+
+// defaultSortDateLayouts are the layouts tried for SortDate keys without DateLayouts
+var defaultSortDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006/01/02",
+	"01/02/2006",
+	"02.01.2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	time.RFC3339,
+}
+
+// SortKey describes one sort column
+type SortKey struct {
+	Col         int      // grid column index
+	Type        SortType // comparison type, default SortText
+	Descending  bool     // sort from largest to smallest
+	DateLayouts []string // layouts tried for SortDate, default common date formats
+}
+
+// sortValue is a parsed cell value of a sort key
+type sortValue struct {
+	text   string
+	number float64
+	valid  bool // number holds a parsed number or date
+}
+
+// SortRows sorts the rows from startRow to the end of the table by the given keys
+//
+// Header rows at the top of the table (see SetHeaderRows) are never moved, even when startRow
+// points into them. The sort is stable, so rows with equal keys keep their order. Values that
+// cannot be parsed as numbers or dates sort after the parsed ones, empty cells sort last.
+func (t *Table) SortRows(startRow int, keyCols []SortKey) error {
+	if len(keyCols) == 0 {
+		return NewValidationError("keyCols", "", "at least one sort key is required")
+	}
+
+	start, err := t.dataRowStart(startRow)
+	if err != nil {
+		return err
+	}
+
+	records := t.ToRecords()
+	cols := len(records[0])
+	for _, key := range keyCols {
+		if key.Col < 0 || key.Col >= cols {
+			return NewValidationError("SortKey.Col", fmt.Sprintf("%d", key.Col), fmt.Sprintf("column index out of range, table has %d columns", cols))
+		}
+	}
+
+	rows := make([]int, 0, len(t.Rows)-start)
+	values := make(map[int][]sortValue, len(t.Rows)-start)
+	for r := start; r < len(t.Rows); r++ {
+		rows = append(rows, r)
+		keyValues := make([]sortValue, len(keyCols))
+		for i, key := range keyCols {
+			keyValues[i] = parseSortValue(records[r][key.Col], key)
+		}
+		values[r] = keyValues
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := values[rows[i]], values[rows[j]]
+		for k, key := range keyCols {
+			if c := compareSortValues(a[k], b[k], key); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	sorted := make([]TableRow, len(rows))
+	for i, r := range rows {
+		sorted[i] = t.Rows[r]
+	}
+	copy(t.Rows[start:], sorted)
+
+	Info(fmt.Sprintf("table rows sorted: %d rows from row %d", len(rows), start))
+	return nil
+}
+
+// FilterRows keeps the data rows for which pred returns true and removes the others
+// pred receives the row index and the cell texts by grid column (see ToRecords).
+// Header rows at the top of the table are always kept. It returns the number of removed rows.
+func (t *Table) FilterRows(pred func(row int, values []string) bool) (int, error) {
+	if pred == nil {
+		return 0, NewValidationError("pred", "", "filter predicate is required")
+	}
+
+	start, err := t.dataRowStart(0)
+	if err != nil {
+		return 0, err
+	}
+
+	records := t.ToRecords()
+	kept := make([]TableRow, 0, len(t.Rows))
+	kept = append(kept, t.Rows[:start]...)
+	for r := start; r < len(t.Rows); r++ {
+		if pred(r, records[r]) {
+			kept = append(kept, t.Rows[r])
+		}
+	}
+
+	if len(kept) == 0 {
+		return 0, fmt.Errorf("table must keep at least one row")
+	}
+
+	removed := len(t.Rows) - len(kept)
+	t.Rows = kept
+
+	Info(fmt.Sprintf("table rows filtered: %d rows removed", removed))
+	return removed, nil
+}
+
+// GroupByConfig configures GroupBy
+type GroupByConfig struct {
+	StartRow       int                                 // first row to group, header rows are skipped
+	Sort           bool                                // sort by the group column first, otherwise only adjacent rows are grouped
+	SortType       SortType                            // comparison type used when Sort is set
+	HeaderText     func(value string, rows int) string // text of group header rows, default the group value
+	NoGroupHeader  bool                                // do not insert group header rows
+	HeaderFormat   *CellFormat                         // format of group header rows, default bold on light gray
+	SubtotalCols   []int                               // columns summed in subtotal rows, no subtotal rows when empty
+	SubtotalLabel  func(value string) string           // label of subtotal rows, default "<value> Total"
+	SubtotalFormat *CellFormat                         // format of subtotal rows, default bold
+	NumberFormat   string                              // Word numeric picture for subtotals, e.g. "#,##0.00"
+}
+
+// GroupBy groups the data rows by the value of a grid column
+//
+// A group header row spanning all columns is inserted before each group and, when SubtotalCols
+// is set, a subtotal row summing those columns is inserted after it. The label of a subtotal
+// row is written to the group column, or to the next column that is not summed when the group
+// column is summed itself. Cells that do not contain a number are ignored in subtotals.
+func (t *Table) GroupBy(col int, config *GroupByConfig) error {
+	if config == nil {
+		config = &GroupByConfig{}
+	}
+
+	start, err := t.dataRowStart(config.StartRow)
+	if err != nil {
+		return err
+	}
+
+	cols := len(t.ToRecords()[0])
+	if col < 0 || col >= cols {
+		return NewValidationError("col", fmt.Sprintf("%d", col), fmt.Sprintf("column index out of range, table has %d columns", cols))
+	}
+	for _, c := range config.SubtotalCols {
+		if c < 0 || c >= cols {
+			return NewValidationError("SubtotalCols", fmt.Sprintf("%d", c), fmt.Sprintf("column index out of range, table has %d columns", cols))
+		}
+	}
+	if config.NoGroupHeader && len(config.SubtotalCols) == 0 {
+		return NewValidationError("GroupByConfig", "", "group header rows or subtotal columns are required")
+	}
+
+	if config.Sort {
+		if err := t.SortRows(start, []SortKey{{Col: col, Type: config.SortType}}); err != nil {
+			return err
+		}
+	}
+
+	summed := make(map[int]bool, len(config.SubtotalCols))
+	for _, c := range config.SubtotalCols {
+		summed[c] = true
+	}
+	labelCol := -1
+	for i := 0; i < cols; i++ {
+		if c := (col + i) % cols; !summed[c] {
+			labelCol = c
+			break
+		}
+	}
+
+	headerFormat := config.HeaderFormat
+	if headerFormat == nil {
+		headerFormat = &CellFormat{TextFormat: &TextFormat{Bold: true}, BackgroundColor: "F2F2F2"}
+	}
+	subtotalFormat := config.SubtotalFormat
+	if subtotalFormat == nil {
+		subtotalFormat = &CellFormat{TextFormat: &TextFormat{Bold: true}}
+	}
+
+	records := t.ToRecords()
+	grouped := make([]TableRow, 0, len(t.Rows))
+	grouped = append(grouped, t.Rows[:start]...)
+
+	groups := 0
+	for r := start; r < len(t.Rows); {
+		value := records[r][col]
+		end := r
+		for end < len(t.Rows) && records[end][col] == value {
+			end++
+		}
+		groups++
+
+		if !config.NoGroupHeader {
+			text := value
+			if config.HeaderText != nil {
+				text = config.HeaderText(value, end-r)
+			}
+			header := t.newGroupRow(cols)
+			header.Cells = header.Cells[:1]
+			header.Cells[0].Properties.TableCellW.W = fmt.Sprintf("%d", t.gridWidth(0, cols))
+			if cols > 1 {
+				header.Cells[0].Properties.GridSpan = &GridSpan{Val: fmt.Sprintf("%d", cols)}
+			}
+			header.Cells[0].Paragraphs[0].Runs[0].Text.Content = text
+			applyRowFormat(&header, headerFormat)
+			grouped = append(grouped, header)
+		}
+
+		grouped = append(grouped, t.Rows[r:end]...)
+
+		if len(config.SubtotalCols) > 0 {
+			subtotal := t.newGroupRow(cols)
+			if labelCol >= 0 {
+				label := value + " Total"
+				if config.SubtotalLabel != nil {
+					label = config.SubtotalLabel(value)
+				}
+				subtotal.Cells[labelCol].Paragraphs[0].Runs[0].Text.Content = label
+			}
+			for _, c := range config.SubtotalCols {
+				sum := 0.0
+				for i := r; i < end; i++ {
+					if number, ok := parseCellNumber(records[i][c]); ok {
+						sum += number
+					}
+				}
+				subtotal.Cells[c].Paragraphs[0].Runs[0].Text.Content = formatFormulaNumber(sum, config.NumberFormat)
+			}
+			applyRowFormat(&subtotal, subtotalFormat)
+			grouped = append(grouped, subtotal)
+		}
+
+		r = end
+	}
+
+	t.Rows = grouped
+
+	Info(fmt.Sprintf("table rows grouped by column %d: %d groups", col, groups))
+	return nil
+}
+
+// dataRowStart returns the first row that may be reordered, skipping leading header rows
+// Rows from there on must not take part in vertical merges, which reordering would break.
+func (t *Table) dataRowStart(startRow int) (int, error) {
+	if startRow < 0 || startRow >= len(t.Rows) {
+		return 0, fmt.Errorf("invalid row index: %d, table has %d rows", startRow, len(t.Rows))
+	}
+
+	start := startRow
+	for start < len(t.Rows) && isRepeatedHeaderRow(&t.Rows[start]) {
+		start++
+	}
+
+	for r := start; r < len(t.Rows); r++ {
+		for _, cell := range t.Rows[r].Cells {
+			if cell.Properties != nil && cell.Properties.VMerge != nil {
+				return 0, fmt.Errorf("row %d contains vertically merged cells, unmerge them before reordering rows", r)
+			}
+		}
+	}
+
+	return start, nil
+}
+
+// isRepeatedHeaderRow reports whether a row is marked as a header row repeated on each page
+func isRepeatedHeaderRow(row *TableRow) bool {
+	return row.Properties != nil && row.Properties.TblHeader != nil && isOnOffTrue(row.Properties.TblHeader.Val)
+}
+
+// newGroupRow creates an empty row with one cell per grid column
+func (t *Table) newGroupRow(cols int) TableRow {
+	row := TableRow{Cells: make([]TableCell, cols)}
+	for c := range row.Cells {
+		row.Cells[c] = TableCell{
+			Properties: &TableCellProperties{
+				TableCellW: &TableCellW{W: fmt.Sprintf("%d", t.gridWidth(c, c+1)), Type: "dxa"},
+				VAlign:     &VAlign{Val: "center"},
+			},
+			Paragraphs: []Paragraph{{Runs: []Run{{Text: Text{Content: ""}}}}},
+		}
+	}
+	return row
+}
+
+// gridWidth returns the total width of the grid columns from start up to end
+func (t *Table) gridWidth(start, end int) int {
+	width := 0
+	if t.Grid == nil {
+		return width
+	}
+	for c := start; c < end && c < len(t.Grid.Cols); c++ {
+		var w int
+		fmt.Sscanf(t.Grid.Cols[c].W, "%d", &w)
+		width += w
+	}
+	return width
+}
+
+// applyRowFormat applies a cell format to every cell of a row that is not yet part of a table
+func applyRowFormat(row *TableRow, format *CellFormat) {
+	tmp := &Table{Rows: []TableRow{*row}}
+	for c := range row.Cells {
+		tmp.SetCellFormat(0, c, format)
+		if format.BackgroundColor != "" {
+			tmp.SetCellShading(0, c, &ShadingConfig{Pattern: ShadingPatternClear, BackgroundColor: format.BackgroundColor})
+		}
+	}
+	*row = tmp.Rows[0]
+}
+
+// parseSortValue parses the text of a sort key cell
+func parseSortValue(text string, key SortKey) sortValue {
+	text = strings.TrimSpace(text)
+	value := sortValue{text: strings.ToLower(text)}
+
+	switch key.Type {
+	case SortNumber:
+		value.number, value.valid = parseCellNumber(text)
+	case SortDate:
+		layouts := key.DateLayouts
+		if len(layouts) == 0 {
+			layouts = defaultSortDateLayouts
+		}
+		for _, layout := range layouts {
+			if date, err := time.Parse(layout, text); err == nil {
+				value.number = float64(date.Unix())
+				value.valid = true
+				break
+			}
+		}
+	}
+	return value
+}
+
+// compareSortValues compares two key values, empty values always sort last
+func compareSortValues(a, b sortValue, key SortKey) int {
+	if (a.text == "") != (b.text == "") {
+		if a.text == "" {
+			return 1
+		}
+		return -1
+	}
+
+	result := 0
+	switch {
+	case key.Type != SortText && a.valid && b.valid:
+		if a.number < b.number {
+			result = -1
+		} else if a.number > b.number {
+			result = 1
+		}
+	case key.Type != SortText && a.valid != b.valid:
+		// parsed values come before text that could not be parsed, regardless of direction
+		if a.valid {
+			return -1
+		}
+		return 1
+	default:
+		result = strings.Compare(a.text, b.text)
+	}
+
+	if key.Descending {
+		return -result
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newSalesTable creates a table with a repeated header row and unsorted sales rows
+func newSalesTable(t *testing.T, doc *Document) *Table {
+	table, err := doc.AddTable(&TableConfig{
+		Rows:  6,
+		Cols:  3,
+		Width: 6000,
+		Data: [][]string{
+			{"Region", "Date", "Amount"},
+			{"north", "2024-03-01", "1,200.00"},
+			{"South", "2024-01-15", "99.50"},
+			{"North", "2023-12-31", "10"},
+			{"East", "", "n/a"},
+			{"South", "2024-02-01", "300"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	if err := table.SetHeaderRows(0, 0); err != nil {
+		t.Fatalf("failed to set header rows: %v", err)
+	}
+	return table
+}
+
+// columnTexts returns the texts of a grid column from the given row on
+func columnTexts(table *Table, start, col int) string {
+	var texts []string
+	for _, record := range table.ToRecords()[start:] {
+		texts = append(texts, record[col])
+	}
+	return strings.Join(texts, "|")
+}
+
+// TestSortRows tests sorting rows with text, number and date keys
+func TestSortRows(t *testing.T) {
+	doc := New()
+	table := newSalesTable(t, doc)
+
+	if err := table.SortRows(0, []SortKey{{Col: 2, Type: SortNumber, Descending: true}}); err != nil {
+		t.Fatalf("failed to sort rows: %v", err)
+	}
+	if got := columnTexts(table, 0, 2); got != "Amount|1,200.00|300|99.50|10|n/a" {
+		t.Errorf("unexpected number order: %s", got)
+	}
+
+	if err := table.SortRows(1, []SortKey{{Col: 1, Type: SortDate}}); err != nil {
+		t.Fatalf("failed to sort rows: %v", err)
+	}
+	if got := columnTexts(table, 1, 1); got != "2023-12-31|2024-01-15|2024-02-01|2024-03-01|" {
+		t.Errorf("unexpected date order: %s", got)
+	}
+
+	keys := []SortKey{{Col: 0}, {Col: 2, Type: SortNumber}}
+	if err := table.SortRows(0, keys); err != nil {
+		t.Fatalf("failed to sort rows: %v", err)
+	}
+	if got := columnTexts(table, 0, 0); got != "Region|East|North|north|South|South" {
+		t.Errorf("unexpected text order: %s", got)
+	}
+	if got := columnTexts(table, 4, 2); got != "99.50|300" {
+		t.Errorf("second key should order equal regions: %s", got)
+	}
+	if isHeader, _ := table.IsRowHeader(0); !isHeader {
+		t.Error("header row should stay in place")
+	}
+
+	if err := table.SortRows(0, []SortKey{{Col: 5}}); err == nil {
+		t.Error("expected error for invalid column")
+	}
+	table.MergeCellsVertical(1, 2, 0)
+	if err := table.SortRows(0, keys); err == nil {
+		t.Error("expected error for vertically merged rows")
+	}
+}
+
+// TestFilterRows tests removing rows that do not match a predicate
+func TestFilterRows(t *testing.T) {
+	doc := New()
+	table := newSalesTable(t, doc)
+
+	removed, err := table.FilterRows(func(row int, values []string) bool {
+		return strings.EqualFold(values[0], "south")
+	})
+	if err != nil {
+		t.Fatalf("failed to filter rows: %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("expected 3 removed rows, got %d", removed)
+	}
+	if got := columnTexts(table, 0, 0); got != "Region|South|South" {
+		t.Errorf("unexpected rows after filtering: %s", got)
+	}
+
+	if _, err := table.FilterRows(nil); err == nil {
+		t.Error("expected error for nil predicate")
+	}
+}
+
+// TestGroupBy tests inserting group header and subtotal rows
+func TestGroupBy(t *testing.T) {
+	doc := New()
+	table := newSalesTable(t, doc)
+	table.SetCellText(1, 0, "North")
+
+	err := table.GroupBy(0, &GroupByConfig{
+		Sort:         true,
+		SubtotalCols: []int{2},
+		NumberFormat: "#,##0.00",
+		HeaderText: func(value string, rows int) string {
+			return fmt.Sprintf("%s (%d)", value, rows)
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to group rows: %v", err)
+	}
+
+	expected := []string{
+		"Region|Date|Amount",
+		"East (1)||",
+		"East||n/a",
+		"East Total||0.00",
+		"North (2)||",
+		"North|2024-03-01|1,200.00",
+		"North|2023-12-31|10",
+		"North Total||1,210.00",
+		"South (2)||",
+		"South|2024-01-15|99.50",
+		"South|2024-02-01|300",
+		"South Total||399.50",
+	}
+	records := table.ToRecords()
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(records))
+	}
+	for i, record := range records {
+		if got := strings.Join(record, "|"); got != expected[i] {
+			t.Errorf("row %d: expected %q, got %q", i, expected[i], got)
+		}
+	}
+
+	header := table.Rows[1].Cells
+	if len(header) != 1 || header[0].Properties.GridSpan == nil || header[0].Properties.GridSpan.Val != "3" {
+		t.Error("group header should span all columns")
+	}
+	if header[0].Properties.Shd == nil || header[0].Properties.Shd.Fill != "F2F2F2" {
+		t.Error("group header should be shaded by default")
+	}
+	if run := table.Rows[3].Cells[2].Paragraphs[0].Runs[0]; run.Properties == nil || run.Properties.Bold == nil {
+		t.Error("subtotal row should be bold by default")
+	}
+
+	if err := table.GroupBy(0, &GroupByConfig{NoGroupHeader: true}); err == nil {
+		t.Error("expected error when neither headers nor subtotals are requested")
+	}
+}
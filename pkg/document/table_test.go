@@ -1,6 +1,9 @@
 package document
 
 import (
+	"os"
+	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -1512,3 +1515,114 @@ func TestTableRowPropertiesExtensions(t *testing.T) {
 		t.Error("清除TblHeader失败")
 	}
 }
+
+// TestCellLayoutOptionsRoundTrip tests diagonal borders, margins and cell layout options through save and open
+func TestCellLayoutOptionsRoundTrip(t *testing.T) {
+	doc := New()
+	table, err := doc.AddTable(&TableConfig{Rows: 2, Cols: 2, Width: 4000, Data: [][]string{{"Item / Quarter", "Q1"}, {"Revenue", "100"}}})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	diagonal := &BorderConfig{Style: BorderStyleSingle, Width: 4, Color: "000000"}
+	if err := table.SetCellBorders(0, 0, &CellBorderConfig{Bottom: diagonal}); err != nil {
+		t.Fatalf("failed to set borders: %v", err)
+	}
+	err = table.SetCellFormat(0, 0, &CellFormat{
+		DiagonalDown: diagonal,
+		DiagonalUp:   diagonal,
+		Margins:      &CellMargins{Top: 40, Left: 120, Bottom: 40, Right: 120},
+		NoWrap:       true,
+		FitText:      true,
+		HideMark:     true,
+	})
+	if err != nil {
+		t.Fatalf("failed to set cell format: %v", err)
+	}
+	if table.Rows[0].Cells[0].Properties.TcBorders.Bottom == nil {
+		t.Error("diagonal borders should keep the other cell borders")
+	}
+
+	if err := doc.serializeDocument(); err != nil {
+		t.Fatalf("failed to serialize document: %v", err)
+	}
+	xmlStr := string(doc.parts["word/document.xml"])
+	order := []string{"<w:tcW", "<w:tcBorders>", "<w:tl2br", "<w:tr2bl", "<w:noWrap>", "<w:tcMar>", "<w:tcFitText>", "<w:vAlign", "<w:hideMark>"}
+	pos := 0
+	for _, element := range order {
+		i := strings.Index(xmlStr[pos:], element)
+		if i < 0 {
+			t.Fatalf("%s not found in schema order in cell properties", element)
+		}
+		pos += i
+	}
+
+	filename := "test_cell_layout_options.docx"
+	defer os.Remove(filename)
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	loaded, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+
+	format, err := loaded.Body.GetTables()[0].GetCellFormat(0, 0)
+	if err != nil {
+		t.Fatalf("failed to get cell format: %v", err)
+	}
+	if !format.NoWrap || !format.FitText || !format.HideMark {
+		t.Errorf("layout options should survive a round trip: %+v", format)
+	}
+	if format.Margins == nil || *format.Margins != (CellMargins{Top: 40, Left: 120, Bottom: 40, Right: 120}) {
+		t.Errorf("cell margins should survive a round trip: %+v", format.Margins)
+	}
+	for _, border := range []*BorderConfig{format.DiagonalDown, format.DiagonalUp} {
+		if border == nil || border.Style != BorderStyleSingle || border.Width != 4 || border.Color != "000000" {
+			t.Errorf("diagonal border should survive a round trip: %+v", border)
+		}
+	}
+
+	plain, _ := loaded.Body.GetTables()[0].GetCellFormat(1, 1)
+	if plain.NoWrap || plain.Margins != nil || plain.DiagonalDown != nil {
+		t.Errorf("unformatted cell should report no options: %+v", plain)
+	}
+}
+
+// TestCellPaddingOutput tests the w:tcMar written for CellFormat.Padding and SetCellPadding
+func TestCellPaddingOutput(t *testing.T) {
+	doc := New()
+	table, err := doc.AddTable(&TableConfig{Rows: 1, Cols: 3, Width: 6000})
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	if err := table.SetCellFormat(0, 0, &CellFormat{Padding: 5}); err != nil {
+		t.Fatalf("failed to set cell format: %v", err)
+	}
+	if err := table.SetCellPadding(0, 1, 10); err != nil {
+		t.Fatalf("failed to set cell padding: %v", err)
+	}
+	if err := table.SetCellFormat(0, 2, &CellFormat{Padding: 5, Margins: &CellMargins{Left: 60, Right: 60}}); err != nil {
+		t.Fatalf("failed to set cell format: %v", err)
+	}
+
+	if err := doc.serializeDocument(); err != nil {
+		t.Fatalf("failed to serialize document: %v", err)
+	}
+	xmlStr := regexp.MustCompile(`>\s+<`).ReplaceAllString(string(doc.parts["word/document.xml"]), "><")
+
+	expected := []string{
+		`<w:tcMar><w:top w:w="100" w:type="dxa"></w:top><w:left w:w="100" w:type="dxa"></w:left><w:bottom w:w="100" w:type="dxa"></w:bottom><w:right w:w="100" w:type="dxa"></w:right></w:tcMar>`,
+		`<w:tcMar><w:top w:w="200" w:type="dxa"></w:top><w:left w:w="200" w:type="dxa"></w:left><w:bottom w:w="200" w:type="dxa"></w:bottom><w:right w:w="200" w:type="dxa"></w:right></w:tcMar>`,
+		`<w:tcMar><w:top w:w="0" w:type="dxa"></w:top><w:left w:w="60" w:type="dxa"></w:left><w:bottom w:w="0" w:type="dxa"></w:bottom><w:right w:w="60" w:type="dxa"></w:right></w:tcMar>`,
+	}
+	for i, tcMar := range expected {
+		if !strings.Contains(xmlStr, tcMar) {
+			t.Errorf("cell %d: expected %s", i, tcMar)
+		}
+	}
+	if strings.Count(xmlStr, "<w:tcMar>") != 3 {
+		t.Errorf("expected 3 cell margin elements, got %d", strings.Count(xmlStr, "<w:tcMar>"))
+	}
+}
@@ -1126,6 +1126,13 @@ func (te *TemplateEngine) cloneRunProperties(source *RunProperties) *RunProperti
 
 	props := &RunProperties{}
 
+	// copy character style reference
+	if source.RunStyle != nil {
+		props.RunStyle = &RunStyle{
+			Val: source.RunStyle.Val,
+		}
+	}
+
 	// 复制粗体
 	if source.Bold != nil {
 		props.Bold = &Bold{}
@@ -1701,6 +1708,13 @@ func (te *TemplateEngine) cloneTableCellProperties(source *TableCellProperties)
 		}
 	}
 
+	// copy fit text
+	if source.TcFitText != nil {
+		props.TcFitText = &TcFitText{
+			Val: source.TcFitText.Val,
+		}
+	}
+
 	return props
 }
 
@@ -21,10 +21,19 @@ type TOCConfig struct {
 type TOCEntry struct {
 	Text       string
 	Level      int
+	Number     string // heading number such as "1.2.3" when heading numbering is enabled
 	PageNum    int
 	BookmarkID string
 }
 
+// displayText returns the entry text preceded by its heading number
+func (e TOCEntry) displayText() string {
+	if e.Number == "" {
+		return e.Text
+	}
+	return e.Number + " " + e.Text
+}
+
 // TOCField table of contents field
 type TOCField struct {
 	XMLName xml.Name `xml:"w:fldSimple"`
@@ -89,7 +98,7 @@ func (d *Document) GenerateTOC(config *TOCConfig) error {
 	// 为每个标题条目添加到目录中
 	for i, entry := range entries {
 		entryID := fmt.Sprintf("14746%d", 3000+i)
-		tocSDT.AddTOCEntry(entry.Text, entry.Level, entry.PageNum, entryID)
+		tocSDT.AddTOCEntry(entry.displayText(), entry.Level, entry.PageNum, entryID)
 	}
 
 	// 完成目录SDT构建
@@ -173,7 +182,7 @@ func (d *Document) UpdateTOC() error {
 	// 为每个标题条目添加到目录中
 	for i, entry := range entries {
 		entryID := fmt.Sprintf("14746%d", 3000+i)
-		tocSDT.AddTOCEntry(entry.Text, entry.Level, entry.PageNum, entryID)
+		tocSDT.AddTOCEntry(entry.displayText(), entry.Level, entry.PageNum, entryID)
 	}
 
 	// 完成目录SDT构建
@@ -227,6 +236,7 @@ func (d *Document) AddHeadingWithBookmark(text string, level int, bookmarkName s
 func (d *Document) collectHeadings(maxLevel int) []TOCEntry {
 	var entries []TOCEntry
 	pageNum := 1 // simplified; actual implementation should calculate real page number
+	numbers := d.listLabels()
 
 	for _, element := range d.Body.Elements {
 		if paragraph, ok := element.(*Paragraph); ok {
@@ -237,6 +247,7 @@ func (d *Document) collectHeadings(maxLevel int) []TOCEntry {
 					entry := TOCEntry{
 						Text:       text,
 						Level:      level,
+						Number:     numbers[paragraph],
 						PageNum:    pageNum,
 						BookmarkID: fmt.Sprintf("_Toc_%s", strings.ReplaceAll(text, " ", "_")),
 					}
@@ -405,7 +416,7 @@ func (d *Document) addTOCEntry(entry TOCEntry, config *TOCConfig) error {
 
 		titleRun := Run{
 			Properties: &RunProperties{},
-			Text:       Text{Content: entry.Text},
+			Text:       Text{Content: entry.displayText()},
 		}
 		hyperlink.Runs = append(hyperlink.Runs, titleRun)
 
@@ -427,7 +438,7 @@ func (d *Document) addTOCEntry(entry TOCEntry, config *TOCConfig) error {
 
 		hyperlinkRun := Run{
 			Properties: &RunProperties{},
-			Text:       Text{Content: entry.Text},
+			Text:       Text{Content: entry.displayText()},
 		}
 		entryPara.Runs = append(entryPara.Runs, hyperlinkRun)
 
@@ -442,7 +453,7 @@ func (d *Document) addTOCEntry(entry TOCEntry, config *TOCConfig) error {
 		// 不使用超链接的简单文本
 		titleRun := Run{
 			Properties: &RunProperties{},
-			Text:       Text{Content: entry.Text},
+			Text:       Text{Content: entry.displayText()},
 		}
 		entryPara.Runs = append(entryPara.Runs, titleRun)
 
@@ -818,7 +829,7 @@ func (d *Document) createTOCEntryWithFields(entry TOCEntry, config *TOCConfig) *
 
 	// 添加标题文本
 	para.Runs = append(para.Runs, Run{
-		Text: Text{Content: entry.Text},
+		Text: Text{Content: entry.displayText()},
 	})
 
 	// 添加制表符
@@ -889,6 +900,7 @@ func generateUniqueID(text string) int {
 func (d *Document) collectHeadingsAndAddBookmarks(maxLevel int) []TOCEntry {
 	var entries []TOCEntry
 	pageNum := 1 // simplified; actual implementation should calculate real page number
+	numbers := d.listLabels()
 
 	// 需要一个新的Elements切片来插入书签
 	newElements := make([]interface{}, 0, len(d.Body.Elements)*2)
@@ -906,6 +918,7 @@ func (d *Document) collectHeadingsAndAddBookmarks(maxLevel int) []TOCEntry {
 					entry := TOCEntry{
 						Text:       text,
 						Level:      level,
+						Number:     numbers[paragraph],
 						PageNum:    pageNum,
 						BookmarkID: anchor,
 					}
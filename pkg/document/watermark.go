@@ -0,0 +1,617 @@
+// Package document provides text and picture watermarks
+package document
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// textWatermarkID and pictureWatermarkID prefix the shape IDs Word uses to recognize watermarks
+	textWatermarkID    = "PowerPlusWaterMarkObject"
+	pictureWatermarkID = "WordPictureWatermark"
+	// watermarkZIndex places watermarks behind the text
+	watermarkZIndex = -251654144
+	// defaultWatermarkColor is Word's silver
+	defaultWatermarkColor = "C0C0C0"
+	// defaultWatermarkOpacity is Word's semitransparent text
+	defaultWatermarkOpacity = 0.5
+	// washoutGain and washoutBlackLevel are the image adjustments of Word's washout effect
+	washoutGain       = "19661f"
+	washoutBlackLevel = "22938f"
+)
+
+// textPathShapeType is the VML shape type of WordArt text, as written by Word
+const textPathShapeType = `<v:shapetype id="_x0000_t136" coordsize="21600,21600" o:spt="136" adj="10800" path="m@7,l@8,m@5,21600l@6,21600e">` +
+	`<v:formulas><v:f eqn="sum #0 0 10800"/><v:f eqn="prod #0 2 1"/><v:f eqn="sum 21600 0 @1"/><v:f eqn="sum 0 0 @2"/>` +
+	`<v:f eqn="sum 21600 0 @3"/><v:f eqn="if @0 @3 0"/><v:f eqn="if @0 21600 @1"/><v:f eqn="if @0 0 @2"/>` +
+	`<v:f eqn="if @0 @4 21600"/><v:f eqn="mid @5 @6"/><v:f eqn="mid @8 @5"/><v:f eqn="mid @7 @8"/>` +
+	`<v:f eqn="mid @6 @7"/><v:f eqn="sum @6 0 @5"/></v:formulas>` +
+	`<v:path textpathok="t" o:connecttype="custom" o:connectlocs="@9,0;@10,10800;@11,21600;@12,10800" o:connectangles="270,180,90,0"/>` +
+	`<v:textpath on="t" fitshape="t"/><v:handles><v:h position="#0,bottomRight" xrange="6629,14971"/></v:handles>` +
+	`<o:lock v:ext="edit" text="t" shapetype="t"/></v:shapetype>`
+
+// pictureFrameShapeType is the VML shape type of pictures, as written by Word
+const pictureFrameShapeType = `<v:shapetype id="_x0000_t75" coordsize="21600,21600" o:spt="75" o:preferrelative="t" path="m@4@5l@4@11@9@11@9@5xe" filled="f" stroked="f">` +
+	`<v:stroke joinstyle="miter"/><v:formulas><v:f eqn="if lineDrawn pixelLineWidth 0"/><v:f eqn="sum @0 1 0"/>` +
+	`<v:f eqn="sum 0 0 @1"/><v:f eqn="prod @2 1 2"/><v:f eqn="prod @3 21600 pixelWidth"/><v:f eqn="prod @3 21600 pixelHeight"/>` +
+	`<v:f eqn="sum @0 0 1"/><v:f eqn="prod @6 1 2"/><v:f eqn="prod @7 21600 pixelWidth"/><v:f eqn="sum @8 21600 0"/>` +
+	`<v:f eqn="prod @7 21600 pixelHeight"/><v:f eqn="sum @10 21600 0"/></v:formulas>` +
+	`<v:path o:extrusionok="f" gradientshapeok="t" o:connecttype="rect"/><o:lock v:ext="edit" aspectratio="t"/></v:shapetype>`
+
+// watermarkRun is the header run holding a watermark shape
+type watermarkRun struct {
+	XMLName xml.Name       `xml:"w:r"`
+	NoProof *struct{}      `xml:"w:rPr>w:noProof"`
+	Pict    *watermarkPict `xml:"w:pict"`
+}
+
+// watermarkPict holds the shape type and shape of a watermark
+// The namespaces are declared when the header does not declare them.
+type watermarkPict struct {
+	XMLName   xml.Name        `xml:"w:pict"`
+	XmlnsV    string          `xml:"xmlns:v,attr,omitempty"`
+	XmlnsO    string          `xml:"xmlns:o,attr,omitempty"`
+	XmlnsW10  string          `xml:"xmlns:w10,attr,omitempty"`
+	XmlnsR    string          `xml:"xmlns:r,attr,omitempty"`
+	ShapeType string          `xml:",innerxml"`
+	Shape     *watermarkShape `xml:"v:shape"`
+}
+
+// watermarkShape is the VML shape of a text or picture watermark
+type watermarkShape struct {
+	XMLName     xml.Name               `xml:"v:shape"`
+	ID          string                 `xml:"id,attr"`
+	SpID        string                 `xml:"o:spid,attr"`
+	Type        string                 `xml:"type,attr"`
+	Style       string                 `xml:"style,attr"`
+	AllowInCell string                 `xml:"o:allowincell,attr"`
+	FillColor   string                 `xml:"fillcolor,attr,omitempty"`
+	Stroked     string                 `xml:"stroked,attr,omitempty"`
+	Fill        *watermarkFill         `xml:"v:fill,omitempty"`
+	TextPath    *watermarkTextPath     `xml:"v:textpath,omitempty"`
+	ImageData   *watermarkImageData    `xml:"v:imagedata,omitempty"`
+	Wrap        *watermarkWrapPosition `xml:"w10:wrap"`
+}
+
+// watermarkFill is the transparency of a text watermark
+type watermarkFill struct {
+	XMLName xml.Name `xml:"v:fill"`
+	Opacity string   `xml:"opacity,attr"`
+}
+
+// watermarkTextPath is the text of a text watermark
+type watermarkTextPath struct {
+	XMLName xml.Name `xml:"v:textpath"`
+	Style   string   `xml:"style,attr"`
+	String  string   `xml:"string,attr"`
+}
+
+// watermarkImageData is the picture of a picture watermark
+type watermarkImageData struct {
+	XMLName    xml.Name `xml:"v:imagedata"`
+	ID         string   `xml:"r:id,attr"`
+	Title      string   `xml:"o:title,attr"`
+	Gain       string   `xml:"gain,attr,omitempty"`
+	BlackLevel string   `xml:"blacklevel,attr,omitempty"`
+}
+
+// watermarkWrapPosition anchors a watermark to the margins
+type watermarkWrapPosition struct {
+	XMLName xml.Name `xml:"w10:wrap"`
+	AnchorX string   `xml:"anchorx,attr"`
+	AnchorY string   `xml:"anchory,attr"`
+}
+
+// headerLayout is where the watermarks and the first paragraph of a header part are
+type headerLayout struct {
+	// watermarks are the byte ranges of the runs holding a watermark
+	watermarks [][2]int64
+	// relationIDs are the relationships of the removed picture watermarks
+	relationIDs []string
+	// paragraph is the byte range of the first top-level paragraph, paragraph[0] is -1 when there is none
+	paragraph [2]int64
+	// paragraphEnd is the offset of the end tag of the first paragraph, -1 when it is self-closing
+	paragraphEnd int64
+	// rootEnd is the offset of the end tag of the header
+	rootEnd int64
+	// namespaces are the prefixes declared by the header element
+	namespaces map[string]bool
+}
+
+// headerEdit replaces a byte range of a header part
+type headerEdit struct {
+	start, end int64
+	text       string
+}
+
+// SetTextWatermark places a text watermark behind the text of every page
+//
+// The watermark is added to all headers of the document, creating a default header when there is
+// none. font is Calibri and color silver (C0C0C0) when empty; opacity is between 0 and 1 and is
+// 0.5 when 0. A diagonal watermark is rotated by 45 degrees. Existing watermarks are replaced.
+func (d *Document) SetTextWatermark(text, font, color string, opacity float64, diagonal bool) error {
+	if strings.TrimSpace(text) == "" {
+		return NewValidationError("text", text, "watermark text cannot be empty")
+	}
+	if font == "" {
+		font = defaultMeasureFont
+	}
+	if color == "" {
+		color = defaultWatermarkColor
+	}
+	rgb, ok := parseHexColor(color)
+	if !ok {
+		return NewValidationError("color", color, "must be a hex color such as FF0000")
+	}
+	if opacity < 0 || opacity > 1 {
+		return NewValidationError("opacity", strconv.FormatFloat(opacity, 'f', -1, 64), "must be between 0 and 1")
+	}
+	if opacity == 0 {
+		opacity = defaultWatermarkOpacity
+	}
+
+	// the text is stretched to its shape, which is sized to fit the margins
+	areaWidth, areaHeight := d.watermarkArea()
+	textWidth := float64(measureRunText(text, &RunProperties{FontFamily: &FontFamily{ASCII: font}, FontSize: &FontSize{Val: "200"}})) / 20 / 100
+	fontSize := math.Min(areaWidth/textWidth, areaHeight)
+	rotation := ""
+	if diagonal {
+		fontSize = math.Min(areaWidth, areaHeight) * math.Sqrt2 / (textWidth + 1)
+		rotation = "rotation:315;"
+	}
+	width, height := textWidth*fontSize, fontSize
+
+	shape := &watermarkShape{
+		Type:      "#_x0000_t136",
+		Style:     watermarkStyle(width, height, rotation),
+		FillColor: "#" + formatHexColor(rgb),
+		Stroked:   "f",
+		Fill:      &watermarkFill{Opacity: strconv.FormatFloat(math.Round(opacity*100)/100, 'f', -1, 64)},
+		TextPath:  &watermarkTextPath{Style: fmt.Sprintf(`font-family:"%s";font-size:1pt`, font), String: text},
+	}
+
+	parts, err := d.prepareWatermarkHeaders()
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		if err := d.addHeaderWatermark(part, textWatermarkID, textPathShapeType, shape); err != nil {
+			return err
+		}
+	}
+	Infof("set text watermark %q in %d headers", text, len(parts))
+	return nil
+}
+
+// SetPictureWatermark places a picture watermark behind the text of every page
+//
+// The watermark is added to all headers of the document, creating a default header when there is
+// none. scale resizes the picture from its size at 96 DPI; when 0 the picture fits the margins.
+// washout fades the picture like Word's washout option. Existing watermarks are replaced.
+func (d *Document) SetPictureWatermark(data []byte, scale float64, washout bool) error {
+	format, err := detectImageFormat(data)
+	if err != nil {
+		return WrapError("SetPictureWatermark", err)
+	}
+	pixelWidth, pixelHeight, err := getImageDimensions(data, format)
+	if err != nil {
+		return WrapError("SetPictureWatermark", err)
+	}
+	if scale < 0 {
+		return NewValidationError("scale", strconv.FormatFloat(scale, 'f', -1, 64), "cannot be negative")
+	}
+
+	// pixels are points * 4/3 at 96 DPI
+	width, height := float64(pixelWidth)*0.75, float64(pixelHeight)*0.75
+	if scale == 0 {
+		areaWidth, areaHeight := d.watermarkArea()
+		scale = math.Min(areaWidth/width, areaHeight/height)
+	}
+	width, height = width*scale, height*scale
+
+	parts, err := d.prepareWatermarkHeaders()
+	if err != nil {
+		return err
+	}
+	target := d.storeMediaPart(data, format, "watermark")
+	for _, part := range parts {
+		relationID, err := d.addPartImageRelationship(part, target)
+		if err != nil {
+			return err
+		}
+		shape := &watermarkShape{
+			Type:      "#_x0000_t75",
+			Style:     watermarkStyle(width, height, ""),
+			ImageData: &watermarkImageData{ID: relationID},
+		}
+		if washout {
+			shape.ImageData.Gain, shape.ImageData.BlackLevel = washoutGain, washoutBlackLevel
+		}
+		if err := d.addHeaderWatermark(part, pictureWatermarkID, pictureFrameShapeType, shape); err != nil {
+			return err
+		}
+	}
+	Infof("set picture watermark %s in %d headers", target, len(parts))
+	return nil
+}
+
+// RemoveWatermark removes the text and picture watermarks from all headers
+func (d *Document) RemoveWatermark() error {
+	var parts []string
+	for name := range d.parts {
+		if headerFooterLocation(name) == ImageInHeader {
+			parts = append(parts, name)
+		}
+	}
+	sort.Strings(parts)
+	for _, part := range parts {
+		if err := d.rewriteHeader(part, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watermarkArea returns the width and height between the page margins in points
+func (d *Document) watermarkArea() (float64, float64) {
+	settings := d.GetPageSettings()
+	width, height := getPageDimensions(settings)
+	width -= settings.MarginLeft + settings.MarginRight
+	height -= settings.MarginTop + settings.MarginBottom
+	return width / mmPerPoint, height / mmPerPoint
+}
+
+// watermarkStyle returns the VML style of a watermark centered on the margins, sizes in points
+func watermarkStyle(width, height float64, rotation string) string {
+	return fmt.Sprintf("position:absolute;margin-left:0;margin-top:0;width:%s;height:%s;%sz-index:%d;"+
+		"mso-position-horizontal:center;mso-position-horizontal-relative:margin;"+
+		"mso-position-vertical:center;mso-position-vertical-relative:margin",
+		formatVMLPoints(width*mmPerPoint), formatVMLPoints(height*mmPerPoint), rotation, watermarkZIndex)
+}
+
+// prepareWatermarkHeaders removes existing watermarks and returns the header parts of all sections
+// A section without a default header of its own or of an earlier section gets an empty one, and
+// so do a first page with its own headers and even pages when even and odd pages have their own
+// headers.
+func (d *Document) prepareWatermarkHeaders() ([]string, error) {
+	if err := d.RemoveWatermark(); err != nil {
+		return nil, err
+	}
+	settings, err := d.parseSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []*SectionProperties
+	for _, element := range d.Body.Elements {
+		if p, ok := element.(*Paragraph); ok && p.Properties != nil && p.Properties.SectionProperties != nil {
+			sections = append(sections, p.Properties.SectionProperties)
+		}
+	}
+	sections = append(sections, d.getSectionPropertiesForHeaderFooter())
+
+	// a section without a header of a type repeats the header of the previous section
+	inherited := make(map[string]bool)
+	var parts []string
+	seen := make(map[string]bool)
+	for _, sectPr := range sections {
+		required := []HeaderFooterType{HeaderFooterTypeDefault}
+		if sectPr.TitlePage != nil {
+			required = append(required, HeaderFooterTypeFirst)
+		}
+		if settings.EvenAndOddHeaders != nil {
+			required = append(required, HeaderFooterTypeEven)
+		}
+		for _, headerType := range required {
+			exists := inherited[string(headerType)]
+			for _, ref := range sectPr.HeaderReferences {
+				exists = exists || ref.Type == string(headerType)
+			}
+			if !exists {
+				if err := d.addWatermarkHeader(sectPr, headerType); err != nil {
+					return nil, WrapError("add_watermark_header", err)
+				}
+			}
+		}
+
+		for _, ref := range sectPr.HeaderReferences {
+			inherited[ref.Type] = true
+			for _, rel := range d.documentRelationships.Relationships {
+				if rel.ID != ref.ID {
+					continue
+				}
+				part := resolvePartTarget("", rel.Target)
+				if _, ok := d.parts[part]; ok && !seen[part] {
+					seen[part] = true
+					parts = append(parts, part)
+				}
+			}
+		}
+	}
+	return parts, nil
+}
+
+// addWatermarkHeader adds an empty header of a type to a section, in a header part of its own
+func (d *Document) addWatermarkHeader(sectPr *SectionProperties, headerType HeaderFooterType) error {
+	header := createStandardHeader()
+	header.Paragraphs = append(header.Paragraphs, &Paragraph{})
+	headerXML, err := xml.MarshalIndent(header, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fileName := getFileNameForType("header", headerType)
+	for n := 2; ; n++ {
+		if _, exists := d.parts["word/"+fileName]; !exists {
+			break
+		}
+		fileName = fmt.Sprintf("header%d.xml", n)
+	}
+	d.parts["word/"+fileName] = append([]byte(xml.Header), headerXML...)
+	d.addContentType("word/"+fileName, "application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml")
+
+	ids := make(map[string]bool)
+	for _, rel := range d.documentRelationships.Relationships {
+		ids[rel.ID] = true
+	}
+	headerID := ""
+	for n := len(d.documentRelationships.Relationships) + 2; headerID == "" || ids[headerID]; n++ {
+		headerID = fmt.Sprintf("rId%d", n)
+	}
+	d.documentRelationships.Relationships = append(d.documentRelationships.Relationships, Relationship{
+		ID:     headerID,
+		Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header",
+		Target: fileName,
+	})
+
+	if sectPr.XmlnsR == "" {
+		sectPr.XmlnsR = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	}
+	sectPr.HeaderReferences = append(sectPr.HeaderReferences, &HeaderFooterReference{Type: string(headerType), ID: headerID})
+	return nil
+}
+
+// storeMediaPart stores a picture kept outside the main document, such as a watermark,
+// and returns its part name. A picture already in the document is reused.
+func (d *Document) storeMediaPart(data []byte, format ImageFormat, name string) string {
+	if relationID := d.findMediaRelationship(data); relationID != "" {
+		for _, rel := range d.documentRelationships.Relationships {
+			if rel.ID == relationID {
+				return resolvePartTarget("", rel.Target)
+			}
+		}
+	}
+	media := "word/" + d.uniqueMediaTarget("media/"+name+"."+string(format), format)
+	d.parts[media] = data
+	d.addImageContentType(format)
+	return media
+}
+
+// addPartImageRelationship returns the image relationship of a part kept as XML to a media part
+func (d *Document) addPartImageRelationship(part, media string) (string, error) {
+	rels, err := d.partRelationships(part)
+	if err != nil {
+		return "", err
+	}
+	ids := make(map[string]bool)
+	for _, rel := range rels.Relationships {
+		if rel.Type == imageRelationshipType && resolvePartTarget(part, rel.Target) == media {
+			return rel.ID, nil
+		}
+		ids[rel.ID] = true
+	}
+	relationID := ""
+	for n := len(rels.Relationships) + 1; relationID == "" || ids[relationID]; n++ {
+		relationID = fmt.Sprintf("rId%d", n)
+	}
+	rels.Relationships = append(rels.Relationships, Relationship{
+		ID:     relationID,
+		Type:   imageRelationshipType,
+		Target: relativePartTarget(part, media),
+	})
+	return relationID, d.storePartRelationships(part, rels)
+}
+
+// addHeaderWatermark adds a watermark shape to the first paragraph of a header part
+func (d *Document) addHeaderWatermark(part, idPrefix, shapeType string, shape *watermarkShape) error {
+	layout, err := scanHeaderLayout(d.parts[part])
+	if err != nil {
+		return WrapErrorWithContext("parse_header", err, part)
+	}
+
+	id := d.nextImageID
+	d.nextImageID++
+	shape.ID = fmt.Sprintf("%s%d", idPrefix, id)
+	shape.SpID = fmt.Sprintf("_x0000_s%d", 2049+id)
+	shape.AllowInCell = "f"
+	shape.Wrap = &watermarkWrapPosition{AnchorX: "margin", AnchorY: "margin"}
+
+	pict := &watermarkPict{ShapeType: shapeType, Shape: shape}
+	if !layout.namespaces["v"] {
+		pict.XmlnsV = "urn:schemas-microsoft-com:vml"
+	}
+	if !layout.namespaces["o"] {
+		pict.XmlnsO = "urn:schemas-microsoft-com:office:office"
+	}
+	if !layout.namespaces["w10"] {
+		pict.XmlnsW10 = "urn:schemas-microsoft-com:office:word"
+	}
+	if !layout.namespaces["r"] && shape.ImageData != nil {
+		pict.XmlnsR = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	}
+	run, err := xml.Marshal(&watermarkRun{NoProof: &struct{}{}, Pict: pict})
+	if err != nil {
+		return WrapErrorWithContext("marshal_watermark", err, part)
+	}
+	return d.rewriteHeader(part, string(run))
+}
+
+// rewriteHeader removes the watermarks of a header part and adds run to its first paragraph
+// The relationships and media of removed picture watermarks are removed when no longer used.
+func (d *Document) rewriteHeader(part, run string) error {
+	data := d.parts[part]
+	layout, err := scanHeaderLayout(data)
+	if err != nil {
+		return WrapErrorWithContext("parse_header", err, part)
+	}
+	if run == "" && len(layout.watermarks) == 0 {
+		return nil
+	}
+
+	var edits []headerEdit
+	for _, r := range layout.watermarks {
+		edits = append(edits, headerEdit{r[0], r[1], ""})
+	}
+	if run != "" {
+		switch {
+		case layout.paragraph[0] < 0:
+			edits = append(edits, headerEdit{layout.rootEnd, layout.rootEnd, "<w:p>" + run + "</w:p>"})
+		case layout.paragraphEnd < 0:
+			// expand <w:p/> to hold the run
+			start := strings.TrimSuffix(string(data[layout.paragraph[0]:layout.paragraph[1]]), "/>")
+			edits = append(edits, headerEdit{layout.paragraph[0], layout.paragraph[1], start + ">" + run + "</w:p>"})
+		default:
+			edits = append(edits, headerEdit{layout.paragraphEnd, layout.paragraphEnd, run})
+		}
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+
+	result := append([]byte(nil), data...)
+	for _, edit := range edits {
+		result = append(result[:edit.start], append([]byte(edit.text), result[edit.end:]...)...)
+	}
+	d.parts[part] = result
+
+	return d.removeUnusedRelationships(part, layout.relationIDs)
+}
+
+// removeUnusedRelationships removes image relationships of a header part no longer referenced by
+// its XML, and the media parts no other part refers to
+func (d *Document) removeUnusedRelationships(part string, relationIDs []string) error {
+	if len(relationIDs) == 0 {
+		return nil
+	}
+	rels, err := d.partRelationships(part)
+	if err != nil {
+		return err
+	}
+	var media []string
+	kept := rels.Relationships[:0]
+	for _, rel := range rels.Relationships {
+		unused := false
+		for _, id := range relationIDs {
+			unused = unused || (rel.ID == id && !bytes.Contains(d.parts[part], []byte(`"`+id+`"`)))
+		}
+		if unused {
+			media = append(media, resolvePartTarget(part, rel.Target))
+			continue
+		}
+		kept = append(kept, rel)
+	}
+	rels.Relationships = kept
+	if err := d.storePartRelationships(part, rels); err != nil {
+		return err
+	}
+
+	owners := d.mediaOwners()
+	for _, name := range media {
+		used := false
+		for owner, ownerRels := range owners {
+			for _, rel := range ownerRels.Relationships {
+				used = used || (rel.Type == imageRelationshipType && resolvePartTarget(owner, rel.Target) == name)
+			}
+		}
+		if !used {
+			Debugf("removing unused watermark media %s", path.Base(name))
+			delete(d.parts, name)
+		}
+	}
+	return nil
+}
+
+// scanHeaderLayout finds the watermarks and the first paragraph of a header part
+func scanHeaderLayout(data []byte) (*headerLayout, error) {
+	layout := &headerLayout{paragraph: [2]int64{-1, -1}, paragraphEnd: -1, rootEnd: -1, namespaces: make(map[string]bool)}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	type openRun struct {
+		start     int64
+		watermark bool
+		ids       []string
+	}
+	var runs []*openRun
+	depth := 0
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case depth == 1:
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "xmlns" {
+						layout.namespaces[attr.Name.Local] = true
+					}
+				}
+			case depth == 2 && t.Name.Local == "p" && layout.paragraph[0] < 0:
+				layout.paragraph[0] = offset
+			case t.Name.Local == "r":
+				runs = append(runs, &openRun{start: offset})
+			case t.Name.Local == "shape" && len(runs) > 0:
+				id := getAttributeValue(t.Attr, "id")
+				if strings.HasPrefix(id, textWatermarkID) || strings.HasPrefix(id, pictureWatermarkID) {
+					runs[len(runs)-1].watermark = true
+				}
+			case t.Name.Local == "imagedata" && len(runs) > 0:
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "r" && attr.Name.Local == "id" {
+						runs[len(runs)-1].ids = append(runs[len(runs)-1].ids, attr.Value)
+					}
+				}
+			}
+		case xml.EndElement:
+			end := decoder.InputOffset()
+			switch {
+			case depth == 1:
+				if end == offset {
+					return nil, fmt.Errorf("header element is empty")
+				}
+				layout.rootEnd = offset
+			case depth == 2 && t.Name.Local == "p" && layout.paragraph[0] >= 0 && layout.paragraph[1] < 0:
+				layout.paragraph[1] = end
+				// a self-closing element ends without reading more input
+				if end != offset {
+					layout.paragraphEnd = offset
+				}
+			case t.Name.Local == "r" && len(runs) > 0:
+				r := runs[len(runs)-1]
+				runs = runs[:len(runs)-1]
+				if r.watermark {
+					layout.watermarks = append(layout.watermarks, [2]int64{r.start, end})
+					layout.relationIDs = append(layout.relationIDs, r.ids...)
+				}
+			}
+			depth--
+		}
+	}
+	if layout.rootEnd < 0 {
+		return nil, fmt.Errorf("header element not found")
+	}
+	return layout, nil
+}
@@ -0,0 +1,224 @@
+package document
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+)
+
+// headerXML returns a header part, checking that it is well formed
+func headerXML(t *testing.T, doc *Document, part string) string {
+	data, ok := doc.parts[part]
+	if !ok {
+		t.Fatalf("header part %s not found", part)
+	}
+	if err := xml.Unmarshal(data, new(struct{})); err != nil {
+		t.Fatalf("%s is not well formed: %v", part, err)
+	}
+	return string(data)
+}
+
+// TestSetTextWatermark tests a diagonal text watermark in a new default header
+func TestSetTextWatermark(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Draft contract")
+	if err := doc.SetTextWatermark("DRAFT", "Arial", "#FF0000", 0.3, true); err != nil {
+		t.Fatalf("failed to set watermark: %v", err)
+	}
+
+	sectPr := doc.getSectionPropertiesForHeaderFooter()
+	if len(sectPr.HeaderReferences) != 1 || sectPr.HeaderReferences[0].Type != string(HeaderFooterTypeDefault) {
+		t.Fatalf("a default header should be created, got %+v", sectPr.HeaderReferences)
+	}
+	content := headerXML(t, doc, "word/header1.xml")
+	for _, expected := range []string{
+		`<v:shapetype id="_x0000_t136"`,
+		`<v:shape id="PowerPlusWaterMarkObject`,
+		`type="#_x0000_t136"`,
+		`rotation:315;`,
+		`mso-position-horizontal-relative:margin`,
+		`fillcolor="#FF0000"`,
+		`<v:fill opacity="0.3">`,
+		`string="DRAFT"`,
+		`font-family:&#34;Arial&#34;`,
+		`<w10:wrap anchorx="margin" anchory="margin">`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("header should contain %s", expected)
+		}
+	}
+
+	// setting another watermark replaces the first one
+	if err := doc.SetTextWatermark("CONFIDENTIAL", "", "", 0, false); err != nil {
+		t.Fatalf("failed to replace watermark: %v", err)
+	}
+	content = headerXML(t, doc, "word/header1.xml")
+	if strings.Contains(content, "DRAFT") || strings.Count(content, "<v:shape ") != 1 {
+		t.Error("the previous watermark should be replaced")
+	}
+	if !strings.Contains(content, `fillcolor="#C0C0C0"`) || !strings.Contains(content, `opacity="0.5"`) || strings.Contains(content, "rotation") {
+		t.Error("expected a silver horizontal watermark")
+	}
+	if len(sectPr.HeaderReferences) != 1 {
+		t.Error("no other header should be created")
+	}
+
+	filename := "test_text_watermark.docx"
+	defer os.Remove(filename)
+	if err := doc.Save(filename); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+	opened, err := Open(filename)
+	if err != nil {
+		t.Fatalf("failed to open document: %v", err)
+	}
+	if err := opened.RemoveWatermark(); err != nil {
+		t.Fatalf("failed to remove watermark: %v", err)
+	}
+	if content := headerXML(t, opened, "word/header1.xml"); strings.Contains(content, "CONFIDENTIAL") {
+		t.Error("watermark should be removed")
+	}
+
+	invalid := []struct {
+		text, color string
+		opacity     float64
+	}{{"", "", 0}, {"DRAFT", "silver", 0}, {"DRAFT", "", 1.5}}
+	for _, tt := range invalid {
+		if err := doc.SetTextWatermark(tt.text, "", tt.color, tt.opacity, true); err == nil {
+			t.Errorf("expected error for %+v", tt)
+		}
+	}
+}
+
+// TestWatermarkAllHeaders tests that a watermark is added to the existing headers of every type
+func TestWatermarkAllHeaders(t *testing.T) {
+	doc := New()
+	if err := doc.AddHeader(HeaderFooterTypeDefault, "Company"); err != nil {
+		t.Fatalf("failed to add header: %v", err)
+	}
+	if err := doc.AddHeader(HeaderFooterTypeEven, "Even pages"); err != nil {
+		t.Fatalf("failed to add header: %v", err)
+	}
+	doc.SetDifferentFirstPage(true)
+
+	if err := doc.SetTextWatermark("DRAFT", "", "", 0, true); err != nil {
+		t.Fatalf("failed to set watermark: %v", err)
+	}
+	for _, part := range []string{"word/header1.xml", "word/headereven.xml", "word/headerfirst.xml"} {
+		content := headerXML(t, doc, part)
+		if strings.Count(content, "PowerPlusWaterMarkObject") != 1 {
+			t.Errorf("%s should contain one watermark", part)
+		}
+	}
+	// the watermark joins the existing paragraph
+	content := headerXML(t, doc, "word/header1.xml")
+	if strings.Count(content, "</w:p>") != 1 || !strings.Contains(content, "Company") {
+		t.Error("the watermark should be added to the first paragraph")
+	}
+}
+
+// TestWatermarkAllSections tests the headers of every section and of even pages
+func TestWatermarkAllSections(t *testing.T) {
+	doc := New()
+	if err := doc.AddHeader(HeaderFooterTypeDefault, "Company"); err != nil {
+		t.Fatalf("failed to add header: %v", err)
+	}
+	cover := doc.AddParagraph("Cover")
+	cover.Properties = &ParagraphProperties{SectionProperties: &SectionProperties{TitlePage: &TitlePage{}}}
+	doc.AddParagraph("Contract")
+	settings, _ := doc.parseSettings()
+	settings.EvenAndOddHeaders = &EvenAndOddHeaders{}
+	if err := doc.saveSettings(settings); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+
+	if err := doc.SetTextWatermark("DRAFT", "", "", 0, true); err != nil {
+		t.Fatalf("failed to set watermark: %v", err)
+	}
+	first := cover.Properties.SectionProperties
+	types := make(map[string]bool)
+	for _, ref := range first.HeaderReferences {
+		types[ref.Type] = true
+	}
+	for _, headerType := range []HeaderFooterType{HeaderFooterTypeDefault, HeaderFooterTypeFirst, HeaderFooterTypeEven} {
+		if !types[string(headerType)] {
+			t.Errorf("the first section should get a %s header", headerType)
+		}
+	}
+	// the last section repeats the first page and even headers of the first section
+	if refs := doc.getSectionPropertiesForHeaderFooter().HeaderReferences; len(refs) != 1 {
+		t.Errorf("the last section should keep its own header only, got %d headers", len(refs))
+	}
+
+	parts, err := doc.prepareWatermarkHeaders()
+	if err != nil {
+		t.Fatalf("failed to prepare headers: %v", err)
+	}
+	if len(parts) != 4 {
+		t.Errorf("expected 4 header parts, got %v", parts)
+	}
+	if err := doc.SetTextWatermark("DRAFT", "", "", 0, true); err != nil {
+		t.Fatalf("failed to set watermark: %v", err)
+	}
+	for _, part := range parts {
+		if content := headerXML(t, doc, part); strings.Count(content, "PowerPlusWaterMarkObject") != 1 {
+			t.Errorf("%s should contain one watermark", part)
+		}
+	}
+	if content := headerXML(t, doc, "word/header1.xml"); !strings.Contains(content, "Company") {
+		t.Error("the existing header should be kept")
+	}
+}
+
+// TestSetPictureWatermark tests a washed out picture watermark and its relationships
+func TestSetPictureWatermark(t *testing.T) {
+	doc := New()
+	if err := doc.AddHeader(HeaderFooterTypeDefault, ""); err != nil {
+		t.Fatalf("failed to add header: %v", err)
+	}
+	doc.SetDifferentFirstPage(true)
+	logo := createTestImage(200, 100)
+	if err := doc.SetPictureWatermark(logo, 2, true); err != nil {
+		t.Fatalf("failed to set watermark: %v", err)
+	}
+
+	content := headerXML(t, doc, "word/header1.xml")
+	for _, expected := range []string{
+		`<v:shape id="WordPictureWatermark`,
+		`type="#_x0000_t75"`,
+		`width:300pt;height:150pt`,
+		`gain="19661f" blacklevel="22938f"`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("header should contain %s", expected)
+		}
+	}
+	if data, ok := doc.parts["word/media/watermark.png"]; !ok || string(data) != string(logo) {
+		t.Fatal("watermark picture should be stored once")
+	}
+	for _, part := range []string{"word/header1.xml", "word/headerfirst.xml"} {
+		rels := string(doc.parts[relationshipsPartName(part)])
+		if !strings.Contains(rels, `Target="media/watermark.png"`) {
+			t.Errorf("%s should refer to the watermark picture, got %s", part, rels)
+		}
+	}
+
+	// a text watermark replaces the picture and its relationships
+	if err := doc.SetTextWatermark("DRAFT", "", "", 0, true); err != nil {
+		t.Fatalf("failed to set watermark: %v", err)
+	}
+	if _, ok := doc.parts["word/media/watermark.png"]; ok {
+		t.Error("unused watermark picture should be removed")
+	}
+	if strings.Contains(string(doc.parts["word/_rels/header1.xml.rels"]), "watermark.png") {
+		t.Error("unused watermark relationship should be removed")
+	}
+
+	if err := doc.SetPictureWatermark([]byte("not an image"), 0, false); err == nil {
+		t.Error("expected error for invalid picture")
+	}
+	if err := doc.SetPictureWatermark(logo, -1, false); err == nil {
+		t.Error("expected error for negative scale")
+	}
+}
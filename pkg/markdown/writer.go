@@ -62,6 +62,10 @@ type MarkdownWriter struct {
 	output    strings.Builder
 	imageNum  int
 	footnotes []string
+	// notes holds the text of footnotes and endnotes by note type and ID, loaded on first use
+	notes map[string]string
+	// lists holds the list information of the numbered paragraphs, loaded on first use
+	lists map[*document.Paragraph]*document.ListInfo
 }
 
 // Write generates Markdown content
@@ -192,8 +196,16 @@ func (w *MarkdownWriter) writeListItem(para *document.Paragraph) error {
 	}
 
 	marker := w.opts.BulletListMarker
-	if w.isNumberedList(para) {
-		marker = "1."
+	if w.lists == nil {
+		w.lists = w.doc.ListInfos()
+	}
+	if info := w.lists[para]; info != nil && w.isNumberedList(info) && info.Label != "" {
+		if orderedListMarker.MatchString(info.Label) {
+			marker = info.Label
+		} else {
+			// labels such as "2.3.a" are not Markdown list markers, keep them in the text
+			marker += " " + info.Label
+		}
 	}
 
 	w.output.WriteString(marker + " " + text + "\n")
@@ -304,6 +316,10 @@ func (w *MarkdownWriter) extractParagraphText(para *document.Paragraph) string {
 	var result strings.Builder
 
 	for _, run := range para.Runs {
+		if w.opts.PreserveFootnotes && (run.FootnoteReference != nil || run.EndnoteReference != nil) {
+			result.WriteString(w.noteReference(&run))
+			continue
+		}
 		text := w.formatRunText(&run)
 		result.WriteString(text)
 	}
@@ -311,6 +327,25 @@ func (w *MarkdownWriter) extractParagraphText(para *document.Paragraph) string {
 	return result.String()
 }
 
+// noteReference returns the Markdown footnote reference for a note reference run
+func (w *MarkdownWriter) noteReference(run *document.Run) string {
+	if w.notes == nil {
+		w.notes = make(map[string]string)
+		for _, note := range append(w.doc.Footnotes(), w.doc.Endnotes()...) {
+			w.notes[string(note.Type)+note.ID] = strings.ReplaceAll(note.Text(), "\n", "\n    ")
+		}
+	}
+
+	key := string(document.FootnoteTypeFootnote)
+	if run.FootnoteReference != nil {
+		key += run.FootnoteReference.ID
+	} else {
+		key = string(document.FootnoteTypeEndnote) + run.EndnoteReference.ID
+	}
+	w.footnotes = append(w.footnotes, w.notes[key])
+	return fmt.Sprintf("[^%d]", len(w.footnotes))
+}
+
 // formatRunText 格式化文本运行
 func (w *MarkdownWriter) formatRunText(run *document.Run) string {
 	if run == nil {
@@ -399,10 +434,16 @@ func (w *MarkdownWriter) isListParagraph(para *document.Paragraph) bool {
 	return para.Properties.NumberingProperties != nil
 }
 
+// orderedListMarker matches list labels usable as Markdown ordered list markers
+var orderedListMarker = regexp.MustCompile(`^[0-9]{1,9}[.)]$`)
+
 // isNumberedList 判断是否为编号列表
-func (w *MarkdownWriter) isNumberedList(para *document.Paragraph) bool {
-	// 简单实现，实际应该检查编号格式
-	return false
+func (w *MarkdownWriter) isNumberedList(info *document.ListInfo) bool {
+	if info.Level >= len(info.Definition.Levels) {
+		return false
+	}
+	format := info.Definition.Levels[info.Level].Format
+	return format != document.ListTypeBullet && format != document.ListTypeNone
 }
 
 // isCodeStyle 判断是否为代码样式
@@ -138,6 +138,25 @@ func (api *QuickStyleAPI) CreateQuickStyle(config QuickStyleConfig) (*Style, err
 		style.RunPr = createRunProperties(config.RunConfig)
 	}
 
+	if config.LinkedStyleID != "" {
+		if config.Type != StyleTypeParagraph {
+			api.styleManager.RemoveStyle(config.ID)
+			return nil, fmt.Errorf("linked style can only be created for paragraph styles")
+		}
+		if api.styleManager.StyleExists(config.LinkedStyleID) {
+			api.styleManager.RemoveStyle(config.ID)
+			return nil, fmt.Errorf("style ID %s already exists", config.LinkedStyleID)
+		}
+
+		charStyle := api.styleManager.CreateCustomStyle(config.LinkedStyleID, config.Name+" Char", StyleTypeCharacter, "")
+		if config.RunConfig != nil {
+			charStyle.RunPr = createRunProperties(config.RunConfig)
+		}
+		if err := api.styleManager.LinkStyles(config.ID, config.LinkedStyleID); err != nil {
+			return nil, err
+		}
+	}
+
 	return style, nil
 }
 
@@ -149,6 +168,7 @@ type QuickStyleConfig struct {
 	BasedOn         string                `json:"basedOn,omitempty"`
 	ParagraphConfig *QuickParagraphConfig `json:"paragraphConfig,omitempty"`
 	RunConfig       *QuickRunConfig       `json:"runConfig,omitempty"`
+	LinkedStyleID   string                `json:"linkedStyleId,omitempty"` // creates a linked character style with the same run formatting
 }
 
 // QuickParagraphConfig defines quick paragraph configuration
@@ -199,6 +199,53 @@ func TestCreateQuickStyle(t *testing.T) {
 	}
 }
 
+func TestCreateQuickStyleWithLinkedStyle(t *testing.T) {
+	sm := NewStyleManager()
+	api := NewQuickStyleAPI(sm)
+
+	config := QuickStyleConfig{
+		ID:            "Note",
+		Name:          "Note",
+		Type:          StyleTypeParagraph,
+		BasedOn:       "Normal",
+		LinkedStyleID: "NoteChar",
+		RunConfig: &QuickRunConfig{
+			Italic:    true,
+			FontColor: "595959",
+		},
+	}
+
+	style, err := api.CreateQuickStyle(config)
+	if err != nil {
+		t.Fatalf("failed to create linked style: %v", err)
+	}
+	if style.Link == nil || style.Link.Val != "NoteChar" {
+		t.Errorf("expected link to NoteChar, got %v", style.Link)
+	}
+
+	charStyle := sm.GetStyle("NoteChar")
+	if charStyle == nil {
+		t.Fatal("linked character style should be created")
+	}
+	if charStyle.RunPr == nil || charStyle.RunPr.Italic == nil {
+		t.Error("linked character style should share run formatting")
+	}
+
+	// linked styles are only valid for paragraph styles
+	_, err = api.CreateQuickStyle(QuickStyleConfig{
+		ID:            "CharOnly",
+		Name:          "Char Only",
+		Type:          StyleTypeCharacter,
+		LinkedStyleID: "CharOnlyLinked",
+	})
+	if err == nil {
+		t.Error("expected error when linking a character style")
+	}
+	if sm.StyleExists("CharOnly") {
+		t.Error("failed style creation should not leave the style behind")
+	}
+}
+
 func TestCreateParagraphProperties(t *testing.T) {
 	config := &QuickParagraphConfig{
 		Alignment:       "center",
@@ -2,6 +2,7 @@
 package style
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 )
@@ -18,246 +19,303 @@ const (
 
 // Style defines a Word document style
 type Style struct {
-	XMLName     xml.Name             `xml:"w:style"`
-	Type        string               `xml:"w:type,attr"`
-	StyleID     string               `xml:"w:styleId,attr"`
-	Name        *StyleName           `xml:"w:name,omitempty"`
-	BasedOn     *BasedOn             `xml:"w:basedOn,omitempty"`
-	Next        *Next                `xml:"w:next,omitempty"`
-	Default     bool                 `xml:"w:default,attr,omitempty"`
-	CustomStyle bool                 `xml:"w:customStyle,attr,omitempty"`
-	ParagraphPr *ParagraphProperties `xml:"w:pPr,omitempty"`
-	RunPr       *RunProperties       `xml:"w:rPr,omitempty"`
-	TablePr     *TableProperties     `xml:"w:tblPr,omitempty"`
-	TableRowPr  *TableRowProperties  `xml:"w:trPr,omitempty"`
-	TableCellPr *TableCellProperties `xml:"w:tcPr,omitempty"`
+	XMLName     xml.Name             `xml:"w:style" json:"-"`
+	Type        string               `xml:"w:type,attr" json:"type,omitempty"`
+	StyleID     string               `xml:"w:styleId,attr" json:"styleId,omitempty"`
+	Name        *StyleName           `xml:"w:name,omitempty" json:"name,omitempty"`
+	BasedOn     *BasedOn             `xml:"w:basedOn,omitempty" json:"basedOn,omitempty"`
+	Next        *Next                `xml:"w:next,omitempty" json:"next,omitempty"`
+	Link        *Link                `xml:"w:link,omitempty" json:"link,omitempty"`
+	Default     bool                 `xml:"w:default,attr,omitempty" json:"default,omitempty"`
+	CustomStyle bool                 `xml:"w:customStyle,attr,omitempty" json:"customStyle,omitempty"`
+	ParagraphPr *ParagraphProperties `xml:"w:pPr,omitempty" json:"paragraphPr,omitempty"`
+	RunPr       *RunProperties       `xml:"w:rPr,omitempty" json:"runPr,omitempty"`
+	TablePr     *TableProperties     `xml:"w:tblPr,omitempty" json:"tablePr,omitempty"`
+	TableRowPr  *TableRowProperties  `xml:"w:trPr,omitempty" json:"tableRowPr,omitempty"`
+	TableCellPr *TableCellProperties `xml:"w:tcPr,omitempty" json:"tableCellPr,omitempty"`
+	// TableStylePr holds conditional formatting for table styles (first row, bands, corners, etc.)
+	TableStylePr []TableStyleProperties `xml:"w:tblStylePr,omitempty" json:"tableStylePr,omitempty"`
 }
 
 // StyleName specifies the name of a style
 type StyleName struct {
-	XMLName xml.Name `xml:"w:name"`
-	Val     string   `xml:"w:val,attr"`
+	XMLName xml.Name `xml:"w:name" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 // BasedOn specifies the parent style
 type BasedOn struct {
-	XMLName xml.Name `xml:"w:basedOn"`
-	Val     string   `xml:"w:val,attr"`
+	XMLName xml.Name `xml:"w:basedOn" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 // Next specifies the style that follows
 type Next struct {
-	XMLName xml.Name `xml:"w:next"`
-	Val     string   `xml:"w:val,attr"`
+	XMLName xml.Name `xml:"w:next" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
+}
+
+// Link specifies the linked counterpart of a paragraph or character style
+type Link struct {
+	XMLName xml.Name `xml:"w:link" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 // ParagraphProperties defines paragraph properties
 // Note: field order must comply with OpenXML specification
 type ParagraphProperties struct {
-	XMLName         xml.Name         `xml:"w:pPr"`
-	KeepNext        *KeepNext        `xml:"w:keepNext,omitempty"`
-	KeepLines       *KeepLines       `xml:"w:keepLines,omitempty"`
-	PageBreak       *PageBreak       `xml:"w:pageBreakBefore,omitempty"`
-	ParagraphBorder *ParagraphBorder `xml:"w:pBdr,omitempty"`
-	Shading         *Shading         `xml:"w:shd,omitempty"`
-	SnapToGrid      *SnapToGrid      `xml:"w:snapToGrid,omitempty"`
-	Spacing         *Spacing         `xml:"w:spacing,omitempty"`
-	Indentation     *Indentation     `xml:"w:ind,omitempty"`
-	Justification   *Justification   `xml:"w:jc,omitempty"`
-	OutlineLevel    *OutlineLevel    `xml:"w:outlineLvl,omitempty"`
+	XMLName             xml.Name             `xml:"w:pPr" json:"-"`
+	KeepNext            *KeepNext            `xml:"w:keepNext,omitempty" json:"keepNext,omitempty"`
+	KeepLines           *KeepLines           `xml:"w:keepLines,omitempty" json:"keepLines,omitempty"`
+	PageBreak           *PageBreak           `xml:"w:pageBreakBefore,omitempty" json:"pageBreak,omitempty"`
+	NumberingProperties *NumberingProperties `xml:"w:numPr,omitempty" json:"numberingProperties,omitempty"`
+	ParagraphBorder     *ParagraphBorder     `xml:"w:pBdr,omitempty" json:"paragraphBorder,omitempty"`
+	Shading             *Shading             `xml:"w:shd,omitempty" json:"shading,omitempty"`
+	SnapToGrid          *SnapToGrid          `xml:"w:snapToGrid,omitempty" json:"snapToGrid,omitempty"`
+	Spacing             *Spacing             `xml:"w:spacing,omitempty" json:"spacing,omitempty"`
+	Indentation         *Indentation         `xml:"w:ind,omitempty" json:"indentation,omitempty"`
+	Justification       *Justification       `xml:"w:jc,omitempty" json:"justification,omitempty"`
+	OutlineLevel        *OutlineLevel        `xml:"w:outlineLvl,omitempty" json:"outlineLevel,omitempty"`
 }
 
 // ParagraphBorder defines paragraph borders
 type ParagraphBorder struct {
-	XMLName xml.Name             `xml:"w:pBdr"`
-	Top     *ParagraphBorderLine `xml:"w:top,omitempty"`
-	Left    *ParagraphBorderLine `xml:"w:left,omitempty"`
-	Bottom  *ParagraphBorderLine `xml:"w:bottom,omitempty"`
-	Right   *ParagraphBorderLine `xml:"w:right,omitempty"`
+	XMLName xml.Name             `xml:"w:pBdr" json:"-"`
+	Top     *ParagraphBorderLine `xml:"w:top,omitempty" json:"top,omitempty"`
+	Left    *ParagraphBorderLine `xml:"w:left,omitempty" json:"left,omitempty"`
+	Bottom  *ParagraphBorderLine `xml:"w:bottom,omitempty" json:"bottom,omitempty"`
+	Right   *ParagraphBorderLine `xml:"w:right,omitempty" json:"right,omitempty"`
 }
 
 // ParagraphBorderLine defines paragraph border line properties
 type ParagraphBorderLine struct {
-	XMLName xml.Name `xml:""`
-	Val     string   `xml:"w:val,attr"`
-	Color   string   `xml:"w:color,attr"`
-	Sz      string   `xml:"w:sz,attr"`
-	Space   string   `xml:"w:space,attr"`
+	XMLName xml.Name `xml:"" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
+	Color   string   `xml:"w:color,attr" json:"color,omitempty"`
+	Sz      string   `xml:"w:sz,attr" json:"sz,omitempty"`
+	Space   string   `xml:"w:space,attr" json:"space,omitempty"`
 }
 
 // Shading defines shading and fill color
 type Shading struct {
-	XMLName xml.Name `xml:"w:shd"`
-	Fill    string   `xml:"w:fill,attr"`
-	Val     string   `xml:"w:val,attr,omitempty"`
+	XMLName xml.Name `xml:"w:shd" json:"-"`
+	Fill    string   `xml:"w:fill,attr" json:"fill,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty" json:"val,omitempty"`
 }
 
 // RunProperties defines character properties
 // Note: field order must comply with OpenXML specification, w:rFonts must come before w:color
 type RunProperties struct {
-	XMLName    xml.Name    `xml:"w:rPr"`
-	FontFamily *FontFamily `xml:"w:rFonts,omitempty"`
-	Bold       *Bold       `xml:"w:b,omitempty"`
-	Italic     *Italic     `xml:"w:i,omitempty"`
-	Underline  *Underline  `xml:"w:u,omitempty"`
-	Strike     *Strike     `xml:"w:strike,omitempty"`
-	Color      *Color      `xml:"w:color,omitempty"`
-	FontSize   *FontSize   `xml:"w:sz,omitempty"`
-	Highlight  *Highlight  `xml:"w:highlight,omitempty"`
+	XMLName    xml.Name    `xml:"w:rPr" json:"-"`
+	FontFamily *FontFamily `xml:"w:rFonts,omitempty" json:"fontFamily,omitempty"`
+	Bold       *Bold       `xml:"w:b,omitempty" json:"bold,omitempty"`
+	Italic     *Italic     `xml:"w:i,omitempty" json:"italic,omitempty"`
+	Underline  *Underline  `xml:"w:u,omitempty" json:"underline,omitempty"`
+	Strike     *Strike     `xml:"w:strike,omitempty" json:"strike,omitempty"`
+	Color      *Color      `xml:"w:color,omitempty" json:"color,omitempty"`
+	FontSize   *FontSize   `xml:"w:sz,omitempty" json:"fontSize,omitempty"`
+	Highlight  *Highlight  `xml:"w:highlight,omitempty" json:"highlight,omitempty"`
 }
 
 // TableProperties defines table properties
+// Note: field order must comply with OpenXML specification
 type TableProperties struct {
-	XMLName    xml.Name       `xml:"w:tblPr"`
-	TblInd     *TblIndent     `xml:"w:tblInd,omitempty"`
-	TblBorders *TblBorders    `xml:"w:tblBorders,omitempty"`
-	TblCellMar *TblCellMargin `xml:"w:tblCellMar,omitempty"`
+	XMLName     xml.Name       `xml:"w:tblPr" json:"-"`
+	RowBandSize *TblBandSize   `xml:"w:tblStyleRowBandSize,omitempty" json:"rowBandSize,omitempty"`
+	ColBandSize *TblBandSize   `xml:"w:tblStyleColBandSize,omitempty" json:"colBandSize,omitempty"`
+	TblInd      *TblIndent     `xml:"w:tblInd,omitempty" json:"tblInd,omitempty"`
+	TblBorders  *TblBorders    `xml:"w:tblBorders,omitempty" json:"tblBorders,omitempty"`
+	TblCellMar  *TblCellMargin `xml:"w:tblCellMar,omitempty" json:"tblCellMar,omitempty"`
+}
+
+// TblBandSize specifies how many rows or columns make up one band in a banded table style
+type TblBandSize struct {
+	Val string `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 // TblIndent specifies table indentation
 type TblIndent struct {
-	XMLName xml.Name `xml:"w:tblInd"`
-	W       string   `xml:"w:w,attr"`
-	Type    string   `xml:"w:type,attr"`
+	XMLName xml.Name `xml:"w:tblInd" json:"-"`
+	W       string   `xml:"w:w,attr" json:"w,omitempty"`
+	Type    string   `xml:"w:type,attr" json:"type,omitempty"`
 }
 
 // TblBorders defines table borders
 type TblBorders struct {
-	XMLName xml.Name   `xml:"w:tblBorders"`
-	Top     *TblBorder `xml:"w:top,omitempty"`
-	Left    *TblBorder `xml:"w:left,omitempty"`
-	Bottom  *TblBorder `xml:"w:bottom,omitempty"`
-	Right   *TblBorder `xml:"w:right,omitempty"`
-	InsideH *TblBorder `xml:"w:insideH,omitempty"`
-	InsideV *TblBorder `xml:"w:insideV,omitempty"`
+	XMLName xml.Name   `xml:"w:tblBorders" json:"-"`
+	Top     *TblBorder `xml:"w:top,omitempty" json:"top,omitempty"`
+	Left    *TblBorder `xml:"w:left,omitempty" json:"left,omitempty"`
+	Bottom  *TblBorder `xml:"w:bottom,omitempty" json:"bottom,omitempty"`
+	Right   *TblBorder `xml:"w:right,omitempty" json:"right,omitempty"`
+	InsideH *TblBorder `xml:"w:insideH,omitempty" json:"insideH,omitempty"`
+	InsideV *TblBorder `xml:"w:insideV,omitempty" json:"insideV,omitempty"`
 }
 
 // TblBorder defines a table border
 type TblBorder struct {
-	Val   string `xml:"w:val,attr"`
-	Sz    string `xml:"w:sz,attr"`
-	Space string `xml:"w:space,attr"`
-	Color string `xml:"w:color,attr"`
+	Val   string `xml:"w:val,attr" json:"val,omitempty"`
+	Sz    string `xml:"w:sz,attr" json:"sz,omitempty"`
+	Space string `xml:"w:space,attr" json:"space,omitempty"`
+	Color string `xml:"w:color,attr" json:"color,omitempty"`
 }
 
 // TblCellMargin defines table cell margins
 type TblCellMargin struct {
-	XMLName xml.Name      `xml:"w:tblCellMar"`
-	Top     *TblCellSpace `xml:"w:top,omitempty"`
-	Left    *TblCellSpace `xml:"w:left,omitempty"`
-	Bottom  *TblCellSpace `xml:"w:bottom,omitempty"`
-	Right   *TblCellSpace `xml:"w:right,omitempty"`
+	XMLName xml.Name      `xml:"w:tblCellMar" json:"-"`
+	Top     *TblCellSpace `xml:"w:top,omitempty" json:"top,omitempty"`
+	Left    *TblCellSpace `xml:"w:left,omitempty" json:"left,omitempty"`
+	Bottom  *TblCellSpace `xml:"w:bottom,omitempty" json:"bottom,omitempty"`
+	Right   *TblCellSpace `xml:"w:right,omitempty" json:"right,omitempty"`
 }
 
 // TblCellSpace specifies table cell spacing
 type TblCellSpace struct {
-	W    string `xml:"w:w,attr"`
-	Type string `xml:"w:type,attr"`
+	W    string `xml:"w:w,attr" json:"w,omitempty"`
+	Type string `xml:"w:type,attr" json:"type,omitempty"`
 }
 
 // TableRowProperties defines table row properties
 // Table row style properties to be implemented in future versions
 type TableRowProperties struct {
-	XMLName xml.Name `xml:"w:trPr"`
+	XMLName xml.Name `xml:"w:trPr" json:"-"`
 }
 
 // TableCellProperties defines table cell properties
-// Table cell style properties to be implemented in future versions
+// Note: field order must comply with OpenXML specification
 type TableCellProperties struct {
-	XMLName xml.Name `xml:"w:tcPr"`
+	XMLName   xml.Name          `xml:"w:tcPr" json:"-"`
+	TcBorders *TableCellBorders `xml:"w:tcBorders,omitempty" json:"tcBorders,omitempty"`
+	Shading   *Shading          `xml:"w:shd,omitempty" json:"shading,omitempty"`
+	VAlign    *VAlign           `xml:"w:vAlign,omitempty" json:"vAlign,omitempty"`
+}
+
+// TableCellBorders defines cell borders inside a table style
+type TableCellBorders struct {
+	XMLName xml.Name   `xml:"w:tcBorders" json:"-"`
+	Top     *TblBorder `xml:"w:top,omitempty" json:"top,omitempty"`
+	Left    *TblBorder `xml:"w:left,omitempty" json:"left,omitempty"`
+	Bottom  *TblBorder `xml:"w:bottom,omitempty" json:"bottom,omitempty"`
+	Right   *TblBorder `xml:"w:right,omitempty" json:"right,omitempty"`
+	InsideH *TblBorder `xml:"w:insideH,omitempty" json:"insideH,omitempty"`
+	InsideV *TblBorder `xml:"w:insideV,omitempty" json:"insideV,omitempty"`
+}
+
+// VAlign specifies vertical alignment of cell content
+type VAlign struct {
+	XMLName xml.Name `xml:"w:vAlign" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 // Spacing defines paragraph spacing
 type Spacing struct {
-	XMLName  xml.Name `xml:"w:spacing"`
-	Before   string   `xml:"w:before,attr,omitempty"`
-	After    string   `xml:"w:after,attr,omitempty"`
-	Line     string   `xml:"w:line,attr,omitempty"`
-	LineRule string   `xml:"w:lineRule,attr,omitempty"`
+	XMLName  xml.Name `xml:"w:spacing" json:"-"`
+	Before   string   `xml:"w:before,attr,omitempty" json:"before,omitempty"`
+	After    string   `xml:"w:after,attr,omitempty" json:"after,omitempty"`
+	Line     string   `xml:"w:line,attr,omitempty" json:"line,omitempty"`
+	LineRule string   `xml:"w:lineRule,attr,omitempty" json:"lineRule,omitempty"`
 }
 
 type Justification struct {
-	XMLName xml.Name `xml:"w:jc"`
-	Val     string   `xml:"w:val,attr"`
+	XMLName xml.Name `xml:"w:jc" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 type Indentation struct {
-	XMLName   xml.Name `xml:"w:ind"`
-	FirstLine string   `xml:"w:firstLine,attr,omitempty"`
-	Left      string   `xml:"w:left,attr,omitempty"`
-	Right     string   `xml:"w:right,attr,omitempty"`
+	XMLName   xml.Name `xml:"w:ind" json:"-"`
+	FirstLine string   `xml:"w:firstLine,attr,omitempty" json:"firstLine,omitempty"`
+	Left      string   `xml:"w:left,attr,omitempty" json:"left,omitempty"`
+	Right     string   `xml:"w:right,attr,omitempty" json:"right,omitempty"`
 }
 
 type KeepNext struct {
-	XMLName xml.Name `xml:"w:keepNext"`
+	XMLName xml.Name `xml:"w:keepNext" json:"-"`
 }
 
 type KeepLines struct {
-	XMLName xml.Name `xml:"w:keepLines"`
+	XMLName xml.Name `xml:"w:keepLines" json:"-"`
 }
 
 type PageBreak struct {
-	XMLName xml.Name `xml:"w:pageBreakBefore"`
+	XMLName xml.Name `xml:"w:pageBreakBefore" json:"-"`
+}
+
+// NumberingProperties links a paragraph style to a numbering definition in numbering.xml
+type NumberingProperties struct {
+	XMLName xml.Name `xml:"w:numPr" json:"-"`
+	ILevel  *ILevel  `xml:"w:ilvl,omitempty" json:"ilevel,omitempty"`
+	NumID   *NumID   `xml:"w:numId,omitempty" json:"numId,omitempty"`
+}
+
+// ILevel specifies the numbering level
+type ILevel struct {
+	XMLName xml.Name `xml:"w:ilvl" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
+}
+
+// NumID specifies the numbering instance ID
+type NumID struct {
+	XMLName xml.Name `xml:"w:numId" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 type OutlineLevel struct {
-	XMLName xml.Name `xml:"w:outlineLvl"`
-	Val     string   `xml:"w:val,attr"`
+	XMLName xml.Name `xml:"w:outlineLvl" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 // SnapToGrid controls grid alignment setting
 // Set to "0" to disable grid alignment, "1" to enable (complies with OOXML spec, supports only "0" or "1")
 // Note: This type is intentionally duplicated in the document package to allow independent package usage
 type SnapToGrid struct {
-	XMLName xml.Name `xml:"w:snapToGrid"`
-	Val     string   `xml:"w:val,attr,omitempty"`
+	XMLName xml.Name `xml:"w:snapToGrid" json:"-"`
+	Val     string   `xml:"w:val,attr,omitempty" json:"val,omitempty"`
 }
 
 type Bold struct {
-	XMLName xml.Name `xml:"w:b"`
+	XMLName xml.Name `xml:"w:b" json:"-"`
 }
 
 type Italic struct {
-	XMLName xml.Name `xml:"w:i"`
+	XMLName xml.Name `xml:"w:i" json:"-"`
 }
 
 type Underline struct {
-	XMLName xml.Name `xml:"w:u"`
-	Val     string   `xml:"w:val,attr,omitempty"`
+	XMLName xml.Name `xml:"w:u" json:"-"`
+	Val     string   `xml:"w:val,attr,omitempty" json:"val,omitempty"`
 }
 
 type Strike struct {
-	XMLName xml.Name `xml:"w:strike"`
+	XMLName xml.Name `xml:"w:strike" json:"-"`
 }
 
 type FontSize struct {
-	XMLName xml.Name `xml:"w:sz"`
-	Val     string   `xml:"w:val,attr"`
+	XMLName xml.Name `xml:"w:sz" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 type Color struct {
-	XMLName xml.Name `xml:"w:color"`
-	Val     string   `xml:"w:val,attr"`
+	XMLName xml.Name `xml:"w:color" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 type FontFamily struct {
-	XMLName  xml.Name `xml:"w:rFonts"`
-	ASCII    string   `xml:"w:ascii,attr,omitempty"`
-	EastAsia string   `xml:"w:eastAsia,attr,omitempty"`
-	HAnsi    string   `xml:"w:hAnsi,attr,omitempty"`
-	CS       string   `xml:"w:cs,attr,omitempty"`
+	XMLName  xml.Name `xml:"w:rFonts" json:"-"`
+	ASCII    string   `xml:"w:ascii,attr,omitempty" json:"ascii,omitempty"`
+	EastAsia string   `xml:"w:eastAsia,attr,omitempty" json:"eastAsia,omitempty"`
+	HAnsi    string   `xml:"w:hAnsi,attr,omitempty" json:"hAnsi,omitempty"`
+	CS       string   `xml:"w:cs,attr,omitempty" json:"cs,omitempty"`
 }
 
 type Highlight struct {
-	XMLName xml.Name `xml:"w:highlight"`
-	Val     string   `xml:"w:val,attr"`
+	XMLName xml.Name `xml:"w:highlight" json:"-"`
+	Val     string   `xml:"w:val,attr" json:"val,omitempty"`
 }
 
 // Styles represents a collection of styles
 type Styles struct {
-	XMLName xml.Name `xml:"w:styles"`
-	Xmlns   string   `xml:"xmlns:w,attr"`
-	Styles  []Style  `xml:"w:style"`
+	XMLName xml.Name `xml:"w:styles" json:"-"`
+	Xmlns   string   `xml:"xmlns:w,attr" json:"xmlns,omitempty"`
+	Styles  []Style  `xml:"w:style" json:"styles,omitempty"`
 }
 
 // StyleManager manages all document styles
@@ -1083,6 +1141,7 @@ func (sm *StyleManager) GetStyleWithInheritance(styleID string) *Style {
 		Name:        style.Name,
 		BasedOn:     style.BasedOn,
 		Next:        style.Next,
+		Link:        style.Link,
 		Default:     style.Default,
 		CustomStyle: style.CustomStyle,
 	}
@@ -1095,6 +1154,20 @@ func (sm *StyleManager) GetStyleWithInheritance(styleID string) *Style {
 		mergedStyle.TablePr = baseStyle.TablePr
 	}
 
+	if style.TableCellPr != nil {
+		mergedStyle.TableCellPr = style.TableCellPr
+	} else if baseStyle.TableCellPr != nil {
+		mergedStyle.TableCellPr = baseStyle.TableCellPr
+	}
+
+	// conditional formats are inherited per region, the derived style wins for regions it defines
+	mergedStyle.TableStylePr = append(mergedStyle.TableStylePr, style.TableStylePr...)
+	for _, cond := range baseStyle.TableStylePr {
+		if style.GetConditionalFormat(TableStyleOverrideType(cond.Type)) == nil {
+			mergedStyle.TableStylePr = append(mergedStyle.TableStylePr, cond)
+		}
+	}
+
 	return mergedStyle
 }
 
@@ -1156,6 +1229,12 @@ func mergeParagraphProperties(base, override *ParagraphProperties) *ParagraphPro
 		merged.PageBreak = base.PageBreak
 	}
 
+	if override.NumberingProperties != nil {
+		merged.NumberingProperties = override.NumberingProperties
+	} else if base.NumberingProperties != nil {
+		merged.NumberingProperties = base.NumberingProperties
+	}
+
 	if override.OutlineLevel != nil {
 		merged.OutlineLevel = override.OutlineLevel
 	} else if base.OutlineLevel != nil {
@@ -1247,6 +1326,41 @@ func (sm *StyleManager) CreateCustomStyle(styleID, name string, styleType StyleT
 	return style
 }
 
+// LinkStyles links a paragraph style with a character style (w:link)
+// Word uses linked styles so that applying the paragraph style to a partial selection
+// formats only the selected runs with the character counterpart
+func (sm *StyleManager) LinkStyles(paragraphStyleID, characterStyleID string) error {
+	paraStyle := sm.GetStyle(paragraphStyleID)
+	if paraStyle == nil {
+		return fmt.Errorf("style %s not found", paragraphStyleID)
+	}
+	charStyle := sm.GetStyle(characterStyleID)
+	if charStyle == nil {
+		return fmt.Errorf("style %s not found", characterStyleID)
+	}
+	if StyleType(paraStyle.Type) != StyleTypeParagraph {
+		return fmt.Errorf("style %s is not a paragraph style", paragraphStyleID)
+	}
+	if StyleType(charStyle.Type) != StyleTypeCharacter {
+		return fmt.Errorf("style %s is not a character style", characterStyleID)
+	}
+
+	paraStyle.Link = &Link{Val: characterStyleID}
+	charStyle.Link = &Link{Val: paragraphStyleID}
+	return nil
+}
+
+// CreateLinkedStyles creates a paragraph style and its linked character style
+// The character style is named "<name> Char" and shares the run properties of the paragraph style
+func (sm *StyleManager) CreateLinkedStyles(paragraphStyleID, characterStyleID, name, basedOn string) (*Style, *Style) {
+	paraStyle := sm.CreateCustomStyle(paragraphStyleID, name, StyleTypeParagraph, basedOn)
+	charStyle := sm.CreateCustomStyle(characterStyleID, name+" Char", StyleTypeCharacter, "")
+
+	paraStyle.Link = &Link{Val: characterStyleID}
+	charStyle.Link = &Link{Val: paragraphStyleID}
+	return paraStyle, charStyle
+}
+
 // RemoveStyle removes a style by its ID
 func (sm *StyleManager) RemoveStyle(styleID string) {
 	delete(sm.styles, styleID)
@@ -1296,6 +1410,10 @@ func (sm *StyleManager) cloneStyle(source *Style) *Style {
 		cloned.Next = &Next{Val: source.Next.Val}
 	}
 
+	if source.Link != nil {
+		cloned.Link = &Link{Val: source.Link.Val}
+	}
+
 	if source.ParagraphPr != nil {
 		cloned.ParagraphPr = sm.cloneParagraphProperties(source.ParagraphPr)
 	}
@@ -1316,6 +1434,17 @@ func (sm *StyleManager) cloneStyle(source *Style) *Style {
 		cloned.TableCellPr = sm.cloneTableCellProperties(source.TableCellPr)
 	}
 
+	for _, cond := range source.TableStylePr {
+		cloned.TableStylePr = append(cloned.TableStylePr, TableStyleProperties{
+			Type:        cond.Type,
+			ParagraphPr: sm.cloneParagraphProperties(cond.ParagraphPr),
+			RunPr:       sm.cloneRunProperties(cond.RunPr),
+			TablePr:     sm.cloneTableProperties(cond.TablePr),
+			TableRowPr:  sm.cloneTableRowProperties(cond.TableRowPr),
+			TableCellPr: sm.cloneTableCellProperties(cond.TableCellPr),
+		})
+	}
+
 	return cloned
 }
 
@@ -1403,6 +1532,16 @@ func (sm *StyleManager) cloneParagraphProperties(source *ParagraphProperties) *P
 		cloned.PageBreak = &PageBreak{}
 	}
 
+	if source.NumberingProperties != nil {
+		cloned.NumberingProperties = &NumberingProperties{}
+		if source.NumberingProperties.ILevel != nil {
+			cloned.NumberingProperties.ILevel = &ILevel{Val: source.NumberingProperties.ILevel.Val}
+		}
+		if source.NumberingProperties.NumID != nil {
+			cloned.NumberingProperties.NumID = &NumID{Val: source.NumberingProperties.NumID.Val}
+		}
+	}
+
 	if source.OutlineLevel != nil {
 		cloned.OutlineLevel = &OutlineLevel{
 			Val: source.OutlineLevel.Val,
@@ -1474,6 +1613,14 @@ func (sm *StyleManager) cloneTableProperties(source *TableProperties) *TableProp
 
 	cloned := &TableProperties{}
 
+	if source.RowBandSize != nil {
+		cloned.RowBandSize = &TblBandSize{Val: source.RowBandSize.Val}
+	}
+
+	if source.ColBandSize != nil {
+		cloned.ColBandSize = &TblBandSize{Val: source.ColBandSize.Val}
+	}
+
 	if source.TblInd != nil {
 		cloned.TblInd = &TblIndent{
 			W:    source.TblInd.W,
@@ -1590,9 +1737,41 @@ func (sm *StyleManager) cloneTableCellProperties(source *TableCellProperties) *T
 	}
 
 	cloned := &TableCellProperties{}
+
+	if source.TcBorders != nil {
+		cloned.TcBorders = &TableCellBorders{
+			Top:     cloneTblBorder(source.TcBorders.Top),
+			Left:    cloneTblBorder(source.TcBorders.Left),
+			Bottom:  cloneTblBorder(source.TcBorders.Bottom),
+			Right:   cloneTblBorder(source.TcBorders.Right),
+			InsideH: cloneTblBorder(source.TcBorders.InsideH),
+			InsideV: cloneTblBorder(source.TcBorders.InsideV),
+		}
+	}
+
+	if source.Shading != nil {
+		cloned.Shading = &Shading{
+			Fill: source.Shading.Fill,
+			Val:  source.Shading.Val,
+		}
+	}
+
+	if source.VAlign != nil {
+		cloned.VAlign = &VAlign{Val: source.VAlign.Val}
+	}
+
 	return cloned
 }
 
+// cloneTblBorder creates a copy of a single border definition
+func cloneTblBorder(source *TblBorder) *TblBorder {
+	if source == nil {
+		return nil
+	}
+	cloned := *source
+	return &cloned
+}
+
 // GetStylesByType retrieves all styles of a given type
 func (sm *StyleManager) GetStylesByType(styleType StyleType) []*Style {
 	var styles []*Style
@@ -1736,6 +1915,50 @@ func convertRunPropertiesToMap(props *RunProperties) map[string]interface{} {
 	return result
 }
 
+// prefixedTokenReader keeps namespace prefixes in element and attribute names
+// encoding/xml strips prefixes when decoding, so struct tags such as "w:style" would
+// otherwise never match the elements written by Word
+type prefixedTokenReader struct {
+	decoder *xml.Decoder
+}
+
+// Token returns the next raw token with prefixes folded into the local name
+func (r *prefixedTokenReader) Token() (xml.Token, error) {
+	token, err := r.decoder.RawToken()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case xml.StartElement:
+		t.Name = prefixedName(t.Name)
+		attrs := make([]xml.Attr, len(t.Attr))
+		for i, attr := range t.Attr {
+			attrs[i] = xml.Attr{Name: prefixedName(attr.Name), Value: attr.Value}
+		}
+		t.Attr = attrs
+		return t, nil
+	case xml.EndElement:
+		t.Name = prefixedName(t.Name)
+		return t, nil
+	}
+	return token, nil
+}
+
+// prefixedName folds the prefix of a raw name into its local part
+func prefixedName(name xml.Name) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+	return xml.Name{Local: name.Space + ":" + name.Local}
+}
+
+// UnmarshalPrefixedXML decodes WordprocessingML into structs tagged with "w:" prefixed names
+func UnmarshalPrefixedXML(data []byte, v interface{}) error {
+	reader := &prefixedTokenReader{decoder: xml.NewDecoder(bytes.NewReader(data))}
+	return xml.NewTokenDecoder(reader).Decode(v)
+}
+
 // ParseStylesFromXML parses styles from XML data, replacing all existing styles
 func (sm *StyleManager) ParseStylesFromXML(xmlData []byte) error {
 	type stylesXML struct {
@@ -1744,7 +1967,7 @@ func (sm *StyleManager) ParseStylesFromXML(xmlData []byte) error {
 	}
 
 	var styles stylesXML
-	if err := xml.Unmarshal(xmlData, &styles); err != nil {
+	if err := UnmarshalPrefixedXML(xmlData, &styles); err != nil {
 		return fmt.Errorf("failed to parse styles XML: %v", err)
 	}
 
@@ -1765,7 +1988,7 @@ func (sm *StyleManager) MergeStylesFromXML(xmlData []byte) error {
 	}
 
 	var styles stylesXML
-	if err := xml.Unmarshal(xmlData, &styles); err != nil {
+	if err := UnmarshalPrefixedXML(xmlData, &styles); err != nil {
 		return fmt.Errorf("failed to parse styles XML: %v", err)
 	}
 
@@ -1794,11 +2017,12 @@ func (sm *StyleManager) LoadStylesFromDocument(xmlData []byte) error {
 		sm.addNormalStyle()
 	}
 
-	headingStyles := []string{"Heading1", "Heading2", "Heading3", "Heading4", "Heading5", "Heading6", "Heading7", "Heading8", "Heading9"}
-	for _, styleID := range headingStyles {
+	// only the missing heading styles are added, the headings of the document are kept
+	predefined := &StyleManager{styles: make(map[string]*Style)}
+	predefined.addHeadingStyles()
+	for styleID, heading := range predefined.styles {
 		if !sm.StyleExists(styleID) {
-			sm.addHeadingStyles()
-			break
+			sm.AddStyle(heading)
 		}
 	}
 
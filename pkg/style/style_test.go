@@ -185,6 +185,39 @@ func TestGetStyleWithInheritance(t *testing.T) {
 	}
 }
 
+// TestLinkStyles tests linking paragraph and character styles
+func TestLinkStyles(t *testing.T) {
+	sm := NewStyleManager()
+
+	para, char := sm.CreateLinkedStyles("Callout", "CalloutChar", "Callout", "Normal")
+	if para.Link == nil || para.Link.Val != "CalloutChar" {
+		t.Errorf("paragraph style should link to CalloutChar, got %v", para.Link)
+	}
+	if char.Link == nil || char.Link.Val != "Callout" {
+		t.Errorf("character style should link to Callout, got %v", char.Link)
+	}
+	if StyleType(char.Type) != StyleTypeCharacter {
+		t.Errorf("expected character style, got %s", char.Type)
+	}
+	if char.Name.Val != "Callout Char" {
+		t.Errorf("expected name 'Callout Char', got %s", char.Name.Val)
+	}
+
+	// link type validation
+	if err := sm.LinkStyles("CalloutChar", "Callout"); err == nil {
+		t.Error("linking with swapped style types should fail")
+	}
+	if err := sm.LinkStyles("Callout", "Missing"); err == nil {
+		t.Error("linking a missing style should fail")
+	}
+
+	// link survives cloning
+	cloned := sm.Clone()
+	if cloned.GetStyle("Callout").Link == nil {
+		t.Error("cloned style should keep its link")
+	}
+}
+
 // TestQuickStyleAPI 测试快速API功能
 func TestQuickStyleAPI(t *testing.T) {
 	sm := NewStyleManager()
@@ -0,0 +1,139 @@
+// Package style provides style sheet import and export support
+package style
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// StyleSheet is the portable representation of a set of styles
+// JSON output is also valid YAML, so exported style sheets can be kept in either format
+type StyleSheet struct {
+	Styles []*Style `json:"styles"`
+}
+
+// ExportJSON exports all styles as an indented JSON style sheet, sorted by style ID
+func (sm *StyleManager) ExportJSON() ([]byte, error) {
+	sheet := StyleSheet{Styles: sm.GetAllStyles()}
+	sort.Slice(sheet.Styles, func(i, j int) bool {
+		return sheet.Styles[i].StyleID < sheet.Styles[j].StyleID
+	})
+
+	data, err := json.MarshalIndent(sheet, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to export styles: %v", err)
+	}
+	return data, nil
+}
+
+// ImportJSON imports styles from a JSON style sheet
+// Existing styles with the same ID are replaced only when overwrite is true
+// The style sheet is validated as a whole before any style is added
+func (sm *StyleManager) ImportJSON(data []byte, overwrite bool) error {
+	var sheet StyleSheet
+	if err := json.Unmarshal(data, &sheet); err != nil {
+		return fmt.Errorf("failed to parse style sheet: %v", err)
+	}
+
+	for i, s := range sheet.Styles {
+		if s == nil || s.StyleID == "" {
+			return fmt.Errorf("style %d in style sheet has no style ID", i)
+		}
+		if !isValidStyleType(s.Type) {
+			return fmt.Errorf("style %s has unknown type %q", s.StyleID, s.Type)
+		}
+	}
+
+	for _, s := range sheet.Styles {
+		if sm.StyleExists(s.StyleID) && !overwrite {
+			continue
+		}
+		sm.AddStyle(s)
+	}
+
+	return nil
+}
+
+// ImportStyles copies styles from another style manager
+// Each requested style is copied together with the styles it depends on: its basedOn chain,
+// its next paragraph style and its linked style. When styleIDs is empty all styles are copied.
+// Existing styles are replaced only when overwrite is true. The IDs of the copied styles are
+// returned in dependency order, parents first.
+func (sm *StyleManager) ImportStyles(source *StyleManager, styleIDs []string, overwrite bool) ([]string, error) {
+	if source == nil {
+		return nil, fmt.Errorf("source style manager cannot be nil")
+	}
+
+	if len(styleIDs) == 0 {
+		for _, s := range source.GetAllStyles() {
+			styleIDs = append(styleIDs, s.StyleID)
+		}
+		sort.Strings(styleIDs)
+	}
+
+	var ordered []string
+	visited := make(map[string]bool)
+	var visit func(styleID string) error
+	visit = func(styleID string) error {
+		if visited[styleID] {
+			return nil
+		}
+		visited[styleID] = true
+
+		s := source.GetStyle(styleID)
+		if s == nil {
+			return fmt.Errorf("style %s not found in source", styleID)
+		}
+
+		if s.BasedOn != nil && s.BasedOn.Val != "" {
+			if err := visit(s.BasedOn.Val); err != nil {
+				return err
+			}
+		}
+		ordered = append(ordered, styleID)
+
+		// next and linked styles only need to exist, they may reference this style in turn
+		var refs []string
+		if s.Next != nil {
+			refs = append(refs, s.Next.Val)
+		}
+		if s.Link != nil {
+			refs = append(refs, s.Link.Val)
+		}
+		for _, ref := range refs {
+			if ref != "" && source.StyleExists(ref) {
+				if err := visit(ref); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, styleID := range styleIDs {
+		if err := visit(styleID); err != nil {
+			return nil, err
+		}
+	}
+
+	var imported []string
+	for _, styleID := range ordered {
+		if sm.StyleExists(styleID) && !overwrite {
+			continue
+		}
+		sm.AddStyle(sm.cloneStyle(source.GetStyle(styleID)))
+		imported = append(imported, styleID)
+	}
+
+	return imported, nil
+}
+
+// isValidStyleType reports whether styleType is a known style type
+func isValidStyleType(styleType string) bool {
+	switch StyleType(styleType) {
+	case StyleTypeParagraph, StyleTypeCharacter, StyleTypeTable, StyleTypeNumbering:
+		return true
+	}
+	return false
+}
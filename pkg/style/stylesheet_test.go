@@ -0,0 +1,209 @@
+package style
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestExportImportJSON tests a style sheet JSON round trip
+func TestExportImportJSON(t *testing.T) {
+	sm := NewStyleManager()
+	api := NewQuickStyleAPI(sm)
+	if _, err := api.CreateQuickStyle(QuickStyleConfig{
+		ID:      "Quote2",
+		Name:    "Quote 2",
+		Type:    StyleTypeParagraph,
+		BasedOn: "Normal",
+		RunConfig: &QuickRunConfig{
+			Italic:    true,
+			FontColor: "555555",
+		},
+	}); err != nil {
+		t.Fatalf("failed to create style: %v", err)
+	}
+
+	data, err := sm.ExportJSON()
+	if err != nil {
+		t.Fatalf("failed to export styles: %v", err)
+	}
+	if !strings.Contains(string(data), `"styleId": "Quote2"`) {
+		t.Errorf("exported JSON should contain the custom style: %s", data)
+	}
+
+	target := &StyleManager{styles: make(map[string]*Style)}
+	if err := target.ImportJSON(data, false); err != nil {
+		t.Fatalf("failed to import styles: %v", err)
+	}
+	if len(target.GetAllStyles()) != len(sm.GetAllStyles()) {
+		t.Errorf("expected %d styles, got %d", len(sm.GetAllStyles()), len(target.GetAllStyles()))
+	}
+
+	quote := target.GetStyle("Quote2")
+	if quote == nil || quote.BasedOn == nil || quote.BasedOn.Val != "Normal" {
+		t.Fatal("imported style should keep its basedOn reference")
+	}
+	if quote.RunPr == nil || quote.RunPr.Italic == nil || quote.RunPr.Color == nil || quote.RunPr.Color.Val != "555555" {
+		t.Error("imported style should keep its run properties")
+	}
+}
+
+// TestImportJSONOverwrite tests that existing styles are only replaced on request
+func TestImportJSONOverwrite(t *testing.T) {
+	sm := NewStyleManager()
+	sheet := []byte(`{"styles":[{"type":"paragraph","styleId":"Normal","name":{"val":"Imported"}}]}`)
+
+	if err := sm.ImportJSON(sheet, false); err != nil {
+		t.Fatalf("failed to import styles: %v", err)
+	}
+	if sm.GetStyle("Normal").Name.Val != "Normal" {
+		t.Error("existing style should be kept without overwrite")
+	}
+
+	if err := sm.ImportJSON(sheet, true); err != nil {
+		t.Fatalf("failed to import styles: %v", err)
+	}
+	if sm.GetStyle("Normal").Name.Val != "Imported" {
+		t.Error("existing style should be replaced with overwrite")
+	}
+
+	invalid := []string{
+		`{"styles":[{"type":"paragraph"}]}`,
+		`{"styles":[{"type":"unknown","styleId":"X"}]}`,
+		`not json`,
+	}
+	for _, data := range invalid {
+		if err := sm.ImportJSON([]byte(data), true); err == nil {
+			t.Errorf("expected error for style sheet %s", data)
+		}
+	}
+}
+
+// TestImportStyles tests copying styles together with their dependencies
+func TestImportStyles(t *testing.T) {
+	source := NewStyleManager()
+	source.CreateCustomStyle("Base", "Base", StyleTypeParagraph, "Normal")
+	child := source.CreateCustomStyle("Child", "Child", StyleTypeParagraph, "Base")
+	child.Next = &Next{Val: "Follow"}
+	source.CreateCustomStyle("Follow", "Follow", StyleTypeParagraph, "Normal")
+	source.CreateLinkedStyles("Callout", "CalloutChar", "Callout", "Normal")
+	source.GetStyle("Child").Link = &Link{Val: "CalloutChar"}
+
+	target := NewStyleManager()
+	imported, err := target.ImportStyles(source, []string{"Child"}, false)
+	if err != nil {
+		t.Fatalf("failed to import styles: %v", err)
+	}
+
+	for _, styleID := range []string{"Base", "Child", "Follow", "CalloutChar", "Callout"} {
+		if !target.StyleExists(styleID) {
+			t.Errorf("style %s should have been imported", styleID)
+		}
+	}
+	for _, styleID := range imported {
+		if styleID == "Normal" {
+			t.Error("existing Normal style should not be imported without overwrite")
+		}
+	}
+	if imported[0] != "Base" || imported[1] != "Child" {
+		t.Errorf("parents should be imported first, got %v", imported)
+	}
+
+	// imported styles are copies
+	source.GetStyle("Child").Name.Val = "Changed"
+	if target.GetStyle("Child").Name.Val != "Child" {
+		t.Error("imported style should not share data with the source")
+	}
+
+	if _, err := target.ImportStyles(source, []string{"Missing"}, false); err == nil {
+		t.Error("expected error for missing style")
+	}
+}
+
+// TestParseStylesFromNamespacedXML tests parsing styles.xml as written by Word
+func TestParseStylesFromNamespacedXML(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:docDefaults><w:rPrDefault><w:rPr><w:sz w:val="21"/></w:rPr></w:rPrDefault></w:docDefaults>
+  <w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>
+  <w:style w:type="paragraph" w:styleId="ListHeading">
+    <w:name w:val="List Heading"/>
+    <w:basedOn w:val="Normal"/>
+    <w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr></w:pPr>
+    <w:rPr><w:b/><w:color w:val="FF0000"/></w:rPr>
+  </w:style>
+</w:styles>`)
+
+	sm := NewStyleManager()
+	if err := sm.ParseStylesFromXML(data); err != nil {
+		t.Fatalf("failed to parse styles: %v", err)
+	}
+
+	if len(sm.GetAllStyles()) != 2 {
+		t.Fatalf("expected 2 styles, got %d", len(sm.GetAllStyles()))
+	}
+
+	heading := sm.GetStyle("ListHeading")
+	if heading == nil || heading.Name.Val != "List Heading" || heading.BasedOn.Val != "Normal" {
+		t.Fatal("style attributes should be parsed")
+	}
+	if heading.RunPr == nil || heading.RunPr.Bold == nil || heading.RunPr.Color.Val != "FF0000" {
+		t.Error("run properties should be parsed")
+	}
+	numPr := heading.ParagraphPr.NumberingProperties
+	if numPr == nil || numPr.NumID == nil || numPr.NumID.Val != "3" || numPr.ILevel.Val != "0" {
+		t.Error("numbering properties should be parsed")
+	}
+	if !sm.GetStyle("Normal").Default {
+		t.Error("default flag should be parsed")
+	}
+}
+
+// TestMergeStylesFromNamespacedXML tests that merging keeps existing styles and adds new ones
+func TestMergeStylesFromNamespacedXML(t *testing.T) {
+	data := []byte(`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:styleId="Normal"><w:name w:val="Body"/></w:style>
+  <w:style w:type="character" w:styleId="Code"><w:name w:val="Code"/><w:rPr><w:rFonts w:ascii="Consolas"/></w:rPr></w:style>
+</w:styles>`)
+
+	sm := NewStyleManager()
+	count := len(sm.GetAllStyles())
+	if err := sm.MergeStylesFromXML(data); err != nil {
+		t.Fatalf("failed to merge styles: %v", err)
+	}
+
+	if len(sm.GetAllStyles()) != count+1 {
+		t.Errorf("expected %d styles, got %d", count+1, len(sm.GetAllStyles()))
+	}
+	if name := sm.GetStyle("Normal").Name.Val; name != "Normal" {
+		t.Errorf("existing style should be kept, got name %q", name)
+	}
+	code := sm.GetStyle("Code")
+	if code == nil || code.Type != string(StyleTypeCharacter) || code.RunPr.FontFamily.ASCII != "Consolas" {
+		t.Error("new style should be merged with its properties")
+	}
+
+	if err := sm.MergeStylesFromXML([]byte(`<w:styles><w:style>`)); err == nil {
+		t.Error("expected error for malformed XML")
+	}
+}
+
+// TestLoadStylesFromDocument tests loading styles.xml and filling in missing built-in styles
+func TestLoadStylesFromDocument(t *testing.T) {
+	data := []byte(`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/><w:rPr><w:i/></w:rPr></w:style>
+</w:styles>`)
+
+	sm := NewStyleManager()
+	if err := sm.LoadStylesFromDocument(data); err != nil {
+		t.Fatalf("failed to load styles: %v", err)
+	}
+	if quote := sm.GetStyle("Quote"); quote == nil || quote.RunPr == nil || quote.RunPr.Italic == nil {
+		t.Error("styles of the document should be loaded")
+	}
+	if !sm.StyleExists("Normal") || !sm.StyleExists("Heading1") {
+		t.Error("missing built-in styles should be added")
+	}
+	if sm.StyleExists("Title") {
+		t.Error("predefined styles not in the document should not be loaded")
+	}
+}
@@ -0,0 +1,237 @@
+// Package style provides table style and conditional formatting support
+package style
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// TableStyleOverrideType identifies the table region a conditional format applies to
+type TableStyleOverrideType string
+
+const (
+	TableRegionWholeTable  TableStyleOverrideType = "wholeTable"
+	TableRegionFirstRow    TableStyleOverrideType = "firstRow"
+	TableRegionLastRow     TableStyleOverrideType = "lastRow"
+	TableRegionFirstColumn TableStyleOverrideType = "firstCol"
+	TableRegionLastColumn  TableStyleOverrideType = "lastCol"
+	TableRegionBand1Vert   TableStyleOverrideType = "band1Vert" // odd column bands
+	TableRegionBand2Vert   TableStyleOverrideType = "band2Vert" // even column bands
+	TableRegionBand1Horz   TableStyleOverrideType = "band1Horz" // odd row bands
+	TableRegionBand2Horz   TableStyleOverrideType = "band2Horz" // even row bands
+	TableRegionNECell      TableStyleOverrideType = "neCell"    // top right corner
+	TableRegionNWCell      TableStyleOverrideType = "nwCell"    // top left corner
+	TableRegionSECell      TableStyleOverrideType = "seCell"    // bottom right corner
+	TableRegionSWCell      TableStyleOverrideType = "swCell"    // bottom left corner
+)
+
+// tableRegionOrder is the order Word writes conditional formats in
+var tableRegionOrder = []TableStyleOverrideType{
+	TableRegionWholeTable,
+	TableRegionBand1Vert,
+	TableRegionBand2Vert,
+	TableRegionBand1Horz,
+	TableRegionBand2Horz,
+	TableRegionFirstRow,
+	TableRegionLastRow,
+	TableRegionFirstColumn,
+	TableRegionLastColumn,
+	TableRegionNECell,
+	TableRegionNWCell,
+	TableRegionSECell,
+	TableRegionSWCell,
+}
+
+// TableStyleProperties defines conditional formatting for one region of a table style (w:tblStylePr)
+type TableStyleProperties struct {
+	XMLName     xml.Name             `xml:"w:tblStylePr" json:"-"`
+	Type        string               `xml:"w:type,attr" json:"type,omitempty"`
+	ParagraphPr *ParagraphProperties `xml:"w:pPr,omitempty" json:"paragraphPr,omitempty"`
+	RunPr       *RunProperties       `xml:"w:rPr,omitempty" json:"runPr,omitempty"`
+	TablePr     *TableProperties     `xml:"w:tblPr,omitempty" json:"tablePr,omitempty"`
+	TableRowPr  *TableRowProperties  `xml:"w:trPr,omitempty" json:"tableRowPr,omitempty"`
+	TableCellPr *TableCellProperties `xml:"w:tcPr,omitempty" json:"tableCellPr,omitempty"`
+}
+
+// GetConditionalFormat returns the conditional format for a table region, or nil if none is defined
+func (s *Style) GetConditionalFormat(region TableStyleOverrideType) *TableStyleProperties {
+	for i := range s.TableStylePr {
+		if s.TableStylePr[i].Type == string(region) {
+			return &s.TableStylePr[i]
+		}
+	}
+	return nil
+}
+
+// SetConditionalFormat sets the conditional format for a table region, replacing any existing one
+// Conditional formats are kept in the order Word writes them
+func (s *Style) SetConditionalFormat(region TableStyleOverrideType, props *TableStyleProperties) {
+	s.RemoveConditionalFormat(region)
+	if props == nil {
+		return
+	}
+
+	props.Type = string(region)
+	s.TableStylePr = append(s.TableStylePr, *props)
+
+	ordered := make([]TableStyleProperties, 0, len(s.TableStylePr))
+	for _, r := range tableRegionOrder {
+		if cond := s.GetConditionalFormat(r); cond != nil {
+			ordered = append(ordered, *cond)
+		}
+	}
+	s.TableStylePr = ordered
+}
+
+// RemoveConditionalFormat removes the conditional format for a table region
+func (s *Style) RemoveConditionalFormat(region TableStyleOverrideType) {
+	filtered := s.TableStylePr[:0]
+	for _, cond := range s.TableStylePr {
+		if cond.Type != string(region) {
+			filtered = append(filtered, cond)
+		}
+	}
+	s.TableStylePr = filtered
+}
+
+// CreateTableStyle creates a custom table style
+// Table styles are referenced from tables through TableStyleConfig.StyleID in the document package
+func (sm *StyleManager) CreateTableStyle(styleID, name, basedOn string) *Style {
+	tableStyle := sm.CreateCustomStyle(styleID, name, StyleTypeTable, basedOn)
+	tableStyle.TablePr = &TableProperties{
+		RowBandSize: &TblBandSize{Val: "1"},
+		ColBandSize: &TblBandSize{Val: "1"},
+	}
+	return tableStyle
+}
+
+// QuickTableStyleConfig defines quick table style configuration
+type QuickTableStyleConfig struct {
+	ID          string                                             `json:"id"`
+	Name        string                                             `json:"name"`
+	BasedOn     string                                             `json:"basedOn,omitempty"`
+	RowBandSize int                                                `json:"rowBandSize,omitempty"` // rows per band, default 1
+	ColBandSize int                                                `json:"colBandSize,omitempty"` // columns per band, default 1
+	Borders     *QuickBorderConfig                                 `json:"borders,omitempty"`     // applied to all table borders
+	Regions     map[TableStyleOverrideType]*QuickTableRegionConfig `json:"regions,omitempty"`
+}
+
+// QuickBorderConfig defines quick border configuration
+type QuickBorderConfig struct {
+	Style string `json:"style,omitempty"` // single, double, dotted, etc., default single
+	Size  int    `json:"size,omitempty"`  // in eighths of a point, default 4
+	Color string `json:"color,omitempty"` // hex color code, default auto
+}
+
+// QuickTableRegionConfig defines formatting for one conditional region of a table style
+type QuickTableRegionConfig struct {
+	ParagraphConfig *QuickParagraphConfig `json:"paragraphConfig,omitempty"`
+	RunConfig       *QuickRunConfig       `json:"runConfig,omitempty"`
+	Shading         string                `json:"shading,omitempty"`      // cell fill color, hex color code
+	BottomBorder    *QuickBorderConfig    `json:"bottomBorder,omitempty"` // e.g. the line under a header row
+	TopBorder       *QuickBorderConfig    `json:"topBorder,omitempty"`    // e.g. the line above a total row
+}
+
+// CreateQuickTableStyle creates a new table style with conditional formats from configuration
+func (api *QuickStyleAPI) CreateQuickTableStyle(config QuickTableStyleConfig) (*Style, error) {
+	if config.ID == "" {
+		return nil, fmt.Errorf("table style ID cannot be empty")
+	}
+	if api.styleManager.StyleExists(config.ID) {
+		return nil, fmt.Errorf("style ID %s already exists", config.ID)
+	}
+
+	tableStyle := api.styleManager.CreateTableStyle(config.ID, config.Name, config.BasedOn)
+
+	if config.RowBandSize > 0 {
+		tableStyle.TablePr.RowBandSize.Val = fmt.Sprintf("%d", config.RowBandSize)
+	}
+	if config.ColBandSize > 0 {
+		tableStyle.TablePr.ColBandSize.Val = fmt.Sprintf("%d", config.ColBandSize)
+	}
+
+	if config.Borders != nil {
+		tableStyle.TablePr.TblBorders = &TblBorders{
+			Top:     createTblBorder(config.Borders),
+			Left:    createTblBorder(config.Borders),
+			Bottom:  createTblBorder(config.Borders),
+			Right:   createTblBorder(config.Borders),
+			InsideH: createTblBorder(config.Borders),
+			InsideV: createTblBorder(config.Borders),
+		}
+	}
+
+	for region, regionConfig := range config.Regions {
+		if regionConfig == nil {
+			continue
+		}
+		if !isValidTableRegion(region) {
+			api.styleManager.RemoveStyle(config.ID)
+			return nil, fmt.Errorf("unknown table style region %s", region)
+		}
+		tableStyle.SetConditionalFormat(region, createTableStyleProperties(regionConfig))
+	}
+
+	return tableStyle, nil
+}
+
+// isValidTableRegion reports whether region is a known conditional format type
+func isValidTableRegion(region TableStyleOverrideType) bool {
+	for _, r := range tableRegionOrder {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// createTableStyleProperties creates conditional format properties from configuration
+func createTableStyleProperties(config *QuickTableRegionConfig) *TableStyleProperties {
+	props := &TableStyleProperties{}
+
+	if config.ParagraphConfig != nil {
+		props.ParagraphPr = createParagraphProperties(config.ParagraphConfig)
+	}
+
+	if config.RunConfig != nil {
+		props.RunPr = createRunProperties(config.RunConfig)
+	}
+
+	if config.Shading != "" || config.TopBorder != nil || config.BottomBorder != nil {
+		props.TableCellPr = &TableCellProperties{}
+		if config.Shading != "" {
+			props.TableCellPr.Shading = &Shading{Val: "clear", Fill: config.Shading}
+		}
+		if config.TopBorder != nil || config.BottomBorder != nil {
+			props.TableCellPr.TcBorders = &TableCellBorders{}
+			if config.TopBorder != nil {
+				props.TableCellPr.TcBorders.Top = createTblBorder(config.TopBorder)
+			}
+			if config.BottomBorder != nil {
+				props.TableCellPr.TcBorders.Bottom = createTblBorder(config.BottomBorder)
+			}
+		}
+	}
+
+	return props
+}
+
+// createTblBorder creates a border definition from configuration, filling in defaults
+func createTblBorder(config *QuickBorderConfig) *TblBorder {
+	border := &TblBorder{
+		Val:   "single",
+		Sz:    "4",
+		Space: "0",
+		Color: "auto",
+	}
+	if config.Style != "" {
+		border.Val = config.Style
+	}
+	if config.Size > 0 {
+		border.Sz = fmt.Sprintf("%d", config.Size)
+	}
+	if config.Color != "" {
+		border.Color = config.Color
+	}
+	return border
+}
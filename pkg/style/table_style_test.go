@@ -0,0 +1,145 @@
+package style
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestCreateQuickTableStyle(t *testing.T) {
+	sm := NewStyleManager()
+	api := NewQuickStyleAPI(sm)
+
+	config := QuickTableStyleConfig{
+		ID:      "InvoiceTable",
+		Name:    "Invoice Table",
+		Borders: &QuickBorderConfig{Color: "BFBFBF"},
+		Regions: map[TableStyleOverrideType]*QuickTableRegionConfig{
+			TableRegionLastRow: {
+				RunConfig: &QuickRunConfig{Bold: true},
+				TopBorder: &QuickBorderConfig{Style: "double", Size: 6},
+			},
+			TableRegionFirstRow: {
+				RunConfig: &QuickRunConfig{Bold: true, FontColor: "FFFFFF"},
+				Shading:   "1F3864",
+			},
+			TableRegionBand1Horz: {
+				Shading: "F2F2F2",
+			},
+		},
+	}
+
+	tableStyle, err := api.CreateQuickTableStyle(config)
+	if err != nil {
+		t.Fatalf("failed to create table style: %v", err)
+	}
+
+	if StyleType(tableStyle.Type) != StyleTypeTable {
+		t.Errorf("expected table style, got %s", tableStyle.Type)
+	}
+	if tableStyle.TablePr == nil || tableStyle.TablePr.TblBorders == nil {
+		t.Fatal("table style should have borders")
+	}
+	if tableStyle.TablePr.TblBorders.InsideH.Color != "BFBFBF" {
+		t.Errorf("expected border color BFBFBF, got %s", tableStyle.TablePr.TblBorders.InsideH.Color)
+	}
+
+	// conditional formats are written in Word's canonical order
+	var order []string
+	for _, cond := range tableStyle.TableStylePr {
+		order = append(order, cond.Type)
+	}
+	if strings.Join(order, ",") != "band1Horz,firstRow,lastRow" {
+		t.Errorf("unexpected conditional format order: %v", order)
+	}
+
+	header := tableStyle.GetConditionalFormat(TableRegionFirstRow)
+	if header == nil || header.TableCellPr == nil || header.TableCellPr.Shading.Fill != "1F3864" {
+		t.Error("first row shading not set")
+	}
+	total := tableStyle.GetConditionalFormat(TableRegionLastRow)
+	if total == nil || total.TableCellPr.TcBorders.Top.Val != "double" {
+		t.Error("last row top border not set")
+	}
+
+	if _, err := api.CreateQuickTableStyle(config); err == nil {
+		t.Error("expected error when creating duplicate table style")
+	}
+}
+
+func TestCreateQuickTableStyleInvalidRegion(t *testing.T) {
+	sm := NewStyleManager()
+	api := NewQuickStyleAPI(sm)
+
+	_, err := api.CreateQuickTableStyle(QuickTableStyleConfig{
+		ID:   "Broken",
+		Name: "Broken",
+		Regions: map[TableStyleOverrideType]*QuickTableRegionConfig{
+			"middleRow": {Shading: "FF0000"},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error for unknown region")
+	}
+	if sm.StyleExists("Broken") {
+		t.Error("failed table style should not be registered")
+	}
+}
+
+func TestTableStyleConditionalFormatXML(t *testing.T) {
+	sm := NewStyleManager()
+	tableStyle := sm.CreateTableStyle("Banded", "Banded", "")
+	tableStyle.SetConditionalFormat(TableRegionNWCell, &TableStyleProperties{
+		RunPr: &RunProperties{Bold: &Bold{}},
+	})
+	tableStyle.SetConditionalFormat(TableRegionFirstRow, &TableStyleProperties{
+		RunPr: &RunProperties{Italic: &Italic{}},
+	})
+
+	data, err := xml.Marshal(tableStyle)
+	if err != nil {
+		t.Fatalf("failed to marshal table style: %v", err)
+	}
+	xmlStr := string(data)
+
+	for _, expected := range []string{
+		`<w:tblStyleRowBandSize w:val="1">`,
+		`<w:tblStylePr w:type="firstRow">`,
+		`<w:tblStylePr w:type="nwCell">`,
+	} {
+		if !strings.Contains(xmlStr, expected) {
+			t.Errorf("expected %s in %s", expected, xmlStr)
+		}
+	}
+	if strings.Index(xmlStr, `w:type="firstRow"`) > strings.Index(xmlStr, `w:type="nwCell"`) {
+		t.Error("firstRow should be written before nwCell")
+	}
+
+	// replacing a region keeps a single entry
+	tableStyle.SetConditionalFormat(TableRegionFirstRow, &TableStyleProperties{})
+	if len(tableStyle.TableStylePr) != 2 {
+		t.Errorf("expected 2 conditional formats, got %d", len(tableStyle.TableStylePr))
+	}
+
+}
+
+func TestTableStyleInheritance(t *testing.T) {
+	sm := NewStyleManager()
+	base := sm.CreateTableStyle("BaseTable", "Base Table", "")
+	base.SetConditionalFormat(TableRegionFirstRow, &TableStyleProperties{RunPr: &RunProperties{Bold: &Bold{}}})
+	base.SetConditionalFormat(TableRegionLastRow, &TableStyleProperties{RunPr: &RunProperties{Bold: &Bold{}}})
+
+	derived := sm.CreateTableStyle("DerivedTable", "Derived Table", "BaseTable")
+	derived.SetConditionalFormat(TableRegionFirstRow, &TableStyleProperties{RunPr: &RunProperties{Italic: &Italic{}}})
+
+	merged := sm.GetStyleWithInheritance("DerivedTable")
+	if len(merged.TableStylePr) != 2 {
+		t.Fatalf("expected 2 conditional formats, got %d", len(merged.TableStylePr))
+	}
+	if merged.GetConditionalFormat(TableRegionFirstRow).RunPr.Italic == nil {
+		t.Error("derived style should override firstRow")
+	}
+	if merged.GetConditionalFormat(TableRegionLastRow) == nil {
+		t.Error("lastRow should be inherited from the base style")
+	}
+}